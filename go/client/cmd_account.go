@@ -0,0 +1,21 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+func NewCmdAccount(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "account",
+		Usage: "Move this device's account state to another machine",
+		Subcommands: []cli.Command{
+			NewCmdAccountExport(cl, g),
+			NewCmdAccountImport(cl, g),
+		},
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+)
+
+// CmdAccountExport is the 'account export' command. It writes the
+// current user's local state on this device to an encrypted archive.
+type CmdAccountExport struct {
+	libkb.Contextified
+	filename string
+}
+
+const cmdAccountExportDesc = `Write this device's secret keys, config file entry, local tracks
+and favorites to an archive, encrypted with a passphrase that you pick.
+Use 'keybase account import' on the new machine to restore it there.
+
+This is for moving a device to a new machine when the old one is being
+retired. Don't keep using this device on both machines.`
+
+// NewCmdAccountExport creates a new cli.Command.
+func NewCmdAccountExport(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "export",
+		ArgumentHelp: "<file>",
+		Usage:        "Export this device's account state to an encrypted archive",
+		Description:  cmdAccountExportDesc,
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdAccountExport{Contextified: libkb.NewContextified(g)}, "export", c)
+		},
+	}
+}
+
+// ParseArgv gets the archive filename.
+func (c *CmdAccountExport) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return fmt.Errorf("account export takes one argument: <file>")
+	}
+	c.filename = ctx.Args()[0]
+	return nil
+}
+
+// Run runs the command in client/server mode.
+func (c *CmdAccountExport) Run() error {
+	protocols := []rpc.Protocol{
+		NewSecretUIProtocol(c.G()),
+	}
+	if err := RegisterProtocolsWithContext(protocols, c.G()); err != nil {
+		return err
+	}
+	cli, err := GetAccountClient(c.G())
+	if err != nil {
+		return err
+	}
+
+	// Don't ask for a passphrase only to fail to write the file.
+	f, err := os.OpenFile(c.filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	passphrase, err := PromptArchivePassphrase(c.G(), true)
+	if err == nil {
+		var sealed []byte
+		sealed, err = cli.AccountExport(context.TODO(), keybase1.AccountExportArg{Passphrase: passphrase})
+		if err == nil {
+			_, err = f.Write(sealed)
+		}
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(c.filename)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	c.G().Log.Info("Wrote the account archive to %s.", c.filename)
+	return nil
+}
+
+// GetUsage says what this command needs to operate.
+func (c *CmdAccountExport) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config:    true,
+		KbKeyring: true,
+		API:       true,
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+)
+
+// CmdAccountImport is the 'account import' command. It restores an
+// archive made by 'account export'.
+type CmdAccountImport struct {
+	libkb.Contextified
+	filename string
+	force    bool
+}
+
+const cmdAccountImportDesc = `Restore an archive made by 'keybase account export' on another
+machine. Any current session is logged out, and then you log in again
+as the archived user, with the archived device.`
+
+// NewCmdAccountImport creates a new cli.Command.
+func NewCmdAccountImport(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "import",
+		ArgumentHelp: "<file>",
+		Usage:        "Restore an account archive onto this machine",
+		Description:  cmdAccountImportDesc,
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "force",
+				Usage: "Replace the user's device and secret keys if they're already on this machine.",
+			},
+		},
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdAccountImport{Contextified: libkb.NewContextified(g)}, "import", c)
+		},
+	}
+}
+
+// ParseArgv gets the archive filename.
+func (c *CmdAccountImport) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return fmt.Errorf("account import takes one argument: <file>")
+	}
+	c.filename = ctx.Args()[0]
+	c.force = ctx.Bool("force")
+	return nil
+}
+
+// Run runs the command in client/server mode.
+func (c *CmdAccountImport) Run() error {
+	sealed, err := ioutil.ReadFile(c.filename)
+	if err != nil {
+		return err
+	}
+
+	protocols := []rpc.Protocol{
+		NewLoginUIProtocol(c.G()),
+		NewSecretUIProtocol(c.G()),
+	}
+	if err := RegisterProtocolsWithContext(protocols, c.G()); err != nil {
+		return err
+	}
+	cli, err := GetAccountClient(c.G())
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := PromptArchivePassphrase(c.G(), false)
+	if err != nil {
+		return err
+	}
+	err = cli.AccountImport(context.TODO(), keybase1.AccountImportArg{
+		Archive:    sealed,
+		Passphrase: passphrase,
+		Force:      c.force,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.G().Log.Info("Restored the account archive, and logged in.")
+	return nil
+}
+
+// GetUsage says what this command needs to operate.
+func (c *CmdAccountImport) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config:    true,
+		KbKeyring: true,
+		API:       true,
+	}
+}
@@ -87,6 +87,25 @@ func (c *CmdDecrypt) explainDecryptionFailure(info *keybase1.SaltpackEncryptedMe
 	}
 }
 
+// reportSigncryptedSender tells the user who signed a signcrypted
+// message, once all of its signatures have checked out.
+func (c *CmdDecrypt) reportSigncryptedSender(info *keybase1.SaltpackEncryptedMessageInfo) {
+	if info.SigningKID.IsNil() {
+		return
+	}
+	out := c.G().UI.GetTerminalUI().ErrorWriter()
+	var un string
+	switch info.Sender.SenderType {
+	case keybase1.SaltpackSenderType_UNKNOWN:
+		un = fmt.Sprintf("The signer of this message is unknown to Keybase (signing key ID: %s)", info.SigningKID)
+	case keybase1.SaltpackSenderType_SELF:
+		un = fmt.Sprintf("Signed by %s (you)", ColorString("bold", info.Sender.Username))
+	default:
+		un = fmt.Sprintf("Signed by %s", ColorString("bold", info.Sender.Username))
+	}
+	fmt.Fprintln(out, ColorString("green", fmt.Sprintf("Signature verified. %s.", un)))
+}
+
 func (c *CmdDecrypt) Run() error {
 	cli, err := GetSaltpackClient(c.G())
 	if err != nil {
@@ -122,6 +141,9 @@ func (c *CmdDecrypt) Run() error {
 	if _, ok := err.(libkb.NoDecryptionKeyError); ok {
 		c.explainDecryptionFailure(&info)
 	}
+	if err == nil {
+		c.reportSigncryptedSender(&info)
+	}
 
 	cerr := c.filter.Close(err)
 	return libkb.PickFirstError(err, cerr)
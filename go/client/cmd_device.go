@@ -19,7 +19,9 @@ func NewCmdDevice(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Comman
 		Subcommands: []cli.Command{
 			NewCmdDeviceRemove(cl, g),
 			NewCmdDeviceList(cl, g),
+			NewCmdDeviceNote(cl, g),
 			NewCmdDeviceAdd(cl, g),
+			NewCmdDeviceRotateKeys(cl, g),
 		},
 	}
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/keybase/cli"
 	"github.com/keybase/client/go/libcmdline"
 	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
 	rpc "github.com/keybase/go-framed-msgpack-rpc"
 )
 
@@ -18,11 +19,16 @@ import (
 // device provisioning on the provisioner/device X/C1.
 type CmdDeviceAdd struct {
 	libkb.Contextified
+	directCode string
 }
 
 const cmdDevAddDesc = `When you are adding a new device to your account and you have an 
 existing device, you will be prompted to use this command on your
-existing device to authorize the new device.`
+existing device to authorize the new device.
+
+If the new device is set up to be provisioned directly over the local
+network (with kex2_direct_address in its config), it shows a code to
+pass to --direct instead of a verification code.`
 
 // NewCmdDeviceAdd creates a new cli.Command.
 func NewCmdDeviceAdd(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
@@ -30,6 +36,12 @@ func NewCmdDeviceAdd(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Com
 		Name:        "add",
 		Usage:       "Authorize a new device",
 		Description: cmdDevAddDesc,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "direct",
+				Usage: "Connect directly to the new device, using the code it shows.",
+			},
+		},
 		Action: func(c *cli.Context) {
 			cl.ChooseCommand(&CmdDeviceAdd{Contextified: libkb.NewContextified(g)}, "add", c)
 		},
@@ -51,7 +63,7 @@ func (c *CmdDeviceAdd) Run() error {
 		return err
 	}
 
-	return cli.DeviceAdd(context.TODO(), 0)
+	return cli.DeviceAdd(context.TODO(), keybase1.DeviceAddArg{DirectCode: c.directCode})
 }
 
 // ParseArgv gets the secret phrase from the command args.
@@ -59,6 +71,12 @@ func (c *CmdDeviceAdd) ParseArgv(ctx *cli.Context) error {
 	if len(ctx.Args()) != 0 {
 		return fmt.Errorf("device add takes zero arguments")
 	}
+	c.directCode = ctx.String("direct")
+	if len(c.directCode) > 0 {
+		if _, err := libkb.ParseKex2DirectCode(c.directCode); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -4,6 +4,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"golang.org/x/net/context"
@@ -17,7 +18,8 @@ import (
 // CmdDeviceList is the 'device list' command.  It displays all
 // the devices for the current user.
 type CmdDeviceList struct {
-	all bool
+	all  bool
+	json bool
 	libkb.Contextified
 }
 
@@ -26,6 +28,12 @@ func NewCmdDeviceList(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Co
 	return cli.Command{
 		Name:  "list",
 		Usage: "List devices",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "j, json",
+				Usage: "Output devices as JSON",
+			},
+		},
 		Action: func(c *cli.Context) {
 			cl.ChooseCommand(&CmdDeviceList{Contextified: libkb.NewContextified(g)}, "list", c)
 		},
@@ -42,27 +50,48 @@ func (c *CmdDeviceList) Run() error {
 		return err
 	}
 
-	devs, err := cli.DeviceList(context.TODO(), 0)
+	devs, err := cli.DeviceDetailList(context.TODO(), 0)
 	if err != nil {
 		return err
 	}
+	if c.json {
+		return c.outputJSON(devs)
+	}
 	c.output(devs)
 	return nil
 }
 
-func (c *CmdDeviceList) output(devs []keybase1.Device) {
+func (c *CmdDeviceList) output(devs []keybase1.DeviceDetail) {
 	w := GlobUI.DefaultTabWriter()
-	fmt.Fprintf(w, "Name\tType\tID\n")
-	fmt.Fprintf(w, "==========\t==========\t==========\n")
+	fmt.Fprintf(w, "Name\tType\tID\tAdded\tSeqno\tProvisioned by\tLast seen signing\tNote\n")
+	fmt.Fprintf(w, "==========\t==========\t==========\t==========\t==========\t==========\t==========\t==========\n")
 	for _, v := range devs {
-		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Name, v.Type, v.DeviceID)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", v.Device.Name, v.Device.Type, v.Device.DeviceID,
+			deviceListTime(v.AddedTime), v.AddedSeqno, v.ProvisionerName, deviceListTime(v.LastSeenSigningTime), v.Note)
 	}
 	w.Flush()
 }
 
+func (c *CmdDeviceList) outputJSON(devs []keybase1.DeviceDetail) error {
+	b, err := json.MarshalIndent(devs, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = GlobUI.Println(string(b))
+	return err
+}
+
+func deviceListTime(t keybase1.Time) string {
+	if t == 0 {
+		return "-"
+	}
+	return keybase1.FromTime(t).Format("2006-01-02")
+}
+
 // ParseArgv does nothing for this command.
 func (c *CmdDeviceList) ParseArgv(ctx *cli.Context) error {
 	c.all = ctx.Bool("all")
+	c.json = ctx.Bool("json")
 	return nil
 }
 
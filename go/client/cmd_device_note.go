@@ -0,0 +1,87 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// CmdDeviceNote is the 'device note' command. It sets the note for one of
+// the user's devices, which is kept only on this machine.
+type CmdDeviceNote struct {
+	device string
+	note   string
+	libkb.Contextified
+}
+
+// NewCmdDeviceNote creates a new cli.Command.
+func NewCmdDeviceNote(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "note",
+		ArgumentHelp: "<name|id> [<note>]",
+		Usage:        "Set a local note for a device, or remove it",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdDeviceNote{Contextified: libkb.NewContextified(g)}, "note", c)
+		},
+	}
+}
+
+// ParseArgv gets the device and the note.
+func (c *CmdDeviceNote) ParseArgv(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) < 1 {
+		return fmt.Errorf("Device note needs a device name or ID.")
+	}
+	c.device = args[0]
+	c.note = strings.Join(args[1:], " ")
+	return nil
+}
+
+// Run runs the command in client/server mode.
+func (c *CmdDeviceNote) Run() error {
+	cli, err := GetDeviceClient()
+	if err != nil {
+		return err
+	}
+	if err := RegisterProtocols(nil); err != nil {
+		return err
+	}
+
+	devs, err := cli.DeviceDetailList(context.TODO(), 0)
+	if err != nil {
+		return err
+	}
+	var id keybase1.DeviceID
+	for _, d := range devs {
+		if d.Device.Name == c.device || d.Device.DeviceID.String() == c.device {
+			id = d.Device.DeviceID
+			break
+		}
+	}
+	if id.IsNil() {
+		return fmt.Errorf("No device named %q", c.device)
+	}
+
+	return cli.SetDeviceNote(context.TODO(), keybase1.SetDeviceNoteArg{
+		DeviceID: id,
+		Note:     c.note,
+	})
+}
+
+// GetUsage says what this command needs to operate.
+func (c *CmdDeviceNote) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config:    true,
+		KbKeyring: true,
+		API:       true,
+	}
+}
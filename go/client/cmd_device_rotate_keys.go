@@ -0,0 +1,85 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+)
+
+// CmdDeviceRotateKeys is the 'device rotate-keys' command.  It
+// replaces the current device's keys with new ones.
+type CmdDeviceRotateKeys struct {
+	libkb.Contextified
+	pkcs11Token string
+}
+
+const cmdDevRotateKeysDesc = `Generate a new signing key and a new encryption key for this device,
+and revoke its old ones. The device keeps its name and ID.
+
+Anything encrypted only for the old encryption key can't be decrypted
+with the new one.
+
+With --pkcs11-token, the new keys are made on the PKCS#11 token with
+that label, and never leave it. The PKCS#11 module to use is
+pkcs11_module in the config file.`
+
+// NewCmdDeviceRotateKeys creates a new cli.Command.
+func NewCmdDeviceRotateKeys(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:        "rotate-keys",
+		Usage:       "Replace this device's keys with new ones",
+		Description: cmdDevRotateKeysDesc,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "pkcs11-token",
+				Usage: "Make the new keys on the PKCS#11 token with this label.",
+			},
+		},
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdDeviceRotateKeys{Contextified: libkb.NewContextified(g)}, "rotate-keys", c)
+		},
+	}
+}
+
+// Run runs the command in client/server mode.
+func (c *CmdDeviceRotateKeys) Run() error {
+	cli, err := GetDeviceClient()
+	if err != nil {
+		return err
+	}
+	protocols := []rpc.Protocol{
+		NewSecretUIProtocol(c.G()),
+	}
+	if err := RegisterProtocols(protocols); err != nil {
+		return err
+	}
+
+	return cli.RotateDeviceKeys(context.TODO(), keybase1.RotateDeviceKeysArg{Pkcs11Token: c.pkcs11Token})
+}
+
+// ParseArgv gets the optional PKCS#11 token label.
+func (c *CmdDeviceRotateKeys) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 0 {
+		return fmt.Errorf("device rotate-keys takes zero arguments")
+	}
+	c.pkcs11Token = ctx.String("pkcs11-token")
+	return nil
+}
+
+// GetUsage says what this command needs to operate.
+func (c *CmdDeviceRotateKeys) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config:    true,
+		KbKeyring: true,
+		API:       true,
+	}
+}
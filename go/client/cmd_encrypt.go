@@ -4,7 +4,9 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
+	"path/filepath"
 
 	"golang.org/x/net/context"
 
@@ -22,12 +24,15 @@ type CmdEncrypt struct {
 	noSelfEncrypt  bool
 	binary         bool
 	hideRecipients bool
+	signcrypt      bool
+	recipientsFile string
+	groups         []string
 }
 
 func NewCmdEncrypt(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
 	return cli.Command{
 		Name:         "encrypt",
-		ArgumentHelp: "<usernames...>",
+		ArgumentHelp: "[<usernames...>]",
 		Usage:        "Encrypt messages or files for keybase users",
 		Action: func(c *cli.Context) {
 			cl.ChooseCommand(&CmdEncrypt{
@@ -59,7 +64,29 @@ func NewCmdEncrypt(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Comma
 				Name:  "no-self",
 				Usage: "Don't encrypt for yourself",
 			},
+			cli.BoolFlag{
+				Name:  "signcrypt",
+				Usage: "Sign the message with your device key inside the encryption",
+			},
+			cli.StringFlag{
+				Name:  "recipients-file",
+				Usage: "Also encrypt for the users in this file, one per line.",
+			},
+			cli.StringSliceFlag{
+				Name:  "g, group",
+				Usage: "Also encrypt for the users in this recipient group from your config.",
+				Value: &cli.StringSlice{},
+			},
 		},
+		Description: `If you give a recipients file or any groups, all of the recipients
+   are identified at once, without prompting, and a JSON summary of
+   the users and devices that can read the message is written to stderr.
+
+   Recipient groups live in your user's section of config.json, like:
+
+       "recipient_groups": {
+           "release": ["alice", "bob@github", "ci_bot"]
+       }`,
 	}
 }
 
@@ -84,17 +111,32 @@ func (c *CmdEncrypt) Run() error {
 	}
 
 	opts := keybase1.SaltpackEncryptOptions{
-		Recipients:     c.recipients,
-		NoSelfEncrypt:  c.noSelfEncrypt,
-		Binary:         c.binary,
-		HideRecipients: c.hideRecipients,
+		Recipients:      c.recipients,
+		NoSelfEncrypt:   c.noSelfEncrypt,
+		Binary:          c.binary,
+		HideRecipients:  c.hideRecipients,
+		Signcrypt:       c.signcrypt,
+		RecipientsFile:  c.recipientsFile,
+		RecipientGroups: c.groups,
 	}
 	arg := keybase1.SaltpackEncryptArg{Source: src, Sink: snk, Opts: opts}
-	err = cli.SaltpackEncrypt(context.TODO(), arg)
+	res, err := cli.SaltpackEncrypt(context.TODO(), arg)
 	cerr := c.filter.Close(err)
+	if err == nil && (len(c.recipientsFile) > 0 || len(c.groups) > 0) {
+		err = c.writeSummary(res)
+	}
 	return libkb.PickFirstError(err, cerr)
 }
 
+func (c *CmdEncrypt) writeSummary(res keybase1.SaltpackEncryptResult) error {
+	b, err := json.MarshalIndent(res, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = c.G().UI.GetTerminalUI().ErrorWriter().Write(append(b, '\n'))
+	return err
+}
+
 func (c *CmdEncrypt) GetUsage() libkb.Usage {
 	return libkb.Usage{
 		API:       true,
@@ -104,10 +146,20 @@ func (c *CmdEncrypt) GetUsage() libkb.Usage {
 }
 
 func (c *CmdEncrypt) ParseArgv(ctx *cli.Context) error {
-	if len(ctx.Args()) == 0 {
+	c.recipients = ctx.Args()
+	c.groups = ctx.StringSlice("group")
+	if file := ctx.String("recipients-file"); len(file) > 0 {
+		// The service reads the file, and it might not share our
+		// working directory.
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		c.recipientsFile = abs
+	}
+	if len(c.recipients) == 0 && len(c.recipientsFile) == 0 && len(c.groups) == 0 {
 		return errors.New("Encrypt needs at least one recipient")
 	}
-	c.recipients = ctx.Args()
 
 	msg := ctx.String("message")
 	outfile := ctx.String("outfile")
@@ -115,6 +167,7 @@ func (c *CmdEncrypt) ParseArgv(ctx *cli.Context) error {
 	c.noSelfEncrypt = ctx.Bool("no-self")
 	c.binary = ctx.Bool("binary")
 	c.hideRecipients = ctx.Bool("hide-recipients")
+	c.signcrypt = ctx.Bool("signcrypt")
 	if err := c.filter.FilterInit(msg, infile, outfile); err != nil {
 		return err
 	}
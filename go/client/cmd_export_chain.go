@@ -0,0 +1,73 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// CmdExportChain is the wrapper structure for `keybase export-chain`.
+type CmdExportChain struct {
+	libkb.Contextified
+	username string
+	output   string
+}
+
+func NewCmdExportChain(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "export-chain",
+		ArgumentHelp: "<username>",
+		Usage:        "Export a user's sigchain, merkle path and keys for offline verification",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "o, outfile",
+				Usage: "Write the bundle to the given file (default is stdout).",
+			},
+		},
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdExportChain{Contextified: libkb.NewContextified(g)}, "export-chain", c)
+		},
+	}
+}
+
+func (c *CmdExportChain) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return fmt.Errorf("export-chain takes one argument: <username>")
+	}
+	c.username = ctx.Args()[0]
+	c.output = ctx.String("outfile")
+	return nil
+}
+
+func (c *CmdExportChain) Run() error {
+	cli, err := GetUserClient()
+	if err != nil {
+		return err
+	}
+	bundle, err := cli.ExportChain(context.TODO(), keybase1.ExportChainArg{Username: c.username})
+	if err != nil {
+		return err
+	}
+	if len(c.output) == 0 {
+		c.G().UI.GetDumbOutputUI().Printf("%s\n", bundle)
+		return nil
+	}
+	return ioutil.WriteFile(c.output, []byte(bundle), os.FileMode(0644))
+}
+
+func (c *CmdExportChain) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+		API:    true,
+	}
+}
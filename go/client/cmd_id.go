@@ -4,7 +4,12 @@
 package client
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 
 	"golang.org/x/net/context"
 
@@ -21,6 +26,9 @@ type CmdID struct {
 	trackStatement bool
 	useDelegateUI  bool
 	skipProofCache bool
+	batchFile      string
+	parallelism    int
+	reportFile     string
 }
 
 func (v *CmdID) ParseArgv(ctx *cli.Context) error {
@@ -29,10 +37,24 @@ func (v *CmdID) ParseArgv(ctx *cli.Context) error {
 		return fmt.Errorf("Identify only takes one argument, the user to lookup.")
 	}
 
+	v.batchFile = ctx.String("batch")
+	v.parallelism = ctx.Int("parallel")
+	if len(v.batchFile) > 0 && nargs > 0 {
+		return fmt.Errorf("Can't specify both a user and a batch file.")
+	}
+
+	v.reportFile = ctx.String("report")
+	if len(v.reportFile) > 0 && len(v.batchFile) > 0 {
+		return fmt.Errorf("Can't write a report in batch mode.")
+	}
+
 	if nargs == 1 {
 		v.user = ctx.Args()[0]
 	}
 	v.trackStatement = ctx.Bool("track-statement")
+	if len(v.reportFile) > 0 && v.trackStatement {
+		return fmt.Errorf("Can't specify both --report and --track-statement.")
+	}
 	v.useDelegateUI = ctx.Bool("ui")
 	v.skipProofCache = ctx.Bool("skip-proof-cache")
 	return nil
@@ -49,6 +71,13 @@ func (v *CmdID) makeArg() keybase1.IdentifyArg {
 }
 
 func (v *CmdID) Run() error {
+	if len(v.batchFile) > 0 {
+		return v.runBatch()
+	}
+	if len(v.reportFile) > 0 {
+		return v.runReport()
+	}
+
 	var cli keybase1.IdentifyClient
 	protocols := []rpc.Protocol{}
 
@@ -97,6 +126,18 @@ func NewCmdID(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
 				Usage:     "Skip cached proofs, force re-check",
 				HideUsage: !develUsage,
 			},
+			cli.StringFlag{
+				Name:  "batch",
+				Usage: "Identify every assertion in the given file (one per line), and output one JSON result per line. Exits with an error if any identify fails.",
+			},
+			cli.IntFlag{
+				Name:  "parallel",
+				Usage: "Number of identifies to run at once in batch mode.",
+			},
+			cli.StringFlag{
+				Name:  "report",
+				Usage: "Also write a JSON report of the outcome, signed with this device's key, to the given file.",
+			},
 		},
 		Action: func(c *cli.Context) {
 			cl.ChooseCommand(NewCmdIDRunner(g), "id", c)
@@ -105,6 +146,112 @@ func NewCmdID(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
 	return ret
 }
 
+// readBatchFile reads one assertion per line, skipping blank lines and
+// lines starting with '#'.
+func (v *CmdID) readBatchFile() (assertions []string, err error) {
+	f, err := os.Open(v.batchFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		assertions = append(assertions, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return assertions, nil
+}
+
+func (v *CmdID) runBatch() error {
+	assertions, err := v.readBatchFile()
+	if err != nil {
+		return err
+	}
+	if len(assertions) == 0 {
+		return fmt.Errorf("No assertions found in %s", v.batchFile)
+	}
+
+	cli, err := GetIdentifyClient(v.G())
+	if err != nil {
+		return err
+	}
+	ui := &idBatchUI{Contextified: libkb.NewContextified(v.G())}
+	protocols := []rpc.Protocol{
+		NewLogUIProtocol(),
+		keybase1.IdentifyBatchUiProtocol(ui),
+	}
+	if err := RegisterProtocolsWithContext(protocols, v.G()); err != nil {
+		return err
+	}
+
+	err = cli.Identify2Batch(context.TODO(), keybase1.Identify2BatchArg{
+		Assertions:       assertions,
+		Reason:           keybase1.IdentifyReason{Reason: "CLI id command"},
+		Parallelism:      v.parallelism,
+		ForceRemoteCheck: v.skipProofCache,
+	})
+	if err != nil {
+		return err
+	}
+	if ui.numFailed > 0 {
+		return fmt.Errorf("%d of %d identifies failed", ui.numFailed, len(assertions))
+	}
+	return nil
+}
+
+func (v *CmdID) runReport() error {
+	cli, err := GetIdentifyClient(v.G())
+	if err != nil {
+		return err
+	}
+	protocols := []rpc.Protocol{
+		NewIdentifyUIProtocol(v.G()),
+		NewSecretUIProtocol(v.G()),
+	}
+	if err := RegisterProtocolsWithContext(protocols, v.G()); err != nil {
+		return err
+	}
+
+	report, err := cli.IdentifyReport(context.TODO(), keybase1.IdentifyReportArg{
+		UserAssertion:    v.user,
+		ForceRemoteCheck: v.skipProofCache,
+	})
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(v.reportFile, []byte(report+"\n"), 0644); err != nil {
+		return err
+	}
+	v.G().UI.GetTerminalUI().ErrorWriter().Write([]byte(fmt.Sprintf("Wrote signed report to %s\n", v.reportFile)))
+	return nil
+}
+
+// idBatchUI prints each batch identify result as a single line of JSON,
+// and counts the ones that failed.
+type idBatchUI struct {
+	libkb.Contextified
+	numFailed int
+}
+
+func (u *idBatchUI) Identify2BatchResult(_ context.Context, arg keybase1.Identify2BatchResultArg) error {
+	if arg.Result.Status != nil {
+		u.numFailed++
+	}
+	out, err := json.Marshal(arg.Result)
+	if err != nil {
+		return err
+	}
+	u.G().UI.GetDumbOutputUI().Printf("%s\n", out)
+	return nil
+}
+
 func NewCmdIDRunner(g *libkb.GlobalContext) *CmdID {
 	return &CmdID{Contextified: libkb.NewContextified(g)}
 }
@@ -0,0 +1,127 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"encoding/json"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+)
+
+// CmdInspect shows what's in the header of an encrypted or signed
+// message, without decrypting or verifying it.
+type CmdInspect struct {
+	libkb.Contextified
+	UnixFilter
+	json bool
+}
+
+func NewCmdInspect(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "inspect",
+		Usage: "Show the header of an encrypted or signed message, without decrypting it",
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdInspect{Contextified: libkb.NewContextified(g)}, "inspect", c)
+		},
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "i, infile",
+				Usage: "Specify an input file.",
+			},
+			cli.StringFlag{
+				Name:  "m, message",
+				Usage: "Provide the message on the command line.",
+			},
+			cli.BoolFlag{
+				Name:  "j, json",
+				Usage: "Output as JSON (default is a summary).",
+			},
+		},
+	}
+}
+
+func (c *CmdInspect) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		return UnexpectedArgsError("inspect")
+	}
+	c.json = ctx.Bool("json")
+	return c.FilterInit(ctx.String("message"), ctx.String("infile"), "/dev/null")
+}
+
+func (c *CmdInspect) Run() error {
+	cli, err := GetSaltpackClient(c.G())
+	if err != nil {
+		return err
+	}
+	protocols := []rpc.Protocol{
+		NewStreamUIProtocol(c.G()),
+	}
+	if err := RegisterProtocolsWithContext(protocols, c.G()); err != nil {
+		return err
+	}
+	_, src, err := c.ClientFilterOpen()
+	if err != nil {
+		return err
+	}
+	info, err := cli.SaltpackInspect(context.TODO(), keybase1.SaltpackInspectArg{Source: src})
+	cerr := c.Close(err)
+	if err = libkb.PickFirstError(err, cerr); err != nil {
+		return err
+	}
+
+	dui := c.G().UI.GetDumbOutputUI()
+	if c.json {
+		b, err := json.MarshalIndent(info, "", "    ")
+		if err != nil {
+			return err
+		}
+		dui.Printf("%s\n", b)
+		return nil
+	}
+
+	armor := "binary"
+	if info.Armored {
+		armor = "armored"
+		if len(info.Brand) > 0 {
+			armor += " (" + info.Brand + ")"
+		}
+	}
+	dui.Printf("Format: %s, %s\n", info.Format, armor)
+	dui.Printf("Mode: %s\n", info.Mode)
+	if info.Format != string(libkb.CryptoMessageFormatSaltpack) {
+		return nil
+	}
+	dui.Printf("Version: %d.%d\n", info.VersionMajor, info.VersionMinor)
+	if !info.SenderKID.IsNil() {
+		dui.Printf("Sender KID: %s\n", info.SenderKID)
+	}
+	if info.NumReceivers == 0 {
+		return nil
+	}
+	dui.Printf("Recipients: %d (%d anonymous)\n", info.NumReceivers, info.NumAnonReceivers)
+	for _, kid := range info.ReceiverKIDs {
+		dui.Printf("  %s\n", kid)
+	}
+	if len(info.Devices) > 0 {
+		dui.Printf("Your devices that can open it:\n")
+		for _, d := range info.Devices {
+			dui.Printf("  %s (%s): %s\n", d.Name, d.Type, d.EncryptKey)
+		}
+	}
+	return nil
+}
+
+func (c *CmdInspect) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config:    true,
+		API:       true,
+		KbKeyring: true,
+	}
+}
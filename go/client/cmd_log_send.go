@@ -9,6 +9,7 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -19,6 +20,7 @@ import (
 	"github.com/keybase/cli"
 	"github.com/keybase/client/go/libcmdline"
 	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/logger"
 )
 
 const (
@@ -181,12 +183,24 @@ func addFile(mpart *multipart.Writer, param, filename, data string) error {
 	return nil
 }
 
+// tail gets the last numLines lines of the log at filename. If the log
+// was rotated recently and doesn't have that many lines yet, the rest come
+// from the end of the newest rotated segment.
 func (c *CmdLogSend) tail(filename string, numLines int) string {
+	lines := c.tailLines(filename, numLines)
+	if len(lines) < numLines {
+		lines = append(c.tailRotated(filename, numLines-len(lines)), lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *CmdLogSend) tailLines(filename string, numLines int) []string {
 	f, err := os.Open(filename)
 	if err != nil {
 		c.G().Log.Warning("error opening log %q: %s", filename, err)
-		return ""
+		return nil
 	}
+	defer f.Close()
 	b := reverse.NewScanner(f)
 	b.Split(bufio.ScanLines)
 
@@ -202,7 +216,54 @@ func (c *CmdLogSend) tail(filename string, numLines int) string {
 		lines[left], lines[right] = lines[right], lines[left]
 	}
 
-	return strings.Join(lines, "\n")
+	return lines
+}
+
+// tailRotated gets the last numLines lines of the newest rotated segment
+// of the log at filename, which is gzipped unless it was only just
+// rotated.
+func (c *CmdLogSend) tailRotated(filename string, numLines int) []string {
+	rotated, err := logger.RotatedLogFiles(filename)
+	if err != nil || len(rotated) == 0 {
+		return nil
+	}
+	newest := rotated[len(rotated)-1]
+	c.G().Log.Debug("adding lines from rotated log %q", newest)
+
+	f, err := os.Open(newest)
+	if err != nil {
+		c.G().Log.Warning("error opening log %q: %s", newest, err)
+		return nil
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(newest, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			c.G().Log.Warning("error reading log %q: %s", newest, err)
+			return nil
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	// gzip can't be read backwards, so keep the last numLines lines while
+	// reading forwards.
+	var lines []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+		if len(lines) >= 2*numLines {
+			lines = append(lines[:0], lines[len(lines)-numLines:]...)
+		}
+	}
+	if err := s.Err(); err != nil {
+		c.G().Log.Warning("error reading log %q: %s", newest, err)
+	}
+	if len(lines) > numLines {
+		lines = lines[len(lines)-numLines:]
+	}
+	return lines
 }
 
 func (c *CmdLogSend) ParseArgv(ctx *cli.Context) error {
@@ -30,6 +30,10 @@ func NewCmdLogin(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command
 				Name:  "provision-by-email",
 				Usage: "Use an email address associated with a keybase account to provision a device",
 			},
+			cli.StringFlag{
+				Name:  "pkcs11-token",
+				Usage: "Make this device's keys on the PKCS#11 token with this label (the module is pkcs11_module in the config file)",
+			},
 		},
 	}
 	// Note we'll only be able to set this via mode via Environment variable
@@ -45,11 +49,12 @@ func NewCmdLogin(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command
 
 type CmdLogin struct {
 	libkb.Contextified
-	username   string
-	clientType keybase1.ClientType
-	cancel     func()
-	done       chan struct{}
-	SessionID  int
+	username    string
+	clientType  keybase1.ClientType
+	pkcs11Token string
+	cancel      func()
+	done        chan struct{}
+	SessionID   int
 }
 
 func NewCmdLoginRunner(g *libkb.GlobalContext) *CmdLogin {
@@ -91,6 +96,7 @@ func (c *CmdLogin) Run() error {
 			DeviceType:      libkb.DeviceTypeDesktop,
 			ClientType:      c.clientType,
 			SessionID:       c.SessionID,
+			Pkcs11Token:     c.pkcs11Token,
 		})
 	c.done <- struct{}{}
 
@@ -118,6 +124,7 @@ func (c *CmdLogin) ParseArgv(ctx *cli.Context) error {
 	}
 
 	provisionByEmail := ctx.Bool("provision-by-email")
+	c.pkcs11Token = ctx.String("pkcs11-token")
 
 	if nargs == 1 {
 		c.username = ctx.Args()[0]
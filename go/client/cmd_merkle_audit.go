@@ -0,0 +1,107 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// CmdMerkleAudit is the `keybase merkle audit` command.
+type CmdMerkleAudit struct {
+	libkb.Contextified
+	json bool
+}
+
+// NewCmdMerkleAudit creates a new cli.Command.
+func NewCmdMerkleAudit(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:  "audit",
+		Usage: "Check the stored Merkle roots for rollbacks and forks",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "j, json",
+				Usage: "Output as JSON, including evidence for any forks.",
+			},
+		},
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdMerkleAudit{Contextified: libkb.NewContextified(g)}, "audit", c)
+		},
+	}
+}
+
+// ParseArgv parses the command args.
+func (c *CmdMerkleAudit) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) > 0 {
+		return fmt.Errorf("merkle audit doesn't take any arguments")
+	}
+	c.json = ctx.Bool("json")
+	return nil
+}
+
+// Run runs the command in client/server mode.
+func (c *CmdMerkleAudit) Run() error {
+	cli, err := GetMerkleClient(c.G())
+	if err != nil {
+		return err
+	}
+	res, err := cli.MerkleAudit(context.TODO(), 0)
+	if err != nil {
+		return err
+	}
+
+	dui := c.G().UI.GetDumbOutputUI()
+	if c.json {
+		b, err := json.MarshalIndent(res, "", "    ")
+		if err != nil {
+			return err
+		}
+		dui.Printf("%s\n", b)
+	} else {
+		c.display(res)
+	}
+
+	if len(res.Problems) > 0 || len(res.Forks) > 0 {
+		return errors.New("Merkle audit failed")
+	}
+	return nil
+}
+
+func (c *CmdMerkleAudit) display(res keybase1.MerkleAuditResult) {
+	dui := c.G().UI.GetDumbOutputUI()
+	if res.Latest == nil {
+		dui.Printf("No Merkle roots stored yet.\n")
+	} else {
+		dui.Printf("Checked %d Merkle roots, from %d (%s) to %d (%s).\n", res.NumRoots,
+			res.Oldest.Seqno, keybase1.FromTime(res.Oldest.Ctime),
+			res.Latest.Seqno, keybase1.FromTime(res.Latest.Ctime))
+	}
+	for _, p := range res.Problems {
+		dui.Printf("Problem: %s\n", p)
+	}
+	for _, f := range res.Forks {
+		dui.Printf("Possible fork (%s), seen %s:\n", f.Type, keybase1.FromTime(f.SeenAt))
+		dui.Printf("    had root %d (%s)\n", f.Prev.Seqno, f.Prev.Hash)
+		dui.Printf("    got root %d (%s)\n", f.Root.Seqno, f.Root.Hash)
+	}
+	if len(res.Problems) == 0 && len(res.Forks) == 0 {
+		dui.Printf("No problems found.\n")
+	}
+}
+
+// GetUsage says what this command needs to operate.
+func (c *CmdMerkleAudit) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+		API:    true,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/keybase/cli"
 	"github.com/keybase/client/go/libcmdline"
 	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
 	rpc "github.com/keybase/go-framed-msgpack-rpc"
 	"golang.org/x/net/context"
 )
@@ -15,6 +16,16 @@ func NewCmdPaperKey(cl *libcmdline.CommandLine) cli.Command {
 	return cli.Command{
 		Name:  "paperkey",
 		Usage: "Generate paper keys for recovering your account",
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "shares",
+				Usage: "Split the paper key into this many shares.",
+			},
+			cli.IntFlag{
+				Name:  "threshold",
+				Usage: "Number of shares needed to recover the account.",
+			},
+		},
 		Action: func(c *cli.Context) {
 			cl.ChooseCommand(&CmdPaperKey{}, "paperkey", c)
 		},
@@ -22,6 +33,8 @@ func NewCmdPaperKey(cl *libcmdline.CommandLine) cli.Command {
 }
 
 type CmdPaperKey struct {
+	shares    int
+	threshold int
 }
 
 func (c *CmdPaperKey) Run() error {
@@ -36,11 +49,16 @@ func (c *CmdPaperKey) Run() error {
 	if err := RegisterProtocols(protocols); err != nil {
 		return err
 	}
-	return cli.PaperKey(context.TODO(), 0)
+	return cli.PaperKey(context.TODO(), keybase1.PaperKeyArg{ShareThreshold: c.threshold, ShareCount: c.shares})
 }
 
 func (c *CmdPaperKey) ParseArgv(ctx *cli.Context) error {
-	return nil
+	c.shares = ctx.Int("shares")
+	c.threshold = ctx.Int("threshold")
+	if c.shares == 0 && c.threshold == 0 {
+		return nil
+	}
+	return libkb.CheckPaperKeyShareCount(c.threshold, c.shares)
 }
 
 func (c *CmdPaperKey) GetUsage() libkb.Usage {
@@ -0,0 +1,88 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	"golang.org/x/net/context"
+)
+
+const defaultPGPExtendDays = 730
+
+type CmdPGPExtend struct {
+	fingerprints []string
+	all          bool
+	days         int
+}
+
+func (v *CmdPGPExtend) ParseArgv(ctx *cli.Context) error {
+	v.fingerprints = ctx.Args()
+	v.all = ctx.Bool("all")
+	v.days = ctx.Int("days")
+	return nil
+}
+
+func (v *CmdPGPExtend) Run() (err error) {
+	cli, err := GetPGPClient()
+	if err != nil {
+		return err
+	}
+
+	protocols := []rpc.Protocol{
+		NewSecretUIProtocol(G),
+	}
+	if err = RegisterProtocols(protocols); err != nil {
+		return err
+	}
+
+	return cli.PGPExtend(context.TODO(), keybase1.PGPExtendArg{
+		Fingerprints: v.fingerprints,
+		All:          v.all,
+		Days:         v.days,
+	})
+}
+
+func NewCmdPGPExtend(cl *libcmdline.CommandLine) cli.Command {
+	return cli.Command{
+		Name:         "extend",
+		ArgumentHelp: "[fingerprints...]",
+		Usage:        "Push a later expiration time for PGP keys in your local keybase keyring",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "all",
+				Usage: "Extend all available keys.",
+			},
+			cli.IntFlag{
+				Name:  "days",
+				Value: defaultPGPExtendDays,
+				Usage: "Make the keys expire this many days from now.",
+			},
+		},
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdPGPExtend{}, "extend", c)
+		},
+		Description: `'keybase pgp extend' gives PGP keys a new expiration time.
+   The keys' secret halves must be in your local keybase keyring. Each key
+   gets new self signatures, which are posted to the Keybase server as with
+   'keybase pgp update', and saved back to the local keyring.
+
+   Signing subkeys can't be extended this way; extend those in GPG and use
+   'keybase pgp update' instead.
+
+   Only keys with the specified PGP fingerprints will be extended, unless the
+   '--all' flag is specified, in which case all PGP keys will be extended.`,
+	}
+}
+
+func (v *CmdPGPExtend) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config:    true,
+		KbKeyring: true,
+		API:       true,
+	}
+}
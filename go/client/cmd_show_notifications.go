@@ -4,6 +4,7 @@
 package client
 
 import (
+	"fmt"
 	"time"
 
 	"golang.org/x/net/context"
@@ -37,12 +38,16 @@ func (c *CmdShowNotifications) Run() error {
 		keybase1.NotifyUsersProtocol(display),
 		keybase1.NotifyFSProtocol(display),
 		keybase1.NotifyTrackingProtocol(display),
+		keybase1.NotifyAuditProtocol(display),
+		keybase1.NotifyKeysProtocol(display),
 	}
 	channels := keybase1.NotificationChannels{
 		Session:  true,
 		Users:    true,
 		Kbfs:     true,
 		Tracking: true,
+		Audit:    true,
+		Keys:     true,
 	}
 
 	if err := RegisterProtocols(protocols); err != nil {
@@ -111,3 +116,23 @@ func (d *notificationDisplay) FSActivity(_ context.Context, notification keybase
 func (d *notificationDisplay) TrackingChanged(_ context.Context, arg keybase1.TrackingChangedArg) error {
 	return d.printf("Tracking changed for %s (%s)\n", arg.Username, arg.Uid)
 }
+
+func (d *notificationDisplay) MerkleForkDetected(_ context.Context, fork keybase1.MerkleFork) error {
+	return d.printf("Possible Merkle tree fork (%s): root %d (%s) vs. root %d (%s)\n",
+		fork.Type, fork.Prev.Seqno, fork.Prev.Hash, fork.Root.Seqno, fork.Root.Hash)
+}
+
+func (d *notificationDisplay) KeysExpiring(_ context.Context, arg keybase1.KeysExpiringArg) error {
+	for _, key := range arg.Keys {
+		desc := key.Kid.String()
+		if len(key.PGPFingerprint) > 0 {
+			desc = "PGP key " + key.PGPFingerprint
+		} else if len(key.DeviceName) > 0 {
+			desc = fmt.Sprintf("%s (device %q)", desc, key.DeviceName)
+		}
+		if err := d.printf("Key %s expires at %s\n", desc, keybase1.FromTime(key.Etime)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
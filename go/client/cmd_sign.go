@@ -4,6 +4,10 @@
 package client
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
 	"golang.org/x/net/context"
 
 	"github.com/keybase/cli"
@@ -37,6 +41,10 @@ func NewCmdSign(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command
 				Name:  "m, message",
 				Usage: "Provide the message to sign on the command line.",
 			},
+			cli.StringFlag{
+				Name:  "manifest",
+				Usage: "Sign a manifest of the hashes of every file in a directory. The manifest goes in the outfile (<dir>.manifest by default), and its detached signature in <outfile>.sig.",
+			},
 			cli.StringFlag{
 				Name:  "o, outfile",
 				Usage: "Specify an outfile (default is STDOUT).",
@@ -48,8 +56,10 @@ func NewCmdSign(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command
 type CmdSign struct {
 	libkb.Contextified
 	UnixFilter
-	detached bool
-	binary   bool
+	detached     bool
+	binary       bool
+	manifestDir  string
+	manifestFile string
 }
 
 func (s *CmdSign) ParseArgv(ctx *cli.Context) error {
@@ -64,10 +74,46 @@ func (s *CmdSign) ParseArgv(ctx *cli.Context) error {
 	outfile := ctx.String("outfile")
 	infile := ctx.String("infile")
 
+	if s.manifestDir = ctx.String("manifest"); len(s.manifestDir) > 0 {
+		if len(msg) > 0 || len(infile) > 0 {
+			return fmt.Errorf("Can't sign a manifest and a message at the same time")
+		}
+		s.detached = true
+		s.manifestFile = outfile
+		if len(s.manifestFile) == 0 {
+			s.manifestFile = filepath.Clean(s.manifestDir) + ".manifest"
+		}
+		return nil
+	}
+
 	return s.FilterInit(msg, infile, outfile)
 }
 
+// writeManifest hashes the files in the manifest directory, writes the
+// manifest out, and sets things up to sign it into <manifest>.sig.
+func (s *CmdSign) writeManifest() error {
+	sigFile := s.manifestFile + ".sig"
+	manifest, err := libkb.MakeManifest(s.manifestDir, s.manifestFile, sigFile)
+	if err != nil {
+		return err
+	}
+	if len(manifest) == 0 {
+		return fmt.Errorf("No files to sign in %s", s.manifestDir)
+	}
+	encoded := manifest.Encode()
+	if err := ioutil.WriteFile(s.manifestFile, encoded, 0644); err != nil {
+		return err
+	}
+	return s.FilterInit(string(encoded), "", sigFile)
+}
+
 func (s *CmdSign) Run() (err error) {
+	if len(s.manifestDir) > 0 {
+		if err = s.writeManifest(); err != nil {
+			return err
+		}
+	}
+
 	protocols := []rpc.Protocol{
 		NewStreamUIProtocol(s.G()),
 		NewSecretUIProtocol(s.G()),
@@ -93,7 +139,10 @@ func (s *CmdSign) Run() (err error) {
 		err = cli.SaltpackSign(context.TODO(), arg)
 	}
 	cerr := s.Close(err)
-	return libkb.PickFirstError(err, cerr)
+	if err = libkb.PickFirstError(err, cerr); err == nil && len(s.manifestDir) > 0 {
+		fmt.Fprintf(s.G().UI.GetTerminalUI().ErrorWriter(), "Signed the manifest in %s; the signature is in %s.sig\n", s.manifestFile, s.manifestFile)
+	}
+	return err
 }
 
 func (s *CmdSign) GetUsage() libkb.Usage {
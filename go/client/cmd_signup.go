@@ -210,7 +210,7 @@ func (s *CmdSignup) prompt() (err error) {
 	f := s.fields.passphraseRetry
 	if f.Disabled || libkb.IsYes(f.GetValue()) {
 		var res keybase1.GetPassphraseRes
-		res, err = PromptPassphrase(s.G())
+		res, err = PromptPassphrase(s.G(), s.fields.username.GetValue(), s.fields.email.GetValue())
 		if err != nil {
 			return
 		}
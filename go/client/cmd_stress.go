@@ -45,7 +45,7 @@ type CmdStress struct {
 	passphrase string
 }
 
-func (c *CmdStress) rpcClient() (*rpc.Client, error) {
+func (c *CmdStress) rpcClient() (rpc.GenericClient, error) {
 	cli, _, err := GetRPCClient()
 	if err != nil {
 		return nil, err
@@ -103,7 +103,7 @@ func (c *CmdStress) GetUsage() libkb.Usage {
 	}
 }
 
-func (c *CmdStress) signup(cli *rpc.Client) (username, passphrase string, err error) {
+func (c *CmdStress) signup(cli rpc.GenericClient) (username, passphrase string, err error) {
 	buf := make([]byte, 5)
 	if _, err = rand.Read(buf); err != nil {
 		return
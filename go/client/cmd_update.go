@@ -26,6 +26,8 @@ func NewCmdUpdate(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Comman
 			NewCmdUpdateCheck(cl, g),
 			NewCmdUpdateRun(cl, g),
 			NewCmdUpdateRunLocal(cl, g),
+			NewCmdUpdateRestart(cl, g),
+			NewCmdUpdateRollback(cl, g),
 		},
 	}
 }
@@ -0,0 +1,112 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/engine"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+func NewCmdUpdateRestart(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	defaultOptions := engine.DefaultUpdaterOptions(g)
+	return cli.Command{
+		Name:  "restart",
+		Usage: "Restart the service after an update, and roll the update back if the new service doesn't answer",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "d, destination-path",
+				Usage: fmt.Sprintf("Where the update was applied, default is %q", defaultOptions.DestinationPath),
+			},
+		},
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdUpdateRestart{Contextified: libkb.NewContextified(g), destinationPath: defaultOptions.DestinationPath}, "restart", c)
+			cl.SetForkCmd(libcmdline.NoFork)
+			cl.SetNoStandalone()
+		},
+	}
+}
+
+type CmdUpdateRestart struct {
+	libkb.Contextified
+	destinationPath string
+}
+
+func (v *CmdUpdateRestart) ParseArgv(ctx *cli.Context) error {
+	if d := ctx.String("destination-path"); d != "" {
+		v.destinationPath = d
+	}
+	return nil
+}
+
+func (v *CmdUpdateRestart) Run() error {
+	version := libkb.VersionString()
+	err := (&CmdCtlRestart{v.Contextified}).Run()
+	if err == nil {
+		err = checkServiceHealth(v.G(), version)
+	}
+	if err == nil {
+		v.G().Log.Info("Service restarted on %s", version)
+		return nil
+	}
+
+	v.G().Log.Errorf("Service isn't answering after the update to %s, rolling back: %s", version, err)
+	return rollbackUpdate(v.G(), v.destinationPath)
+}
+
+func (v *CmdUpdateRestart) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+	}
+}
+
+// checkServiceHealth waits for the service to answer the config RPC that
+// ctl restart starts with, as version.
+func checkServiceHealth(g *libkb.GlobalContext, version string) (err error) {
+	for i := 0; i < 20; i++ {
+		if err = pingService(g, version); err == nil {
+			return nil
+		}
+		g.Log.Debug("Service not answering (%d): %s", i, err)
+		time.Sleep(time.Second)
+	}
+	return err
+}
+
+func pingService(g *libkb.GlobalContext, version string) error {
+	if _, err := getSocketWithRetry(g); err != nil {
+		return err
+	}
+	cli, err := GetConfigClient(g)
+	if err != nil {
+		return err
+	}
+	config, err := cli.GetConfig(context.TODO(), 0)
+	if err != nil {
+		return err
+	}
+	if config.Version != version {
+		return fmt.Errorf("Service is on %s, not %s", config.Version, version)
+	}
+	return nil
+}
+
+// restartService restarts the service through ctl restart, or if it's not
+// answering at all, starts a new one.
+func restartService(g *libkb.GlobalContext) error {
+	err := (&CmdCtlRestart{libkb.NewContextified(g)}).Run()
+	if err == nil {
+		return nil
+	}
+	g.Log.Warning("Restart failed, starting the service instead: %s", err)
+	_, err = ForkServer(g, g.Env.GetCommandLine(), keybase1.ForkType_AUTO)
+	return err
+}
@@ -0,0 +1,78 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/engine"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/updater"
+)
+
+func NewCmdUpdateRollback(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	defaultOptions := engine.DefaultUpdaterOptions(g)
+	return cli.Command{
+		Name:  "rollback",
+		Usage: "Go back to the version before the last update, and restart the service",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "d, destination-path",
+				Usage: fmt.Sprintf("Where the update was applied, default is %q", defaultOptions.DestinationPath),
+			},
+		},
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdUpdateRollback{Contextified: libkb.NewContextified(g), destinationPath: defaultOptions.DestinationPath}, "rollback", c)
+			cl.SetForkCmd(libcmdline.NoFork)
+			cl.SetNoStandalone()
+		},
+	}
+}
+
+type CmdUpdateRollback struct {
+	libkb.Contextified
+	destinationPath string
+}
+
+func (v *CmdUpdateRollback) ParseArgv(ctx *cli.Context) error {
+	if d := ctx.String("destination-path"); d != "" {
+		v.destinationPath = d
+	}
+	if v.destinationPath == "" {
+		return fmt.Errorf("Need a destination path to roll back")
+	}
+	return nil
+}
+
+func (v *CmdUpdateRollback) Run() error {
+	if err := checkBrew(); err != nil {
+		return err
+	}
+	return rollbackUpdate(v.G(), v.destinationPath)
+}
+
+func (v *CmdUpdateRollback) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+	}
+}
+
+// rollbackUpdate puts back the binaries from before the last update to
+// destinationPath, which is the update to this version, makes sure this
+// version isn't offered again, and restarts the service.
+func rollbackUpdate(g *libkb.GlobalContext, destinationPath string) error {
+	version := libkb.VersionString()
+	previousVersion, err := updater.Rollback(destinationPath, g.Log)
+	if err != nil {
+		return err
+	}
+	g.Log.Info("Rolled back from %s to %s", version, previousVersion)
+
+	if err := g.Env.SetUpdatePreferenceSkip(version); err != nil {
+		g.Log.Warning("Error skipping %s in future updates: %s", version, err)
+	}
+	return restartService(g)
+}
@@ -5,7 +5,9 @@ package client
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"path/filepath"
 
 	"golang.org/x/net/context"
 
@@ -36,6 +38,10 @@ func NewCmdVerify(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Comman
 				Name:  "m, message",
 				Usage: "Provide the message to verify on the command line.",
 			},
+			cli.StringFlag{
+				Name:  "manifest",
+				Usage: "Verify a directory against a manifest from `keybase sign --manifest`. The manifest is the infile (<dir>.manifest by default), and the signature is <infile>.sig unless -d is given.",
+			},
 			cli.BoolFlag{
 				Name:  "no-output",
 				Usage: "Don't output the verified message.",
@@ -58,6 +64,11 @@ type CmdVerify struct {
 	detachedData []byte
 	signedBy     string
 	spui         *SaltpackUI
+	manifestDir  string
+	manifest     libkb.Manifest
+	// The manifest and signature files, which aren't counted as part
+	// of the directory if they're kept inside it.
+	manifestFiles []string
 }
 
 func (c *CmdVerify) ParseArgv(ctx *cli.Context) error {
@@ -68,6 +79,13 @@ func (c *CmdVerify) ParseArgv(ctx *cli.Context) error {
 	msg := ctx.String("message")
 	infile := ctx.String("infile")
 	outfile := ctx.String("outfile")
+	detachedFilename := ctx.String("detached")
+	c.signedBy = ctx.String("signed-by")
+
+	if c.manifestDir = ctx.String("manifest"); len(c.manifestDir) > 0 {
+		return c.parseManifest(msg, infile, outfile, detachedFilename)
+	}
+
 	if ctx.Bool("no-output") {
 		if len(outfile) > 0 {
 			return errors.New("Cannot specify an outfile and no-output")
@@ -77,9 +95,6 @@ func (c *CmdVerify) ParseArgv(ctx *cli.Context) error {
 	if err := c.FilterInit(msg, infile, outfile); err != nil {
 		return err
 	}
-	c.signedBy = ctx.String("signed-by")
-	detachedFilename := ctx.String("detached")
-
 	if len(detachedFilename) > 0 {
 		data, err := ioutil.ReadFile(detachedFilename)
 		if err != nil {
@@ -91,6 +106,65 @@ func (c *CmdVerify) ParseArgv(ctx *cli.Context) error {
 	return nil
 }
 
+// parseManifest reads the manifest and its signature, and sets things up
+// to check the signature over the manifest's contents.
+func (c *CmdVerify) parseManifest(msg, infile, outfile, detachedFilename string) error {
+	if len(msg) > 0 || len(outfile) > 0 {
+		return errors.New("Cannot verify a manifest and a message at the same time")
+	}
+	manifestFile := infile
+	if len(manifestFile) == 0 {
+		manifestFile = filepath.Clean(c.manifestDir) + ".manifest"
+	}
+	if len(detachedFilename) == 0 {
+		detachedFilename = manifestFile + ".sig"
+	}
+	c.manifestFiles = []string{manifestFile, detachedFilename}
+	data, err := ioutil.ReadFile(manifestFile)
+	if err != nil {
+		return err
+	}
+	if c.manifest, err = libkb.ParseManifest(data); err != nil {
+		return err
+	}
+	if len(c.manifest) == 0 {
+		return libkb.BadManifestError{Msg: "it's empty"}
+	}
+	if c.detachedData, err = ioutil.ReadFile(detachedFilename); err != nil {
+		return err
+	}
+	// The manifest was already read in full, and the verified message
+	// doesn't need to go anywhere.
+	c.source = NewBufferSource(string(data))
+	c.sink = &DiscardSink{}
+	return nil
+}
+
+// checkManifest compares the files in the manifest directory with the
+// (verified) manifest, and lists any differences.
+func (c *CmdVerify) checkManifest() error {
+	current, err := libkb.MakeManifest(c.manifestDir, c.manifestFiles...)
+	if err != nil {
+		return err
+	}
+	d := c.manifest.Diff(current)
+	if d.Empty() {
+		fmt.Fprintf(c.G().UI.GetTerminalUI().ErrorWriter(), "All %d files match the signed manifest\n", len(current))
+		return nil
+	}
+	dui := c.G().UI.GetDumbOutputUI()
+	for _, p := range d.Added {
+		dui.Printf("added: %s\n", p)
+	}
+	for _, p := range d.Removed {
+		dui.Printf("removed: %s\n", p)
+	}
+	for _, p := range d.Modified {
+		dui.Printf("modified: %s\n", p)
+	}
+	return libkb.ManifestMismatchError{Added: len(d.Added), Removed: len(d.Removed), Modified: len(d.Modified)}
+}
+
 func (c *CmdVerify) Run() (err error) {
 	cli, err := GetSaltpackClient(c.G())
 	if err != nil {
@@ -125,7 +199,13 @@ func (c *CmdVerify) Run() (err error) {
 		err = cli.SaltpackVerify(context.TODO(), arg)
 	}
 	cerr := c.Close(err)
-	return libkb.PickFirstError(err, cerr)
+	if err = libkb.PickFirstError(err, cerr); err != nil {
+		return err
+	}
+	if len(c.manifestDir) > 0 {
+		return c.checkManifest()
+	}
+	return nil
 }
 
 func (c *CmdVerify) GetUsage() libkb.Usage {
@@ -0,0 +1,99 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+)
+
+// CmdVerifyChain checks a bundle written by `keybase export-chain`. It runs
+// entirely in the client process, and never talks to the service or the
+// network, so it works on an air-gapped machine.
+type CmdVerifyChain struct {
+	libkb.Contextified
+	filename string
+	json     bool
+}
+
+func NewCmdVerifyChain(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "verify-chain",
+		ArgumentHelp: "<bundle>",
+		Usage:        "Verify a bundle from export-chain, without network access",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "j, json",
+				Usage: "Output as JSON (default is text).",
+			},
+		},
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdVerifyChain{Contextified: libkb.NewContextified(g)}, "verify-chain", c)
+			cl.SetForkCmd(libcmdline.NoFork)
+			cl.SetLogForward(libcmdline.LogForwardNone)
+		},
+	}
+}
+
+func (c *CmdVerifyChain) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return fmt.Errorf("verify-chain takes one argument: <bundle>")
+	}
+	c.filename = ctx.Args()[0]
+	c.json = ctx.Bool("json")
+	return nil
+}
+
+func (c *CmdVerifyChain) Run() error {
+	data, err := ioutil.ReadFile(c.filename)
+	if err != nil {
+		return err
+	}
+	bundle, err := libkb.ReadChainBundle(data)
+	if err != nil {
+		return err
+	}
+	res, err := bundle.Verify(c.G())
+	if err != nil {
+		return err
+	}
+
+	dui := c.G().UI.GetDumbOutputUI()
+	if c.json {
+		b, err := json.MarshalIndent(res, "", "    ")
+		if err != nil {
+			return err
+		}
+		dui.Printf("%s\n", b)
+		return nil
+	}
+
+	dui.Printf("Verified %s (%s)\n", res.Username, res.UID)
+	dui.Printf("Merkle root: seqno %d, %s\n", res.MerkleSeqno, res.MerkleCTime)
+	dui.Printf("Sigchain: %d links\n", res.ChainSeqno)
+	if res.Eldest.IsNil() {
+		dui.Printf("No active keys\n")
+		return nil
+	}
+	dui.Printf("Eldest key: %s\n", res.Eldest)
+	for _, k := range res.Keys {
+		dui.Printf("%s %s", k.Role, k.KID)
+		if len(k.DeviceName) > 0 {
+			dui.Printf("  %s (%s)", k.DeviceName, k.DeviceType)
+		}
+		dui.Printf("\n")
+	}
+	return nil
+}
+
+func (c *CmdVerifyChain) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config: true,
+	}
+}
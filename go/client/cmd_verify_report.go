@@ -0,0 +1,101 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/cli"
+	"github.com/keybase/client/go/libcmdline"
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// CmdVerifyReport checks a report written by `keybase id --report`.
+type CmdVerifyReport struct {
+	libkb.Contextified
+	filename string
+	json     bool
+}
+
+func NewCmdVerifyReport(cl *libcmdline.CommandLine, g *libkb.GlobalContext) cli.Command {
+	return cli.Command{
+		Name:         "verify-report",
+		ArgumentHelp: "<report>",
+		Usage:        "Verify a signed identify report from `keybase id --report`",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "j, json",
+				Usage: "Output the verified report as JSON (default is a summary).",
+			},
+		},
+		Action: func(c *cli.Context) {
+			cl.ChooseCommand(&CmdVerifyReport{Contextified: libkb.NewContextified(g)}, "verify-report", c)
+		},
+	}
+}
+
+func (c *CmdVerifyReport) ParseArgv(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return fmt.Errorf("verify-report takes one argument: <report>")
+	}
+	c.filename = ctx.Args()[0]
+	c.json = ctx.Bool("json")
+	return nil
+}
+
+func (c *CmdVerifyReport) Run() error {
+	data, err := ioutil.ReadFile(c.filename)
+	if err != nil {
+		return err
+	}
+	cli, err := GetIdentifyClient(c.G())
+	if err != nil {
+		return err
+	}
+	if err := RegisterProtocolsWithContext(nil, c.G()); err != nil {
+		return err
+	}
+	report, err := cli.VerifyIdentifyReport(context.TODO(), keybase1.VerifyIdentifyReportArg{Report: string(data)})
+	if err != nil {
+		return err
+	}
+
+	dui := c.G().UI.GetDumbOutputUI()
+	if c.json {
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return err
+		}
+		dui.Printf("%s\n", b)
+		return nil
+	}
+
+	o := report.Outcome
+	dui.Printf("Signature OK: signed by %s with key %s\n", report.SignerUsername, report.SigningKID)
+	dui.Printf("Identified %q (%s) at %s\n", report.Assertion, o.Username, keybase1.FromTime(report.CTime))
+	dui.Printf("Merkle root: seqno %d\n", report.MerkleSeqno)
+	dui.Printf("Track status: %s\n", o.TrackStatus)
+	dui.Printf("Proofs: %d ok, %d failed, %d revoked\n", o.NumProofSuccesses, o.NumProofFailures, o.NumRevoked)
+	for _, pc := range report.ProofChecks {
+		status := "ok"
+		if pc.Result.ProofResult.State != keybase1.ProofState_OK {
+			status = pc.Result.ProofResult.Desc
+		}
+		dui.Printf("  %s %s: %s\n", pc.Proof.Key, pc.Proof.Value, status)
+	}
+	return nil
+}
+
+func (c *CmdVerifyReport) GetUsage() libkb.Usage {
+	return libkb.Usage{
+		Config:    true,
+		API:       true,
+		KbKeyring: true,
+	}
+}
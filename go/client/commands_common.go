@@ -14,6 +14,7 @@ import (
 
 func GetCommands(cl *libcmdline.CommandLine, g *libkb.GlobalContext) []cli.Command {
 	ret := []cli.Command{
+		NewCmdAccount(cl, g),
 		NewCmdBase62(cl, g),
 		NewCmdBTC(cl, g),
 		NewCmdCA(cl, g),
@@ -28,12 +29,15 @@ func GetCommands(cl *libcmdline.CommandLine, g *libkb.GlobalContext) []cli.Comma
 		NewCmdDevice(cl, g),
 		NewCmdDumpKeyfamily(cl, g),
 		NewCmdEncrypt(cl, g),
+		NewCmdExportChain(cl, g),
 		NewCmdID(cl, g),
+		NewCmdInspect(cl, g),
 		NewCmdListTracking(cl),
 		NewCmdListTrackers(cl),
 		NewCmdLog(cl, g),
 		NewCmdLogin(cl, g),
 		NewCmdLogout(cl, g),
+		NewCmdMerkle(cl, g),
 		NewCmdPaperKey(cl),
 		NewCmdPassphrase(cl, g),
 		NewCmdPGP(cl, g),
@@ -49,6 +53,8 @@ func GetCommands(cl *libcmdline.CommandLine, g *libkb.GlobalContext) []cli.Comma
 		NewCmdUntrack(cl, g),
 		NewCmdUpdate(cl, g),
 		NewCmdVerify(cl, g),
+		NewCmdVerifyChain(cl, g),
+		NewCmdVerifyReport(cl, g),
 		NewCmdVersion(cl, g),
 	}
 	ret = append(ret, getBuildSpecificCommands(cl, g)...)
@@ -163,6 +163,15 @@ func (s *StdoutSink) Write(b []byte) (n int, err error) {
 
 func (s *StdoutSink) HitError(e error) error { return nil }
 
+// DiscardSink drops everything written to it, for when the output
+// isn't wanted.
+type DiscardSink struct{}
+
+func (s *DiscardSink) Open() error                       { return nil }
+func (s *DiscardSink) Close() error                      { return nil }
+func (s *DiscardSink) Write(b []byte) (n int, err error) { return len(b), nil }
+func (s *DiscardSink) HitError(e error) error            { return nil }
+
 type FileSink struct {
 	name   string
 	file   *os.File
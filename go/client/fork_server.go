@@ -116,6 +116,7 @@ func makeServerCommandLine(g *libkb.GlobalContext, cl libkb.CommandLine,
 		"socket-file",
 		"gpg-options",
 		"local-rpc-debug-unsafe",
+		"log-format",
 		"run-mode",
 		"timers",
 		"tor-mode",
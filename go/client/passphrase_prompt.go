@@ -11,13 +11,17 @@ import (
 )
 
 // promptPassphrase asks the user for a passphrase.
-// Used during signup.
-func PromptPassphrase(g *libkb.GlobalContext) (keybase1.GetPassphraseRes, error) {
+// Used during signup, for the given username and email.
+func PromptPassphrase(g *libkb.GlobalContext, username, email string) (keybase1.GetPassphraseRes, error) {
 	arg := libkb.DefaultPassphraseArg(g.SecretStoreAll != nil)
 	arg.WindowTitle = "Passphrase"
 	arg.Prompt = "Pick a strong passphrase (12+ characters)"
 	arg.Type = keybase1.PassphraseType_PASS_PHRASE
-	return promptPassphraseWithArg(g, arg, "Please reenter your passphrase for confirmation")
+	check, err := passphrasePolicyCheck(g, username, email)
+	if err != nil {
+		return keybase1.GetPassphraseRes{}, err
+	}
+	return promptPassphraseWithArg(g, arg, "Please reenter your passphrase for confirmation", check)
 }
 
 // promptNewPassphrase asks the user for a new passphrase.
@@ -28,14 +32,59 @@ func PromptNewPassphrase(g *libkb.GlobalContext) (string, error) {
 	arg.Prompt = "Pick a new strong passphrase (12+ characters)"
 	arg.Type = keybase1.PassphraseType_VERIFY_PASS_PHRASE
 	arg.Features.StoreSecret.Allow = false
-	res, err := promptPassphraseWithArg(g, arg, "Please reenter your new passphrase for confirmation")
+	check, err := passphrasePolicyCheck(g, g.Env.GetUsername().String(), g.Env.GetEmail())
+	if err != nil {
+		return "", err
+	}
+	res, err := promptPassphraseWithArg(g, arg, "Please reenter your new passphrase for confirmation", check)
+	if err != nil {
+		return "", err
+	}
+	return res.Passphrase, nil
+}
+
+// PromptArchivePassphrase asks the user for the passphrase of an account
+// archive. When making a new archive, confirm is true, and the user types
+// it twice.
+func PromptArchivePassphrase(g *libkb.GlobalContext, confirm bool) (string, error) {
+	arg := libkb.DefaultPassphraseArg(false)
+	arg.WindowTitle = "Account archive passphrase"
+	arg.Type = keybase1.PassphraseType_PASS_PHRASE
+	if !confirm {
+		arg.Prompt = "Please enter the passphrase for the account archive"
+		res, err := libkb.GetPassphraseUntilCheck(arg, newClientPrompter(g), &libkb.CheckPassphraseSimple)
+		if err != nil {
+			return "", err
+		}
+		return res.Passphrase, nil
+	}
+	arg.Prompt = "Pick a strong passphrase for the account archive (12+ characters)"
+	res, err := promptPassphraseWithArg(g, arg, "Please reenter the archive passphrase for confirmation", nil)
 	if err != nil {
 		return "", err
 	}
 	return res.Passphrase, nil
 }
 
-func promptPassphraseWithArg(g *libkb.GlobalContext, arg keybase1.GUIEntryArg, promptConfirm string) (keybase1.GetPassphraseRes, error) {
+// passphrasePolicyCheck gets the service's passphrase policy, and
+// returns a check of new passphrases against it for the given account.
+func passphrasePolicyCheck(g *libkb.GlobalContext, username, email string) (func(string) error, error) {
+	cli, err := GetAccountClient(g)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := cli.GetPassphrasePolicy(context.TODO(), 0)
+	if err != nil {
+		return nil, err
+	}
+	return func(passphrase string) error {
+		return libkb.CheckPassphrasePolicy(policy, passphrase, username, email)
+	}, nil
+}
+
+// promptPassphraseWithArg asks for a new passphrase twice. If check isn't
+// nil, passphrases it rejects are asked for again, with the reason why.
+func promptPassphraseWithArg(g *libkb.GlobalContext, arg keybase1.GUIEntryArg, promptConfirm string, check func(string) error) (keybase1.GetPassphraseRes, error) {
 	prompter := newClientPrompter(g)
 
 	firstPrompt := arg.Prompt
@@ -47,6 +96,14 @@ func promptPassphraseWithArg(g *libkb.GlobalContext, arg keybase1.GUIEntryArg, p
 			return keybase1.GetPassphraseRes{}, err
 		}
 
+		if check != nil {
+			if err := check(res.Passphrase); err != nil {
+				arg.Prompt = firstPrompt
+				arg.RetryLabel = err.Error()
+				continue
+			}
+		}
+
 		// get confirmation passphrase
 		arg.RetryLabel = ""
 		arg.Prompt = promptConfirm
@@ -208,6 +208,10 @@ func (p ProvisionUI) DisplayAndPromptSecret(ctx context.Context, arg keybase1.Di
 		// this is the provisionee device (device Y)
 		// For command line app, the provisionee displays secrets only
 
+		if len(arg.DirectCode) > 0 {
+			return resp, p.displayDirectCode(arg.DirectCode)
+		}
+
 		p.parent.Output("Type this verification code into your other device:\n\n")
 		p.parent.Output("\t" + arg.Phrase + "\n\n")
 		p.parent.Output("If you are using the command line client on your other device, run this command:\n\n")
@@ -235,6 +239,22 @@ func (p ProvisionUI) DisplayAndPromptSecret(ctx context.Context, arg keybase1.Di
 	return resp, libkb.InvalidArgumentError{Msg: fmt.Sprintf("invalid ProvisionUI role: %d", p.role)}
 }
 
+// displayDirectCode shows the code that the provisioner needs to connect
+// directly to this device.
+func (p ProvisionUI) displayDirectCode(code string) error {
+	p.parent.Output("This device is waiting for your other device to connect to it directly.\n")
+	p.parent.Output("On your other device, run this command:\n\n")
+	p.parent.Output("\tkeybase device add --direct " + code + "\n\n")
+
+	encodings, err := qrcode.Encode([]byte(code))
+	// ignoring any of these errors...the command above will suffice.
+	if err == nil {
+		p.parent.Output("Or, scan this QR Code with the keybase app on your mobile phone:\n\n")
+		p.parent.Output(encodings.Terminal)
+	}
+	return nil
+}
+
 func (p ProvisionUI) PromptNewDeviceName(ctx context.Context, arg keybase1.PromptNewDeviceNameArg) (string, error) {
 	for i := 0; i < 10; i++ {
 
@@ -10,7 +10,7 @@ import (
 	"golang.org/x/net/context"
 )
 
-func GetRPCClient() (ret *rpc.Client, xp rpc.Transporter, err error) {
+func GetRPCClient() (ret rpc.GenericClient, xp rpc.Transporter, err error) {
 	return GetRPCClientWithContext(G)
 }
 
@@ -31,13 +31,35 @@ func getSocket(g *libkb.GlobalContext, clearError bool) (xp rpc.Transporter, err
 	return xp, err
 }
 
-func GetRPCClientWithContext(g *libkb.GlobalContext) (ret *rpc.Client, xp rpc.Transporter, err error) {
+func GetRPCClientWithContext(g *libkb.GlobalContext) (ret rpc.GenericClient, xp rpc.Transporter, err error) {
 	if xp, err = getSocketNoRetry(g); err == nil {
 		ret = rpc.NewClient(xp, libkb.ErrorUnwrapper{})
+		if u := g.Env.GetActingUser(); !u.IsNil() {
+			ret = actingUserClient{GenericClient: ret, username: u}
+		}
 	}
 	return
 }
 
+// actingUserClient asks the service to act as username for every call,
+// by way of libkb.ActingUserRPCTag.
+type actingUserClient struct {
+	rpc.GenericClient
+	username libkb.NormalizedUsername
+}
+
+func (c actingUserClient) Call(ctx context.Context, method string, arg interface{}, res interface{}) error {
+	return c.GenericClient.Call(c.tag(ctx), method, arg, res)
+}
+
+func (c actingUserClient) Notify(ctx context.Context, method string, arg interface{}) error {
+	return c.GenericClient.Notify(c.tag(ctx), method, arg)
+}
+
+func (c actingUserClient) tag(ctx context.Context) context.Context {
+	return rpc.AddRpcTagsToContext(ctx, rpc.CtxRpcTags{libkb.ActingUserRPCTag: c.username.String()})
+}
+
 func GetRPCServer(g *libkb.GlobalContext) (ret *rpc.Server, xp rpc.Transporter, err error) {
 	if xp, err = getSocketNoRetry(g); err == nil {
 		ret = rpc.NewServer(xp, libkb.WrapError)
@@ -49,7 +71,7 @@ func GetRPCServer(g *libkb.GlobalContext) (ret *rpc.Server, xp rpc.Transporter,
 }
 
 func GetSignupClient(g *libkb.GlobalContext) (cli keybase1.SignupClient, err error) {
-	var rpc *rpc.Client
+	var rpc rpc.GenericClient
 	if rpc, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.SignupClient{Cli: rpc}
 	}
@@ -57,7 +79,7 @@ func GetSignupClient(g *libkb.GlobalContext) (cli keybase1.SignupClient, err err
 }
 
 func GetConfigClient(g *libkb.GlobalContext) (cli keybase1.ConfigClient, err error) {
-	var rpc *rpc.Client
+	var rpc rpc.GenericClient
 	if rpc, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.ConfigClient{Cli: rpc}
 	}
@@ -65,7 +87,7 @@ func GetConfigClient(g *libkb.GlobalContext) (cli keybase1.ConfigClient, err err
 }
 
 func GetSaltpackClient(g *libkb.GlobalContext) (cli keybase1.SaltpackClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.SaltpackClient{Cli: rcli}
 	}
@@ -73,7 +95,7 @@ func GetSaltpackClient(g *libkb.GlobalContext) (cli keybase1.SaltpackClient, err
 }
 
 func GetLoginClient(g *libkb.GlobalContext) (cli keybase1.LoginClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.LoginClient{Cli: rcli}
 	}
@@ -81,7 +103,7 @@ func GetLoginClient(g *libkb.GlobalContext) (cli keybase1.LoginClient, err error
 }
 
 func GetLogClient(g *libkb.GlobalContext) (cli keybase1.LogClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.LogClient{Cli: rcli}
 	}
@@ -110,7 +132,7 @@ func RegisterProtocols(prots []rpc.Protocol) (err error) {
 }
 
 func GetIdentifyClient(g *libkb.GlobalContext) (cli keybase1.IdentifyClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.IdentifyClient{Cli: rcli}
 	}
@@ -118,7 +140,7 @@ func GetIdentifyClient(g *libkb.GlobalContext) (cli keybase1.IdentifyClient, err
 }
 
 func GetProveClient() (cli keybase1.ProveClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClient(); err == nil {
 		cli = keybase1.ProveClient{Cli: rcli}
 	}
@@ -126,7 +148,7 @@ func GetProveClient() (cli keybase1.ProveClient, err error) {
 }
 
 func GetTrackClient(g *libkb.GlobalContext) (cli keybase1.TrackClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.TrackClient{Cli: rcli}
 	}
@@ -134,7 +156,7 @@ func GetTrackClient(g *libkb.GlobalContext) (cli keybase1.TrackClient, err error
 }
 
 func GetDeviceClient() (cli keybase1.DeviceClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClient(); err == nil {
 		cli = keybase1.DeviceClient{Cli: rcli}
 	}
@@ -142,7 +164,7 @@ func GetDeviceClient() (cli keybase1.DeviceClient, err error) {
 }
 
 func GetUserClient() (cli keybase1.UserClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClient(); err == nil {
 		cli = keybase1.UserClient{Cli: rcli}
 	}
@@ -150,7 +172,7 @@ func GetUserClient() (cli keybase1.UserClient, err error) {
 }
 
 func GetSigsClient() (cli keybase1.SigsClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClient(); err == nil {
 		cli = keybase1.SigsClient{Cli: rcli}
 	}
@@ -158,7 +180,7 @@ func GetSigsClient() (cli keybase1.SigsClient, err error) {
 }
 
 func GetPGPClient() (cli keybase1.PGPClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClient(); err == nil {
 		cli = keybase1.PGPClient{Cli: rcli}
 	}
@@ -166,7 +188,7 @@ func GetPGPClient() (cli keybase1.PGPClient, err error) {
 }
 
 func GetRevokeClient() (cli keybase1.RevokeClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClient(); err == nil {
 		cli = keybase1.RevokeClient{Cli: rcli}
 	}
@@ -174,7 +196,7 @@ func GetRevokeClient() (cli keybase1.RevokeClient, err error) {
 }
 
 func GetBTCClient(g *libkb.GlobalContext) (cli keybase1.BTCClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.BTCClient{Cli: rcli}
 	}
@@ -182,7 +204,7 @@ func GetBTCClient(g *libkb.GlobalContext) (cli keybase1.BTCClient, err error) {
 }
 
 func GetCtlClient(g *libkb.GlobalContext) (cli keybase1.CtlClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.CtlClient{Cli: rcli}
 	}
@@ -190,7 +212,7 @@ func GetCtlClient(g *libkb.GlobalContext) (cli keybase1.CtlClient, err error) {
 }
 
 func GetAccountClient(g *libkb.GlobalContext) (cli keybase1.AccountClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClientWithContext(g); err == nil {
 		cli = keybase1.AccountClient{Cli: rcli}
 	}
@@ -198,7 +220,7 @@ func GetAccountClient(g *libkb.GlobalContext) (cli keybase1.AccountClient, err e
 }
 
 func GetFavoriteClient() (cli keybase1.FavoriteClient, err error) {
-	var rcli *rpc.Client
+	var rcli rpc.GenericClient
 	if rcli, _, err = GetRPCClient(); err == nil {
 		cli = keybase1.FavoriteClient{Cli: rcli}
 	}
@@ -241,6 +263,15 @@ func GetSecretKeysClient(g *libkb.GlobalContext) (cli keybase1.SecretKeysClient,
 	return cli, nil
 }
 
+func GetMerkleClient(g *libkb.GlobalContext) (cli keybase1.MerkleClient, err error) {
+	rcli, _, err := GetRPCClientWithContext(g)
+	if err != nil {
+		return cli, err
+	}
+	cli = keybase1.MerkleClient{Cli: rcli}
+	return cli, nil
+}
+
 func introduceMyself(g *libkb.GlobalContext, xp rpc.Transporter) error {
 	cli := rpc.NewClient(xp, libkb.ErrorUnwrapper{})
 	ccli := keybase1.ConfigClient{Cli: cli}
@@ -731,6 +731,15 @@ func (l LoginUI) DisplayPaperKeyPhrase(_ context.Context, arg keybase1.DisplayPa
 	if l.noPrompt {
 		return nil
 	}
+	if len(arg.Shares) > 0 {
+		l.parent.Printf("Your secret paper key has been split into %d shares. Any %d of them\n", len(arg.Shares), arg.ShareThreshold)
+		l.parent.Printf("can recover your account, but fewer are useless:\n\n")
+		for i, s := range arg.Shares {
+			l.parent.Printf("\t%d. %s\n\n", i+1, s)
+		}
+		l.parent.Printf("Write each one down and keep them in separate, safe places.\n")
+		return nil
+	}
 	l.parent.Printf("Here is your secret paper key phrase:\n\n")
 	l.parent.Printf("\t%s\n\n", arg.Phrase)
 	l.parent.Printf("Write it down and keep somewhere safe.\n")
@@ -0,0 +1,76 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"github.com/keybase/client/go/libkb"
+)
+
+// AccountExport is an engine that seals the current user's local state
+// on this device into an archive, for moving the device to another
+// machine.
+type AccountExport struct {
+	libkb.Contextified
+	passphrase string
+	sealed     []byte
+}
+
+// NewAccountExport creates an AccountExport engine. The archive is
+// sealed with a key derived from passphrase.
+func NewAccountExport(g *libkb.GlobalContext, passphrase string) *AccountExport {
+	return &AccountExport{
+		Contextified: libkb.NewContextified(g),
+		passphrase:   passphrase,
+	}
+}
+
+// Name is the unique engine name.
+func (e *AccountExport) Name() string {
+	return "AccountExport"
+}
+
+// GetPrereqs returns the engine prereqs.
+func (e *AccountExport) Prereqs() Prereqs {
+	return Prereqs{
+		Device: true,
+	}
+}
+
+// RequiredUIs returns the required UIs.
+func (e *AccountExport) RequiredUIs() []libkb.UIKind {
+	return []libkb.UIKind{}
+}
+
+// SubConsumers returns the other UI consumers for this engine.
+func (e *AccountExport) SubConsumers() []libkb.UIConsumer {
+	return []libkb.UIConsumer{
+		&FavoriteList{},
+	}
+}
+
+// Run starts the engine.
+func (e *AccountExport) Run(ctx *Context) error {
+	if len(e.passphrase) == 0 {
+		return libkb.PassphraseError{Msg: "the archive needs a passphrase"}
+	}
+
+	archive, err := libkb.NewAccountArchive(e.G(), e.G().Env.GetUsername())
+	if err != nil {
+		return err
+	}
+
+	favorites := NewFavoriteList(e.G())
+	if err := RunEngine(favorites, ctx); err != nil {
+		return err
+	}
+	archive.Favorites = favorites.Favorites()
+
+	e.sealed, err = archive.Seal(e.passphrase)
+	return err
+}
+
+// Sealed returns the sealed archive that Run made.
+func (e *AccountExport) Sealed() []byte {
+	return e.sealed
+}
@@ -0,0 +1,81 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/keybase/client/go/libkb"
+)
+
+func TestAccountExportImport(t *testing.T) {
+	// The old machine.
+	tc := SetupEngineTest(t, "export")
+	defer tc.Cleanup()
+	fu := CreateAndSignupFakeUser(tc, "acct")
+	addfav("t_alice,t_bob", true, tc)
+
+	export := NewAccountExport(tc.G, "moving to a new laptop")
+	if err := RunEngine(export, &Context{}); err != nil {
+		t.Fatal(err)
+	}
+	sealed := export.Sealed()
+
+	// The new machine.
+	tc2 := SetupEngineTest(t, "import")
+	defer tc2.Cleanup()
+	ctx := &Context{
+		LoginUI:  &libkb.TestLoginUI{Username: fu.Username},
+		SecretUI: fu.NewSecretUI(),
+	}
+
+	eng := NewAccountImport(tc2.G, sealed, "not the passphrase", false)
+	if err := RunEngine(eng, ctx); err == nil {
+		t.Fatal("imported with the wrong passphrase")
+	} else if _, ok := err.(libkb.PassphraseError); !ok {
+		t.Fatalf("wrong passphrase error: %T %s", err, err)
+	}
+
+	eng = NewAccountImport(tc2.G, sealed, "moving to a new laptop", false)
+	if err := RunEngine(eng, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := AssertProvisioned(tc2); err != nil {
+		t.Fatal(err)
+	}
+	if tc2.G.Env.GetDeviceID() != tc.G.Env.GetDeviceID() {
+		t.Errorf("device ID %s, expected %s", tc2.G.Env.GetDeviceID(), tc.G.Env.GetDeviceID())
+	}
+	if len(listfav(tc2)) != 1 {
+		t.Errorf("favorites len: %d, expected 1", len(listfav(tc2)))
+	}
+
+	// The device can sign with the restored keys.
+	me, err := libkb.LoadMe(libkb.NewLoadUserArg(tc2.G))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tc2.G.Keyrings.GetSecretKeyWithPrompt(libkb.SecretKeyPromptArg{
+		Ska: libkb.SecretKeyArg{
+			Me:      me,
+			KeyType: libkb.DeviceSigningKeyType,
+		},
+		SecretUI: ctx.SecretUI,
+		Reason:   "testing",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Importing again would replace the device.
+	eng = NewAccountImport(tc2.G, sealed, "moving to a new laptop", false)
+	if err := RunEngine(eng, ctx); err == nil {
+		t.Fatal("imported over an existing device")
+	} else if _, ok := err.(libkb.AccountArchiveExistsError); !ok {
+		t.Fatalf("existing device error: %T %s", err, err)
+	}
+	eng = NewAccountImport(tc2.G, sealed, "moving to a new laptop", true)
+	if err := RunEngine(eng, ctx); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// AccountImport is an engine that restores an archive made by
+// AccountExport onto this machine. Whatever session there was is thrown
+// away, and the archived user logs in again with the restored device.
+type AccountImport struct {
+	libkb.Contextified
+	sealed     []byte
+	passphrase string
+	force      bool
+}
+
+// NewAccountImport creates an AccountImport engine. Unless force is set,
+// it won't replace a user who already has a device or secret keys here.
+func NewAccountImport(g *libkb.GlobalContext, sealed []byte, passphrase string, force bool) *AccountImport {
+	return &AccountImport{
+		Contextified: libkb.NewContextified(g),
+		sealed:       sealed,
+		passphrase:   passphrase,
+		force:        force,
+	}
+}
+
+// Name is the unique engine name.
+func (e *AccountImport) Name() string {
+	return "AccountImport"
+}
+
+// GetPrereqs returns the engine prereqs.
+func (e *AccountImport) Prereqs() Prereqs {
+	return Prereqs{}
+}
+
+// RequiredUIs returns the required UIs.
+func (e *AccountImport) RequiredUIs() []libkb.UIKind {
+	return []libkb.UIKind{
+		libkb.LoginUIKind,
+		libkb.SecretUIKind,
+	}
+}
+
+// SubConsumers returns the other UI consumers for this engine.
+func (e *AccountImport) SubConsumers() []libkb.UIConsumer {
+	return []libkb.UIConsumer{
+		&loginProvisionedDevice{},
+		&FavoriteList{},
+		&FavoriteAdd{},
+	}
+}
+
+// Run starts the engine.
+func (e *AccountImport) Run(ctx *Context) error {
+	archive, err := libkb.OpenAccountArchive(e.sealed, e.passphrase)
+	if err != nil {
+		return err
+	}
+
+	if !e.force {
+		exists, err := libkb.AccountArchiveExists(e.G(), archive.Username)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return libkb.AccountArchiveExistsError{Username: archive.Username}
+		}
+	}
+
+	// Start from a clean slate: no session, and no stored secret for
+	// the old keyring.
+	if err := e.G().Logout(); err != nil {
+		return err
+	}
+	if err := libkb.ClearStoredSecret(e.G(), archive.Username); err != nil {
+		e.G().Log.Debug("error clearing stored secret for %s: %s", archive.Username, err)
+	}
+
+	if err := archive.Restore(e.G()); err != nil {
+		return err
+	}
+	e.G().Log.Debug("restored account archive for %s from %s", archive.Username, keybase1.FromTime(archive.CTime))
+
+	login := newLoginProvisionedDevice(e.G(), archive.Username.String())
+	if err := RunEngine(login, ctx); err != nil {
+		return err
+	}
+
+	return e.restoreFavorites(ctx, archive.Favorites)
+}
+
+// restoreFavorites adds back any archived favorites that the server
+// doesn't have anymore.
+func (e *AccountImport) restoreFavorites(ctx *Context, favorites []keybase1.Folder) error {
+	if len(favorites) == 0 {
+		return nil
+	}
+	list := NewFavoriteList(e.G())
+	if err := RunEngine(list, ctx); err != nil {
+		return err
+	}
+	type folderKey struct {
+		name    string
+		private bool
+	}
+	have := make(map[folderKey]bool)
+	for _, f := range list.Favorites() {
+		have[folderKey{f.Name, f.Private}] = true
+	}
+	for _, f := range favorites {
+		if have[folderKey{f.Name, f.Private}] {
+			continue
+		}
+		add := NewFavoriteAdd(&keybase1.FavoriteAddArg{Folder: f}, e.G())
+		if err := RunEngine(add, ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"github.com/keybase/client/go/libkb"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+// ChainExport fetches everything needed to check a user's keys offline,
+// and packs it into a libkb.ChainBundle. The bundle is verified before
+// it's handed back, so a user whose chain moved while we were fetching
+// gets an error rather than a bundle that can never verify.
+type ChainExport struct {
+	libkb.Contextified
+	username string
+	bundle   *libkb.ChainBundle
+}
+
+var _ (Engine) = (*ChainExport)(nil)
+
+func NewChainExport(g *libkb.GlobalContext, username string) *ChainExport {
+	return &ChainExport{
+		Contextified: libkb.NewContextified(g),
+		username:     username,
+	}
+}
+
+// Name is the unique engine name.
+func (e *ChainExport) Name() string {
+	return "ChainExport"
+}
+
+// GetPrereqs returns the engine prereqs.
+func (e *ChainExport) Prereqs() Prereqs {
+	return Prereqs{}
+}
+
+// RequiredUIs returns the required UIs.
+func (e *ChainExport) RequiredUIs() []libkb.UIKind {
+	return []libkb.UIKind{}
+}
+
+// SubConsumers returns the other UI consumers for this engine.
+func (e *ChainExport) SubConsumers() []libkb.UIConsumer {
+	return nil
+}
+
+// Run the engine.
+func (e *ChainExport) Run(ctx *Context) (err error) {
+	defer e.G().Trace("ChainExport::Run", func() error { return err })()
+
+	user, err := libkb.LoadUser(libkb.NewLoadUserByNameArg(e.G(), e.username))
+	if err != nil {
+		return err
+	}
+	uid := user.GetUID()
+
+	pathJSON, err := e.get("merkle/path", libkb.HTTPArgs{"uid": libkb.UIDArg(uid), "poll": libkb.I{Val: 10}}, "")
+	if err != nil {
+		return err
+	}
+	vp, err := libkb.NewVerificationPathFromJSON(e.G(), pathJSON)
+	if err != nil {
+		return err
+	}
+	merkleKey, err := e.G().MerkleClient.RootSigningKey(vp.Root())
+	if err != nil {
+		return err
+	}
+
+	userJSON, err := e.get("user/lookup", libkb.HTTPArgs{"uid": libkb.UIDArg(uid)}, "them")
+	if err != nil {
+		return err
+	}
+	sigsJSON, err := e.get("sig/get", libkb.HTTPArgs{"uid": libkb.UIDArg(uid), "low": libkb.I{Val: 0}}, "sigs")
+	if err != nil {
+		return err
+	}
+
+	bundle, err := libkb.NewChainBundle(uid, user.GetName(), userJSON, pathJSON, sigsJSON, merkleKey)
+	if err != nil {
+		return err
+	}
+	if _, err = bundle.Verify(e.G()); err != nil {
+		return err
+	}
+	e.bundle = bundle
+	return nil
+}
+
+func (e *ChainExport) get(endpoint string, args libkb.HTTPArgs, key string) (*jsonw.Wrapper, error) {
+	res, err := e.G().API.Get(libkb.APIArg{
+		Endpoint:     endpoint,
+		NeedSession:  false,
+		Args:         args,
+		Contextified: libkb.NewContextified(e.G()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(key) == 0 {
+		return res.Body, nil
+	}
+	return res.Body.AtKey(key), nil
+}
+
+// Bundle returns the verified bundle.
+func (e *ChainExport) Bundle() *libkb.ChainBundle {
+	return e.bundle
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/keybase/client/go/libkb"
+)
+
+func TestChainExport(t *testing.T) {
+	tc := SetupEngineTest(t, "ChainExport")
+	defer tc.Cleanup()
+
+	eng := NewChainExport(tc.G, "t_alice")
+	if err := RunEngine(eng, &Context{}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := eng.Bundle().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := libkb.ReadChainBundle(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := bundle.Verify(tc.G)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Username != "t_alice" {
+		t.Errorf("username: got %q, wanted t_alice", res.Username)
+	}
+	if len(res.Keys) == 0 {
+		t.Errorf("expected some active keys")
+	}
+
+	// A chain that's missing its tail must not match the merkle leaf.
+	var sigs []json.RawMessage
+	if err := json.Unmarshal(bundle.Sigs, &sigs); err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Sigs, err = json.Marshal(sigs[:len(sigs)-1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundle.Verify(tc.G); err == nil {
+		t.Fatal("expected a truncated chain to fail")
+	}
+}
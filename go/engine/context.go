@@ -26,6 +26,8 @@ type Context struct {
 	NetContext   context.Context
 	SaltpackUI   libkb.SaltpackUI
 
+	IdentifyBatchUI libkb.IdentifyBatchUI
+
 	SessionID int
 }
 
@@ -51,6 +53,8 @@ func (c *Context) HasUI(kind libkb.UIKind) bool {
 		return c.UpdateUI != nil
 	case libkb.SaltpackUIKind:
 		return c.SaltpackUI != nil
+	case libkb.IdentifyBatchUIKind:
+		return c.IdentifyBatchUI != nil
 	}
 	panic(fmt.Sprintf("unhandled kind:  %d", kind))
 }
@@ -54,14 +54,27 @@ func SignED25519(g *libkb.GlobalContext, secretUI libkb.SecretUI,
 		return
 	}
 
-	kp, ok := signingKey.(libkb.NaclSigningKeyPair)
-	if !ok || kp.Private == nil {
+	var sig libkb.NaclSignature
+	var publicKey libkb.NaclSigningKeyPublic
+	switch kp := signingKey.(type) {
+	case libkb.NaclSigningKeyPair:
+		if kp.Private == nil {
+			err = libkb.KeyCannotSignError{}
+			return
+		}
+		sig = *kp.Private.Sign(arg.Msg)
+		publicKey = kp.Public
+	case *libkb.PKCS11SigningKey:
+		sig, err = kp.SignED25519(arg.Msg)
+		if err != nil {
+			return
+		}
+		publicKey = kp.Public
+	default:
 		err = libkb.KeyCannotSignError{}
 		return
 	}
 
-	sig := *kp.Private.Sign(arg.Msg)
-	publicKey := kp.Public
 	ret = keybase1.ED25519SignatureInfo{
 		Sig:       keybase1.ED25519Signature(sig),
 		PublicKey: keybase1.ED25519PublicKey(publicKey),
@@ -80,13 +93,18 @@ func SignToString(g *libkb.GlobalContext, secretUI libkb.SecretUI,
 		return
 	}
 
-	kp, ok := signingKey.(libkb.NaclSigningKeyPair)
-	if !ok || kp.Private == nil {
+	switch kp := signingKey.(type) {
+	case libkb.NaclSigningKeyPair:
+		if kp.Private == nil {
+			err = libkb.KeyCannotSignError{}
+			return
+		}
+		sig, _, err = kp.SignToString(arg.Msg)
+	case *libkb.PKCS11SigningKey:
+		sig, _, err = kp.SignToString(arg.Msg)
+	default:
 		err = libkb.KeyCannotSignError{}
-		return
 	}
-
-	sig, _, err = kp.SignToString(arg.Msg)
 	return
 }
 
@@ -131,21 +149,28 @@ func UnboxBytes32Any(g *libkb.GlobalContext, secretUI libkb.SecretUI, arg keybas
 }
 
 func unboxBytes32(encryptionKey libkb.GenericKey, ciphertext keybase1.EncryptedBytes32, nonce keybase1.BoxNonce, peerPubKey keybase1.BoxPublicKey) (bytes32 keybase1.Bytes32, err error) {
-	kp, ok := encryptionKey.(libkb.NaclDHKeyPair)
-	if !ok {
+	var decryptedData []byte
+	switch kp := encryptionKey.(type) {
+	case libkb.NaclDHKeyPair:
+		if kp.Private == nil {
+			err = libkb.NoSecretKeyError{}
+			return
+		}
+		var ok bool
+		decryptedData, ok = box.Open(nil, ciphertext[:], (*[24]byte)(&nonce), (*[32]byte)(&peerPubKey), (*[32]byte)(kp.Private))
+		if !ok {
+			err = libkb.DecryptionError{}
+			return
+		}
+	case *libkb.PKCS11DHKey:
+		decryptedData, err = kp.OpenBox(ciphertext[:], (*[24]byte)(&nonce), (*libkb.NaclDHKeyPublic)(&peerPubKey))
+		if err != nil {
+			return
+		}
+	default:
 		err = libkb.KeyCannotDecryptError{}
 		return
 	}
-	if kp.Private == nil {
-		err = libkb.NoSecretKeyError{}
-		return
-	}
-
-	decryptedData, ok := box.Open(nil, ciphertext[:], (*[24]byte)(&nonce), (*[32]byte)(&peerPubKey), (*[32]byte)(kp.Private))
-	if !ok {
-		err = libkb.DecryptionError{}
-		return
-	}
 
 	if len(decryptedData) != len(bytes32) {
 		err = libkb.DecryptionError{}
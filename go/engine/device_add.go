@@ -14,6 +14,7 @@ import (
 // DeviceAdd is an engine.
 type DeviceAdd struct {
 	libkb.Contextified
+	directCode string
 }
 
 // NewDeviceAdd creates a DeviceAdd engine.
@@ -23,6 +24,16 @@ func NewDeviceAdd(g *libkb.GlobalContext) *DeviceAdd {
 	}
 }
 
+// NewDeviceAddDirect creates a DeviceAdd engine that connects
+// directly to the new device, using the code it showed, instead
+// of going through the server.
+func NewDeviceAddDirect(g *libkb.GlobalContext, directCode string) *DeviceAdd {
+	return &DeviceAdd{
+		Contextified: libkb.NewContextified(g),
+		directCode:   directCode,
+	}
+}
+
 // Name is the unique engine name.
 func (e *DeviceAdd) Name() string {
 	return "DeviceAdd"
@@ -50,6 +61,10 @@ func (e *DeviceAdd) Run(ctx *Context) (err error) {
 	e.G().Log.Debug("+ DeviceAdd.Run()")
 	defer func() { e.G().Log.Debug("- DeviceAdd.Run() -> %s", libkb.ErrToOk(err)) }()
 
+	if len(e.directCode) > 0 {
+		return e.runDirect(ctx)
+	}
+
 	arg := keybase1.ChooseDeviceTypeArg{Kind: keybase1.ChooseType_NEW_DEVICE}
 	provisioneeType, err := ctx.ProvisionUI.ChooseDeviceType(context.TODO(), arg)
 	if err != nil {
@@ -124,3 +139,42 @@ func (e *DeviceAdd) Run(ctx *Context) (err error) {
 
 	return nil
 }
+
+// runDirect provisions the new device over a direct connection to it.
+// The new device is already waiting with the secret in the code, so
+// there's nothing to display or prompt for.
+func (e *DeviceAdd) runDirect(ctx *Context) error {
+	code, err := libkb.ParseKex2DirectCode(e.directCode)
+	if err != nil {
+		return err
+	}
+
+	pps, err := e.G().LoginState().GetPassphraseStream(ctx.SecretUI)
+	if err != nil {
+		return err
+	}
+
+	router, err := libkb.DialKexDirect(e.G(), code)
+	if err != nil {
+		return err
+	}
+	defer router.Close()
+
+	// The provisioner always listens on a secret of its own, but the
+	// new device won't use it.
+	secret, err := libkb.NewKex2Secret()
+	if err != nil {
+		return err
+	}
+	provisioner := NewKex2Provisioner(e.G(), secret.Secret(), pps)
+	provisioner.mr = router
+	go provisioner.AddSecret(code.Secret)
+
+	if err = RunEngine(provisioner, ctx); err != nil {
+		if err == kex2.ErrHelloTimeout {
+			return libkb.CanceledError{M: "Failed to provision device: is the code from the other device correct?"}
+		}
+		return err
+	}
+	return nil
+}
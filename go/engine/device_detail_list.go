@@ -0,0 +1,67 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"sort"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// DeviceDetailList is an engine that gets a list of all the user's
+// devices, along with where each came from and when it was last used.
+type DeviceDetailList struct {
+	details []keybase1.DeviceDetail
+	libkb.Contextified
+}
+
+// NewDeviceDetailList creates a DeviceDetailList engine.
+func NewDeviceDetailList(g *libkb.GlobalContext) *DeviceDetailList {
+	return &DeviceDetailList{
+		Contextified: libkb.NewContextified(g),
+	}
+}
+
+func (d *DeviceDetailList) Name() string {
+	return "DeviceDetailList"
+}
+
+func (d *DeviceDetailList) Prereqs() Prereqs {
+	return Prereqs{Session: true}
+}
+
+func (d *DeviceDetailList) RequiredUIs() []libkb.UIKind {
+	return []libkb.UIKind{libkb.LogUIKind}
+}
+
+func (d *DeviceDetailList) SubConsumers() []libkb.UIConsumer {
+	return nil
+}
+
+// Run starts the engine.
+func (d *DeviceDetailList) Run(ctx *Context) error {
+	me, err := libkb.LoadMe(libkb.NewLoadUserForceArg(d.G()))
+	if err != nil {
+		return err
+	}
+	details, err := me.DeviceDetails()
+	if err != nil {
+		return err
+	}
+	sort.Sort(ddname(details))
+	d.details = details
+	return nil
+}
+
+// Details returns the devices for a user.
+func (d *DeviceDetailList) Details() []keybase1.DeviceDetail {
+	return d.details
+}
+
+type ddname []keybase1.DeviceDetail
+
+func (d ddname) Len() int           { return len(d) }
+func (d ddname) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d ddname) Less(i, j int) bool { return d[i].Device.Name < d[j].Device.Name }
@@ -0,0 +1,158 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+func TestDeviceDetailList(t *testing.T) {
+	tc := SetupEngineTest(t, "devicedetaillist")
+	defer tc.Cleanup()
+
+	CreateAndSignupFakeUser(tc, "login")
+
+	ctx := &Context{LogUI: tc.G.UI.GetLogUI()}
+	eng := NewDeviceDetailList(tc.G)
+	if err := RunEngine(eng, ctx); err != nil {
+		t.Fatal(err)
+	}
+	details := eng.Details()
+	if len(details) != 2 {
+		t.Fatalf("devices: %d, expected 2", len(details))
+	}
+
+	deviceID := tc.G.Env.GetDeviceID()
+	for _, d := range details {
+		if d.AddedSeqno <= 0 || d.AddedTime == 0 {
+			t.Errorf("%s: added at seqno %d, time %d", d.Device.Name, d.AddedSeqno, d.AddedTime)
+		}
+		if d.Device.DeviceID == deviceID {
+			if !d.Provisioner.IsNil() {
+				t.Errorf("first device was provisioned by %s", d.Provisioner)
+			}
+			if d.LastSeenSigningTime == 0 {
+				t.Errorf("first device has never signed anything")
+			}
+		} else if d.Provisioner != deviceID {
+			t.Errorf("paper key provisioned by %q, expected %s", d.Provisioner, deviceID)
+		}
+	}
+
+	if err := libkb.SetDeviceNote(tc.G, deviceID, "my laptop"); err != nil {
+		t.Fatal(err)
+	}
+	eng = NewDeviceDetailList(tc.G)
+	if err := RunEngine(eng, ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range eng.Details() {
+		if d.Device.DeviceID == deviceID && d.Note != "my laptop" {
+			t.Errorf("note %q, expected %q", d.Note, "my laptop")
+		}
+	}
+}
+
+func TestDeviceDetailListSeenSigning(t *testing.T) {
+	tc := SetupEngineTest(t, "devicedetaillist")
+	defer tc.Cleanup()
+
+	fu := CreateAndSignupFakeUser(tc, "login")
+	ctx := &Context{
+		IdentifyUI: &FakeIdentifyUI{},
+		SecretUI:   fu.NewSecretUI(),
+		LogUI:      tc.G.UI.GetLogUI(),
+		SaltpackUI: &fakeSaltpackUI{},
+	}
+
+	deviceID := tc.G.Env.GetDeviceID()
+	lastSeen := func() keybase1.Time {
+		eng := NewDeviceDetailList(tc.G)
+		if err := RunEngine(eng, ctx); err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range eng.Details() {
+			if d.Device.DeviceID == deviceID {
+				return d.LastSeenSigningTime
+			}
+		}
+		t.Fatalf("no device %s", deviceID)
+		return 0
+	}
+
+	// Loading the user verified the links the device signed.
+	seen := lastSeen()
+	me, err := libkb.LoadMe(libkb.NewLoadUserForceArg(tc.G))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := me.GetComputedKeyFamily().GetSibkeyForDevice(deviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded, err := libkb.GetKeySeenSigning(tc.G, key.GetKID()); err != nil {
+		t.Fatal(err)
+	} else if recorded == 0 || recorded > seen {
+		t.Errorf("recorded %d for the device's sigchain links, reported %d", recorded, seen)
+	}
+
+	// A saltpack signature it verifies.
+	var sig bytes.Buffer
+	sign := NewSaltpackSign(&SaltpackSignArg{
+		Sink:   libkb.NopWriteCloser{W: &sig},
+		Source: ioutil.NopCloser(strings.NewReader("signed")),
+	}, tc.G)
+	if err := RunEngine(sign, ctx); err != nil {
+		t.Fatal(err)
+	}
+	before := keybase1.ToTime(time.Now())
+	verify := NewSaltpackVerify(&SaltpackVerifyArg{
+		Sink:   libkb.NopWriteCloser{W: ioutil.Discard},
+		Source: strings.NewReader(sig.String()),
+	}, tc.G)
+	if err := RunEngine(verify, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if seen = lastSeen(); seen < before {
+		t.Errorf("last seen signing %d, before verifying a signature %d", seen, before)
+	}
+
+	// A signcrypted message it opens.
+	sink := libkb.NewBufferCloser()
+	enc := NewSaltpackEncrypt(&SaltpackEncryptArg{
+		Opts:   keybase1.SaltpackEncryptOptions{Signcrypt: true},
+		Source: strings.NewReader("signcrypted"),
+		Sink:   sink,
+	}, tc.G)
+	if err := RunEngine(enc, ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	before = keybase1.ToTime(time.Now())
+	dec := NewSaltpackDecrypt(&SaltpackDecryptArg{
+		Source: strings.NewReader(sink.String()),
+		Sink:   libkb.NewBufferCloser(),
+	}, tc.G)
+	if err := RunEngine(dec, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if seen = lastSeen(); seen < before {
+		t.Errorf("last seen signing %d, before opening a signcrypted message %d", seen, before)
+	}
+
+	// An older time doesn't replace a later one.
+	if err := libkb.RecordKeySeenSigning(tc.G, key.GetKID(), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if s := lastSeen(); s != seen {
+		t.Errorf("last seen signing went from %d to %d", seen, s)
+	}
+}
@@ -23,6 +23,10 @@ type DeviceKeyfinderArg struct {
 	NeedEncryptKeys bool
 	NeedVerifyKeys  bool
 	Self            *libkb.User
+	// Batch identifies all of the users at once with Identify2Batch,
+	// rather than one at a time. There's no chance to interact with
+	// the IdentifyUI for each user in that case.
+	Batch bool
 }
 
 // NewDeviceKeyfinder creates a DeviceKeyfinder engine.
@@ -74,6 +78,9 @@ func (e *DeviceKeyfinder) UsersPlusKeys() map[keybase1.UID](*keybase1.UserPlusKe
 }
 
 func (e *DeviceKeyfinder) identifyUsers(ctx *Context) error {
+	if e.arg.Batch {
+		return e.identifyUsersBatch(ctx)
+	}
 	for _, u := range e.arg.Users {
 		if err := e.identifyUser(ctx, u); err != nil {
 			return err
@@ -100,6 +107,53 @@ func (e *DeviceKeyfinder) identifyUser(ctx *Context, user string) error {
 	return nil
 }
 
+func (e *DeviceKeyfinder) identifyUsersBatch(ctx *Context) error {
+	if len(e.arg.Users) == 0 {
+		return nil
+	}
+	arg := keybase1.Identify2BatchArg{
+		Assertions: e.arg.Users,
+		Reason: keybase1.IdentifyReason{
+			Type: keybase1.IdentifyReasonType_ENCRYPT,
+		},
+	}
+	// The batch results come back here rather than to the caller, so
+	// it doesn't need an IdentifyBatchUI of its own.
+	bctx := &Context{
+		LogUI:           ctx.LogUI,
+		IdentifyBatchUI: discardIdentifyBatchUI{},
+		NetContext:      ctx.NetContext,
+		SessionID:       ctx.SessionID,
+	}
+	eng := NewIdentify2Batch(e.G(), &arg)
+	if err := RunEngine(eng, bctx); err != nil {
+		return err
+	}
+
+	// Report failures in the order the users were given, not the order
+	// that the identifies happened to finish in.
+	results := make(map[string]keybase1.Identify2BatchResult)
+	for _, res := range eng.Results() {
+		results[res.Assertion] = res
+	}
+	for _, u := range e.arg.Users {
+		res, ok := results[u]
+		if !ok {
+			return libkb.IdentifyFailedError{Assertion: u, Reason: "no result"}
+		}
+		if res.Status != nil {
+			return libkb.IdentifyFailedError{Assertion: u, Reason: res.Status.Desc}
+		}
+		if res.Upk == nil {
+			return libkb.IdentifyFailedError{Assertion: u, Reason: "no user found"}
+		}
+		if err := e.addUser(&keybase1.Identify2Res{Upk: *res.Upk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (e *DeviceKeyfinder) hasUser(upk *keybase1.UserPlusKeys) bool {
 	_, ok := e.userMap[upk.Uid]
 	return ok
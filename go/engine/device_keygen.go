@@ -16,6 +16,7 @@ type DeviceKeygenArgs struct {
 	DeviceName string
 	DeviceType string
 	Lks        *libkb.LKSec
+	Token      *libkb.PKCS11Token // optional; if set, the keys are made on it
 }
 
 // DeviceKeygenPushArgs determines how the push will run.  There are
@@ -37,12 +38,17 @@ type DeviceKeygenArgs struct {
 // The User argument is optional, but it is necessary if the
 // user's sigchain changes between key generation and key push.
 //
+// RevokeKIDs is also optional.  For a sibling, they are revoked
+// in the same link that delegates the new signing key (this is
+// how a device rotates its keys).
+//
 type DeviceKeygenPushArgs struct {
 	IsEldest       bool
 	SkipSignerPush bool
 	Signer         libkb.GenericKey
 	EldestKID      keybase1.KID
-	User           *libkb.User // optional
+	User           *libkb.User    // optional
+	RevokeKIDs     []keybase1.KID // optional
 }
 
 type DeviceKeygen struct {
@@ -96,11 +102,13 @@ func (e *DeviceKeygen) Run(ctx *Context) error {
 }
 
 func (e *DeviceKeygen) SigningKeyPublic() (libkb.NaclSigningKeyPublic, error) {
-	s, ok := e.naclSignGen.GetKeyPair().(libkb.NaclSigningKeyPair)
-	if !ok {
-		return libkb.NaclSigningKeyPublic{}, libkb.BadKeyError{Msg: fmt.Sprintf("invalid key type %T", e.naclSignGen.GetKeyPair())}
+	switch s := e.naclSignGen.GetKeyPair().(type) {
+	case libkb.NaclSigningKeyPair:
+		return s.Public, nil
+	case *libkb.PKCS11SigningKey:
+		return s.Public, nil
 	}
-	return s.Public, nil
+	return libkb.NaclSigningKeyPublic{}, libkb.BadKeyError{Msg: fmt.Sprintf("invalid key type %T", e.naclSignGen.GetKeyPair())}
 
 }
 
@@ -147,6 +155,11 @@ func (e *DeviceKeygen) setup(ctx *Context) {
 		return
 	}
 
+	if e.args.Token != nil {
+		e.setupPKCS11(ctx)
+		return
+	}
+
 	signArg := e.newNaclArg(ctx, func() (libkb.NaclKeyPair, error) {
 		kp, err := libkb.GenerateNaclSigningKeyPair()
 		if err != nil {
@@ -166,6 +179,28 @@ func (e *DeviceKeygen) setup(ctx *Context) {
 	e.naclEncGen = libkb.NewNaclKeyGen(encArg)
 }
 
+// setupPKCS11 is setup for keys that are made on a PKCS#11 token.
+func (e *DeviceKeygen) setupPKCS11(ctx *Context) {
+	token := e.args.Token
+	signArg := e.newNaclArg(ctx, func() (libkb.NaclKeyPair, error) {
+		k, err := token.GenerateSigningKey()
+		if err != nil {
+			return nil, err
+		}
+		return k, nil
+	}, libkb.NaclEdDSAExpireIn)
+	e.naclSignGen = libkb.NewNaclKeyGen(signArg)
+
+	encArg := e.newNaclArg(ctx, func() (libkb.NaclKeyPair, error) {
+		k, err := token.GenerateDHKey()
+		if err != nil {
+			return nil, err
+		}
+		return k, nil
+	}, libkb.NaclDHExpireIn)
+	e.naclEncGen = libkb.NewNaclKeyGen(encArg)
+}
+
 func (e *DeviceKeygen) generate() {
 	if e.runErr != nil {
 		return
@@ -219,6 +254,7 @@ func (e *DeviceKeygen) appendSibkey(ds []libkb.Delegator, ctx *Context, pargs *D
 	d, e.pushErr = e.naclSignGen.Push(ctx.LoginContext, true)
 	if e.pushErr == nil {
 		d.SetGlobalContext(e.G())
+		d.RevokeKIDs = pargs.RevokeKIDs
 		return append(ds, d)
 	}
 
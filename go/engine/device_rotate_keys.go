@@ -0,0 +1,237 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"fmt"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// DeviceRotateKeys is an engine that replaces the current device's
+// signing and encryption keys with new ones.  The new signing key is
+// delegated by the old one in a sibkey link that also revokes the
+// old keys, and the new encryption key is delegated by the new signing
+// key; both links are posted together.
+type DeviceRotateKeys struct {
+	libkb.Contextified
+	pkcs11Token string
+	oldKIDs     []keybase1.KID
+	signing     libkb.GenericKey
+	enc         libkb.GenericKey
+}
+
+// NewDeviceRotateKeys creates a DeviceRotateKeys engine.  If
+// pkcs11Token is set, the new keys are made on the PKCS#11 token
+// with that label.
+func NewDeviceRotateKeys(g *libkb.GlobalContext, pkcs11Token string) *DeviceRotateKeys {
+	return &DeviceRotateKeys{
+		Contextified: libkb.NewContextified(g),
+		pkcs11Token:  pkcs11Token,
+	}
+}
+
+// Name is the unique engine name.
+func (e *DeviceRotateKeys) Name() string {
+	return "DeviceRotateKeys"
+}
+
+// GetPrereqs returns the engine prereqs.
+func (e *DeviceRotateKeys) Prereqs() Prereqs {
+	return Prereqs{Device: true}
+}
+
+// RequiredUIs returns the required UIs.
+func (e *DeviceRotateKeys) RequiredUIs() []libkb.UIKind {
+	return []libkb.UIKind{
+		libkb.LogUIKind,
+		libkb.SecretUIKind,
+	}
+}
+
+// SubConsumers returns the other UI consumers for this engine.
+func (e *DeviceRotateKeys) SubConsumers() []libkb.UIConsumer {
+	return []libkb.UIConsumer{
+		&DeviceKeygen{},
+	}
+}
+
+// Run starts the engine.
+func (e *DeviceRotateKeys) Run(ctx *Context) (err error) {
+	defer e.G().Trace("DeviceRotateKeys#Run", func() error { return err })()
+
+	me, err := libkb.LoadMe(libkb.NewLoadUserForceArg(e.G()))
+	if err != nil {
+		return err
+	}
+	ckf := me.GetComputedKeyFamily()
+	if ckf == nil {
+		return libkb.KeyFamilyError{Msg: "no key family for current user"}
+	}
+	device, err := ckf.GetCurrentDevice(e.G())
+	if err != nil {
+		return err
+	}
+	if device.Description == nil {
+		return fmt.Errorf("current device %s has no name", device.ID)
+	}
+	if e.oldKIDs, err = ckf.GetAllActiveKeysForDevice(device.ID); err != nil {
+		return err
+	}
+
+	ska := libkb.SecretKeyArg{
+		Me:      me,
+		KeyType: libkb.DeviceSigningKeyType,
+	}
+	signer, err := e.G().Keyrings.GetSecretKeyWithPrompt(ctx.SecretKeyPromptArg(ska, "to rotate this device's keys"))
+	if err != nil {
+		return err
+	}
+	if err = signer.CheckSecretKey(); err != nil {
+		return err
+	}
+	// The old encryption key stays in the keyring once it's revoked, so
+	// that what was encrypted to it can still be decrypted.
+	eska := libkb.SecretKeyArg{
+		Me:      me,
+		KeyType: libkb.DeviceEncryptionKeyType,
+	}
+	oldEnc, err := e.G().Keyrings.GetSecretKeyWithPrompt(ctx.SecretKeyPromptArg(eska, "to rotate this device's keys"))
+	if err != nil {
+		return err
+	}
+
+	// The new keys are locked with the same local key security as the
+	// old ones, so the server half doesn't change.
+	lks, err := libkb.NewLKSecForEncrypt(ctx.SecretUI, me.GetUID(), e.G())
+	if err != nil {
+		return err
+	}
+	if err = lks.Load(ctx.LoginContext); err != nil {
+		return err
+	}
+
+	args := &DeviceKeygenArgs{
+		Me:         me,
+		DeviceID:   device.ID,
+		DeviceName: *device.Description,
+		DeviceType: device.Type,
+		Lks:        lks,
+	}
+	if len(e.pkcs11Token) > 0 {
+		if args.Token, err = libkb.OpenPKCS11TokenWithPrompt(e.G(), ctx.SecretUI, e.pkcs11Token); err != nil {
+			return err
+		}
+	}
+	kg := NewDeviceKeygen(args, e.G())
+	if err = RunEngine(kg, ctx); err != nil {
+		return err
+	}
+	e.signing = kg.SigningKey()
+	e.enc = kg.EncryptionKey()
+
+	pargs := &DeviceKeygenPushArgs{
+		Signer:     signer,
+		EldestKID:  me.GetEldestKID(),
+		User:       me,
+		RevokeKIDs: e.oldKIDs,
+	}
+	if err = kg.Push(ctx, pargs); err != nil {
+		// The new keys never made it into the sigchain, so don't
+		// leave them in the keyring.
+		if rerr := e.removeLocalKeys([]keybase1.KID{e.signing.GetKID(), e.enc.GetKID()}); rerr != nil {
+			e.G().Log.Warning("error removing unused new device keys: %s", rerr)
+		}
+		return err
+	}
+
+	// The old keys are revoked now, so rewrite the keyring with the old
+	// encryption key locked under the current local key security, and
+	// without the rest.
+	if err = e.relockOldKeys(oldEnc, lks); err != nil {
+		return err
+	}
+
+	e.updateCaches(me.GetUID())
+
+	ctx.LogUI.Info("Rotated keys for device %q:", *device.Description)
+	ctx.LogUI.Info("  signing key:    %s", e.signing.GetKID())
+	ctx.LogUI.Info("  encryption key: %s", e.enc.GetKID())
+	return nil
+}
+
+// OldKIDs returns the device keys that were revoked.
+func (e *DeviceRotateKeys) OldKIDs() []keybase1.KID {
+	return e.oldKIDs
+}
+
+// SigningKey returns the device's new signing key.
+func (e *DeviceRotateKeys) SigningKey() libkb.GenericKey {
+	return e.signing
+}
+
+// EncryptionKey returns the device's new encryption key.
+func (e *DeviceRotateKeys) EncryptionKey() libkb.GenericKey {
+	return e.enc
+}
+
+func (e *DeviceRotateKeys) removeLocalKeys(kids []keybase1.KID) error {
+	var err error
+	kerr := e.G().LoginState().Keyring(func(ring *libkb.SKBKeyringFile) {
+		for _, kid := range kids {
+			ring.Remove(kid)
+		}
+		err = ring.Save()
+	}, "DeviceRotateKeys - removeLocalKeys")
+	if kerr != nil {
+		return kerr
+	}
+	return err
+}
+
+// relockOldKeys replaces the old encryption key in the keyring with a copy
+// of oldEnc locked with lks, and removes the device's other old keys.
+func (e *DeviceRotateKeys) relockOldKeys(oldEnc libkb.GenericKey, lks *libkb.LKSec) error {
+	skb, err := oldEnc.ToLksSKB(lks)
+	if err != nil {
+		return err
+	}
+	kerr := e.G().LoginState().Keyring(func(ring *libkb.SKBKeyringFile) {
+		for _, kid := range e.oldKIDs {
+			ring.Remove(kid)
+		}
+		if err = ring.Push(skb); err != nil {
+			return
+		}
+		err = ring.Save()
+	}, "DeviceRotateKeys - relockOldKeys")
+	if kerr != nil {
+		return kerr
+	}
+	return err
+}
+
+// updateCaches replaces the cached unlocked device keys with the new ones,
+// and drops the cached identify of the current user, so that the
+// DeviceKeyfinder and friends see the new keys right away.
+func (e *DeviceRotateKeys) updateCaches(uid keybase1.UID) {
+	aerr := e.G().LoginState().Account(func(a *libkb.Account) {
+		if err := a.SetCachedSecretKey(libkb.SecretKeyArg{KeyType: libkb.DeviceSigningKeyType}, e.signing); err != nil {
+			e.G().Log.Debug("error caching new signing key: %s", err)
+		}
+		if err := a.SetCachedSecretKey(libkb.SecretKeyArg{KeyType: libkb.DeviceEncryptionKeyType}, e.enc); err != nil {
+			e.G().Log.Debug("error caching new encryption key: %s", err)
+		}
+	}, "DeviceRotateKeys - updateCaches")
+	if aerr != nil {
+		e.G().Log.Debug("error updating cached device keys: %s", aerr)
+	}
+
+	if e.G().Identify2Cache != nil {
+		if err := e.G().Identify2Cache.Delete(uid); err != nil {
+			e.G().Log.Debug("error removing %s from the identify2 cache: %s", uid, err)
+		}
+	}
+}
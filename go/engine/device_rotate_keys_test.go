@@ -0,0 +1,116 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keybase/client/go/libkb"
+)
+
+func TestDeviceRotateKeys(t *testing.T) {
+	tc := SetupEngineTest(t, "rotate")
+	defer tc.Cleanup()
+
+	u := CreateAndSignupFakeUser(tc, "rot")
+	assertNumDevicesAndKeys(tc, u, 2, 4)
+
+	ctx := &Context{
+		IdentifyUI: &FakeIdentifyUI{},
+		LogUI:      tc.G.UI.GetLogUI(),
+		SaltpackUI: &fakeSaltpackUI{},
+		SecretUI:   u.NewSecretUI(),
+	}
+
+	// A message encrypted to the device before its keys are rotated.
+	msg := "encrypted before the rotation"
+	sink := libkb.NewBufferCloser()
+	enc := NewSaltpackEncrypt(&SaltpackEncryptArg{Source: strings.NewReader(msg), Sink: sink}, tc.G)
+	if err := RunEngine(enc, ctx); err != nil {
+		t.Fatal(err)
+	}
+	oldEnc, err := cachedSecretKey(tc, libkb.DeviceEncryptionKeyType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eng := NewDeviceRotateKeys(tc.G, "")
+	if err := RunEngine(eng, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(eng.OldKIDs()) != 2 {
+		t.Fatalf("revoked %d keys, expected 2", len(eng.OldKIDs()))
+	}
+
+	// Same devices, same number of keys, but this device's are new.
+	assertNumDevicesAndKeys(tc, u, 2, 4)
+	_, keys := getActiveDevicesAndKeys(tc, u)
+	active := make(map[string]bool)
+	for _, k := range keys {
+		active[k.GetKID().String()] = true
+	}
+	for _, kid := range eng.OldKIDs() {
+		if active[kid.String()] {
+			t.Errorf("old key %s is still active", kid)
+		}
+	}
+	for _, k := range []libkb.GenericKey{eng.SigningKey(), eng.EncryptionKey()} {
+		if !active[k.GetKID().String()] {
+			t.Errorf("new key %s isn't active", k.GetKID())
+		}
+	}
+
+	// The old signing key is gone from the keyring, but the old encryption
+	// key is kept, and the new ones are cached.
+	err = tc.G.LoginState().Keyring(func(ring *libkb.SKBKeyringFile) {
+		for _, kid := range eng.OldKIDs() {
+			kept := ring.LookupByKid(kid) != nil
+			if kid.Equal(oldEnc.GetKID()) && !kept {
+				t.Errorf("old encryption key %s isn't in the keyring", kid)
+			} else if !kid.Equal(oldEnc.GetKID()) && kept {
+				t.Errorf("old signing key %s is still in the keyring", kid)
+			}
+		}
+	}, "TestDeviceRotateKeys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	skey, err := cachedSecretKey(tc, libkb.DeviceSigningKeyType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !skey.GetKID().Equal(eng.SigningKey().GetKID()) {
+		t.Errorf("cached signing key is %s, expected %s", skey.GetKID(), eng.SigningKey().GetKID())
+	}
+
+	// After logging out and in again, the new keys unlock and are used.
+	Logout(tc)
+	u.LoginOrBust(tc)
+	me, err := libkb.LoadMe(libkb.NewLoadUserForceArg(tc.G))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ska := libkb.SecretKeyArg{
+		Me:      me,
+		KeyType: libkb.DeviceEncryptionKeyType,
+	}
+	key, err := tc.G.Keyrings.GetSecretKeyWithPrompt(ctx.SecretKeyPromptArg(ska, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.GetKID().Equal(eng.EncryptionKey().GetKID()) {
+		t.Errorf("device encryption key is %s, expected %s", key.GetKID(), eng.EncryptionKey().GetKID())
+	}
+
+	// The message encrypted before the rotation still decrypts.
+	decoded := libkb.NewBufferCloser()
+	dec := NewSaltpackDecrypt(&SaltpackDecryptArg{Source: strings.NewReader(sink.String()), Sink: decoded}, tc.G)
+	if err := RunEngine(dec, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.String() != msg {
+		t.Errorf("decrypted %q, expected %q", decoded.String(), msg)
+	}
+}
@@ -26,6 +26,7 @@ type DeviceWrapArgs struct {
 	IsEldest   bool
 	Signer     libkb.GenericKey
 	EldestKID  keybase1.KID
+	Token      *libkb.PKCS11Token // optional; if set, the keys are made on it
 }
 
 // NewDeviceWrap creates a DeviceWrap engine.
@@ -79,6 +80,7 @@ func (e *DeviceWrap) Run(ctx *Context) error {
 		DeviceName: e.args.DeviceName,
 		DeviceType: e.args.DeviceType,
 		Lks:        e.args.Lks,
+		Token:      e.args.Token,
 	}
 	kgEng := NewDeviceKeygen(kgArgs, e.G())
 	if err := RunEngine(kgEng, ctx); err != nil {
@@ -0,0 +1,107 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// These run whole flows against a libkb.FakeAPIServer, so they don't
+// need the API server.
+
+func TestFakeAPISignupLogin(t *testing.T) {
+	tc := libkb.SetupTestWithFakeAPI(t, "fake", nil)
+	defer tc.Cleanup()
+
+	fu := CreateAndSignupFakeUser(tc, "fake")
+	if err := AssertProvisioned(tc); err != nil {
+		t.Fatal(err)
+	}
+
+	Logout(tc)
+	fu.LoginOrBust(tc)
+	if err := AssertLoggedIn(tc); err != nil {
+		t.Fatal(err)
+	}
+
+	Logout(tc)
+	fu.Passphrase = fu.Passphrase + "xxx"
+	if err := fu.Login(tc.G); err == nil {
+		t.Fatal("login with a bad passphrase worked")
+	}
+}
+
+func TestFakeAPITrackProve(t *testing.T) {
+	tc := libkb.SetupTestWithFakeAPI(t, "fake", nil)
+	defer tc.Cleanup()
+
+	them := CreateAndSignupFakeUser(tc, "fake")
+	if _, _, err := proveRooter(tc.G, them); err != nil {
+		t.Fatal(err)
+	}
+	Logout(tc)
+
+	tc2 := libkb.SetupTestWithFakeAPI(t, "fake", tc.FakeAPI)
+	defer tc2.Cleanup()
+
+	me := CreateAndSignupFakeUser(tc2, "fake")
+	idUI, _, err := runTrack(tc2, me, them.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTracking(tc2, them.Username)
+	if len(idUI.Proofs) != 1 {
+		t.Fatalf("expected one proof, got %d", len(idUI.Proofs))
+	}
+	if _, ok := idUI.Proofs["rooter"]; !ok {
+		t.Errorf("expected a rooter proof, got %v", idUI.Proofs)
+	}
+
+	if err := runUntrack(tc2.G, me, them.Username); err != nil {
+		t.Fatal(err)
+	}
+	assertNotTracking(tc2, them.Username)
+}
+
+func TestFakeAPIProvisionPaper(t *testing.T) {
+	tc := libkb.SetupTestWithFakeAPI(t, "fake", nil)
+	defer tc.Cleanup()
+
+	fu := NewFakeUserOrBust(t, "fake")
+	arg := MakeTestSignupEngineRunArg(fu)
+	loginUI := &paperLoginUI{Username: fu.Username}
+	ctx := &Context{
+		LogUI:    tc.G.UI.GetLogUI(),
+		GPGUI:    &gpgtestui{},
+		SecretUI: fu.NewSecretUI(),
+		LoginUI:  loginUI,
+	}
+	if err := RunEngine(NewSignupEngine(&arg, tc.G), ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tc2 := libkb.SetupTestWithFakeAPI(t, "fake", tc.FakeAPI)
+	defer tc2.Cleanup()
+
+	secUI := fu.NewSecretUI()
+	secUI.Passphrase = loginUI.PaperPhrase
+	ctx = &Context{
+		ProvisionUI: newTestProvisionUIPaper(),
+		LogUI:       tc2.G.UI.GetLogUI(),
+		SecretUI:    secUI,
+		LoginUI:     &libkb.TestLoginUI{Username: fu.Username},
+		GPGUI:       &gpgtestui{},
+	}
+	eng := NewLogin(tc2.G, libkb.DeviceTypeDesktop, "", keybase1.ClientType_CLI)
+	if err := RunEngine(eng, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := AssertProvisioned(tc2); err != nil {
+		t.Fatal(err)
+	}
+	assertNumDevicesAndKeys(tc2, fu, 3, 6)
+}
@@ -0,0 +1,205 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// identify2BatchDefaultParallelism is the number of workers we use if the
+// caller doesn't ask for a specific number.
+const identify2BatchDefaultParallelism = 4
+
+// Identify2Batch runs Identify2WithUID over a list of assertions, using a
+// pool of workers. Since every worker runs against the same GlobalContext,
+// they all share the Identify2Cache and ProofCache. Results are reported
+// through the IdentifyBatchUI as each one finishes.
+type Identify2Batch struct {
+	libkb.Contextified
+	arg *keybase1.Identify2BatchArg
+
+	numFailed int
+	results   []keybase1.Identify2BatchResult
+}
+
+var _ (Engine) = (*Identify2Batch)(nil)
+
+func NewIdentify2Batch(g *libkb.GlobalContext, arg *keybase1.Identify2BatchArg) *Identify2Batch {
+	return &Identify2Batch{
+		Contextified: libkb.NewContextified(g),
+		arg:          arg,
+	}
+}
+
+// Name is the unique engine name.
+func (e *Identify2Batch) Name() string {
+	return "Identify2Batch"
+}
+
+// GetPrereqs returns the engine prereqs.
+func (e *Identify2Batch) Prereqs() Prereqs {
+	return Prereqs{}
+}
+
+// RequiredUIs returns the required UIs.
+func (e *Identify2Batch) RequiredUIs() []libkb.UIKind {
+	return []libkb.UIKind{
+		libkb.IdentifyBatchUIKind,
+	}
+}
+
+// SubConsumers returns the other UI consumers for this engine.
+func (e *Identify2Batch) SubConsumers() []libkb.UIConsumer {
+	return nil
+}
+
+func (e *Identify2Batch) parallelism() int {
+	n := e.arg.Parallelism
+	if n <= 0 {
+		n = identify2BatchDefaultParallelism
+	}
+	if n > len(e.arg.Assertions) {
+		n = len(e.arg.Assertions)
+	}
+	return n
+}
+
+// Run the engine.
+func (e *Identify2Batch) Run(ctx *Context) (err error) {
+	defer e.G().Trace("Identify2Batch::Run", func() error { return err })()
+
+	n := e.parallelism()
+	e.G().Log.Debug("| identifying %d assertions with %d workers", len(e.arg.Assertions), n)
+
+	jobs := make(chan string)
+	results := make(chan keybase1.Identify2BatchResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for assertion := range jobs {
+				results <- e.identifyOne(ctx, assertion)
+			}
+		}()
+	}
+
+	go func() {
+		for _, assertion := range e.arg.Assertions {
+			jobs <- assertion
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	// Report results from this goroutine only, so that the UI never sees
+	// concurrent calls. Keep draining on error so the workers can exit.
+	for res := range results {
+		if res.Status != nil {
+			e.numFailed++
+		}
+		e.results = append(e.results, res)
+		if err != nil {
+			continue
+		}
+		err = ctx.IdentifyBatchUI.Identify2BatchResult(ctx.GetNetContext(), keybase1.Identify2BatchResultArg{
+			SessionID: ctx.SessionID,
+			Result:    res,
+		})
+	}
+	return err
+}
+
+func (e *Identify2Batch) identifyOne(ctx *Context, assertion string) keybase1.Identify2BatchResult {
+	res := keybase1.Identify2BatchResult{Assertion: assertion}
+
+	arg := keybase1.Identify2Arg{
+		UserAssertion:    assertion,
+		Reason:           e.arg.Reason,
+		ForceRemoteCheck: e.arg.ForceRemoteCheck,
+		NeedProofSet:     e.arg.NeedProofSet,
+		AlwaysBlock:      true,
+	}
+
+	// Each identify gets its own quiet IdentifyUI, since the per-proof
+	// callbacks from concurrent identifies would be meaningless to a
+	// single listener.
+	ictx := &Context{
+		LogUI:      ctx.LogUI,
+		IdentifyUI: batchIdentifyUI{},
+		NetContext: ctx.NetContext,
+		SessionID:  ctx.SessionID,
+	}
+
+	eng := NewResolveThenIdentify2(e.G(), &arg)
+	err := RunEngine(eng, ictx)
+
+	// Resolving the assertion fills in arg.Uid, so a failed identify can
+	// still say whom it was for. If the assertion didn't resolve, the
+	// caller only has res.Assertion to go on.
+	res.Uid = arg.Uid
+	r := eng.Result()
+	if r != nil && !r.Upk.Uid.IsNil() {
+		res.Uid = r.Upk.Uid
+		res.Username = r.Upk.Username
+	}
+
+	if err != nil {
+		e.G().Log.Debug("| identify of %q (%s) failed: %s", assertion, res.Uid, err)
+		res.Status = libkb.ExportErrorAsStatus(err)
+		return res
+	}
+
+	if r != nil {
+		res.Upk = &r.Upk
+	}
+	return res
+}
+
+// NumFailed returns how many of the assertions could not be identified.
+func (e *Identify2Batch) NumFailed() int {
+	return e.numFailed
+}
+
+// Results returns the result for each assertion, in the order that the
+// identifies finished.
+func (e *Identify2Batch) Results() []keybase1.Identify2BatchResult {
+	return e.results
+}
+
+// discardIdentifyBatchUI is for callers that only want Results.
+type discardIdentifyBatchUI struct{}
+
+func (discardIdentifyBatchUI) Identify2BatchResult(context.Context, keybase1.Identify2BatchResultArg) error {
+	return nil
+}
+
+// batchIdentifyUI drops all of the per-proof identify callbacks; in
+// batch mode, the only output is the per-user IdentifyBatchUI result.
+type batchIdentifyUI struct{}
+
+var _ libkb.IdentifyUI = batchIdentifyUI{}
+
+func (batchIdentifyUI) Start(string, keybase1.IdentifyReason)                                 {}
+func (batchIdentifyUI) FinishWebProofCheck(keybase1.RemoteProof, keybase1.LinkCheckResult)    {}
+func (batchIdentifyUI) FinishSocialProofCheck(keybase1.RemoteProof, keybase1.LinkCheckResult) {}
+func (batchIdentifyUI) Confirm(*keybase1.IdentifyOutcome) (keybase1.ConfirmResult, error) {
+	return keybase1.ConfirmResult{}, nil
+}
+func (batchIdentifyUI) DisplayCryptocurrency(keybase1.Cryptocurrency)          {}
+func (batchIdentifyUI) DisplayKey(keybase1.IdentifyKey)                        {}
+func (batchIdentifyUI) ReportLastTrack(*keybase1.TrackSummary)                 {}
+func (batchIdentifyUI) LaunchNetworkChecks(*keybase1.Identity, *keybase1.User) {}
+func (batchIdentifyUI) DisplayTrackStatement(string) error                     { return nil }
+func (batchIdentifyUI) DisplayUserCard(keybase1.UserCard)                      {}
+func (batchIdentifyUI) ReportTrackToken(keybase1.TrackToken) error             { return nil }
+func (batchIdentifyUI) SetStrict(b bool)                                       {}
+func (batchIdentifyUI) Finish()                                                {}
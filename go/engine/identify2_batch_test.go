@@ -0,0 +1,75 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"sync"
+	"testing"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	"golang.org/x/net/context"
+)
+
+type fakeIdentifyBatchUI struct {
+	sync.Mutex
+	results map[string]keybase1.Identify2BatchResult
+}
+
+func (u *fakeIdentifyBatchUI) Identify2BatchResult(_ context.Context, arg keybase1.Identify2BatchResultArg) error {
+	u.Lock()
+	defer u.Unlock()
+	if u.results == nil {
+		u.results = make(map[string]keybase1.Identify2BatchResult)
+	}
+	u.results[arg.Result.Assertion] = arg.Result
+	return nil
+}
+
+func TestIdentify2Batch(t *testing.T) {
+	tc := SetupEngineTest(t, "Identify2Batch")
+	defer tc.Cleanup()
+	i := newIdentify2WithUIDTester(tc.G)
+	tc.G.ProofCheckerFactory = i
+
+	arg := &keybase1.Identify2BatchArg{
+		Assertions: []string{
+			"t_tracy",
+			"t_alice",
+			"tacovontaco@twitter+t_tracy@rooter",
+			"t_tracy+t_tracy@github",
+		},
+		Parallelism: 2,
+	}
+	ui := &fakeIdentifyBatchUI{}
+	ctx := Context{IdentifyBatchUI: ui}
+	eng := NewIdentify2Batch(tc.G, arg)
+	if err := RunEngine(eng, &ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ui.results) != len(arg.Assertions) {
+		t.Fatalf("got %d results, wanted %d", len(ui.results), len(arg.Assertions))
+	}
+	for _, a := range arg.Assertions[0:3] {
+		res := ui.results[a]
+		if res.Status != nil {
+			t.Errorf("%s: unexpected failure: %+v", a, *res.Status)
+			continue
+		}
+		if res.Upk == nil || res.Uid.IsNil() {
+			t.Errorf("%s: expected a user in the result", a)
+		}
+	}
+	if ui.results["tacovontaco@twitter+t_tracy@rooter"].Uid != tracyUID {
+		t.Errorf("wrong UID for t_tracy assertion")
+	}
+	if res := ui.results["t_tracy+t_tracy@github"]; res.Status == nil {
+		t.Errorf("expected a failure for an unmet assertion")
+	} else if res.Uid != tracyUID {
+		t.Errorf("failed result should still have the resolved UID, got %q", res.Uid)
+	}
+	if eng.NumFailed() != 1 {
+		t.Errorf("NumFailed: got %d, wanted 1", eng.NumFailed())
+	}
+}
@@ -148,6 +148,11 @@ func (i *Identify2WithUIDTester) Insert(up *keybase1.UserPlusKeys) error {
 	return nil
 }
 
+func (i *Identify2WithUIDTester) Delete(uid keybase1.UID) error {
+	delete(i.cache, uid)
+	return nil
+}
+
 func (i *Identify2WithUIDTester) Shutdown() {}
 
 var _ libkb.Identify2Cacher = (*Identify2WithUIDTester)(nil)
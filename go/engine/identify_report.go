@@ -0,0 +1,177 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// IdentifyReportEngine identifies a user, and makes a report of the
+// outcome that's signed with this device's signing key, so that it can
+// be archived and checked later with IdentifyReportVerify.
+type IdentifyReportEngine struct {
+	libkb.Contextified
+	arg    *keybase1.IdentifyReportArg
+	report keybase1.IdentifyReport
+	signed []byte
+}
+
+func NewIdentifyReportEngine(arg *keybase1.IdentifyReportArg, g *libkb.GlobalContext) *IdentifyReportEngine {
+	return &IdentifyReportEngine{
+		Contextified: libkb.NewContextified(g),
+		arg:          arg,
+	}
+}
+
+// Name is the unique engine name.
+func (e *IdentifyReportEngine) Name() string {
+	return "IdentifyReport"
+}
+
+// GetPrereqs returns the engine prereqs.
+func (e *IdentifyReportEngine) Prereqs() Prereqs {
+	return Prereqs{Device: true}
+}
+
+// RequiredUIs returns the required UIs.
+func (e *IdentifyReportEngine) RequiredUIs() []libkb.UIKind {
+	return []libkb.UIKind{
+		libkb.SecretUIKind,
+	}
+}
+
+// SubConsumers returns the other UI consumers for this engine.
+func (e *IdentifyReportEngine) SubConsumers() []libkb.UIConsumer {
+	return []libkb.UIConsumer{
+		&IDEngine{},
+	}
+}
+
+// Run starts the engine.
+func (e *IdentifyReportEngine) Run(ctx *Context) (err error) {
+	defer e.G().Trace("IdentifyReportEngine::Run", func() error { return err })()
+
+	idarg := keybase1.IdentifyArg{
+		UserAssertion:    e.arg.UserAssertion,
+		ForceRemoteCheck: e.arg.ForceRemoteCheck,
+		Reason:           keybase1.IdentifyReason{Reason: "identify report"},
+	}
+	ideng := NewIDEngine(&idarg, e.G())
+	if err = RunEngine(ideng, ctx); err != nil {
+		return err
+	}
+	res := ideng.Result()
+	exp := res.Export()
+
+	me, err := libkb.LoadMe(libkb.NewLoadUserArg(e.G()))
+	if err != nil {
+		return err
+	}
+	ska := libkb.SecretKeyArg{
+		Me:      me,
+		KeyType: libkb.DeviceSigningKeyType,
+	}
+	key, err := e.G().Keyrings.GetSecretKeyWithPrompt(ctx.SecretKeyPromptArg(ska, "signing an identify report"))
+	if err != nil {
+		return err
+	}
+
+	e.report = keybase1.IdentifyReport{
+		Version:        libkb.IdentifyReportVersion,
+		CTime:          keybase1.ToTime(e.G().Clock.Now()),
+		Assertion:      e.arg.UserAssertion,
+		User:           exp.User,
+		PublicKeys:     exp.PublicKeys,
+		Outcome:        exp.Outcome,
+		ProofChecks:    res.Outcome.ExportProofChecks(),
+		MerkleSeqno:    int(res.User.GetMerkleSeqno()),
+		SignerUID:      me.GetUID(),
+		SignerUsername: me.GetName(),
+		SignerDeviceID: e.G().Env.GetDeviceID(),
+		SigningKID:     key.GetKID(),
+	}
+	e.signed, err = libkb.SignIdentifyReport(e.report, key)
+	return err
+}
+
+// Report returns the report that was signed.
+func (e *IdentifyReportEngine) Report() keybase1.IdentifyReport {
+	return e.report
+}
+
+// Signed returns the signed report, as JSON.
+func (e *IdentifyReportEngine) Signed() []byte {
+	return e.signed
+}
+
+// IdentifyReportVerify checks a signed report from IdentifyReportEngine.
+// Along with the signature, it checks that the signing key was one of the
+// signer's sibkeys as of the merkle root the report's identify was made
+// against.
+type IdentifyReportVerify struct {
+	libkb.Contextified
+	signed []byte
+	report keybase1.IdentifyReport
+}
+
+func NewIdentifyReportVerify(signed []byte, g *libkb.GlobalContext) *IdentifyReportVerify {
+	return &IdentifyReportVerify{
+		Contextified: libkb.NewContextified(g),
+		signed:       signed,
+	}
+}
+
+// Name is the unique engine name.
+func (e *IdentifyReportVerify) Name() string {
+	return "IdentifyReportVerify"
+}
+
+// GetPrereqs returns the engine prereqs.
+func (e *IdentifyReportVerify) Prereqs() Prereqs {
+	return Prereqs{}
+}
+
+// RequiredUIs returns the required UIs.
+func (e *IdentifyReportVerify) RequiredUIs() []libkb.UIKind {
+	return nil
+}
+
+// SubConsumers returns the other UI consumers for this engine.
+func (e *IdentifyReportVerify) SubConsumers() []libkb.UIConsumer {
+	return nil
+}
+
+// Run starts the engine.
+func (e *IdentifyReportVerify) Run(ctx *Context) (err error) {
+	defer e.G().Trace("IdentifyReportVerify::Run", func() error { return err })()
+
+	report, err := libkb.OpenIdentifyReport(e.signed)
+	if err != nil {
+		return err
+	}
+
+	signer, err := libkb.LoadUser(libkb.NewLoadUserByUIDArg(e.G(), report.SignerUID))
+	if err != nil {
+		return err
+	}
+	if !signer.GetNormalizedName().Eq(libkb.NewNormalizedUsername(report.SignerUsername)) {
+		return libkb.BadIdentifyReportError{Msg: "signer's username doesn't match their UID"}
+	}
+	ckf := signer.GetComputedKeyFamily()
+	if ckf == nil {
+		return libkb.BadIdentifyReportError{Msg: "signer has no keys"}
+	}
+	if _, _, err = ckf.FindSibkeyAtMerkleSeqno(report.SigningKID, libkb.Seqno(report.MerkleSeqno)); err != nil {
+		return libkb.BadIdentifyReportError{Msg: "signing key wasn't one of the signer's keys at the report's merkle root: " + err.Error()}
+	}
+
+	e.report = report
+	return nil
+}
+
+// Report returns the report, once it's been verified.
+func (e *IdentifyReportVerify) Report() keybase1.IdentifyReport {
+	return e.report
+}
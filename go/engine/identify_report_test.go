@@ -0,0 +1,75 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+func TestIdentifyReport(t *testing.T) {
+	tc := libkb.SetupTestWithFakeAPI(t, "idreport", nil)
+	defer tc.Cleanup()
+
+	them := CreateAndSignupFakeUser(tc, "idr")
+	if _, _, err := proveRooter(tc.G, them); err != nil {
+		t.Fatal(err)
+	}
+	Logout(tc)
+	me := CreateAndSignupFakeUser(tc, "idr")
+
+	ctx := &Context{
+		LogUI:      tc.G.UI.GetLogUI(),
+		IdentifyUI: &FakeIdentifyUI{},
+		SecretUI:   me.NewSecretUI(),
+	}
+	eng := NewIdentifyReportEngine(&keybase1.IdentifyReportArg{UserAssertion: them.Username}, tc.G)
+	if err := RunEngine(eng, ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	report := eng.Report()
+	if report.Outcome.Username != them.Username {
+		t.Errorf("outcome username: %q, expected %q", report.Outcome.Username, them.Username)
+	}
+	if report.Outcome.TrackStatus != keybase1.TrackStatus_NEW_OK {
+		t.Errorf("track status: %v, expected NEW_OK", report.Outcome.TrackStatus)
+	}
+	if len(report.ProofChecks) != 1 || report.ProofChecks[0].Proof.Key != "rooter" {
+		t.Errorf("expected one rooter proof check, got %+v", report.ProofChecks)
+	}
+	if report.MerkleSeqno == 0 {
+		t.Errorf("no merkle seqno in report")
+	}
+	if report.SignerUsername != me.Username {
+		t.Errorf("signer: %q, expected %q", report.SignerUsername, me.Username)
+	}
+
+	verify := func(signed []byte) error {
+		return RunEngine(NewIdentifyReportVerify(signed, tc.G), &Context{LogUI: tc.G.UI.GetLogUI()})
+	}
+	if err := verify(eng.Signed()); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := bytes.Replace(eng.Signed(), []byte(`"numProofFailures": 0`), []byte(`"numProofFailures": 1`), 1)
+	if bytes.Equal(tampered, eng.Signed()) {
+		t.Fatal("failed to tamper with the report")
+	}
+	if _, ok := verify(tampered).(libkb.BadIdentifyReportError); !ok {
+		t.Errorf("expected a BadIdentifyReportError for a tampered report")
+	}
+
+	// Once the signing device is revoked, the report no longer checks out,
+	// whatever time it claims to have been made at.
+	if err := doRevokeDevice(tc, me, tc.G.Env.GetDeviceID(), true); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := verify(eng.Signed()).(libkb.BadIdentifyReportError); !ok {
+		t.Errorf("expected a BadIdentifyReportError for a report signed by a revoked key")
+	}
+}
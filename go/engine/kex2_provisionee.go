@@ -33,6 +33,8 @@ type Kex2Provisionee struct {
 	lks          *libkb.LKSec
 	kex2Cancel   func()
 	ctx          *Context
+	mr           kex2.MessageRouter // if nil, kex2 goes through the server
+	token        *libkb.PKCS11Token // if set, the device keys are made on it
 }
 
 // Kex2Provisionee implements kex2.Provisionee, libkb.UserBasic,
@@ -100,7 +102,7 @@ func (e *Kex2Provisionee) Run(ctx *Context) error {
 
 	karg := kex2.KexBaseArg{
 		Ctx:           nctx,
-		Mr:            libkb.NewKexRouter(e.G()),
+		Mr:            e.router(),
 		DeviceID:      e.device.ID,
 		Secret:        e.secret,
 		SecretChannel: e.secretCh,
@@ -131,6 +133,13 @@ func (e *Kex2Provisionee) AddSecret(s kex2.Secret) {
 	e.secretCh <- s
 }
 
+func (e *Kex2Provisionee) router() kex2.MessageRouter {
+	if e.mr != nil {
+		return e.mr
+	}
+	return libkb.NewKexRouter(e.G())
+}
+
 // GetLogFactory implements GetLogFactory in kex2.Provisionee.
 func (e *Kex2Provisionee) GetLogFactory() rpc.LogFactory {
 	return rpc.NewSimpleLogFactory(e.G().Log, nil)
@@ -158,7 +167,11 @@ func (e *Kex2Provisionee) HandleHello(harg keybase1.HelloArg) (res keybase1.Hell
 		return res, err
 	}
 
-	e.eddsa, err = libkb.GenerateNaclSigningKeyPair()
+	if e.token != nil {
+		e.eddsa, err = e.token.GenerateSigningKey()
+	} else {
+		e.eddsa, err = libkb.GenerateNaclSigningKeyPair()
+	}
 	if err != nil {
 		return res, err
 	}
@@ -200,7 +213,11 @@ func (e *Kex2Provisionee) HandleDidCounterSign(sig []byte) (err error) {
 		return err
 	}
 
-	e.dh, err = libkb.GenerateNaclDHKeyPair()
+	if e.token != nil {
+		e.dh, err = e.token.GenerateDHKey()
+	} else {
+		e.dh, err = libkb.GenerateNaclDHKeyPair()
+	}
 	if err != nil {
 		return err
 	}
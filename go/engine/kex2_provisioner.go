@@ -25,6 +25,7 @@ type Kex2Provisioner struct {
 	provisioneeDeviceName string
 	provisioneeDeviceType string
 	ctx                   *Context
+	mr                    kex2.MessageRouter // if nil, kex2 goes through the server
 }
 
 // Kex2Provisioner implements kex2.Provisioner interface.
@@ -100,7 +101,7 @@ func (e *Kex2Provisioner) Run(ctx *Context) error {
 	// all set:  start provisioner
 	karg := kex2.KexBaseArg{
 		Ctx:           context.TODO(),
-		Mr:            libkb.NewKexRouter(e.G()),
+		Mr:            e.router(),
 		DeviceID:      deviceID,
 		Secret:        e.secret,
 		SecretChannel: e.secretCh,
@@ -133,6 +134,13 @@ func (e *Kex2Provisioner) AddSecret(s kex2.Secret) {
 	e.secretCh <- s
 }
 
+func (e *Kex2Provisioner) router() kex2.MessageRouter {
+	if e.mr != nil {
+		return e.mr
+	}
+	return libkb.NewKexRouter(e.G())
+}
+
 // GetLogFactory implements GetLogFactory in kex2.Provisioner.
 func (e *Kex2Provisioner) GetLogFactory() rpc.LogFactory {
 	return rpc.NewSimpleLogFactory(e.G().Log, nil)
@@ -95,3 +95,77 @@ func TestKex2Provision(t *testing.T) {
 
 	wg.Wait()
 }
+
+// TestKex2ProvisionDirect is like TestKex2Provision, but with the
+// devices talking directly instead of through the server.
+func TestKex2ProvisionDirect(t *testing.T) {
+	tcX := SetupEngineTest(t, "kex2direct")
+	defer tcX.Cleanup()
+	userX := CreateAndSignupFakeUser(tcX, "login")
+
+	tcY := SetupEngineTest(t, "kex2direct")
+	defer tcY.Cleanup()
+
+	secretY, err := libkb.NewKex2Secret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	routerY, code, err := libkb.ListenKexDirect(tcY.G, "127.0.0.1:0", secretY.Secret())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer routerY.Close()
+
+	var wg sync.WaitGroup
+
+	// start provisionee
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		f := func(lctx libkb.LoginContext) error {
+			ctx := &Context{
+				ProvisionUI:  &testProvisionUI{secretCh: make(chan kex2.Secret, 1)},
+				LoginContext: lctx,
+			}
+			deviceID, err := libkb.NewDeviceID()
+			if err != nil {
+				t.Errorf("provisionee device id error: %s", err)
+				return err
+			}
+			dname := "direct device"
+			device := &libkb.Device{
+				ID:          deviceID,
+				Description: &dname,
+				Type:        libkb.DeviceTypeDesktop,
+			}
+			provisionee := NewKex2Provisionee(tcY.G, device, secretY.Secret())
+			provisionee.mr = routerY
+			if err := RunEngine(provisionee, ctx); err != nil {
+				t.Errorf("provisionee error: %s", err)
+				return err
+			}
+			return nil
+		}
+
+		if err := tcY.G.LoginState().ExternalFunc(f, "Test - Kex2ProvisionDirect"); err != nil {
+			t.Errorf("kex2 provisionee error: %s", err)
+		}
+	}()
+
+	// start provisioner
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ctx := &Context{
+			SecretUI:    userX.NewSecretUI(),
+			ProvisionUI: &testProvisionUI{},
+		}
+		eng := NewDeviceAddDirect(tcX.G, code.String())
+		if err := RunEngine(eng, ctx); err != nil {
+			t.Errorf("device add error: %s", err)
+		}
+	}()
+
+	wg.Wait()
+}
@@ -21,6 +21,7 @@ type Login struct {
 	deviceType      string
 	usernameOrEmail string
 	clientType      keybase1.ClientType
+	pkcs11Token     string
 }
 
 // NewLogin creates a Login engine.  username is optional.
@@ -35,6 +36,12 @@ func NewLogin(g *libkb.GlobalContext, deviceType string, usernameOrEmail string,
 	}
 }
 
+// SetPKCS11Token makes provisioning put the new device's keys on the
+// PKCS#11 token with the given label.
+func (e *Login) SetPKCS11Token(label string) {
+	e.pkcs11Token = label
+}
+
 // Name is the unique engine name.
 func (e *Login) Name() string {
 	return "Login"
@@ -119,9 +126,10 @@ func (e *Login) Run(ctx *Context) error {
 	}
 
 	darg := &loginProvisionArg{
-		DeviceType: e.deviceType,
-		ClientType: e.clientType,
-		User:       ueng.User(),
+		DeviceType:  e.deviceType,
+		ClientType:  e.clientType,
+		User:        ueng.User(),
+		PKCS11Token: e.pkcs11Token,
 	}
 	deng := newLoginProvision(e.G(), darg)
 	if err = RunEngine(deng, ctx); err != nil {
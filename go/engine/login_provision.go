@@ -29,6 +29,7 @@ type loginProvision struct {
 	cleanupOnErr  bool
 	hasPGP        bool
 	hasDevice     bool
+	token         *libkb.PKCS11Token
 }
 
 // gpgInterface defines the portions of gpg client that provision
@@ -39,9 +40,10 @@ type gpgInterface interface {
 }
 
 type loginProvisionArg struct {
-	DeviceType string // desktop or mobile
-	ClientType keybase1.ClientType
-	User       *libkb.User
+	DeviceType  string // desktop or mobile
+	ClientType  keybase1.ClientType
+	User        *libkb.User
+	PKCS11Token string // optional; label of the token to make the device keys on
 }
 
 // newLoginProvision creates a loginProvision engine.
@@ -86,6 +88,16 @@ func (e *loginProvision) Run(ctx *Context) error {
 		return err
 	}
 
+	// open the token first, so that a missing token or a wrong PIN
+	// don't leave provisioning half done:
+	if len(e.arg.PKCS11Token) > 0 {
+		token, err := libkb.OpenPKCS11TokenWithPrompt(e.G(), ctx.SecretUI, e.arg.PKCS11Token)
+		if err != nil {
+			return err
+		}
+		e.token = token
+	}
+
 	e.cleanupOnErr = true
 	// based on information in e.arg.User, route the user
 	// through the provisioning options
@@ -128,6 +140,20 @@ func (e *loginProvision) deviceWithType(ctx *Context, provisionerType keybase1.D
 
 	// create provisionee engine
 	provisionee := NewKex2Provisionee(e.G(), device, secret.Secret())
+	provisionee.token = e.token
+
+	// if configured to, wait for the provisioner to connect directly,
+	// instead of going through the server:
+	var directCode string
+	if addr := e.G().Env.GetKex2DirectAddress(); len(addr) > 0 {
+		router, code, err := libkb.ListenKexDirect(e.G(), addr, secret.Secret())
+		if err != nil {
+			return err
+		}
+		defer router.Close()
+		provisionee.mr = router
+		directCode = code.String()
+	}
 
 	var canceler func()
 
@@ -138,6 +164,7 @@ func (e *loginProvision) deviceWithType(ctx *Context, provisionerType keybase1.D
 			Secret:          sb[:],
 			Phrase:          secret.Phrase(),
 			OtherDeviceType: provisionerType,
+			DirectCode:      directCode,
 		}
 		var contxt context.Context
 		contxt, canceler = context.WithCancel(context.Background())
@@ -320,6 +347,7 @@ func (e *loginProvision) makeDeviceWrapArgs(ctx *Context) (*DeviceWrapArgs, erro
 		DeviceName: e.devname,
 		DeviceType: e.arg.DeviceType,
 		Lks:        e.lks,
+		Token:      e.token,
 	}, nil
 }
 
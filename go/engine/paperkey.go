@@ -19,8 +19,11 @@ import (
 
 // PaperKey is an engine.
 type PaperKey struct {
-	passphrase libkb.PaperKeyPhrase
-	gen        *PaperKeyGen
+	passphrase     libkb.PaperKeyPhrase
+	shares         []libkb.PaperKeyShare
+	shareThreshold int
+	shareCount     int
+	gen            *PaperKeyGen
 	libkb.Contextified
 }
 
@@ -31,6 +34,17 @@ func NewPaperKey(g *libkb.GlobalContext) *PaperKey {
 	}
 }
 
+// NewPaperKeyShares creates a PaperKey engine that splits the new
+// paper key into count shares, any threshold of which can rebuild
+// it.  Only the shares are displayed.
+func NewPaperKeyShares(g *libkb.GlobalContext, threshold, count int) *PaperKey {
+	return &PaperKey{
+		Contextified:   libkb.NewContextified(g),
+		shareThreshold: threshold,
+		shareCount:     count,
+	}
+}
+
 // Name is the unique engine name.
 func (e *PaperKey) Name() string {
 	return "PaperKey"
@@ -60,6 +74,13 @@ func (e *PaperKey) SubConsumers() []libkb.UIConsumer {
 
 // Run starts the engine.
 func (e *PaperKey) Run(ctx *Context) error {
+	if e.shareCount > 0 {
+		// check before revoking anything
+		if err := libkb.CheckPaperKeyShareCount(e.shareThreshold, e.shareCount); err != nil {
+			return err
+		}
+	}
+
 	me, err := libkb.LoadMe(libkb.NewLoadUserArg(e.G()))
 	if err != nil {
 		return err
@@ -130,14 +151,33 @@ func (e *PaperKey) Run(ctx *Context) error {
 		return err
 	}
 
-	return ctx.LoginUI.DisplayPaperKeyPhrase(context.TODO(), keybase1.DisplayPaperKeyPhraseArg{Phrase: e.passphrase.String()})
+	if e.shareCount == 0 {
+		return ctx.LoginUI.DisplayPaperKeyPhrase(context.TODO(), keybase1.DisplayPaperKeyPhraseArg{Phrase: e.passphrase.String()})
+	}
 
+	// The key is already pushed, so if splitting fails, the user
+	// still needs to see the phrase.
+	e.shares, err = libkb.SplitPaperKeyPhrase(e.passphrase, e.shareThreshold, e.shareCount)
+	if err != nil {
+		e.G().Log.Warning("failed to split paper key: %s", err)
+		return ctx.LoginUI.DisplayPaperKeyPhrase(context.TODO(), keybase1.DisplayPaperKeyPhraseArg{Phrase: e.passphrase.String()})
+	}
+	arg := keybase1.DisplayPaperKeyPhraseArg{ShareThreshold: e.shareThreshold}
+	for _, s := range e.shares {
+		arg.Shares = append(arg.Shares, s.String())
+	}
+	return ctx.LoginUI.DisplayPaperKeyPhrase(context.TODO(), arg)
 }
 
 func (e *PaperKey) Passphrase() string {
 	return e.passphrase.String()
 }
 
+// Shares returns the shares of the paper key, if it was split.
+func (e *PaperKey) Shares() []libkb.PaperKeyShare {
+	return e.shares
+}
+
 func (e *PaperKey) SigKey() libkb.GenericKey {
 	return e.gen.SigKey()
 }
@@ -65,14 +65,21 @@ func (c *PassphraseChange) Run(ctx *Context) (err error) {
 		c.G().Log.Debug("- PassphraseChange.Run -> %s", libkb.ErrToOk(err))
 	}()
 
-	if len(c.arg.Passphrase) < libkb.MinPassphraseLength {
-		return libkb.PassphraseError{Msg: "too short"}
+	// Check what can be checked before loading the user, and then check
+	// again for the username.
+	policy := c.G().Env.GetPassphrasePolicy()
+	if err = libkb.CheckPassphrasePolicy(policy, c.arg.Passphrase, "", c.G().Env.GetEmail()); err != nil {
+		return
 	}
 
 	if err = c.loadMe(); err != nil {
 		return
 	}
 
+	if err = libkb.CheckPassphrasePolicy(policy, c.arg.Passphrase, c.me.GetName(), ""); err != nil {
+		return
+	}
+
 	c.G().LoginState().RunSecretSyncer(c.me.GetUID())
 
 	if c.arg.Force {
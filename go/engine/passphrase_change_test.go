@@ -137,8 +137,8 @@ func TestPassphraseChangeShort(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error with new short passphrase")
 	}
-	if _, ok := err.(libkb.PassphraseError); !ok {
-		t.Fatalf("expected libkb.PassphraseError, got %T", err)
+	if _, ok := err.(libkb.ShortPassphraseError); !ok {
+		t.Fatalf("expected libkb.ShortPassphraseError, got %T", err)
 	}
 }
 
@@ -393,6 +393,44 @@ func TestPassphraseChangeLoggedOutBackupKey(t *testing.T) {
 	assertLoadSecretKeys(tc, u, "logged out w/ backup key, after passphrase change")
 }
 
+// Test changing the passphrase when user forgets current passphrase
+// and is logged out, but has a backup key split into shares.
+func TestPassphraseChangeLoggedOutBackupKeyShares(t *testing.T) {
+	tc := SetupEngineTest(t, "PassphraseChange")
+	defer tc.Cleanup()
+
+	u := CreateAndSignupFakeUser(tc, "login")
+
+	ctx := &Context{
+		LogUI:    tc.G.UI.GetLogUI(),
+		LoginUI:  &libkb.TestLoginUI{},
+		SecretUI: &libkb.TestSecretUI{},
+	}
+	beng := NewPaperKeyShares(tc.G, 2, 3)
+	if err := RunEngine(beng, ctx); err != nil {
+		t.Fatal(err)
+	}
+	shares := beng.Shares()
+	if len(shares) != 3 {
+		t.Fatalf("num shares: %d, expected 3", len(shares))
+	}
+	ctx.SecretUI = &testRetrySecretUI{Passphrases: []string{shares[2].String(), shares[0].String()}}
+
+	Logout(tc)
+
+	newPassphrase := "password1234"
+	arg := &keybase1.PassphraseChangeArg{
+		Passphrase: newPassphrase,
+		Force:      true,
+	}
+	eng := NewPassphraseChange(arg, tc.G)
+	if err := RunEngine(eng, ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	verifyPassphraseChange(tc, u, newPassphrase)
+}
+
 // Test changing the passphrase when user forgets current passphrase
 // and is logged out, but has a backup key (generated by a secret from
 // the secret store).
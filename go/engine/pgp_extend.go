@@ -0,0 +1,174 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/keybase/client/go/libkb"
+)
+
+// PGPExtendEngine pushes a later expiration time for PGP keys whose
+// secret halves are in the local keyring. The keys get new self
+// signatures, are posted with a pgp_update link, and are written back to
+// the local keyring.
+type PGPExtendEngine struct {
+	selectedFingerprints map[string]bool
+	all                  bool
+	days                 int
+	extended             []libkb.PGPFingerprint
+	libkb.Contextified
+}
+
+func NewPGPExtendEngine(fingerprints []string, all bool, days int, g *libkb.GlobalContext) *PGPExtendEngine {
+	selectedFingerprints := make(map[string]bool)
+	for _, fpString := range fingerprints {
+		selectedFingerprints[strings.ToLower(fpString)] = true
+	}
+	return &PGPExtendEngine{
+		selectedFingerprints: selectedFingerprints,
+		all:                  all,
+		days:                 days,
+		Contextified:         libkb.NewContextified(g),
+	}
+}
+
+func (e *PGPExtendEngine) Name() string {
+	return "PGPExtend"
+}
+
+func (e *PGPExtendEngine) Prereqs() Prereqs {
+	return Prereqs{
+		Session: true,
+	}
+}
+
+func (e *PGPExtendEngine) RequiredUIs() []libkb.UIKind {
+	return []libkb.UIKind{
+		libkb.LogUIKind,
+		libkb.SecretUIKind,
+	}
+}
+
+func (e *PGPExtendEngine) SubConsumers() []libkb.UIConsumer {
+	return []libkb.UIConsumer{}
+}
+
+// Extended returns the fingerprints of the keys that got a new expiration.
+func (e *PGPExtendEngine) Extended() []libkb.PGPFingerprint {
+	return e.extended
+}
+
+func (e *PGPExtendEngine) Run(ctx *Context) error {
+	if e.all && len(e.selectedFingerprints) > 0 {
+		return fmt.Errorf("Cannot use explicit fingerprints with --all.")
+	}
+	if e.days <= 0 {
+		return fmt.Errorf("The number of days must be positive, not %d.", e.days)
+	}
+
+	me, err := libkb.LoadMe(libkb.NewLoadUserArg(e.G()))
+	if err != nil {
+		return err
+	}
+	fingerprints := me.GetActivePGPFingerprints(false /* not just sibkeys */)
+	if len(fingerprints) > 1 && !e.all && len(e.selectedFingerprints) == 0 {
+		return fmt.Errorf("You have more than one PGP key. To extend all of them, use --all.")
+	}
+
+	del := libkb.Delegator{
+		DelegationType: libkb.PGPUpdateType,
+		Me:             me,
+		Expire:         libkb.KeyExpireIn,
+		Contextified:   libkb.NewContextified(e.G()),
+	}
+	if err = del.LoadSigningKey(ctx.LoginContext, ctx.SecretUI); err != nil {
+		return err
+	}
+
+	lks, err := libkb.NewLKSecForEncrypt(ctx.SecretUI, me.GetUID(), e.G())
+	if err != nil {
+		return err
+	}
+	if err = lks.Load(ctx.LoginContext); err != nil {
+		return err
+	}
+
+	now := e.G().GetClock().Now()
+	etime := now.Add(time.Duration(e.days) * 24 * time.Hour)
+
+	for _, fingerprint := range fingerprints {
+		if len(e.selectedFingerprints) > 0 && !e.selectedFingerprints[fingerprint.String()] {
+			ctx.LogUI.Warning("Skipping key %s", fingerprint.String())
+			continue
+		}
+
+		ska := libkb.SecretKeyArg{
+			Me:         me,
+			KeyType:    libkb.PGPKeyType,
+			KeyQuery:   fingerprint.String(),
+			ExactMatch: true,
+		}
+		key, err := e.G().Keyrings.GetSecretKeyWithPrompt(ctx.SecretKeyPromptArg(ska, "to extend its expiration"))
+		if err != nil {
+			if _, isNoKey := err.(libkb.NoSecretKeyError); isNoKey {
+				ctx.LogUI.Warning(
+					"No secret key for %s in the local keyring. Extend it in GPG, and then use 'keybase pgp update'.",
+					fingerprint.String())
+				continue
+			}
+			return err
+		}
+		bundle, ok := key.(*libkb.PGPKeyBundle)
+		if !ok {
+			return libkb.BadKeyError{Msg: fmt.Sprintf("key %s is not a PGP key", fingerprint)}
+		}
+
+		skipped, err := bundle.ExtendExpiration(etime, now)
+		if err != nil {
+			return err
+		}
+		for _, id := range skipped {
+			ctx.LogUI.Warning("Signing subkey %016X of key %s can't be extended here; it keeps its old expiration.", id, fingerprint)
+		}
+
+		del.NewKey = bundle
+
+		ctx.LogUI.Info("Posting new expiration %s for key %s.", etime.Format("2006-01-02"), fingerprint)
+		if err := del.Run(ctx.LoginContext); err != nil {
+			if appStatusErr, ok := err.(libkb.AppStatusError); ok && appStatusErr.Code == libkb.SCKeyDuplicateUpdate {
+				ctx.LogUI.Info("Key was already up to date.")
+				continue
+			}
+			return err
+		}
+
+		if err := e.saveLocalKey(bundle, lks); err != nil {
+			return err
+		}
+		e.extended = append(e.extended, fingerprint)
+		ctx.LogUI.Info("Extended key %s.", fingerprint)
+	}
+	return nil
+}
+
+// saveLocalKey replaces the key in the local keyring with the one carrying
+// the new self signatures, so that a later export has them too.
+func (e *PGPExtendEngine) saveLocalKey(bundle *libkb.PGPKeyBundle, lks *libkb.LKSec) error {
+	skb, err := bundle.ToLksSKB(lks)
+	if err != nil {
+		return err
+	}
+	var err2 error
+	kerr := e.G().LoginState().Keyring(func(ring *libkb.SKBKeyringFile) {
+		ring.Remove(bundle.GetKID())
+		err2 = ring.PushAndSave(skb)
+	}, "PGPExtendEngine - saveLocalKey")
+	if kerr != nil {
+		return kerr
+	}
+	return err2
+}
@@ -0,0 +1,71 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keybase/client/go/libkb"
+)
+
+func TestPGPExtend(t *testing.T) {
+	tc := SetupEngineTest(t, "pgp_extend")
+	defer tc.Cleanup()
+
+	fu := createFakeUserWithPGPSibkey(tc)
+	before := getFakeUsersKeyBundleFromServer(tc, fu)
+	originalBundlesLen := len(getFakeUsersBundlesList(tc, fu))
+
+	ctx := Context{
+		LogUI:    tc.G.UI.GetLogUI(),
+		SecretUI: fu.NewSecretUI(),
+	}
+	days := 3650
+	eng := NewPGPExtendEngine(nil, false, days, tc.G)
+	if err := RunEngine(eng, &ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(eng.Extended()) != 1 || !eng.Extended()[0].Eq(before.GetFingerprint()) {
+		t.Fatalf("extended %v, expected %s", eng.Extended(), before.GetFingerprint())
+	}
+
+	if n := len(getFakeUsersBundlesList(tc, fu)); n != originalBundlesLen+1 {
+		t.Errorf("got %d bundles, expected %d", n, originalBundlesLen+1)
+	}
+	after := getFakeUsersKeyBundleFromServer(tc, fu)
+	want := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+	if d := after.ExpirationTime().Sub(want); d > time.Hour || d < -time.Hour {
+		t.Errorf("key on the server expires at %s, expected about %s", after.ExpirationTime(), want)
+	}
+
+	// Days has to be positive.
+	eng = NewPGPExtendEngine(nil, false, 0, tc.G)
+	if err := RunEngine(eng, &ctx); err == nil {
+		t.Error("expected an error extending by 0 days")
+	}
+
+	// The local copy has the new self signatures too.
+	me, err := libkb.LoadMe(libkb.NewLoadUserForceArg(tc.G))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ska := libkb.SecretKeyArg{
+		Me:         me,
+		KeyType:    libkb.PGPKeyType,
+		KeyQuery:   before.GetFingerprint().String(),
+		ExactMatch: true,
+	}
+	key, err := tc.G.Keyrings.GetSecretKeyWithPrompt(ctx.SecretKeyPromptArg(ska, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, ok := key.(*libkb.PGPKeyBundle)
+	if !ok {
+		t.Fatalf("local key is a %T", key)
+	}
+	if !local.ExpirationTime().Equal(after.ExpirationTime()) {
+		t.Errorf("local key expires at %s, expected %s", local.ExpirationTime(), after.ExpirationTime())
+	}
+}
@@ -58,12 +58,20 @@ func (e *SaltpackDecrypt) SubConsumers() []libkb.UIConsumer {
 	}
 }
 
-func (e *SaltpackDecrypt) promptForDecrypt(ctx *Context, mki *saltpack.MessageKeyInfo) (err error) {
+func (e *SaltpackDecrypt) promptForDecrypt(ctx *Context, mki *libkb.SaltpackMessageKeyInfo) (err error) {
 	defer e.G().Trace("SaltpackDecrypt::promptForDecrypt", func() error { return err })()
 
+	// A signcrypted message is vouched for by the sender's signing key
+	// rather than by their encryption key.
+	publicKey := libkb.BoxPublicKeyToKeybaseKID(mki.SenderKey)
+	if mki.SenderSigningKey != nil {
+		publicKey = libkb.SigningPublicKeyToKeybaseKID(mki.SenderSigningKey)
+		e.res.SigningKID = publicKey
+	}
+
 	spsiArg := SaltpackSenderIdentifyArg{
 		isAnon:           mki.SenderIsAnon,
-		publicKey:        libkb.BoxPublicKeyToKeybaseKID(mki.SenderKey),
+		publicKey:        publicKey,
 		interactive:      e.arg.Opts.Interactive,
 		forceRemoteCheck: e.arg.Opts.ForceRemoteCheck,
 		reason: keybase1.IdentifyReason{
@@ -77,8 +85,9 @@ func (e *SaltpackDecrypt) promptForDecrypt(ctx *Context, mki *saltpack.MessageKe
 		return err
 	}
 
+	e.res.Sender = spsiEng.Result()
 	arg := keybase1.SaltpackPromptForDecryptArg{
-		Sender: spsiEng.Result(),
+		Sender: e.res.Sender,
 	}
 
 	err = ctx.SaltpackUI.SaltpackPromptForDecrypt(context.TODO(), arg)
@@ -88,7 +97,7 @@ func (e *SaltpackDecrypt) promptForDecrypt(ctx *Context, mki *saltpack.MessageKe
 	return err
 }
 
-func (e *SaltpackDecrypt) makeMessageInfo(me *libkb.User, mki *saltpack.MessageKeyInfo) {
+func (e *SaltpackDecrypt) makeMessageInfo(me *libkb.User, mki *libkb.SaltpackMessageKeyInfo) {
 	if mki == nil {
 		return
 	}
@@ -126,18 +135,15 @@ func (e *SaltpackDecrypt) Run(ctx *Context) (err error) {
 		return err
 	}
 
-	kp, ok := key.(libkb.NaclDHKeyPair)
-	if !ok || kp.Private == nil {
-		return libkb.KeyCannotDecryptError{}
-	}
+	revoked := e.revokedKeys(ctx, me)
 
-	hook := func(mki *saltpack.MessageKeyInfo) error {
+	hook := func(mki *libkb.SaltpackMessageKeyInfo) error {
 		return e.promptForDecrypt(ctx, mki)
 	}
 
 	e.G().Log.Debug("| SaltpackDecrypt")
-	var mki *saltpack.MessageKeyInfo
-	mki, err = libkb.SaltpackDecrypt(e.G(), e.arg.Source, e.arg.Sink, kp, hook)
+	var mki *libkb.SaltpackMessageKeyInfo
+	mki, err = libkb.SaltpackDecrypt(e.G(), e.arg.Source, e.arg.Sink, key, revoked, hook)
 	if err == saltpack.ErrNoDecryptionKey {
 		err = libkb.NoDecryptionKeyError{Msg: "no suitable device key found"}
 	}
@@ -147,6 +153,43 @@ func (e *SaltpackDecrypt) Run(ctx *Context) (err error) {
 	return err
 }
 
+// revokedKeys unlocks the revoked encryption keys that are still in the
+// local keyring. Rotating a device's keys keeps its old encryption key
+// there, so that messages encrypted to it can still be decrypted. Keys
+// that can't be unlocked without a prompt are skipped.
+func (e *SaltpackDecrypt) revokedKeys(ctx *Context, me *libkb.User) []libkb.GenericKey {
+	ckf := me.GetComputedKeyFamily()
+	if ckf == nil {
+		return nil
+	}
+	var skbs []*libkb.SKB
+	kerr := e.G().LoginState().Keyring(func(ring *libkb.SKBKeyringFile) {
+		for _, skb := range ring.Blocks {
+			pub, err := skb.GetPubKey()
+			if err == nil && pub.CanEncrypt() && ckf.IsRevokedSubkey(pub.GetKID()) {
+				skbs = append(skbs, skb)
+			}
+		}
+	}, "SaltpackDecrypt - revokedKeys")
+	if kerr != nil {
+		e.G().Log.Debug("| error reading the keyring for revoked keys: %s", kerr)
+		return nil
+	}
+
+	secretStore := libkb.NewSecretStore(e.G(), me.GetNormalizedName())
+	var keys []libkb.GenericKey
+	for _, skb := range skbs {
+		skb.SetUID(me.GetUID())
+		key, err := skb.UnlockNoPrompt(ctx.LoginContext, secretStore)
+		if err != nil {
+			e.G().Log.Debug("| skipping revoked key: %s", err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 func (e *SaltpackDecrypt) MessageInfo() keybase1.SaltpackEncryptedMessageInfo {
 	return e.res
 }
@@ -98,6 +98,60 @@ HTngZWUk8Tjn6Q8zrnnoB92G1G+rZHAiChgBFQCaYDBsWa0Pia6Vm+10OAIulGGj
 
 }
 
+func TestSaltpackDecryptSigncrypted(t *testing.T) {
+	tc := SetupEngineTest(t, "SaltpackDecrypt")
+	defer tc.Cleanup()
+	fu := CreateAndSignupFakeUser(tc, "naclp")
+
+	// signcrypt a message
+	msg := "a message only I could have sent"
+	sink := libkb.NewBufferCloser()
+	ctx := &Context{
+		IdentifyUI: &FakeIdentifyUI{},
+		SecretUI:   fu.NewSecretUI(),
+		LogUI:      tc.G.UI.GetLogUI(),
+		SaltpackUI: &fakeSaltpackUI{},
+	}
+	arg := &SaltpackEncryptArg{
+		Opts: keybase1.SaltpackEncryptOptions{
+			Signcrypt: true,
+		},
+		Source: strings.NewReader(msg),
+		Sink:   sink,
+	}
+	enc := NewSaltpackEncrypt(arg, tc.G)
+	if err := RunEngine(enc, ctx); err != nil {
+		t.Fatal(err)
+	}
+	out := sink.String()
+
+	// decrypt it
+	decoded := libkb.NewBufferCloser()
+	decarg := &SaltpackDecryptArg{
+		Source: strings.NewReader(out),
+		Sink:   decoded,
+	}
+	dec := NewSaltpackDecrypt(decarg, tc.G)
+	if err := RunEngine(dec, ctx); err != nil {
+		t.Fatal(err)
+	}
+	decmsg := decoded.String()
+	if decmsg != msg {
+		t.Errorf("decoded: %s, expected: %s", decmsg, msg)
+	}
+
+	info := dec.MessageInfo()
+	if info.SigningKID.IsNil() {
+		t.Fatal("expected a signing KID for a signcrypted message")
+	}
+	if info.Sender.SenderType != keybase1.SaltpackSenderType_SELF {
+		t.Errorf("sender type: %v, expected %v", info.Sender.SenderType, keybase1.SaltpackSenderType_SELF)
+	}
+	if info.Sender.Username != fu.Username {
+		t.Errorf("sender: %s, expected %s", info.Sender.Username, fu.Username)
+	}
+}
+
 type testDecryptSaltpackUI struct {
 	fakeSaltpackUI
 	f func(arg keybase1.SaltpackPromptForDecryptArg) error
@@ -5,6 +5,9 @@ package engine
 
 import (
 	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
 
 	"github.com/keybase/client/go/libkb"
 	keybase1 "github.com/keybase/client/go/protocol"
@@ -21,7 +24,8 @@ type SaltpackEncryptArg struct {
 type SaltpackEncrypt struct {
 	arg *SaltpackEncryptArg
 	libkb.Contextified
-	me *libkb.User
+	me  *libkb.User
+	res keybase1.SaltpackEncryptResult
 }
 
 // NewSaltpackEncrypt creates a SaltpackEncrypt engine.
@@ -54,6 +58,54 @@ func (e *SaltpackEncrypt) SubConsumers() []libkb.UIConsumer {
 	}
 }
 
+// recipients returns the assertions given directly in the options, plus
+// those in the recipients file and in each recipient group, without
+// duplicates.
+func (e *SaltpackEncrypt) recipients() ([]string, error) {
+	ret := append([]string{}, e.arg.Opts.Recipients...)
+
+	if len(e.arg.Opts.RecipientsFile) > 0 {
+		data, err := ioutil.ReadFile(e.arg.Opts.RecipientsFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			ret = append(ret, line)
+		}
+	}
+
+	if len(e.arg.Opts.RecipientGroups) > 0 {
+		uc, err := e.G().Env.GetConfig().GetUserConfig()
+		if err != nil {
+			return nil, err
+		}
+		if uc == nil {
+			return nil, libkb.NoUserConfigError{}
+		}
+		for _, name := range e.arg.Opts.RecipientGroups {
+			members, err := uc.GetRecipientGroup(name)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, members...)
+		}
+	}
+
+	seen := make(map[string]bool)
+	uniq := ret[:0]
+	for _, r := range ret {
+		if !seen[r] {
+			seen[r] = true
+			uniq = append(uniq, r)
+		}
+	}
+	return uniq, nil
+}
+
 func (e *SaltpackEncrypt) loadMyPublicKeys() ([]libkb.NaclDHKeyPublic, error) {
 
 	var ret []libkb.NaclDHKeyPublic
@@ -92,7 +144,6 @@ func (e *SaltpackEncrypt) Run(ctx *Context) (err error) {
 	}()
 
 	var receivers []libkb.NaclDHKeyPublic
-	var sender libkb.NaclDHKeyPair
 
 	if err = e.loadMe(ctx); err != nil {
 		return err
@@ -103,12 +154,19 @@ func (e *SaltpackEncrypt) Run(ctx *Context) (err error) {
 		if err != nil {
 			return err
 		}
+		e.addSelfToResult(receivers)
+	}
+
+	recipients, err := e.recipients()
+	if err != nil {
+		return err
 	}
 
 	kfarg := DeviceKeyfinderArg{
-		Users:           e.arg.Opts.Recipients,
+		Users:           recipients,
 		NeedEncryptKeys: true,
 		Self:            e.me,
+		Batch:           len(e.arg.Opts.RecipientsFile) > 0 || len(e.arg.Opts.RecipientGroups) > 0,
 	}
 
 	kf := NewDeviceKeyfinder(e.G(), kfarg)
@@ -116,7 +174,13 @@ func (e *SaltpackEncrypt) Run(ctx *Context) (err error) {
 		return err
 	}
 	uplus := kf.UsersPlusKeys()
+	var others []keybase1.SaltpackEncryptedRecipient
 	for _, up := range uplus {
+		others = append(others, keybase1.SaltpackEncryptedRecipient{
+			Uid:        up.Uid,
+			Username:   up.Username,
+			DeviceKeys: up.DeviceKeys,
+		})
 		for _, k := range up.DeviceKeys {
 			gk, err := libkb.ImportKeypairFromKID(k.KID)
 			if err != nil {
@@ -129,30 +193,90 @@ func (e *SaltpackEncrypt) Run(ctx *Context) (err error) {
 			receivers = append(receivers, kp.Public)
 		}
 	}
+	sort.Sort(recipientsByName(others))
+	e.res.Recipients = append(e.res.Recipients, others...)
+
+	encarg := libkb.SaltpackEncryptArg{
+		Source:         e.arg.Source,
+		Sink:           e.arg.Sink,
+		Receivers:      receivers,
+		Binary:         e.arg.Opts.Binary,
+		HideRecipients: e.arg.Opts.HideRecipients,
+		Signcrypt:      e.arg.Opts.Signcrypt,
+	}
 
 	if !e.arg.Opts.HideSelf && e.me != nil {
-		ska := libkb.SecretKeyArg{
-			Me:      e.me,
-			KeyType: libkb.DeviceEncryptionKeyType,
+		if e.arg.Opts.Signcrypt {
+			encarg.SigningKey, err = e.loadSigningKey(ctx)
+		} else {
+			encarg.Sender, err = e.loadSenderKey(ctx)
 		}
-		key, err := e.G().Keyrings.GetSecretKeyWithPrompt(ctx.SecretKeyPromptArg(ska, "encrypting a message/file"))
 		if err != nil {
 			return err
 		}
-		kp, ok := key.(libkb.NaclDHKeyPair)
-		if !ok || kp.Private == nil {
-			return libkb.KeyCannotDecryptError{}
+	}
+
+	return libkb.SaltpackEncrypt(e.G(), &encarg)
+}
+
+// addSelfToResult adds our own device keys that we're encrypting for to
+// the result.
+func (e *SaltpackEncrypt) addSelfToResult(keys []libkb.NaclDHKeyPublic) {
+	kids := make(map[keybase1.KID]bool)
+	for _, k := range keys {
+		kids[k.GetKID()] = true
+	}
+	upk := e.me.ExportToUserPlusKeys(keybase1.Time(0))
+	r := keybase1.SaltpackEncryptedRecipient{
+		Uid:      upk.Uid,
+		Username: upk.Username,
+	}
+	for _, k := range upk.DeviceKeys {
+		if kids[k.KID] {
+			r.DeviceKeys = append(r.DeviceKeys, k)
 		}
-		sender = kp
 	}
+	e.res.Recipients = append(e.res.Recipients, r)
+}
 
-	encarg := libkb.SaltpackEncryptArg{
-		Source:         e.arg.Source,
-		Sink:           e.arg.Sink,
-		Receivers:      receivers,
-		Sender:         sender,
-		Binary:         e.arg.Opts.Binary,
-		HideRecipients: e.arg.Opts.HideRecipients,
+type recipientsByName []keybase1.SaltpackEncryptedRecipient
+
+func (r recipientsByName) Len() int           { return len(r) }
+func (r recipientsByName) Less(i, j int) bool { return r[i].Username < r[j].Username }
+func (r recipientsByName) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
+// Result describes who the message was encrypted for, and which of
+// their devices can read it.
+func (e *SaltpackEncrypt) Result() keybase1.SaltpackEncryptResult {
+	return e.res
+}
+
+func (e *SaltpackEncrypt) loadSenderKey(ctx *Context) (libkb.GenericKey, error) {
+	ska := libkb.SecretKeyArg{
+		Me:      e.me,
+		KeyType: libkb.DeviceEncryptionKeyType,
 	}
-	return libkb.SaltpackEncrypt(e.G(), &encarg)
+	key, err := e.G().Keyrings.GetSecretKeyWithPrompt(ctx.SecretKeyPromptArg(ska, "encrypting a message/file"))
+	if err != nil {
+		return nil, err
+	}
+	if !key.CanDecrypt() {
+		return nil, libkb.KeyCannotDecryptError{}
+	}
+	return key, nil
+}
+
+func (e *SaltpackEncrypt) loadSigningKey(ctx *Context) (libkb.GenericKey, error) {
+	ska := libkb.SecretKeyArg{
+		Me:      e.me,
+		KeyType: libkb.DeviceSigningKeyType,
+	}
+	key, err := e.G().Keyrings.GetSecretKeyWithPrompt(ctx.SecretKeyPromptArg(ska, "signcrypting a message/file"))
+	if err != nil {
+		return nil, err
+	}
+	if !key.CanSign() {
+		return nil, libkb.KeyCannotSignError{}
+	}
+	return key, nil
 }
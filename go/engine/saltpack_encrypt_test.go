@@ -4,6 +4,10 @@
 package engine
 
 import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -310,3 +314,64 @@ func TestSaltpackEncryptBinary(t *testing.T) {
 		t.Errorf("decoded: %s, expected: %s", decmsg, msg)
 	}
 }
+
+func TestSaltpackEncryptRecipientsFileAndGroups(t *testing.T) {
+	tc := libkb.SetupTestWithFakeAPI(t, "SaltpackEncrypt", nil)
+	defer tc.Cleanup()
+
+	u1 := CreateAndSignupFakeUser(tc, "nalcp")
+	u2 := CreateAndSignupFakeUser(tc, "nalcp")
+	u3 := CreateAndSignupFakeUser(tc, "nalcp")
+	u4 := CreateAndSignupFakeUser(tc, "nalcp")
+
+	file := filepath.Join(tc.Tp.Home, "recipients")
+	contents := "# release signers\n" + u1.Username + "\n\n" + u2.Username + "\n"
+	if err := ioutil.WriteFile(file, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	uc, err := tc.G.Env.GetConfig().GetUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc.RecipientGroups = map[string][]string{"release": {u2.Username, u3.Username}}
+	if err := tc.G.Env.GetConfigWriter().SetUserConfig(uc, true); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &Context{IdentifyUI: &FakeIdentifyUI{}, SecretUI: u4.NewSecretUI()}
+	arg := &SaltpackEncryptArg{
+		Opts: keybase1.SaltpackEncryptOptions{
+			RecipientsFile:  file,
+			RecipientGroups: []string{"release"},
+		},
+		Source: strings.NewReader("for everyone on the list"),
+		Sink:   libkb.NewBufferCloser(),
+	}
+	eng := NewSaltpackEncrypt(arg, tc.G)
+	if err := RunEngine(eng, ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, r := range eng.Result().Recipients {
+		if len(r.DeviceKeys) == 0 {
+			t.Errorf("no device keys for %s", r.Username)
+		}
+		names = append(names, r.Username)
+	}
+	// Ourselves first, then everyone else by name, each only once.
+	others := []string{u1.Username, u2.Username, u3.Username}
+	sort.Strings(others)
+	expected := append([]string{u4.Username}, others...)
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("recipients: %v, expected %v", names, expected)
+	}
+
+	arg.Opts = keybase1.SaltpackEncryptOptions{RecipientGroups: []string{"nope"}}
+	arg.Source = strings.NewReader("for nobody")
+	err = RunEngine(NewSaltpackEncrypt(arg, tc.G), ctx)
+	if _, ok := err.(libkb.RecipientGroupNotFoundError); !ok {
+		t.Errorf("expected a RecipientGroupNotFoundError, got %v", err)
+	}
+}
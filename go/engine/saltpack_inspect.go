@@ -0,0 +1,96 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"io"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// SaltpackInspect reads the header of a message and reports on it,
+// without decrypting or verifying anything. If a user is logged in, it
+// also works out which of their devices can open the message.
+type SaltpackInspect struct {
+	libkb.Contextified
+	source io.Reader
+	res    keybase1.SaltpackHeaderInfo
+}
+
+// NewSaltpackInspect creates a SaltpackInspect engine.
+func NewSaltpackInspect(source io.Reader, g *libkb.GlobalContext) *SaltpackInspect {
+	return &SaltpackInspect{
+		Contextified: libkb.NewContextified(g),
+		source:       source,
+	}
+}
+
+// Name is the unique engine name.
+func (e *SaltpackInspect) Name() string {
+	return "SaltpackInspect"
+}
+
+// GetPrereqs returns the engine prereqs.
+func (e *SaltpackInspect) Prereqs() Prereqs {
+	return Prereqs{}
+}
+
+// RequiredUIs returns the required UIs.
+func (e *SaltpackInspect) RequiredUIs() []libkb.UIKind {
+	return nil
+}
+
+// SubConsumers returns the other UI consumers for this engine.
+func (e *SaltpackInspect) SubConsumers() []libkb.UIConsumer {
+	return nil
+}
+
+// Run starts the engine.
+func (e *SaltpackInspect) Run(ctx *Context) (err error) {
+	defer e.G().Trace("SaltpackInspect::Run", func() error { return err })()
+
+	info, err := libkb.SaltpackInspect(e.source)
+	if err != nil {
+		return err
+	}
+	sc := info.Classification
+	e.res = keybase1.SaltpackHeaderInfo{
+		Format:           string(sc.Format),
+		Mode:             sc.Type.String(),
+		Armored:          sc.Armored,
+		Brand:            info.Brand,
+		VersionMajor:     info.Version.Major,
+		VersionMinor:     info.Version.Minor,
+		NumReceivers:     info.NumReceivers,
+		NumAnonReceivers: info.NumAnonReceivers,
+		ReceiverKIDs:     info.ReceiverKIDs,
+		SenderKID:        info.SenderKID,
+	}
+
+	if len(info.ReceiverKIDs) == 0 {
+		return nil
+	}
+	if ok, _, _ := IsLoggedIn(e, ctx); !ok {
+		return nil
+	}
+	me, err := libkb.LoadMe(libkb.NewLoadUserArg(e.G()))
+	if err != nil {
+		return err
+	}
+	ckf := me.GetComputedKeyFamily()
+	for _, kid := range info.ReceiverKIDs {
+		if dev, _ := ckf.GetDeviceForKID(kid); dev != nil {
+			edev := dev.ProtExport()
+			edev.EncryptKey = kid
+			e.res.Devices = append(e.res.Devices, *edev)
+		}
+	}
+	return nil
+}
+
+// Result returns what was found in the header.
+func (e *SaltpackInspect) Result() keybase1.SaltpackHeaderInfo {
+	return e.res
+}
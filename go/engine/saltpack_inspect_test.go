@@ -0,0 +1,54 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+func TestSaltpackInspect(t *testing.T) {
+	tc := libkb.SetupTestWithFakeAPI(t, "SaltpackInspect", nil)
+	defer tc.Cleanup()
+
+	them := CreateAndSignupFakeUser(tc, "spi")
+	Logout(tc)
+	me := CreateAndSignupFakeUser(tc, "spi")
+
+	ctx := &Context{IdentifyUI: &FakeIdentifyUI{}, SecretUI: me.NewSecretUI()}
+	sink := libkb.NewBufferCloser()
+	arg := &SaltpackEncryptArg{
+		Opts:   keybase1.SaltpackEncryptOptions{Recipients: []string{them.Username}},
+		Source: strings.NewReader("a message to inspect"),
+		Sink:   sink,
+	}
+	if err := RunEngine(NewSaltpackEncrypt(arg, tc.G), ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	eng := NewSaltpackInspect(bytes.NewReader(sink.Bytes()), tc.G)
+	if err := RunEngine(eng, &Context{}); err != nil {
+		t.Fatal(err)
+	}
+	res := eng.Result()
+	if res.Format != "saltpack" || res.Mode != "encryption" || !res.Armored {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	// One device key and one paper key for each of us.
+	if res.NumReceivers != 4 || res.NumAnonReceivers != 0 || len(res.ReceiverKIDs) != 4 {
+		t.Errorf("receivers: %d (%d anonymous), kids: %v", res.NumReceivers, res.NumAnonReceivers, res.ReceiverKIDs)
+	}
+	if len(res.Devices) != 2 {
+		t.Fatalf("expected 2 of my devices to be able to open it, got %+v", res.Devices)
+	}
+	for _, d := range res.Devices {
+		if d.EncryptKey.IsNil() {
+			t.Errorf("device %s has no encryption key", d.Name)
+		}
+	}
+}
@@ -14,7 +14,7 @@ import (
 type SaltpackSign struct {
 	libkb.Contextified
 	arg *SaltpackSignArg
-	key libkb.NaclSigningKeyPair
+	key libkb.GenericKey
 }
 
 type SaltpackSignArg struct {
@@ -81,10 +81,9 @@ func (e *SaltpackSign) loadKey(ctx *Context) error {
 	if err != nil {
 		return err
 	}
-	kp, ok := key.(libkb.NaclSigningKeyPair)
-	if !ok || kp.Private == nil {
+	if !key.CanSign() {
 		return libkb.KeyCannotSignError{}
 	}
-	e.key = kp
+	e.key = key
 	return nil
 }
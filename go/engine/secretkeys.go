@@ -71,7 +71,7 @@ func (e *SecretKeysEngine) Run(ctx *Context) (err error) {
 	}
 	sigNaclKey, ok := sigKey.(libkb.NaclSigningKeyPair)
 	if !ok {
-		return fmt.Errorf("Expected a NaCl signing key.")
+		return secretKeyNotExportable(sigKey, "NaCl signing key")
 	}
 	e.G().Log.Debug("| got signing key")
 
@@ -85,7 +85,7 @@ func (e *SecretKeysEngine) Run(ctx *Context) (err error) {
 	}
 	encNaclKey, ok := encKey.(libkb.NaclDHKeyPair)
 	if !ok {
-		return fmt.Errorf("Expected a NaCl encryption key.")
+		return secretKeyNotExportable(encKey, "NaCl encryption key")
 	}
 	e.G().Log.Debug("| got encryption key")
 
@@ -95,6 +95,16 @@ func (e *SecretKeysEngine) Run(ctx *Context) (err error) {
 	return nil
 }
 
+// secretKeyNotExportable explains why key's secret half can't be
+// exported. Keys on PKCS#11 tokens never leave the token.
+func secretKeyNotExportable(key libkb.GenericKey, want string) error {
+	switch key.(type) {
+	case *libkb.PKCS11SigningKey, *libkb.PKCS11DHKey:
+		return libkb.PKCS11Error{Msg: fmt.Sprintf("can't export %s; it never leaves its token", key.VerboseDescription())}
+	}
+	return fmt.Errorf("Expected a %s.", want)
+}
+
 func (e *SecretKeysEngine) Result() keybase1.SecretKeys {
 	return e.result
 }
@@ -66,6 +66,10 @@ func (s *SignupEngine) GetMe() *libkb.User {
 }
 
 func (s *SignupEngine) Run(ctx *Context) error {
+	if err := libkb.CheckPassphrasePolicy(s.G().Env.GetPassphrasePolicy(), s.arg.Passphrase, s.arg.Username, s.arg.Email); err != nil {
+		return err
+	}
+
 	// make sure we're starting with a clear login state:
 	if err := s.G().Logout(); err != nil {
 		return err
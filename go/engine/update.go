@@ -6,8 +6,10 @@ package engine
 import (
 	"fmt"
 	"io"
+	"path/filepath"
 	"runtime"
 
+	"github.com/kardianos/osext"
 	"github.com/keybase/client/go/libkb"
 	keybase1 "github.com/keybase/client/go/protocol"
 	"github.com/keybase/client/go/updater"
@@ -95,6 +97,10 @@ func (u UpdaterContext) AfterUpdateApply(willRestart bool) error {
 	return AfterUpdateApply(u.g, willRestart)
 }
 
+func (u UpdaterContext) RestartService() error {
+	return RestartService(u.g)
+}
+
 func (u UpdaterContext) Verify(r io.Reader, signature string) error {
 	checkSender := func(key saltpack.SigningPublicKey) error {
 		kid := libkb.SigningPublicKeyToKeybaseKID(key)
@@ -181,6 +187,11 @@ func DefaultUpdaterOptions(g *libkb.GlobalContext) keybase1.UpdateOptions {
 	switch {
 	case runtime.GOOS == "darwin" && g.Env.GetRunMode() == libkb.ProductionRunMode:
 		updateOptions.DestinationPath = "/Applications/Keybase.app"
+	case runtime.GOOS == "linux":
+		// The update replaces the binaries next to this one.
+		if path, err := osext.Executable(); err == nil {
+			updateOptions.DestinationPath = filepath.Dir(path)
+		}
 	}
 	return updateOptions
 }
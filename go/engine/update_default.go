@@ -5,8 +5,32 @@
 
 package engine
 
-import "github.com/keybase/client/go/libkb"
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/kardianos/osext"
+	"github.com/keybase/client/go/libkb"
+)
 
 func AfterUpdateApply(g *libkb.GlobalContext, willRestart bool) error {
 	return nil
 }
+
+// RestartService restarts the service with "keybase update restart", run
+// from the keybase binary that the update replaced. That goes through
+// "keybase ctl restart", and then rolls the update back if the new service
+// doesn't answer.
+func RestartService(g *libkb.GlobalContext) error {
+	path, err := osext.Executable()
+	if err != nil {
+		return err
+	}
+	args := []string{"--home", g.Env.GetHome(), "--run-mode", string(g.Env.GetRunMode())}
+	if socketFile, err := g.Env.GetSocketFile(); err == nil && socketFile != "" {
+		args = append(args, "--socket-file", socketFile)
+	}
+	args = append(args, "update", "restart")
+	g.Log.Info("Running %s %s", path, strings.Join(args, " "))
+	return exec.Command(path, args...).Start()
+}
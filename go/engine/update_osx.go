@@ -6,6 +6,8 @@
 package engine
 
 import (
+	"fmt"
+
 	"github.com/keybase/client/go/install"
 	"github.com/keybase/client/go/libkb"
 	"github.com/keybase/client/go/protocol"
@@ -38,3 +40,7 @@ func AfterUpdateApply(g *libkb.GlobalContext, willRestart bool) error {
 
 	return nil
 }
+
+func RestartService(g *libkb.GlobalContext) error {
+	return fmt.Errorf("Restarting the service after an update isn't supported on this platform")
+}
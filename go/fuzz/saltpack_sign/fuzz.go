@@ -63,7 +63,7 @@ func (s *sigPrivKey) Sign(message []byte) ([]byte, error) {
 	return sig[:], nil
 }
 
-func (s *sigPrivKey) PublicKey() saltpack.SigningPublicKey {
+func (s *sigPrivKey) GetPublicKey() saltpack.SigningPublicKey {
 	return s.public
 }
 
@@ -72,7 +72,7 @@ type keyring struct {
 }
 
 func (k keyring) LookupSigningPublicKey(kid []byte) saltpack.SigningPublicKey {
-	pk := k.PublicKey()
+	pk := k.GetPublicKey()
 	if !bytes.Equal(pk.ToKID(), kid) {
 		return nil
 	}
@@ -102,18 +102,18 @@ func init() {
 }
 
 func Fuzz(data []byte) int {
-	smsg, err := saltpack.Sign(data, &key)
+	smsg, err := saltpack.Sign(saltpack.Version1(), data, &key)
 	if err != nil {
 		panic(err)
 	}
-	skey, vmsg, err := saltpack.Verify(smsg, keyring{&key})
+	skey, vmsg, err := saltpack.Verify(saltpack.CheckKnownMajorVersion, smsg, keyring{&key})
 	if err != nil {
 		panic(err)
 	}
 	if !bytes.Equal(vmsg, data) {
 		panic("message mismatch")
 	}
-	if !saltpack.KIDEqual(key.PublicKey(), skey) {
+	if !saltpack.PublicKeyEqual(key.GetPublicKey(), skey) {
 		panic("verifier key doesn't match signer key")
 	}
 
@@ -112,6 +112,9 @@ func (p CommandLine) GetRunMode() (libkb.RunMode, error) {
 func (p CommandLine) GetPinentry() string {
 	return p.GetGString("pinentry")
 }
+func (p CommandLine) GetActingUser() string {
+	return p.GetGString("user")
+}
 func (p CommandLine) GetGString(s string) string {
 	return p.ctx.GlobalString(s)
 }
@@ -311,6 +314,10 @@ func (p *CommandLine) PopulateApp(addHelp bool, extraFlags []cli.Flag) {
 			Name:  "api-uri-path-prefix",
 			Usage: "Specify an alternate API URI path prefix.",
 		},
+		cli.StringFlag{
+			Name:  "user",
+			Usage: "Act as this user, one of those provisioned on this machine, instead of the current one.",
+		},
 		cli.StringFlag{
 			Name:  "pinentry",
 			Usage: "Specify a path to find a pinentry program.",
@@ -349,7 +356,7 @@ func (p *CommandLine) PopulateApp(addHelp bool, extraFlags []cli.Flag) {
 		},
 		cli.StringFlag{
 			Name:  "log-format",
-			Usage: "Log format (default, plain, file, fancy).",
+			Usage: "Log format (default, plain, file, fancy, json).",
 		},
 		cli.StringFlag{
 			Name:  "pgpdir, gpgdir",
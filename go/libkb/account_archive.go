@@ -0,0 +1,253 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	jsonw "github.com/keybase/go-jsonw"
+	triplesec "github.com/keybase/go-triplesec"
+	"github.com/ugorji/go/codec"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// AccountArchiveVersion is the version of the sealed account archive
+// format.
+const AccountArchiveVersion = 1
+
+// AccountArchive is the local state of a user on a device, enough to
+// carry the device over to another machine: the user's entry in the
+// config file, the secret keyring, local tracks and favorites.
+type AccountArchive struct {
+	Username      NormalizedUsername     `codec:"username"`
+	UID           keybase1.UID           `codec:"uid"`
+	UserConfig    []byte                 `codec:"user_config"`
+	SecretKeyring []byte                 `codec:"secret_keyring"`
+	LocalTracks   []AccountArchiveDbItem `codec:"local_tracks"`
+	Favorites     []keybase1.Folder      `codec:"favorites"`
+	CTime         keybase1.Time          `codec:"ctime"`
+}
+
+// AccountArchiveDbItem is an object from the local db, kept as the JSON
+// it's stored as.
+type AccountArchiveDbItem struct {
+	Key   string `codec:"key"`
+	Value []byte `codec:"value"`
+}
+
+// sealedAccountArchive is what's written to disk. The key for the box
+// comes from the passphrase and salt, by way of a PassphraseStream.
+type sealedAccountArchive struct {
+	Version    int    `codec:"version"`
+	Salt       []byte `codec:"salt"`
+	Nonce      []byte `codec:"nonce"`
+	Ciphertext []byte `codec:"ciphertext"`
+}
+
+// NewAccountArchive gathers the local state for the given user. The
+// favorites are on the server, so the caller fills those in.
+func NewAccountArchive(g *GlobalContext, username NormalizedUsername) (*AccountArchive, error) {
+	uc, err := g.Env.GetConfig().GetUserConfigForUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if uc == nil {
+		return nil, NoUserConfigError{}
+	}
+	if uc.GetDeviceID().IsNil() {
+		return nil, DeviceRequiredError{}
+	}
+	ucJSON, err := json.Marshal(uc)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring, err := ioutil.ReadFile(g.SKBFilenameForUser(username))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &AccountArchive{
+		Username:      username,
+		UID:           uc.GetUID(),
+		UserConfig:    ucJSON,
+		SecretKeyring: keyring,
+		CTime:         keybase1.ToTime(g.GetClock().Now()),
+	}
+
+	keys, err := g.LocalDb.KeysWithType(DBLocalTrack)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if !strings.HasPrefix(key.Key, uc.GetUID().String()+"-") {
+			continue
+		}
+		val, found, err := g.LocalDb.GetRaw(key)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		ret.LocalTracks = append(ret.LocalTracks, AccountArchiveDbItem{Key: key.Key, Value: val})
+	}
+
+	return ret, nil
+}
+
+// Seal encrypts the archive with a key stretched from passphrase.
+func (a *AccountArchive) Seal(passphrase string) ([]byte, error) {
+	var plaintext []byte
+	if err := codec.NewEncoderBytes(&plaintext, codecHandle()).Encode(a); err != nil {
+		return nil, err
+	}
+
+	salt, err := RandBytes(triplesec.SaltLen)
+	if err != nil {
+		return nil, err
+	}
+	key, err := accountArchiveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := RandBytes(24)
+	if err != nil {
+		return nil, err
+	}
+	var fnonce [24]byte
+	copy(fnonce[:], nonce)
+
+	sealed := sealedAccountArchive{
+		Version:    AccountArchiveVersion,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: secretbox.Seal(nil, plaintext, &fnonce, key),
+	}
+	var ret []byte
+	if err := codec.NewEncoderBytes(&ret, codecHandle()).Encode(sealed); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// OpenAccountArchive decrypts the output of Seal. A wrong passphrase
+// gives a PassphraseError.
+func OpenAccountArchive(b []byte, passphrase string) (*AccountArchive, error) {
+	var sealed sealedAccountArchive
+	if err := MsgpackDecodeAll(b, codecHandle(), &sealed); err != nil {
+		return nil, BadAccountArchiveError{Msg: err.Error()}
+	}
+	if sealed.Version != AccountArchiveVersion {
+		return nil, BadAccountArchiveError{Msg: fmt.Sprintf("unknown version %d", sealed.Version)}
+	}
+	if len(sealed.Salt) != triplesec.SaltLen || len(sealed.Nonce) != 24 {
+		return nil, BadAccountArchiveError{Msg: "bad salt or nonce"}
+	}
+
+	key, err := accountArchiveKey(passphrase, sealed.Salt)
+	if err != nil {
+		return nil, err
+	}
+	var fnonce [24]byte
+	copy(fnonce[:], sealed.Nonce)
+	plaintext, ok := secretbox.Open(nil, sealed.Ciphertext, &fnonce, key)
+	if !ok {
+		return nil, PassphraseError{Msg: "can't open the account archive"}
+	}
+
+	var ret AccountArchive
+	if err := MsgpackDecodeAll(plaintext, codecHandle(), &ret); err != nil {
+		return nil, BadAccountArchiveError{Msg: err.Error()}
+	}
+	return &ret, nil
+}
+
+func accountArchiveKey(passphrase string, salt []byte) (*[32]byte, error) {
+	_, pps, err := StretchPassphrase(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	var key [32]byte
+	copy(key[:], pps.LksClientHalf())
+	return &key, nil
+}
+
+// GetUserConfig parses the archived config file entry.
+func (a *AccountArchive) GetUserConfig() (*UserConfig, error) {
+	jw, err := jsonw.Unmarshal(a.UserConfig)
+	if err != nil {
+		return nil, BadAccountArchiveError{Msg: err.Error()}
+	}
+	uc, err := ImportUserConfigFromJSONWrapper(jw)
+	if err != nil {
+		return nil, err
+	}
+	if uc == nil || !uc.GetUsername().Eq(a.Username) {
+		return nil, BadAccountArchiveError{Msg: "config entry doesn't match the user"}
+	}
+	return uc, nil
+}
+
+// Restore writes the archived state into this machine's config file,
+// secret keyring and local db, and makes the archived user the current
+// one.
+func (a *AccountArchive) Restore(g *GlobalContext) error {
+	uc, err := a.GetUserConfig()
+	if err != nil {
+		return err
+	}
+
+	filename := g.SKBFilenameForUser(a.Username)
+	if err := MakeParentDirs(filename); err != nil {
+		return err
+	}
+	if err := NewFile(filename, a.SecretKeyring, 0600).Save(); err != nil {
+		return err
+	}
+
+	for _, item := range a.LocalTracks {
+		if !strings.HasPrefix(item.Key, uc.GetUID().String()+"-") {
+			return BadAccountArchiveError{Msg: fmt.Sprintf("local track %q isn't the user's", item.Key)}
+		}
+		if err := g.LocalDb.PutRaw(DbKey{Typ: DBLocalTrack, Key: item.Key}, item.Value); err != nil {
+			return err
+		}
+	}
+
+	return g.Env.GetConfigWriter().SetUserConfig(uc, true)
+}
+
+// AccountArchiveExists returns true if the user already has a device or
+// a secret keyring on this machine, which restoring an archive would
+// replace.
+func AccountArchiveExists(g *GlobalContext, username NormalizedUsername) (bool, error) {
+	current, others, err := g.Env.GetConfig().GetAllUsernames()
+	if err != nil {
+		return false, err
+	}
+	for _, nu := range append(others, current) {
+		if !nu.Eq(username) {
+			continue
+		}
+		uc, err := g.Env.GetConfig().GetUserConfigForUsername(username)
+		if err != nil {
+			return false, err
+		}
+		if uc != nil && !uc.GetDeviceID().IsNil() {
+			return true, nil
+		}
+	}
+	if _, err := os.Stat(g.SKBFilenameForUser(username)); err == nil {
+		return true, nil
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+	return false, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+func TestAccountArchive(t *testing.T) {
+	tc := SetupTest(t, "account archive")
+	defer tc.Cleanup()
+
+	uid, err := UIDFromHex("11111111111111111111111111111119")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := UIDFromHex("22222222222222222222222222222219")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deviceID, err := NewDeviceID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := []byte("some salt bytes!")
+	nu := NewNormalizedUsername("archived")
+	uc := NewUserConfig(uid, nu, salt, deviceID)
+	uc.RecipientGroups = map[string][]string{"team": {"t_alice"}}
+	if err := tc.G.Env.GetConfigWriter().SetUserConfig(uc, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewAccountArchive(tc.G, nu); err == nil {
+		t.Fatal("archived a user without a secret keyring")
+	}
+	keyring := []byte("a secret keyring")
+	filename := tc.G.SKBFilenameForUser(nu)
+	if err := MakeParentDirs(filename); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filename, keyring, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	statement := jsonw.NewDictionary()
+	statement.SetKey("track", jsonw.NewString("statement"))
+	if err := StoreLocalTrack(uid, other, false, statement, tc.G); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreLocalTrack(uid, other, true, statement, tc.G); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreLocalTrack(other, uid, false, statement, tc.G); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := NewAccountArchive(tc.G, nu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.LocalTracks) != 2 {
+		t.Errorf("archived %d local tracks, expected the user's 2", len(archive.LocalTracks))
+	}
+	archive.Favorites = []keybase1.Folder{{Name: "archived,t_alice", Private: true}}
+
+	sealed, err := archive.Seal("archive passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, keyring) {
+		t.Fatal("sealed archive has the keyring in the clear")
+	}
+	if _, err := OpenAccountArchive(sealed, "wrong passphrase"); err == nil {
+		t.Fatal("opened the archive with the wrong passphrase")
+	} else if _, ok := err.(PassphraseError); !ok {
+		t.Fatalf("wrong passphrase error: %T %s", err, err)
+	}
+	if _, err := OpenAccountArchive(sealed[1:], "archive passphrase"); err == nil {
+		t.Fatal("opened a damaged archive")
+	}
+	opened, err := OpenAccountArchive(sealed, "archive passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opened.Favorites) != 1 || opened.Favorites[0].Name != "archived,t_alice" {
+		t.Errorf("favorites %+v", opened.Favorites)
+	}
+
+	// Forget everything, and then restore it.
+	if exists, err := AccountArchiveExists(tc.G, nu); err != nil || !exists {
+		t.Fatalf("before nuking, exists = %v, %v", exists, err)
+	}
+	if err := tc.G.Env.GetConfigWriter().NukeUser(nu); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filename); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveLocalTracks(uid, other, tc.G); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := AccountArchiveExists(tc.G, nu); err != nil || exists {
+		t.Fatalf("after nuking, exists = %v, %v", exists, err)
+	}
+
+	if err := opened.Restore(tc.G); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, keyring) {
+		t.Errorf("restored keyring %q, expected %q", got, keyring)
+	}
+	if tc.G.Env.GetUsername() != nu {
+		t.Errorf("current user %q, expected %q", tc.G.Env.GetUsername(), nu)
+	}
+	restored, err := tc.G.Env.GetConfig().GetUserConfigForUsername(nu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.GetUID().Equal(uid) || restored.GetDeviceID() != deviceID || !bytes.Equal(restored.GetSalt(), salt) {
+		t.Errorf("restored config %+v", restored)
+	}
+	if _, err := restored.GetRecipientGroup("team"); err != nil {
+		t.Error(err)
+	}
+	for _, expiring := range []bool{false, true} {
+		obj, err := tc.G.LocalDb.Get(LocalTrackDBKey(uid, other, expiring))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj == nil {
+			t.Errorf("local track (expiring = %v) wasn't restored", expiring)
+		}
+	}
+}
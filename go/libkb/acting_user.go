@@ -0,0 +1,263 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"sync"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	"golang.org/x/net/context"
+)
+
+// ActingUserRPCTag is the RPC tag that a client sets on a call to have
+// the service run it as one of the users provisioned on this machine,
+// rather than as the current user.
+const ActingUserRPCTag = "acting_user"
+
+// ActingUserFromContext returns the user that the RPC tags in ctx ask to
+// act as, if any.
+func ActingUserFromContext(ctx context.Context) NormalizedUsername {
+	tags, ok := rpc.RpcTagsFromContext(ctx)
+	if !ok {
+		return NormalizedUsername("")
+	}
+	switch v := tags[ActingUserRPCTag].(type) {
+	case string:
+		return NewNormalizedUsername(v)
+	case []byte:
+		return NewNormalizedUsername(string(v))
+	}
+	return NormalizedUsername("")
+}
+
+// actingUsers lets several users stay logged in to one service. Each
+// user other than the current one in the config file gets a
+// GlobalContext of their own, with their own LoginState and session,
+// which calls acting as them use. The config file's current user never
+// changes.
+type actingUsers struct {
+	sync.Mutex
+	contexts map[NormalizedUsername]*GlobalContext
+}
+
+func newActingUsers() *actingUsers {
+	return &actingUsers{contexts: make(map[NormalizedUsername]*GlobalContext)}
+}
+
+// ActingUserContext returns the GlobalContext for calls that act as
+// username. That's g itself if username is empty or the current user;
+// otherwise it's a context that shares g's caches, databases and
+// routers, but has username's config, LoginState, session and keyrings.
+// It's made the first time it's asked for, and kept after that.
+func (g *GlobalContext) ActingUserContext(username NormalizedUsername) (*GlobalContext, error) {
+	if username.IsNil() || username.Eq(g.Env.GetUsername()) {
+		return g, nil
+	}
+	a := g.actingUsers
+	if a == nil {
+		return nil, ActingUserError{Username: username, Msg: "can't act as another user from here"}
+	}
+	a.Lock()
+	defer a.Unlock()
+	if ug, ok := a.contexts[username]; ok {
+		return ug, nil
+	}
+
+	config := g.Env.GetConfig()
+	_, others, err := config.GetAllUsernames()
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, nu := range others {
+		if nu.Eq(username) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, UserNotFoundError{Msg: username.String()}
+	}
+	uc, err := config.GetUserConfigForUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	ug, err := g.newActingUserContext(uc)
+	if err != nil {
+		return nil, err
+	}
+	a.contexts[username] = ug
+	return ug, nil
+}
+
+func (g *GlobalContext) newActingUserContext(uc *UserConfig) (*GlobalContext, error) {
+	g.Log.Debug("making a context to act as %s", uc.GetUsername())
+	ug := &GlobalContext{
+		Log:                 g.Log,
+		VDL:                 g.VDL,
+		Env:                 g.Env.forActingUser(uc),
+		API:                 g.API,
+		Resolver:            g.Resolver,
+		LocalDb:             g.LocalDb,
+		MerkleClient:        g.MerkleClient,
+		XAPI:                g.XAPI,
+		Output:              g.Output,
+		ProofCache:          g.ProofCache,
+		GpgClient:           g.GpgClient,
+		SocketInfo:          g.SocketInfo,
+		LoopbackListener:    g.LoopbackListener,
+		XStreams:            g.XStreams,
+		Timers:              g.Timers,
+		LinkCache:           g.LinkCache,
+		UI:                  g.UI,
+		Service:             g.Service,
+		ConnectionManager:   g.ConnectionManager,
+		NotifyRouter:        g.NotifyRouter,
+		UIRouter:            g.UIRouter,
+		ProofCheckerFactory: g.ProofCheckerFactory,
+		RateLimits:          g.RateLimits,
+		Clock:               g.Clock,
+		SecretStoreAll:      g.SecretStoreAll,
+		Metrics:             g.Metrics,
+	}
+	ug.Keyrings = NewKeyrings(ug)
+	trackCache := NewTrackCache()
+	identify2Cache := NewIdentify2Cache(ug.Env.GetUserCacheMaxAge())
+	if g.Env.GetMetricsAddr() != "" {
+		trackCache.countStats()
+		identify2Cache.countStats()
+	}
+	ug.TrackCache = trackCache
+	ug.Identify2Cache = identify2Cache
+	// The API server session comes from the API engine's context, so
+	// the acting user needs an engine of their own. (Tests can swap in
+	// an API that isn't an engine, and that's shared.)
+	if _, ok := g.API.(*InternalAPIEngine); ok {
+		api, err := NewInternalAPIEngine(ug)
+		if err != nil {
+			return nil, err
+		}
+		ug.API = api
+	}
+	ug.createLoginState()
+	return ug, nil
+}
+
+// shutdownActingUsers shuts down the LoginStates and caches of the users
+// acted as.
+func (g *GlobalContext) shutdownActingUsers() error {
+	a := g.actingUsers
+	if a == nil {
+		return nil
+	}
+	a.Lock()
+	defer a.Unlock()
+	epick := FirstErrorPicker{}
+	for nu, ug := range a.contexts {
+		epick.Push(ug.LoginState().Shutdown())
+		ug.TrackCache.Shutdown()
+		ug.Identify2Cache.Shutdown()
+		delete(a.contexts, nu)
+	}
+	return epick.Error()
+}
+
+// forActingUser returns an Env that reads the config as if uc's user
+// were its current user, and keeps their session in a file of their
+// own. Its ConfigWriter won't change the config file's current user.
+func (e *Env) forActingUser(uc *UserConfig) *Env {
+	e.Lock()
+	defer e.Unlock()
+	ae := &Env{
+		cmd:        e.cmd,
+		homeFinder: e.homeFinder,
+		Test:       e.Test,
+		parent:     e,
+		actingUser: uc,
+	}
+	ae.setActingConfigLocked(e.config, e.writer)
+	e.actingEnvs = append(e.actingEnvs, ae)
+	return ae
+}
+
+func (e *Env) setActingConfig(r ConfigReader, w ConfigWriter) {
+	e.Lock()
+	defer e.Unlock()
+	e.setActingConfigLocked(r, w)
+}
+
+func (e *Env) setActingConfigLocked(r ConfigReader, w ConfigWriter) {
+	e.config = actingUserConfig{ConfigReader: r, uc: e.actingUser}
+	e.writer = nil
+	if w != nil {
+		e.writer = actingUserConfigWriter{ConfigWriter: w, uc: e.actingUser}
+	}
+}
+
+// actingUserConfig reads the config as if uc's user were its current
+// user.
+type actingUserConfig struct {
+	ConfigReader
+	uc *UserConfig
+}
+
+func (c actingUserConfig) GetUserConfig() (*UserConfig, error) { return c.uc, nil }
+func (c actingUserConfig) GetUsername() NormalizedUsername     { return c.uc.GetUsername() }
+func (c actingUserConfig) GetUID() keybase1.UID                { return c.uc.GetUID() }
+func (c actingUserConfig) GetDeviceID() keybase1.DeviceID      { return c.uc.GetDeviceID() }
+func (c actingUserConfig) GetSalt() []byte                     { return c.uc.GetSalt() }
+
+func (c actingUserConfig) GetAllUsernames() (current NormalizedUsername, others []NormalizedUsername, err error) {
+	configCurrent, configOthers, err := c.ConfigReader.GetAllUsernames()
+	if err != nil {
+		return current, others, err
+	}
+	current = c.uc.GetUsername()
+	for _, nu := range append(configOthers, configCurrent) {
+		if !nu.IsNil() && !nu.Eq(current) {
+			others = append(others, nu)
+		}
+	}
+	return current, others, nil
+}
+
+// actingUserConfigWriter writes the config for an acting user. Writes
+// that would change which user is current, or the acting user's own
+// config, are refused; saving the config the user already has is a
+// no-op.
+type actingUserConfigWriter struct {
+	ConfigWriter
+	uc *UserConfig
+}
+
+func (w actingUserConfigWriter) refuse(msg string) error {
+	return ActingUserError{Username: w.uc.GetUsername(), Msg: msg}
+}
+
+func (w actingUserConfigWriter) SetUserConfig(cfg *UserConfig, overwrite bool) error {
+	if cfg != nil && !overwrite && cfg.GetUsername().Eq(w.uc.GetUsername()) && cfg.GetUID().Equal(w.uc.GetUID()) {
+		return nil
+	}
+	return w.refuse("can't change the configured user")
+}
+
+func (w actingUserConfigWriter) SwitchUser(nu NormalizedUsername) error {
+	if nu.Eq(w.uc.GetUsername()) {
+		return nil
+	}
+	return w.refuse("can't switch users")
+}
+
+func (w actingUserConfigWriter) NukeUser(nu NormalizedUsername) error {
+	return w.refuse("can't remove a user")
+}
+
+func (w actingUserConfigWriter) SetDeviceID(keybase1.DeviceID) error {
+	return w.refuse("can't change the device ID")
+}
+
+func (w actingUserConfigWriter) BeginTransaction() (ConfigWriterTransacter, error) {
+	return nil, w.refuse("can't change the configured user")
+}
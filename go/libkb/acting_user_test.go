@@ -0,0 +1,123 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	"golang.org/x/net/context"
+)
+
+func TestActingUserContext(t *testing.T) {
+	tc := SetupTest(t, "acting user context")
+	defer tc.Cleanup()
+
+	users := []struct{ name, uid string }{
+		{"alice", "11111111111111111111111111111119"},
+		{"bob", "22222222222222222222222222222219"},
+	}
+	for _, u := range users {
+		uid, err := UIDFromHex(u.uid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uc := NewUserConfig(uid, NewNormalizedUsername(u.name), nil, "")
+		if err := tc.G.Env.GetConfigWriter().SetUserConfig(uc, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alice := NewNormalizedUsername("alice")
+	bob := NewNormalizedUsername("bob")
+	current := func() NormalizedUsername { return tc.G.Env.GetConfig().GetUsername() }
+	if !current().Eq(bob) {
+		t.Fatalf("current user %q, expected bob", current())
+	}
+
+	g, err := tc.G.ActingUserContext(NormalizedUsername(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != tc.G {
+		t.Error("no acting user, got another context")
+	}
+	g, err = tc.G.ActingUserContext(bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != tc.G {
+		t.Error("acting as the current user, got another context")
+	}
+
+	ag, err := tc.G.ActingUserContext(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ag == tc.G {
+		t.Fatal("acting as alice, got the current user's context")
+	}
+	if u := ag.Env.GetUsername(); !u.Eq(alice) {
+		t.Errorf("acting as alice, username %q", u)
+	}
+	if ag.LoginState() == tc.G.LoginState() {
+		t.Error("alice shares bob's login state")
+	}
+	if ag.Env.GetSessionFilename() == tc.G.Env.GetSessionFilename() {
+		t.Error("alice shares bob's session file")
+	}
+	if cur, others, err := ag.Env.GetConfig().GetAllUsernames(); err != nil {
+		t.Fatal(err)
+	} else if !cur.Eq(alice) || len(others) != 1 || !others[0].Eq(bob) {
+		t.Errorf("acting as alice, all usernames %q %q", cur, others)
+	}
+	again, err := tc.G.ActingUserContext(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != ag {
+		t.Error("alice's context wasn't kept")
+	}
+
+	// Acting as alice never changes the configured user.
+	if !current().Eq(bob) {
+		t.Fatalf("current user %q, expected bob", current())
+	}
+	onDisk := NewJSONConfigFile(tc.G, tc.G.Env.GetConfigFilename())
+	if err := onDisk.Load(false); err != nil {
+		t.Fatal(err)
+	}
+	if u := onDisk.GetUsername(); !u.Eq(bob) {
+		t.Errorf("current user on disk %q, expected bob", u)
+	}
+	aliceConfig, err := ag.Env.GetConfig().GetUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := ag.Env.GetConfigWriter()
+	if err := w.SetUserConfig(aliceConfig, false); err != nil {
+		t.Errorf("saving alice's own config: %s", err)
+	}
+	if err := w.SwitchUser(bob); err == nil {
+		t.Error("switched users while acting as alice")
+	} else if _, ok := err.(ActingUserError); !ok {
+		t.Errorf("unexpected error %T %s", err, err)
+	}
+	if !current().Eq(bob) {
+		t.Fatalf("current user %q, expected bob", current())
+	}
+
+	if _, err := tc.G.ActingUserContext(NewNormalizedUsername("carol")); err == nil {
+		t.Fatal("acted as a user who isn't on this machine")
+	} else if _, ok := err.(UserNotFoundError); !ok {
+		t.Fatalf("unexpected error %T %s", err, err)
+	}
+
+	ctx := rpc.AddRpcTagsToContext(context.Background(), rpc.CtxRpcTags{ActingUserRPCTag: []byte("Alice")})
+	if u := ActingUserFromContext(ctx); !u.Eq(alice) {
+		t.Errorf("acting user from tags %q, expected alice", u)
+	}
+	if u := ActingUserFromContext(context.Background()); !u.IsNil() {
+		t.Errorf("acting user without tags %q", u)
+	}
+}
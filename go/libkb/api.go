@@ -204,6 +204,12 @@ func doRequestShared(api Requester, arg APIArg, req *http.Request, wantJSONRes b
 	}
 
 	timer := arg.G().Timers.Start(timerType)
+	if !api.isExternal() && arg.G().Env.GetMetricsAddr() != "" {
+		start := time.Now()
+		defer func() {
+			arg.G().Metrics.RecordAPICall(arg.Endpoint, time.Since(start), err)
+		}()
+	}
 	internalResp, err := cli.cli.Do(req)
 	defer func() {
 		if internalResp != nil && err != nil {
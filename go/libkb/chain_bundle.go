@@ -0,0 +1,286 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+// ChainBundleVersion is the current version of the ChainBundle format.
+const ChainBundleVersion = 1
+
+// ChainBundle is a self-contained snapshot of a user's public identity:
+// the full sigchain, the merkle path to the user's leaf along with the
+// signed root, and the key family. The server replies are kept verbatim,
+// so that ChainBundle.Verify can replay the same checks that LoadUser does,
+// without any network access.
+type ChainBundle struct {
+	Version  int          `json:"version"`
+	UID      keybase1.UID `json:"uid"`
+	Username string       `json:"username"`
+
+	// The "them" object from user/lookup, which holds the key family.
+	User json.RawMessage `json:"user"`
+
+	// The body of the merkle/path reply, including the signed root.
+	MerklePath json.RawMessage `json:"merkle_path"`
+
+	// The armored PGP key that signed the merkle root. It's only needed
+	// for PGP root keys; NaCl KIDs contain their own public key. In both
+	// cases, the key must be one of the configured merkle KIDs.
+	MerkleKey string `json:"merkle_key,omitempty"`
+
+	// The "sigs" array from sig/get, starting at seqno 1.
+	Sigs json.RawMessage `json:"sigs"`
+}
+
+// ChainBundleKey is an active key that was computed from a ChainBundle.
+type ChainBundleKey struct {
+	KID        keybase1.KID      `json:"kid"`
+	Role       string            `json:"role"`
+	DeviceID   keybase1.DeviceID `json:"device_id,omitempty"`
+	DeviceName string            `json:"device_name,omitempty"`
+	DeviceType string            `json:"device_type,omitempty"`
+}
+
+// ChainBundleResult is the outcome of a successful ChainBundle.Verify.
+type ChainBundleResult struct {
+	UID         keybase1.UID     `json:"uid"`
+	Username    string           `json:"username"`
+	MerkleSeqno Seqno            `json:"merkle_seqno"`
+	MerkleCTime time.Time        `json:"merkle_ctime"`
+	ChainSeqno  Seqno            `json:"chain_seqno"`
+	Eldest      keybase1.KID     `json:"eldest_kid"`
+	Keys        []ChainBundleKey `json:"keys"`
+}
+
+// ChainBundleError is returned when a bundle fails to verify.
+type ChainBundleError struct {
+	msg string
+}
+
+func (e ChainBundleError) Error() string {
+	return fmt.Sprintf("chain bundle error: %s", e.msg)
+}
+
+func rawJSON(jw *jsonw.Wrapper) (json.RawMessage, error) {
+	b, err := jw.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(b), nil
+}
+
+// NewChainBundle makes a bundle out of the raw server replies. The caller
+// is responsible for fetching them for the same user at about the same
+// time; Verify will catch any inconsistency.
+func NewChainBundle(uid keybase1.UID, username string, user, merklePath, sigs *jsonw.Wrapper, merkleKey GenericKey) (*ChainBundle, error) {
+	ret := &ChainBundle{
+		Version:  ChainBundleVersion,
+		UID:      uid,
+		Username: username,
+	}
+	var err error
+	if ret.User, err = rawJSON(user); err != nil {
+		return nil, err
+	}
+	if ret.MerklePath, err = rawJSON(merklePath); err != nil {
+		return nil, err
+	}
+	if ret.Sigs, err = rawJSON(sigs); err != nil {
+		return nil, err
+	}
+	if pgp, ok := merkleKey.(*PGPKeyBundle); ok {
+		if ret.MerkleKey, err = pgp.Encode(); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// ReadChainBundle parses a ChainBundle that was written by Marshal.
+func ReadChainBundle(b []byte) (*ChainBundle, error) {
+	var ret ChainBundle
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return nil, err
+	}
+	if ret.Version != ChainBundleVersion {
+		return nil, ChainBundleError{fmt.Sprintf("unsupported version %d", ret.Version)}
+	}
+	return &ret, nil
+}
+
+// Marshal outputs the bundle as JSON.
+func (b *ChainBundle) Marshal() ([]byte, error) {
+	return json.MarshalIndent(b, "", "    ")
+}
+
+func (b *ChainBundle) unmarshalPart(name string, raw json.RawMessage) (*jsonw.Wrapper, error) {
+	if len(raw) == 0 {
+		return nil, ChainBundleError{fmt.Sprintf("missing %s", name)}
+	}
+	jw, err := jsonw.Unmarshal(raw)
+	if err != nil {
+		return nil, ChainBundleError{fmt.Sprintf("bad %s: %s", name, err)}
+	}
+	return jw, nil
+}
+
+// verifyRoot checks the root signature against one of the configured merkle
+// KIDs. Unlike MerkleClient.VerifyRoot, it never touches the network or the
+// local DB.
+func (b *ChainBundle) verifyRoot(g *GlobalContext, root *MerkleRoot) error {
+	keyring := NewSpecialKeyRing(g.Env.GetMerkleKIDs(), g)
+	kid, sig, err := root.findValidKIDAndSig(keyring)
+	if err != nil {
+		return err
+	}
+
+	key, found := keyring.keys[kid]
+	if !found {
+		if len(b.MerkleKey) == 0 {
+			return ChainBundleError{fmt.Sprintf("no key for merkle KID %s", kid)}
+		}
+		pgp, err := ReadOneKeyFromString(b.MerkleKey)
+		if err != nil {
+			return err
+		}
+		if pgp.GetKID().NotEqual(kid) {
+			return ChainBundleError{fmt.Sprintf("merkle key %s doesn't match KID %s", pgp.GetKID(), kid)}
+		}
+		key = pgp
+	}
+
+	_, err = key.VerifyString(sig, []byte(root.payloadJSONString))
+	return err
+}
+
+func (b *ChainBundle) importLinks(sc *SigChain, sigs *jsonw.Wrapper) error {
+	n, err := sigs.Len()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		link, err := ImportLinkFromServer(sc.G(), sc, sigs.AtIndex(i), sc.uid)
+		if err != nil {
+			return err
+		}
+		sc.chainLinks = append(sc.chainLinks, link)
+	}
+	return nil
+}
+
+// Verify checks everything in the bundle, with no network access: the root
+// signature, the merkle path down to the user's leaf, that the sigchain tail
+// matches the leaf, every link in the sigchain, and finally computes the
+// user's active keys from the key family.
+func (b *ChainBundle) Verify(g *GlobalContext) (res *ChainBundleResult, err error) {
+	defer g.Trace("ChainBundle::Verify", func() error { return err })()
+
+	userJSON, err := b.unmarshalPart("user", b.User)
+	if err != nil {
+		return nil, err
+	}
+	pathJSON, err := b.unmarshalPart("merkle_path", b.MerklePath)
+	if err != nil {
+		return nil, err
+	}
+	sigsJSON, err := b.unmarshalPart("sigs", b.Sigs)
+	if err != nil {
+		return nil, err
+	}
+
+	vp, err := NewVerificationPathFromJSON(g, pathJSON)
+	if err != nil {
+		return nil, err
+	}
+	if vp.uid.NotEqual(b.UID) {
+		return nil, UIDMismatchError{fmt.Sprintf("merkle path is for %s, not %s", vp.uid, b.UID)}
+	}
+	if err = b.verifyRoot(g, vp.root); err != nil {
+		return nil, err
+	}
+	leaf, err := vp.VerifyUser()
+	if err != nil {
+		return nil, err
+	}
+	username, err := vp.VerifyUsername()
+	if err != nil {
+		return nil, err
+	}
+	if !NewNormalizedUsername(username).Eq(NewNormalizedUsername(b.Username)) {
+		return nil, ChainBundleError{fmt.Sprintf("merkle path is for %s, not %s", username, b.Username)}
+	}
+
+	user, err := NewUserFromServer(g, userJSON)
+	if err != nil {
+		return nil, err
+	}
+	if user.GetUID().NotEqual(b.UID) {
+		return nil, UIDMismatchError{fmt.Sprintf("key family is for %s, not %s", user.GetUID(), b.UID)}
+	}
+
+	sc := &SigChain{
+		uid:               b.UID,
+		username:          NewNormalizedUsername(username),
+		allKeys:           true,
+		loadedFromLinkOne: true,
+		Contextified:      NewContextified(g),
+	}
+	if err = b.importLinks(sc, sigsJSON); err != nil {
+		return nil, err
+	}
+
+	// The merkle leaf commits to the chain tail, so a bundle with links
+	// chopped off the end (or made up) fails here.
+	tail := sc.GetCurrentTailTriple()
+	switch {
+	case leaf.public == nil && tail == nil:
+	case leaf.public == nil || tail == nil:
+		return nil, ChainBundleError{"sigchain doesn't match the merkle leaf"}
+	case leaf.public.Seqno != tail.Seqno || !leaf.public.LinkID.Eq(tail.LinkID):
+		return nil, ChainBundleError{fmt.Sprintf("sigchain tail (%d, %s) doesn't match merkle leaf (%d, %s)",
+			tail.Seqno, tail.LinkID, leaf.public.Seqno, leaf.public.LinkID)}
+	}
+
+	ckf := ComputedKeyFamily{kf: user.GetKeyFamily(), Contextified: NewContextified(g)}
+	if _, err = sc.VerifySigsAndComputeKeys(leaf.eldest, &ckf); err != nil {
+		return nil, err
+	}
+
+	res = &ChainBundleResult{
+		UID:         b.UID,
+		Username:    username,
+		MerkleSeqno: vp.root.seqno,
+		MerkleCTime: time.Unix(vp.root.ctime, 0),
+		Eldest:      leaf.eldest,
+	}
+	if tail != nil {
+		res.ChainSeqno = tail.Seqno
+	}
+	if ckf.cki == nil {
+		return res, nil
+	}
+	addKeys := func(keys []GenericKey, role string) {
+		for _, key := range keys {
+			k := ChainBundleKey{KID: key.GetKID(), Role: role}
+			if dev, err := ckf.GetDeviceForKID(k.KID); err == nil && dev != nil {
+				k.DeviceID = dev.ID
+				k.DeviceType = dev.Type
+				if dev.Description != nil {
+					k.DeviceName = *dev.Description
+				}
+			}
+			res.Keys = append(res.Keys, k)
+		}
+	}
+	addKeys(ckf.GetAllActiveSibkeys(), "sibkey")
+	addKeys(ckf.GetAllActiveSubkeys(), "subkey")
+	return res, nil
+}
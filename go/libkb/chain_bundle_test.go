@@ -0,0 +1,25 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import "testing"
+
+func TestReadChainBundle(t *testing.T) {
+	tc := SetupTest(t, "chain_bundle")
+	defer tc.Cleanup()
+
+	if _, err := ReadChainBundle([]byte(`{"version":2}`)); err == nil {
+		t.Fatal("expected an unsupported version error")
+	}
+
+	b, err := ReadChainBundle([]byte(`{"version":1,"uid":"295a7eea607af32040647123732bc819","username":"t_alice","merkle_path":{},"sigs":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Verify(tc.G); err == nil {
+		t.Fatal("expected a bundle without a user to fail")
+	} else if _, ok := err.(ChainBundleError); !ok {
+		t.Fatalf("unexpected error type %T: %s", err, err)
+	}
+}
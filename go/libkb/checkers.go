@@ -68,6 +68,11 @@ var CheckDeviceName = Checker{
 	Hint: "between 3 and 64 characters long; use a-Z, 0-9, space, plus, underscore, dash and apostrophe",
 }
 
+var CheckPaperKeyShare = Checker{
+	F:    IsPaperKeyShare,
+	Hint: "not a valid paper key share; check for typos",
+}
+
 var CheckKex2SecretPhrase = Checker{
 	F: func(s string) bool {
 		if err := validPhrase(s, Kex2PhraseEntropy); err != nil {
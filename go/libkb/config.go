@@ -475,6 +475,15 @@ func (f JSONConfigFile) GetLogFormat() string {
 func (f JSONConfigFile) GetStandalone() (bool, bool) {
 	return f.GetTopLevelBool("standalone")
 }
+func (f JSONConfigFile) GetSecretStoreKeyFile() string {
+	return f.GetTopLevelString("secret_store_key_file")
+}
+func (f JSONConfigFile) GetKex2DirectAddress() string {
+	return f.GetTopLevelString("kex2_direct_address")
+}
+func (f JSONConfigFile) GetPKCS11Module() string {
+	return f.GetTopLevelString("pkcs11_module")
+}
 
 func (f JSONConfigFile) getCacheSize(w string) (int, bool) {
 	return f.jw.AtPathGetInt(w)
@@ -513,6 +522,50 @@ func (f JSONConfigFile) GetLinkCacheCleanDur() (time.Duration, bool) {
 	return f.GetDurationAtPath("cache.clean_duration.links")
 }
 
+func (f JSONConfigFile) GetKeyExpiryWindow() (time.Duration, bool) {
+	return f.GetDurationAtPath("key_expiry.window")
+}
+
+func (f JSONConfigFile) GetLogMaxSize() (int, bool) {
+	return f.GetIntAtPath("log_rotate.max_size")
+}
+
+func (f JSONConfigFile) GetLogMaxAge() (time.Duration, bool) {
+	return f.GetDurationAtPath("log_rotate.max_age")
+}
+
+func (f JSONConfigFile) GetLogMaxKeepFiles() (int, bool) {
+	return f.GetIntAtPath("log_rotate.keep")
+}
+
+func (f JSONConfigFile) GetMetricsAddr() string {
+	res, _ := f.GetStringAtPath("metrics.addr")
+	return res
+}
+
+func (f JSONConfigFile) GetPassphrasePolicyMinLength() (int, bool) {
+	return f.GetIntAtPath("passphrase_policy.min_length")
+}
+
+func (f JSONConfigFile) GetPassphrasePolicyMinEntropyBits() (int, bool) {
+	return f.GetIntAtPath("passphrase_policy.min_entropy_bits")
+}
+
+func (f JSONConfigFile) GetPassphrasePolicyBannedWords() []string {
+	if f.jw == nil {
+		return nil
+	}
+	v, err := f.jw.AtPath("passphrase_policy.banned_words").ToArray()
+	if err != nil || v == nil {
+		return nil
+	}
+	return f.getStringArray(v)
+}
+
+func (f JSONConfigFile) GetPassphrasePolicyAllowUserInfo() (bool, bool) {
+	return f.GetBoolAtPath("passphrase_policy.allow_user_info")
+}
+
 func (f JSONConfigFile) getStringArray(v *jsonw.Wrapper) []string {
 	n, err := v.Len()
 	if err != nil {
@@ -559,6 +612,20 @@ func (f JSONConfigFile) GetCodeSigningKIDs() []string {
 	return f.getStringArray(v)
 }
 
+func (f JSONConfigFile) GetProofServices() (ret []GenericServiceConfig, err error) {
+	if f.jw == nil {
+		return nil, nil
+	}
+	v := f.jw.AtKey("proof_services")
+	if v.IsNil() {
+		return nil, nil
+	}
+	if err = v.UnmarshalAgain(&ret); err != nil {
+		err = ConfigError{f.filename, fmt.Sprintf("Can't read proof_services: %s", err)}
+	}
+	return ret, err
+}
+
 func (f JSONConfigFile) GetGpgHome() (ret string) {
 	ret, _ = f.GetStringAtPath("gpg.home")
 	return ret
@@ -626,6 +693,13 @@ func (f JSONConfigFile) GetUpdatePreferenceSkip() string {
 	return s
 }
 
+// GetUpdateInstallID gets the random ID that the updater uses to place
+// this install in staged rollouts.
+func (f JSONConfigFile) GetUpdateInstallID() string {
+	s, _ := f.GetStringAtPath("updates.install_id")
+	return s
+}
+
 func (f *JSONConfigFile) SetUpdatePreferenceAuto(b bool) error {
 	return f.SetBoolAtPath("updates.auto", b)
 }
@@ -642,6 +716,10 @@ func (f *JSONConfigFile) SetUpdateLastChecked(t keybase1.Time) error {
 	return f.SetTimeAtPath("updates.last_checked", t)
 }
 
+func (f *JSONConfigFile) SetUpdateInstallID(id string) error {
+	return f.SetStringAtPath("updates.install_id", id)
+}
+
 func (f JSONConfigFile) GetUpdateURL() string {
 	s, _ := f.GetStringAtPath("updates.url")
 	return s
@@ -8,6 +8,7 @@ import (
 	keybase1 "github.com/keybase/client/go/protocol"
 	rpc "github.com/keybase/go-framed-msgpack-rpc"
 	"sort"
+	"sync/atomic"
 )
 
 // ConnectionID is a sequential integer assigned to each RPC connection
@@ -43,6 +44,11 @@ type rpcConnection struct {
 // ConnectionManager manages all connections active for a given service.
 // It can be called from multiple goroutines.
 type ConnectionManager struct {
+	// count is len(lookup), kept by run so that Count works even
+	// after Shutdown. It's first so that it's 64-bit aligned for
+	// atomic operations.
+	count int64
+
 	nxt    ConnectionID
 	lookup map[ConnectionID](*rpcConnection)
 
@@ -102,6 +108,11 @@ func (c *ConnectionManager) ListAllLabeledConnections() []keybase1.ClientDetails
 	return <-retCh
 }
 
+// Count returns the number of connections, labeled or not.
+func (c *ConnectionManager) Count() int {
+	return int(atomic.LoadInt64(&c.count))
+}
+
 type byClientType []keybase1.ClientDetails
 
 func (a byClientType) Len() int           { return len(a) }
@@ -127,11 +138,13 @@ func (c *ConnectionManager) run() {
 			c.nxt++ // increment first, since 0 is reserved
 			nxt := c.nxt
 			c.lookup[nxt] = &rpcConnection{transporter: addConnectionObj.xp}
+			atomic.StoreInt64(&c.count, int64(len(c.lookup)))
 			addConnectionObj.ch <- nxt
 		case lookupConnectionObj := <-c.lookupConnectionCh:
 			lookupConnectionObj.ch <- c.lookupTransporter(lookupConnectionObj.id)
 		case id := <-c.removeConnectionCh:
 			delete(c.lookup, id)
+			atomic.StoreInt64(&c.count, int64(len(c.lookup)))
 		case labelConnectionObj := <-c.labelConnectionCh:
 			id := labelConnectionObj.id
 			var err error
@@ -80,6 +80,13 @@ const (
 
 	SigShortIDBytes  = 27
 	LocalTrackMaxAge = 48 * time.Hour
+
+	KeyExpiryCheckInterval = 24 * time.Hour
+	KeyExpiryWindow        = 30 * 24 * time.Hour
+
+	LogMaxSize      = 128 * 1024 * 1024 // 128mb
+	LogMaxAge       = 30 * 24 * time.Hour
+	LogMaxKeepFiles = 10
 )
 
 var MerkleProdKIDs = []string{
@@ -171,6 +178,7 @@ const (
 	SCKeyNoPGPEncryption     = int(keybase1.StatusCode_SCKeyNoPGPEncryption)
 	SCKeyNoNaClEncryption    = int(keybase1.StatusCode_SCKeyNoNaClEncryption)
 	SCWrongCryptoFormat      = int(keybase1.StatusCode_SCWrongCryptoFormat)
+	SCWeakPassphrase         = int(keybase1.StatusCode_SCWeakPassphrase)
 )
 
 const (
@@ -267,6 +275,7 @@ var RemoteServiceOrder = []keybase1.ProofType{
 	keybase1.ProofType_REDDIT,
 	keybase1.ProofType_COINBASE,
 	keybase1.ProofType_HACKERNEWS,
+	keybase1.ProofType_GENERIC_SOCIAL,
 	keybase1.ProofType_GENERIC_WEB_SITE,
 	keybase1.ProofType_ROOTER,
 }
@@ -365,6 +374,8 @@ const (
 	PaperKeyIDBits        = 22
 	PaperKeyVersionBits   = 4
 	PaperKeyVersion       = 0
+	PaperKeyShareVersion  = 1
+	PaperKeyMaxShares     = 16
 )
 
 const UserSummaryLimit = 500 // max number of user summaries in one request
@@ -104,6 +104,14 @@ func (j *JSONLocalDb) Lookup(id DbKey) (*jsonw.Wrapper, error) {
 
 func (j *JSONLocalDb) Delete(id DbKey) error { return j.engine.Delete(id) }
 
+func (j *JSONLocalDb) KeysWithType(typ ObjType) ([]DbKey, error) { return j.engine.KeysWithType(typ) }
+
+// GetRaw gets the JSON stored at id without parsing it.
+func (j *JSONLocalDb) GetRaw(id DbKey) ([]byte, bool, error) { return j.engine.Get(id) }
+
+// PutRaw stores JSON that's already encoded at id.
+func (j *JSONLocalDb) PutRaw(id DbKey, val []byte) error { return j.engine.Put(id, nil, val) }
+
 const (
 	DBUser                    = 0x00
 	DBSig                     = 0x0f
@@ -118,6 +126,9 @@ const (
 	DBSigChainTailEncrypted   = 0xe9
 	DBMerkleRoot              = 0xf0
 	DBTrackers                = 0xf1
+	DBMerkleAudit             = 0xf2
+	DBDeviceNote              = 0xf3
+	DBKeySeenSigning          = 0xf4
 )
 
 const (
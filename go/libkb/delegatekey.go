@@ -30,7 +30,8 @@ type Delegator struct {
 	EncodedPrivateKey string
 	Ctime             int64
 	DelegationType    DelegationType
-	Aggregated        bool // During aggregation we skip some steps (posting, updating some state)
+	Aggregated        bool           // During aggregation we skip some steps (posting, updating some state)
+	RevokeKIDs        []keybase1.KID // Keys to revoke in the same link, when rotating a device's keys
 
 	// Optional precalculated values used by KeyProof
 	LastSeqno   Seqno     // kex2 HandleDidCounterSign needs to sign subkey without a user but we know what the last seqno was
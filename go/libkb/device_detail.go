@@ -0,0 +1,179 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"sync"
+	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+func deviceNoteKey(id keybase1.DeviceID) DbKey {
+	return DbKey{Typ: DBDeviceNote, Key: id.String()}
+}
+
+// GetDeviceNote gets the local note for the device, or "" if it hasn't
+// got one.
+func GetDeviceNote(g *GlobalContext, id keybase1.DeviceID) (string, error) {
+	jw, err := g.LocalDb.Get(deviceNoteKey(id))
+	if err != nil || jw == nil {
+		return "", err
+	}
+	return jw.GetString()
+}
+
+// SetDeviceNote sets the local note for the device. An empty note
+// removes it.
+func SetDeviceNote(g *GlobalContext, id keybase1.DeviceID, note string) error {
+	if len(note) == 0 {
+		return g.LocalDb.Delete(deviceNoteKey(id))
+	}
+	return g.LocalDb.Put(deviceNoteKey(id), nil, jsonw.NewString(note))
+}
+
+func keySeenSigningKey(kid keybase1.KID) DbKey {
+	return DbKey{Typ: DBKeySeenSigning, Key: kid.String()}
+}
+
+// keySeenSigningMu keeps RecordKeySeenSigning's read and write together.
+var keySeenSigningMu sync.Mutex
+
+// GetKeySeenSigning gets the last time this service saw the key sign
+// something, or 0 if it never has.
+func GetKeySeenSigning(g *GlobalContext, kid keybase1.KID) (keybase1.Time, error) {
+	jw, err := g.LocalDb.Get(keySeenSigningKey(kid))
+	if err != nil || jw == nil {
+		return 0, err
+	}
+	var t int64
+	jw.GetInt64Void(&t, &err)
+	return keybase1.Time(t), err
+}
+
+// RecordKeySeenSigning records that this service saw the key sign
+// something at t: a sigchain link made then, or a saltpack message it
+// verified then. It keeps the time it has if that's later.
+func RecordKeySeenSigning(g *GlobalContext, kid keybase1.KID, t time.Time) error {
+	keySeenSigningMu.Lock()
+	defer keySeenSigningMu.Unlock()
+
+	last, err := GetKeySeenSigning(g, kid)
+	if err != nil {
+		return err
+	}
+	seen := keybase1.ToTime(t)
+	if seen <= last {
+		return nil
+	}
+	return g.LocalDb.Put(keySeenSigningKey(kid), nil, jsonw.NewInt64(int64(seen)))
+}
+
+// noteKeySeenSigning is RecordKeySeenSigning for callers that are busy
+// verifying something, and shouldn't fail because the time can't be
+// recorded.
+func noteKeySeenSigning(g *GlobalContext, kid keybase1.KID, t time.Time) {
+	if g.LocalDb == nil || kid.IsNil() {
+		return
+	}
+	if err := RecordKeySeenSigning(g, kid, t); err != nil {
+		g.Log.Warning("Error recording that %s signed at %s: %s", kid, t, err)
+	}
+}
+
+// DeviceDetails describes the user's active devices: when each was
+// added and by which device, from the key family and sigchain, and the
+// last time this service saw each sign something.
+func (u *User) DeviceDetails() ([]keybase1.DeviceDetail, error) {
+	ckf := u.GetComputedKeyFamily()
+	if ckf == nil {
+		return nil, NoKeyError{"no key family"}
+	}
+	sc := u.sigChain()
+
+	lastLink := make(map[keybase1.KID]keybase1.Time)
+	var eldestLink *ChainLink
+	eldest := u.GetEldestKID()
+	if sc != nil {
+		for _, link := range sc.chainLinks {
+			kid := link.GetKID()
+			if t := keybase1.ToTime(link.GetCTime()); t > lastLink[kid] {
+				lastLink[kid] = t
+			}
+		}
+		// The eldest key isn't delegated by any link; the first link of
+		// the current subchain is the one that added it.
+		if eldest.Exists() {
+			links, err := sc.GetCurrentSubchain(eldest)
+			if err != nil {
+				return nil, err
+			}
+			if len(links) > 0 {
+				eldestLink = links[0]
+			}
+		}
+	}
+
+	var ret []keybase1.DeviceDetail
+	for _, dev := range ckf.GetAllActiveDevices() {
+		detail := keybase1.DeviceDetail{Device: *dev.ProtExport()}
+		detail.Device.VerifyKey = dev.Kid
+		// Links verified before the service kept signing times are only
+		// in the sigchain.
+		detail.LastSeenSigningTime = lastLink[dev.Kid]
+		seen, err := GetKeySeenSigning(u.G(), dev.Kid)
+		if err != nil {
+			return nil, err
+		}
+		if seen > detail.LastSeenSigningTime {
+			detail.LastSeenSigningTime = seen
+		}
+
+		if eldestLink != nil && dev.Kid.Equal(eldest) {
+			detail.AddedTime = keybase1.ToTime(eldestLink.GetCTime())
+			detail.AddedSeqno = int(eldestLink.GetSeqno())
+		} else if info, ok := ckf.cki.Infos[dev.Kid]; ok {
+			if info.DelegatedAt != nil {
+				detail.AddedTime = keybase1.TimeFromSeconds(info.DelegatedAt.Unix)
+			} else {
+				detail.AddedTime = keybase1.TimeFromSeconds(info.CTime)
+			}
+
+			// A key can be delegated more than once; the first link is
+			// the one that added it.
+			var signer keybase1.KID
+			for sigID, kid := range info.Delegations {
+				var link *ChainLink
+				if sc != nil {
+					link = sc.GetLinkFromSigID(sigID)
+				}
+				if link == nil {
+					continue
+				}
+				if seqno := int(link.GetSeqno()); detail.AddedSeqno == 0 || seqno < detail.AddedSeqno {
+					detail.AddedSeqno = seqno
+					signer = kid
+				}
+			}
+			if signer.Exists() && !signer.Equal(dev.Kid) {
+				if id, ok := ckf.cki.KIDToDeviceID[signer]; ok {
+					detail.Provisioner = id
+					if pdev, ok := ckf.cki.Devices[id]; ok && pdev.Description != nil {
+						detail.ProvisionerName = *pdev.Description
+					}
+				}
+			}
+		}
+
+		note, err := GetDeviceNote(u.G(), dev.ID)
+		if err != nil {
+			return nil, err
+		}
+		detail.Note = note
+
+		ret = append(ret, detail)
+	}
+	return ret, nil
+}
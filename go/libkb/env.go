@@ -13,64 +13,82 @@ import (
 	"sync"
 	"time"
 
+	"github.com/keybase/client/go/logger"
 	keybase1 "github.com/keybase/client/go/protocol"
 )
 
 type NullConfiguration struct{}
 
-func (n NullConfiguration) GetHome() string                               { return "" }
-func (n NullConfiguration) GetServerURI() string                          { return "" }
-func (n NullConfiguration) GetConfigFilename() string                     { return "" }
-func (n NullConfiguration) GetSessionFilename() string                    { return "" }
-func (n NullConfiguration) GetDbFilename() string                         { return "" }
-func (n NullConfiguration) GetUsername() NormalizedUsername               { return NormalizedUsername("") }
-func (n NullConfiguration) GetEmail() string                              { return "" }
-func (n NullConfiguration) GetProxy() string                              { return "" }
-func (n NullConfiguration) GetGpgHome() string                            { return "" }
-func (n NullConfiguration) GetBundledCA(h string) string                  { return "" }
-func (n NullConfiguration) GetUserCacheMaxAge() (time.Duration, bool)     { return 0, false }
-func (n NullConfiguration) GetProofCacheSize() (int, bool)                { return 0, false }
-func (n NullConfiguration) GetProofCacheLongDur() (time.Duration, bool)   { return 0, false }
-func (n NullConfiguration) GetProofCacheMediumDur() (time.Duration, bool) { return 0, false }
-func (n NullConfiguration) GetProofCacheShortDur() (time.Duration, bool)  { return 0, false }
-func (n NullConfiguration) GetLinkCacheSize() (int, bool)                 { return 0, false }
-func (n NullConfiguration) GetLinkCacheCleanDur() (time.Duration, bool)   { return 0, false }
-func (n NullConfiguration) GetMerkleKIDs() []string                       { return nil }
-func (n NullConfiguration) GetCodeSigningKIDs() []string                  { return nil }
-func (n NullConfiguration) GetPinentry() string                           { return "" }
-func (n NullConfiguration) GetUID() (ret keybase1.UID)                    { return }
-func (n NullConfiguration) GetGpg() string                                { return "" }
-func (n NullConfiguration) GetGpgOptions() []string                       { return nil }
-func (n NullConfiguration) GetPGPFingerprint() *PGPFingerprint            { return nil }
-func (n NullConfiguration) GetSecretKeyringTemplate() string              { return "" }
-func (n NullConfiguration) GetSalt() []byte                               { return nil }
-func (n NullConfiguration) GetSocketFile() string                         { return "" }
-func (n NullConfiguration) GetPidFile() string                            { return "" }
-func (n NullConfiguration) GetStandalone() (bool, bool)                   { return false, false }
-func (n NullConfiguration) GetLocalRPCDebug() string                      { return "" }
-func (n NullConfiguration) GetTimers() string                             { return "" }
-func (n NullConfiguration) GetDeviceID() keybase1.DeviceID                { return "" }
-func (n NullConfiguration) GetProxyCACerts() ([]string, error)            { return nil, nil }
-func (n NullConfiguration) GetAutoFork() (bool, bool)                     { return false, false }
-func (n NullConfiguration) GetRunMode() (RunMode, error)                  { return NoRunMode, nil }
-func (n NullConfiguration) GetNoAutoFork() (bool, bool)                   { return false, false }
-func (n NullConfiguration) GetLogFile() string                            { return "" }
-func (n NullConfiguration) GetScraperTimeout() (time.Duration, bool)      { return 0, false }
-func (n NullConfiguration) GetAPITimeout() (time.Duration, bool)          { return 0, false }
-func (n NullConfiguration) GetTorMode() (TorMode, error)                  { return TorNone, nil }
-func (n NullConfiguration) GetTorHiddenAddress() string                   { return "" }
-func (n NullConfiguration) GetTorProxy() string                           { return "" }
-func (n NullConfiguration) GetUpdatePreferenceAuto() (bool, bool)         { return false, false }
-func (n NullConfiguration) GetUpdatePreferenceSnoozeUntil() keybase1.Time { return keybase1.Time(0) }
-func (n NullConfiguration) GetUpdateLastChecked() keybase1.Time           { return keybase1.Time(0) }
-func (n NullConfiguration) GetUpdatePreferenceSkip() string               { return "" }
-func (n NullConfiguration) GetUpdateURL() string                          { return "" }
-func (n NullConfiguration) GetVDebugSetting() string                      { return "" }
-func (n NullConfiguration) GetLocalTrackMaxAge() (time.Duration, bool)    { return 0, false }
-func (n NullConfiguration) GetAppStartMode() AppStartMode                 { return AppStartModeDisabled }
-func (n NullConfiguration) IsAdmin() (bool, bool)                         { return false, false }
+func (n NullConfiguration) GetHome() string                                { return "" }
+func (n NullConfiguration) GetServerURI() string                           { return "" }
+func (n NullConfiguration) GetConfigFilename() string                      { return "" }
+func (n NullConfiguration) GetSessionFilename() string                     { return "" }
+func (n NullConfiguration) GetDbFilename() string                          { return "" }
+func (n NullConfiguration) GetUsername() NormalizedUsername                { return NormalizedUsername("") }
+func (n NullConfiguration) GetEmail() string                               { return "" }
+func (n NullConfiguration) GetProxy() string                               { return "" }
+func (n NullConfiguration) GetGpgHome() string                             { return "" }
+func (n NullConfiguration) GetSecretStoreKeyFile() string                  { return "" }
+func (n NullConfiguration) GetKex2DirectAddress() string                   { return "" }
+func (n NullConfiguration) GetPKCS11Module() string                        { return "" }
+func (n NullConfiguration) GetBundledCA(h string) string                   { return "" }
+func (n NullConfiguration) GetUserCacheMaxAge() (time.Duration, bool)      { return 0, false }
+func (n NullConfiguration) GetProofCacheSize() (int, bool)                 { return 0, false }
+func (n NullConfiguration) GetProofCacheLongDur() (time.Duration, bool)    { return 0, false }
+func (n NullConfiguration) GetProofCacheMediumDur() (time.Duration, bool)  { return 0, false }
+func (n NullConfiguration) GetProofCacheShortDur() (time.Duration, bool)   { return 0, false }
+func (n NullConfiguration) GetLinkCacheSize() (int, bool)                  { return 0, false }
+func (n NullConfiguration) GetLinkCacheCleanDur() (time.Duration, bool)    { return 0, false }
+func (n NullConfiguration) GetKeyExpiryWindow() (time.Duration, bool)      { return 0, false }
+func (n NullConfiguration) GetPassphrasePolicyMinLength() (int, bool)      { return 0, false }
+func (n NullConfiguration) GetPassphrasePolicyMinEntropyBits() (int, bool) { return 0, false }
+func (n NullConfiguration) GetPassphrasePolicyBannedWords() []string       { return nil }
+func (n NullConfiguration) GetPassphrasePolicyAllowUserInfo() (bool, bool) { return false, false }
+func (n NullConfiguration) GetLogMaxSize() (int, bool)                     { return 0, false }
+func (n NullConfiguration) GetLogMaxAge() (time.Duration, bool)            { return 0, false }
+func (n NullConfiguration) GetLogMaxKeepFiles() (int, bool)                { return 0, false }
+func (n NullConfiguration) GetMetricsAddr() string                         { return "" }
+func (n NullConfiguration) GetMerkleKIDs() []string                        { return nil }
+func (n NullConfiguration) GetCodeSigningKIDs() []string                   { return nil }
+func (n NullConfiguration) GetPinentry() string                            { return "" }
+func (n NullConfiguration) GetActingUser() string                          { return "" }
+func (n NullConfiguration) GetUID() (ret keybase1.UID)                     { return }
+func (n NullConfiguration) GetGpg() string                                 { return "" }
+func (n NullConfiguration) GetGpgOptions() []string                        { return nil }
+func (n NullConfiguration) GetPGPFingerprint() *PGPFingerprint             { return nil }
+func (n NullConfiguration) GetSecretKeyringTemplate() string               { return "" }
+func (n NullConfiguration) GetSalt() []byte                                { return nil }
+func (n NullConfiguration) GetSocketFile() string                          { return "" }
+func (n NullConfiguration) GetPidFile() string                             { return "" }
+func (n NullConfiguration) GetStandalone() (bool, bool)                    { return false, false }
+func (n NullConfiguration) GetLocalRPCDebug() string                       { return "" }
+func (n NullConfiguration) GetTimers() string                              { return "" }
+func (n NullConfiguration) GetDeviceID() keybase1.DeviceID                 { return "" }
+func (n NullConfiguration) GetProxyCACerts() ([]string, error)             { return nil, nil }
+func (n NullConfiguration) GetAutoFork() (bool, bool)                      { return false, false }
+func (n NullConfiguration) GetRunMode() (RunMode, error)                   { return NoRunMode, nil }
+func (n NullConfiguration) GetNoAutoFork() (bool, bool)                    { return false, false }
+func (n NullConfiguration) GetLogFile() string                             { return "" }
+func (n NullConfiguration) GetScraperTimeout() (time.Duration, bool)       { return 0, false }
+func (n NullConfiguration) GetAPITimeout() (time.Duration, bool)           { return 0, false }
+func (n NullConfiguration) GetTorMode() (TorMode, error)                   { return TorNone, nil }
+func (n NullConfiguration) GetTorHiddenAddress() string                    { return "" }
+func (n NullConfiguration) GetTorProxy() string                            { return "" }
+func (n NullConfiguration) GetUpdatePreferenceAuto() (bool, bool)          { return false, false }
+func (n NullConfiguration) GetUpdatePreferenceSnoozeUntil() keybase1.Time  { return keybase1.Time(0) }
+func (n NullConfiguration) GetUpdateLastChecked() keybase1.Time            { return keybase1.Time(0) }
+func (n NullConfiguration) GetUpdatePreferenceSkip() string                { return "" }
+func (n NullConfiguration) GetUpdateInstallID() string                     { return "" }
+func (n NullConfiguration) GetUpdateURL() string                           { return "" }
+func (n NullConfiguration) GetVDebugSetting() string                       { return "" }
+func (n NullConfiguration) GetLocalTrackMaxAge() (time.Duration, bool)     { return 0, false }
+func (n NullConfiguration) GetAppStartMode() AppStartMode                  { return AppStartModeDisabled }
+func (n NullConfiguration) IsAdmin() (bool, bool)                          { return false, false }
 
 func (n NullConfiguration) GetUserConfig() (*UserConfig, error) { return nil, nil }
+func (n NullConfiguration) GetProofServices() ([]GenericServiceConfig, error) {
+	return nil, nil
+}
 func (n NullConfiguration) GetUserConfigForUsername(s NormalizedUsername) (*UserConfig, error) {
 	return nil, nil
 }
@@ -131,6 +149,10 @@ type TestParameters struct {
 	// suffix.
 	DevelName  string
 	RuntimeDir string
+	// If set, talk to this server (like a FakeAPIServer) rather than the
+	// one for the run mode, and trust its Merkle keys.
+	ServerURI  string
+	MerkleKIDs []string
 }
 
 func (tp TestParameters) GetDebug() (bool, bool) {
@@ -147,6 +169,13 @@ type Env struct {
 	homeFinder HomeFinder
 	writer     ConfigWriter
 	Test       TestParameters
+
+	// An Env made by forActingUser reads the config as actingUser, and
+	// takes new configs from parent. parent keeps those Envs in
+	// actingEnvs, to pass its new configs on to them.
+	parent     *Env
+	actingUser *UserConfig
+	actingEnvs []*Env
 }
 
 func (e *Env) GetConfig() ConfigReader {
@@ -174,10 +203,18 @@ func (e *Env) GetCommandLine() CommandLine {
 }
 
 func (e *Env) SetConfig(r ConfigReader, w ConfigWriter) {
+	if e.parent != nil {
+		e.parent.SetConfig(r, w)
+		return
+	}
 	e.Lock()
-	defer e.Unlock()
 	e.config = r
 	e.writer = w
+	actingEnvs := e.actingEnvs
+	e.Unlock()
+	for _, ae := range actingEnvs {
+		ae.setActingConfig(r, w)
+	}
 }
 
 func NewEnv(cmd CommandLine, config ConfigReader) *Env {
@@ -330,6 +367,7 @@ func (e *Env) GetDuration(def time.Duration, flist ...func() (time.Duration, boo
 
 func (e *Env) GetServerURI() string {
 	return e.GetString(
+		func() string { return e.Test.ServerURI },
 		func() string { return e.cmd.GetServerURI() },
 		func() string { return os.Getenv("KEYBASE_SERVER_URI") },
 		func() string { return e.config.GetServerURI() },
@@ -348,12 +386,18 @@ func (e *Env) GetConfigFilename() string {
 }
 
 func (e *Env) GetSessionFilename() string {
-	return e.GetString(
+	ret := e.GetString(
 		func() string { return e.cmd.GetSessionFilename() },
 		func() string { return os.Getenv("KEYBASE_SESSION_FILE") },
 		func() string { return e.config.GetSessionFilename() },
 		func() string { return filepath.Join(e.GetCacheDir(), SessionFile) },
 	)
+	if e.actingUser != nil {
+		// Each acting user keeps their session in a file of their own.
+		ext := filepath.Ext(ret)
+		ret = strings.TrimSuffix(ret, ext) + "." + e.actingUser.GetUsername().String() + ext
+	}
+	return ret
 }
 
 func (e *Env) GetDbFilename() string {
@@ -437,6 +481,15 @@ func (e *Env) GetUsername() NormalizedUsername {
 	return e.config.GetUsername()
 }
 
+// GetActingUser is the user that the client asks the service to act as,
+// if it's not the current user.
+func (e *Env) GetActingUser() NormalizedUsername {
+	return NewNormalizedUsername(e.GetString(
+		func() string { return e.cmd.GetActingUser() },
+		func() string { return os.Getenv("KEYBASE_USER") },
+	))
+}
+
 func (e *Env) GetSocketFile() (ret string, err error) {
 	ret = e.GetString(
 		func() string { return e.cmd.GetSocketFile() },
@@ -486,6 +539,35 @@ func (e *Env) GetGpgHome() string {
 	)
 }
 
+// GetSecretStoreKeyFile is the key file for the encrypted file secret
+// store, which is used on Linux when there's no Secret Service.
+func (e *Env) GetSecretStoreKeyFile() string {
+	return e.GetString(
+		func() string { return os.Getenv("KEYBASE_SECRET_STORE_KEY_FILE") },
+		func() string { return e.config.GetSecretStoreKeyFile() },
+	)
+}
+
+// GetKex2DirectAddress is where a new device listens for its provisioner
+// to connect directly, instead of going through the server. It's either a
+// TCP address or "unix:" and a socket path. If it's empty, kex2 goes
+// through the server.
+func (e *Env) GetKex2DirectAddress() string {
+	return e.GetString(
+		func() string { return os.Getenv("KEYBASE_KEX2_DIRECT_ADDRESS") },
+		func() string { return e.config.GetKex2DirectAddress() },
+	)
+}
+
+// GetPKCS11Module is the path to the PKCS#11 library for the token that
+// device keys are generated on, when that's asked for.
+func (e *Env) GetPKCS11Module() string {
+	return e.GetString(
+		func() string { return os.Getenv("KEYBASE_PKCS11_MODULE") },
+		func() string { return e.config.GetPKCS11Module() },
+	)
+}
+
 func (e *Env) GetPinentry() string {
 	return e.GetString(
 		func() string { return e.cmd.GetPinentry() },
@@ -599,6 +681,57 @@ func (e *Env) GetLinkCacheCleanDur() time.Duration {
 	)
 }
 
+// GetKeyExpiryWindow is how long before a key expires the service starts
+// warning about it.
+func (e *Env) GetKeyExpiryWindow() time.Duration {
+	return e.GetDuration(KeyExpiryWindow,
+		func() (time.Duration, bool) { return e.getEnvDuration("KEYBASE_KEY_EXPIRY_WINDOW") },
+		e.config.GetKeyExpiryWindow,
+	)
+}
+
+// GetLogFileConfig is how the log file at filename is rotated: when it gets
+// too big or too old, and how many of the gzipped old ones are kept.
+func (e *Env) GetLogFileConfig(filename string) *logger.LogFileConfig {
+	return &logger.LogFileConfig{
+		Path: filename,
+		MaxSize: int64(e.GetInt(LogMaxSize,
+			func() (int, bool) { return e.getEnvInt("KEYBASE_LOG_MAX_SIZE") },
+			e.config.GetLogMaxSize,
+		)),
+		MaxAge: e.GetDuration(LogMaxAge,
+			func() (time.Duration, bool) { return e.getEnvDuration("KEYBASE_LOG_MAX_AGE") },
+			e.config.GetLogMaxAge,
+		),
+		MaxKeepFiles: e.GetInt(LogMaxKeepFiles,
+			func() (int, bool) { return e.getEnvInt("KEYBASE_LOG_MAX_KEEP_FILES") },
+			e.config.GetLogMaxKeepFiles,
+		),
+	}
+}
+
+// GetPassphrasePolicy is the policy that new account passphrases have to
+// satisfy, from the passphrase_policy section of the config file. The
+// minimum length can be raised, but not lowered below MinPassphraseLength.
+// With no policy configured, only the minimum length is checked, as it
+// always was.
+func (e *Env) GetPassphrasePolicy() keybase1.PassphrasePolicy {
+	minLength := e.GetInt(MinPassphraseLength, e.config.GetPassphrasePolicyMinLength)
+	if minLength < MinPassphraseLength {
+		minLength = MinPassphraseLength
+	}
+	_, minLengthSet := e.config.GetPassphrasePolicyMinLength()
+	minEntropyBits, minEntropyBitsSet := e.config.GetPassphrasePolicyMinEntropyBits()
+	bannedWords := e.config.GetPassphrasePolicyBannedWords()
+	configured := minLengthSet || minEntropyBitsSet || len(bannedWords) > 0
+	return keybase1.PassphrasePolicy{
+		MinLength:      minLength,
+		MinEntropyBits: minEntropyBits,
+		BannedWords:    bannedWords,
+		AllowUserInfo:  e.GetBool(!configured, e.config.GetPassphrasePolicyAllowUserInfo),
+	}
+}
+
 func (e *Env) GetEmailOrUsername() string {
 	un := e.GetUsername().String()
 	if len(un) > 0 {
@@ -643,6 +776,7 @@ func (e *Env) GetStringList(list ...(func() []string)) []string {
 
 func (e *Env) GetMerkleKIDs() []keybase1.KID {
 	slist := e.GetStringList(
+		func() []string { return e.Test.MerkleKIDs },
 		func() []string { return e.cmd.GetMerkleKIDs() },
 		func() []string { return e.getEnvPath("KEYBASE_MERKLE_KIDS") },
 		func() []string { return e.config.GetMerkleKIDs() },
@@ -667,6 +801,12 @@ func (e *Env) GetMerkleKIDs() []keybase1.KID {
 	return ret
 }
 
+// GetProofServices returns the declarative proof services from the
+// config file; they can't be set on the command line or environment.
+func (e *Env) GetProofServices() ([]GenericServiceConfig, error) {
+	return e.config.GetProofServices()
+}
+
 func (e *Env) GetCodeSigningKIDs() []keybase1.KID {
 	slist := e.GetStringList(
 		func() []string { return e.cmd.GetCodeSigningKIDs() },
@@ -731,6 +871,15 @@ func (e *Env) GetLocalRPCDebug() string {
 	)
 }
 
+// GetMetricsAddr is the local address that the service serves metrics on,
+// or "" to not serve them.
+func (e *Env) GetMetricsAddr() string {
+	return e.GetString(
+		func() string { return os.Getenv("KEYBASE_METRICS_ADDR") },
+		func() string { return e.config.GetMetricsAddr() },
+	)
+}
+
 func (e *Env) GetDoLogForward() bool {
 	return e.GetLocalRPCDebug() == ""
 }
@@ -874,6 +1023,10 @@ func (e *Env) GetUpdateLastChecked() keybase1.Time {
 	return e.config.GetUpdateLastChecked()
 }
 
+func (e *Env) GetUpdateInstallID() string {
+	return e.config.GetUpdateInstallID()
+}
+
 func (e *Env) SetUpdatePreferenceAuto(b bool) error {
 	return e.GetConfigWriter().SetUpdatePreferenceAuto(b)
 }
@@ -890,6 +1043,10 @@ func (e *Env) SetUpdateLastChecked(t keybase1.Time) error {
 	return e.GetConfigWriter().SetUpdateLastChecked(t)
 }
 
+func (e *Env) SetUpdateInstallID(id string) error {
+	return e.GetConfigWriter().SetUpdateInstallID(id)
+}
+
 func (e *Env) GetUpdateURL() string {
 	return e.config.GetUpdateURL()
 }
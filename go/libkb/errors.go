@@ -182,6 +182,17 @@ func (e UnexpectedKeyError) Error() string {
 
 //=============================================================================
 
+// ActingUserError is for something that can't be done while acting as
+// a user other than the current one.
+type ActingUserError struct {
+	Username NormalizedUsername
+	Msg      string
+}
+
+func (e ActingUserError) Error() string {
+	return fmt.Sprintf("Acting as %s: %s", e.Username, e.Msg)
+}
+
 type UserNotFoundError struct {
 	UID keybase1.UID
 	Msg string
@@ -340,6 +351,48 @@ func (p PassphraseError) Error() string {
 	return msg
 }
 
+// ShortPassphraseError is for a new passphrase that's shorter than the
+// configured passphrase policy allows.
+type ShortPassphraseError struct {
+	Length   int
+	Required int
+}
+
+func (e ShortPassphraseError) Error() string {
+	return fmt.Sprintf("Passphrase is too short (%d characters, %d required)", e.Length, e.Required)
+}
+
+// WeakPassphraseError is for a new passphrase that's too easy to guess
+// under the configured passphrase policy.
+type WeakPassphraseError struct {
+	Bits     int
+	Required int
+}
+
+func (e WeakPassphraseError) Error() string {
+	return fmt.Sprintf("Passphrase is too easy to guess (about %d bits of entropy, %d required); try a longer one, with more kinds of characters", e.Bits, e.Required)
+}
+
+// BannedPassphraseWordError is for a new passphrase that contains a word
+// the passphrase policy bans.
+type BannedPassphraseWordError struct {
+	Word string
+}
+
+func (e BannedPassphraseWordError) Error() string {
+	return fmt.Sprintf("Passphrase can't contain %q", e.Word)
+}
+
+// PassphraseHasUserInfoError is for a new passphrase that contains the
+// account's username or email address.
+type PassphraseHasUserInfoError struct {
+	What string
+}
+
+func (e PassphraseHasUserInfoError) Error() string {
+	return fmt.Sprintf("Passphrase can't contain your %s", e.What)
+}
+
 //=============================================================================
 
 type BadKeyError struct {
@@ -1202,6 +1255,63 @@ func (k KeyVersionError) Error() string {
 
 //=============================================================================
 
+type PaperKeyShareError struct {
+	Msg string
+}
+
+func (e PaperKeyShareError) Error() string {
+	return fmt.Sprintf("paper key share error: %s", e.Msg)
+}
+
+//=============================================================================
+
+type PKCS11Error struct {
+	Msg string
+}
+
+func (e PKCS11Error) Error() string {
+	return fmt.Sprintf("PKCS#11 error: %s", e.Msg)
+}
+
+type PKCS11BadPINError struct {
+	Token string
+}
+
+func (e PKCS11BadPINError) Error() string {
+	return fmt.Sprintf("incorrect PIN for PKCS#11 token %q", e.Token)
+}
+
+// PKCS11TokenLoggedOutError means a key is on a token that needs its PIN
+// before the key can be used.
+type PKCS11TokenLoggedOutError struct {
+	Module string
+	Token  string
+}
+
+func (e PKCS11TokenLoggedOutError) Error() string {
+	return fmt.Sprintf("PKCS#11 token %q needs its PIN", e.Token)
+}
+
+//=============================================================================
+
+type BadAccountArchiveError struct {
+	Msg string
+}
+
+func (e BadAccountArchiveError) Error() string {
+	return fmt.Sprintf("Bad account archive: %s", e.Msg)
+}
+
+type AccountArchiveExistsError struct {
+	Username NormalizedUsername
+}
+
+func (e AccountArchiveExistsError) Error() string {
+	return fmt.Sprintf("%s already has a device or secret keys on this machine (use --force to replace them)", e.Username)
+}
+
+//=============================================================================
+
 type PIDFileLockError struct {
 	Filename string
 }
@@ -1373,3 +1483,43 @@ type DeviceAlreadyProvisionedError struct{}
 func (e DeviceAlreadyProvisionedError) Error() string {
 	return "Device already provisioned for current user"
 }
+
+//=============================================================================
+
+type RecipientGroupNotFoundError struct {
+	Name string
+}
+
+func (e RecipientGroupNotFoundError) Error() string {
+	return fmt.Sprintf("No recipient group %q in the config for this user", e.Name)
+}
+
+//=============================================================================
+
+type BadIdentifyReportError struct {
+	Msg string
+}
+
+func (e BadIdentifyReportError) Error() string {
+	return fmt.Sprintf("Bad identify report: %s", e.Msg)
+}
+
+//=============================================================================
+
+type BadManifestError struct {
+	Msg string
+}
+
+func (e BadManifestError) Error() string {
+	return fmt.Sprintf("Bad manifest: %s", e.Msg)
+}
+
+//=============================================================================
+
+type ManifestMismatchError struct {
+	Added, Removed, Modified int
+}
+
+func (e ManifestMismatchError) Error() string {
+	return fmt.Sprintf("Directory doesn't match the signed manifest: %d added, %d removed, %d modified", e.Added, e.Removed, e.Modified)
+}
@@ -0,0 +1,1193 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+// +build !production
+
+package libkb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+// FakeAPIServer is an in-process stand-in for the Keybase API server, so
+// that tests can sign up, log in, provision devices, track and prove
+// without a network. It keeps users, sessions, sigchains, keys and devices in memory,
+// and serves them back through the same endpoints that InternalAPIEngine
+// talks to. Every change to a sigchain makes a new Merkle root, which it
+// signs with its own NaCl key.
+//
+// It checks the signature on every link it's given, and that the link
+// extends the tail of the signer's sigchain, but otherwise it trusts the
+// client. It knows nothing about the users on the real test server (like
+// t_alice), and endpoints that it doesn't implement fail with a
+// NOT_IMPLEMENTED status.
+//
+// Tests get one through SetupTestWithFakeAPI, or by setting
+// KEYBASE_TEST_FAKE_API for the whole run.
+type FakeAPIServer struct {
+	sync.Mutex
+	srv  *httptest.Server
+	refs int
+
+	merkleKey   NaclSigningKeyPair
+	merkleSeqno Seqno
+	merkleRoot  map[string]interface{}
+	merkleNode  string
+
+	users         map[keybase1.UID]*fakeAPIUser
+	byEmail       map[string]*fakeAPIUser
+	byKID         map[keybase1.KID]*fakeAPIUser
+	sessions      map[string]fakeAPISession
+	loginSessions map[string]keybase1.UID
+	proofs        map[string]*fakeAPIProof
+	toots         map[string]fakeAPIToot
+	kex           map[string][]fakeAPIKexMsg
+	kexCond       *sync.Cond
+}
+
+type fakeAPIUser struct {
+	uid          keybase1.UID
+	username     string
+	email        string
+	salt         []byte
+	pwh          []byte
+	ppGen        int
+	idVersion    int64
+	lastIDChange int64
+	eldest       keybase1.KID
+	links        []fakeAPILink
+	bundles      []string
+	devices      DeviceKeyMap
+	privateKeys  ServerPrivateKeyMap
+	secretsVers  int
+	recovery     map[keybase1.KID]fakeAPIRecovery
+	hintsVers    int
+	following    map[keybase1.UID]bool
+}
+
+// fakeAPILink is a sigchain link, in the shape sig/get returns it.
+type fakeAPILink struct {
+	Seqno         Seqno          `json:"seqno"`
+	PayloadHash   string         `json:"payload_hash"`
+	SigID         keybase1.SigID `json:"sig_id"`
+	SigIDShort    string         `json:"sig_id_short"`
+	KID           keybase1.KID   `json:"kid"`
+	Sig           string         `json:"sig"`
+	PayloadJSON   string         `json:"payload_json"`
+	CTime         int64          `json:"ctime"`
+	ProofTextFull string         `json:"proof_text_full,omitempty"`
+}
+
+type fakeAPISession struct {
+	uid  keybase1.UID
+	csrf string
+}
+
+type fakeAPIRecovery struct {
+	ctext string
+	ppGen int
+}
+
+type fakeAPIProof struct {
+	uid            keybase1.UID
+	sigID          keybase1.SigID
+	service        string
+	remoteUsername string
+	postID         string
+}
+
+// fakeAPIToot is a post on the fake "rooter" social network, which the
+// rooter proof checker reads back through the same server.
+type fakeAPIToot struct {
+	username string
+	post     string
+}
+
+type fakeAPIKexMsg struct {
+	sender string
+	seqno  int
+	msg    string
+}
+
+// fakeAPIError is an app-level error, which goes back to the client in
+// the reply's status field.
+type fakeAPIError struct {
+	code int
+	name string
+	desc string
+}
+
+func (e fakeAPIError) Error() string {
+	return fmt.Sprintf("%s (%d): %s", e.name, e.code, e.desc)
+}
+
+func newFakeAPIError(code int, name string, format string, args ...interface{}) fakeAPIError {
+	return fakeAPIError{code: code, name: name, desc: fmt.Sprintf(format, args...)}
+}
+
+type fakeAPIBody map[string]interface{}
+
+type fakeAPIRequest struct {
+	*http.Request
+	payload map[string]json.RawMessage
+}
+
+// arg returns the named argument, whether it came in the query string, a
+// form, or as a string in a JSON payload.
+func (r *fakeAPIRequest) arg(k string) string {
+	if r.payload != nil {
+		var s string
+		if err := json.Unmarshal(r.payload[k], &s); err == nil {
+			return s
+		}
+		return ""
+	}
+	return r.FormValue(k)
+}
+
+func (r *fakeAPIRequest) args() map[string]string {
+	ret := make(map[string]string)
+	for k, v := range r.Form {
+		if len(v) > 0 {
+			ret[k] = v[0]
+		}
+	}
+	return ret
+}
+
+type fakeAPIHandler func(f *FakeAPIServer, r *fakeAPIRequest) (fakeAPIBody, error)
+
+var fakeAPIHandlers = map[string]fakeAPIHandler{
+	"getsalt":            (*FakeAPIServer).getSalt,
+	"login":              (*FakeAPIServer).login,
+	"signup":             (*FakeAPIServer).signup,
+	"sesscheck":          (*FakeAPIServer).sessCheck,
+	"logout":             (*FakeAPIServer).logout,
+	"new_session":        (*FakeAPIServer).newSessionForMe,
+	"sig/post_auth":      (*FakeAPIServer).sigPostAuth,
+	"key/add":            (*FakeAPIServer).keyAdd,
+	"key/multi":          (*FakeAPIServer).keyMulti,
+	"key/fetch_private":  (*FakeAPIServer).keyFetchPrivate,
+	"key/owner":          (*FakeAPIServer).keyOwner,
+	"key/basics":         (*FakeAPIServer).keyBasics,
+	"device/update":      (*FakeAPIServer).deviceUpdate,
+	"passphrase/recover": (*FakeAPIServer).passphraseRecover,
+	"sig/post":           (*FakeAPIServer).sigPost,
+	"sig/posted":         (*FakeAPIServer).sigPosted,
+	"sig/get":            (*FakeAPIServer).sigGet,
+	"sig/hints":          (*FakeAPIServer).sigHints,
+	"sig/revoke":         (*FakeAPIServer).sigRevoke,
+	"follow":             (*FakeAPIServer).follow,
+	"merkle/path":        (*FakeAPIServer).merklePath,
+	"user/lookup":        (*FakeAPIServer).userLookup,
+	"user/card":          (*FakeAPIServer).userCard,
+	"invitation/check":   (*FakeAPIServer).invitationCheck,
+	"rooter":             (*FakeAPIServer).rooterPost,
+	"rooter/delete":      (*FakeAPIServer).rooterDelete,
+	"kex2/send":          (*FakeAPIServer).kexSend,
+	"kex2/receive":       (*FakeAPIServer).kexReceive,
+}
+
+// NewFakeAPIServer starts a FakeAPIServer listening on a local port.
+// Close it when done.
+func NewFakeAPIServer() (*FakeAPIServer, error) {
+	key, err := GenerateNaclSigningKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	f := &FakeAPIServer{
+		refs:          1,
+		merkleKey:     key,
+		users:         make(map[keybase1.UID]*fakeAPIUser),
+		byEmail:       make(map[string]*fakeAPIUser),
+		byKID:         make(map[keybase1.KID]*fakeAPIUser),
+		sessions:      make(map[string]fakeAPISession),
+		loginSessions: make(map[string]keybase1.UID),
+		proofs:        make(map[string]*fakeAPIProof),
+		toots:         make(map[string]fakeAPIToot),
+		kex:           make(map[string][]fakeAPIKexMsg),
+	}
+	f.kexCond = sync.NewCond(&f.Mutex)
+	if err = f.updateMerkle(); err != nil {
+		return nil, err
+	}
+	f.srv = httptest.NewServer(f)
+	return f, nil
+}
+
+// URL is the server URI to point the client at.
+func (f *FakeAPIServer) URL() string {
+	return f.srv.URL
+}
+
+// MerkleKID is the KID of the key that signs this server's Merkle roots.
+func (f *FakeAPIServer) MerkleKID() keybase1.KID {
+	return f.merkleKey.GetKID()
+}
+
+// Close shuts the server down.
+func (f *FakeAPIServer) Close() {
+	f.srv.Close()
+}
+
+func (f *FakeAPIServer) retain() *FakeAPIServer {
+	f.Lock()
+	defer f.Unlock()
+	f.refs++
+	return f
+}
+
+func (f *FakeAPIServer) release() {
+	f.Lock()
+	f.refs--
+	done := f.refs == 0
+	f.Unlock()
+	if done {
+		f.Close()
+	}
+}
+
+func (f *FakeAPIServer) ServeHTTP(w http.ResponseWriter, hr *http.Request) {
+	endpoint := strings.TrimSuffix(strings.TrimPrefix(hr.URL.Path, APIURIPathPrefix+"/"), ".json")
+	r := &fakeAPIRequest{Request: hr}
+
+	f.Lock()
+	defer f.Unlock()
+
+	body, err := f.handle(endpoint, r)
+	if body == nil {
+		body = fakeAPIBody{}
+	}
+	status := fakeAPIBody{"code": SCOk, "name": "OK"}
+	if err != nil {
+		ae, ok := err.(fakeAPIError)
+		if !ok {
+			ae = fakeAPIError{code: SCGeneric, name: "GENERIC", desc: err.Error()}
+		}
+		status = fakeAPIBody{"code": ae.code, "name": ae.name, "desc": ae.desc}
+	}
+	body["status"] = status
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (f *FakeAPIServer) handle(endpoint string, r *fakeAPIRequest) (fakeAPIBody, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&r.payload); err != nil {
+			return nil, err
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	if h, ok := fakeAPIHandlers[endpoint]; ok {
+		return h(f, r)
+	}
+	if strings.HasPrefix(endpoint, "rooter/") && r.Method == "GET" {
+		return f.rooterGet(endpoint)
+	}
+	return nil, newFakeAPIError(SCGeneric, "NOT_IMPLEMENTED", "FakeAPIServer doesn't implement %s", endpoint)
+}
+
+//=============================================================================
+// Lookups
+
+func (f *FakeAPIServer) userByName(name string) *fakeAPIUser {
+	u := f.users[UsernameToUID(name)]
+	if u == nil || !strings.EqualFold(u.username, name) {
+		return nil
+	}
+	return u
+}
+
+func (f *FakeAPIServer) userByEmailOrUsername(s string) (*fakeAPIUser, error) {
+	var u *fakeAPIUser
+	if CheckEmail.F(s) {
+		u = f.byEmail[strings.ToLower(s)]
+	} else {
+		u = f.userByName(s)
+	}
+	if u == nil {
+		return nil, newFakeAPIError(SCBadLoginUserNotFound, "BAD_LOGIN_USER_NOT_FOUND", "no user %q", s)
+	}
+	return u, nil
+}
+
+func (f *FakeAPIServer) userByUID(s string) (*fakeAPIUser, error) {
+	uid, err := UIDFromHex(s)
+	if err != nil {
+		return nil, err
+	}
+	u := f.users[uid]
+	if u == nil {
+		return nil, newFakeAPIError(SCNotFound, "NOT_FOUND", "no user with UID %s", uid)
+	}
+	return u, nil
+}
+
+// me returns the user that the request's session belongs to.
+func (f *FakeAPIServer) me(r *fakeAPIRequest) (*fakeAPIUser, error) {
+	s, ok := f.sessions[r.Header.Get("X-Keybase-Session")]
+	if !ok {
+		return nil, newFakeAPIError(SCBadSession, "BAD_SESSION", "no such session")
+	}
+	return f.users[s.uid], nil
+}
+
+func fakeAPIRandHex(n int) (string, error) {
+	b, err := RandBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (f *FakeAPIServer) newSession(u *fakeAPIUser) (fakeAPIBody, error) {
+	tok, err := fakeAPIRandHex(16)
+	if err != nil {
+		return nil, err
+	}
+	csrf, err := fakeAPIRandHex(16)
+	if err != nil {
+		return nil, err
+	}
+	f.sessions[tok] = fakeAPISession{uid: u.uid, csrf: csrf}
+	return fakeAPIBody{"session": tok, "csrf_token": csrf, "uid": u.uid}, nil
+}
+
+func (u *fakeAPIUser) basics() fakeAPIBody {
+	return fakeAPIBody{
+		"username":              u.username,
+		"id_version":            u.idVersion,
+		"last_id_change":        u.lastIDChange,
+		"passphrase_generation": u.ppGen,
+	}
+}
+
+// export is the user object that user/lookup returns as "them".
+func (u *fakeAPIUser) export() fakeAPIBody {
+	return fakeAPIBody{
+		"id":          u.uid,
+		"basics":      u.basics(),
+		"public_keys": RawKeyFamily{AllBundles: u.bundles},
+	}
+}
+
+func (u *fakeAPIUser) touch() {
+	u.idVersion++
+	u.lastIDChange = time.Now().Unix()
+}
+
+//=============================================================================
+// Sessions
+
+func (f *FakeAPIServer) signup(r *fakeAPIRequest) (fakeAPIBody, error) {
+	username, email := r.arg("username"), r.arg("email")
+	if !CheckUsername.F(username) {
+		return nil, newFakeAPIError(SCBadSignupUsernameTaken, "BAD_SIGNUP_USERNAME_TAKEN", "bad username %q", username)
+	}
+	if _, found := f.users[UsernameToUID(username)]; found {
+		return nil, newFakeAPIError(SCBadSignupUsernameTaken, "BAD_SIGNUP_USERNAME_TAKEN", "username %q is taken", username)
+	}
+	if _, found := f.byEmail[strings.ToLower(email)]; found {
+		return nil, newFakeAPIError(SCGeneric, "BAD_SIGNUP_EMAIL_TAKEN", "email %q is taken", email)
+	}
+	salt, err := hex.DecodeString(r.arg("salt"))
+	if err != nil {
+		return nil, err
+	}
+	pwh, err := hex.DecodeString(r.arg("pwh"))
+	if err != nil {
+		return nil, err
+	}
+
+	u := &fakeAPIUser{
+		uid:         UsernameToUID(username),
+		username:    username,
+		email:       email,
+		salt:        salt,
+		pwh:         pwh,
+		ppGen:       1,
+		bundles:     []string{},
+		devices:     make(DeviceKeyMap),
+		privateKeys: make(ServerPrivateKeyMap),
+		recovery:    make(map[keybase1.KID]fakeAPIRecovery),
+		following:   make(map[keybase1.UID]bool),
+	}
+	u.touch()
+	f.users[u.uid] = u
+	f.byEmail[strings.ToLower(email)] = u
+	if err = f.updateMerkle(); err != nil {
+		return nil, err
+	}
+
+	ret, err := f.newSession(u)
+	if err != nil {
+		return nil, err
+	}
+	ret["me"] = fakeAPIBody{"basics": u.basics()}
+	return ret, nil
+}
+
+func (f *FakeAPIServer) getSalt(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.userByEmailOrUsername(r.arg("email_or_username"))
+	if err != nil {
+		return nil, err
+	}
+	ls, err := RandBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	ls64 := base64.StdEncoding.EncodeToString(ls)
+	f.loginSessions[ls64] = u.uid
+	return fakeAPIBody{
+		"uid":           u.uid,
+		"salt":          hex.EncodeToString(u.salt),
+		"login_session": ls64,
+		"pwh_version":   1,
+	}, nil
+}
+
+// useLoginSession checks that the login session was handed out to u by
+// getsalt, and that it's only used once.
+func (f *FakeAPIServer) useLoginSession(u *fakeAPIUser, ls64 string) error {
+	uid, found := f.loginSessions[ls64]
+	if !found || uid.NotEqual(u.uid) {
+		return newFakeAPIError(SCBadLoginPassword, "BAD_LOGIN_PASSWORD", "bad login session")
+	}
+	delete(f.loginSessions, ls64)
+	return nil
+}
+
+func (f *FakeAPIServer) login(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.userByEmailOrUsername(r.arg("email_or_username"))
+	if err != nil {
+		return nil, err
+	}
+	ls64 := r.arg("login_session")
+	if err = f.useLoginSession(u, ls64); err != nil {
+		return nil, err
+	}
+	ls, err := base64.StdEncoding.DecodeString(ls64)
+	if err != nil {
+		return nil, err
+	}
+	got, err := hex.DecodeString(r.arg("hmac_pwh"))
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha512.New, u.pwh)
+	mac.Write(ls)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return nil, newFakeAPIError(SCBadLoginPassword, "BAD_LOGIN_PASSWORD", "bad passphrase")
+	}
+
+	ret, err := f.newSession(u)
+	if err != nil {
+		return nil, err
+	}
+	ret["me"] = fakeAPIBody{"basics": u.basics()}
+	return ret, nil
+}
+
+func (f *FakeAPIServer) sigPostAuth(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.userByUID(r.arg("uid"))
+	if err != nil {
+		return nil, err
+	}
+	jw, _, _, err := u.verify(keybase1.KIDFromString(r.arg("signing_kid")), r.arg("sig"), "")
+	if err != nil {
+		return nil, err
+	}
+	ls64, err := jw.AtPath("body.auth.session").GetString()
+	if err != nil {
+		return nil, err
+	}
+	if err = f.useLoginSession(u, ls64); err != nil {
+		return nil, err
+	}
+	ret, err := f.newSession(u)
+	if err != nil {
+		return nil, err
+	}
+	if ret["auth_id"], err = fakeAPIRandHex(16); err != nil {
+		return nil, err
+	}
+	ret["username"] = u.username
+	ret["passphrase_generation"] = u.ppGen
+	return ret, nil
+}
+
+func (f *FakeAPIServer) sessCheck(r *fakeAPIRequest) (fakeAPIBody, error) {
+	s, ok := f.sessions[r.Header.Get("X-Keybase-Session")]
+	if !ok {
+		return nil, newFakeAPIError(SCBadSession, "BAD_SESSION", "no such session")
+	}
+	return fakeAPIBody{
+		"logged_in_uid": s.uid,
+		"username":      f.users[s.uid].username,
+		"csrf_token":    s.csrf,
+	}, nil
+}
+
+func (f *FakeAPIServer) logout(r *fakeAPIRequest) (fakeAPIBody, error) {
+	delete(f.sessions, r.Header.Get("X-Keybase-Session"))
+	return nil, nil
+}
+
+func (f *FakeAPIServer) newSessionForMe(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.me(r)
+	if err != nil {
+		return nil, err
+	}
+	return f.newSession(u)
+}
+
+func (f *FakeAPIServer) invitationCheck(r *fakeAPIRequest) (fakeAPIBody, error) {
+	return nil, nil
+}
+
+//=============================================================================
+// Sigchains and keys
+
+// verify checks a signature by one of u's keys, or by newKey, a key that
+// the signature is about to add. It returns the signed payload.
+func (u *fakeAPIUser) verify(kid keybase1.KID, sig string, newKey string) (jw *jsonw.Wrapper, payload string, sigID keybase1.SigID, err error) {
+	bundles := u.bundles
+	if len(newKey) > 0 {
+		bundles = append([]string{newKey}, bundles...)
+	}
+	var key GenericKey
+	for _, b := range bundles {
+		if k, e2 := ParseGenericKey(b); e2 == nil && k.GetKID().Equal(kid) {
+			key = k
+			break
+		}
+	}
+	if key == nil {
+		return nil, "", "", newFakeAPIError(SCKeyNotFound, "KEY_NOT_FOUND", "%s has no key %s", u.username, kid)
+	}
+	msg, sigID, err := key.VerifyStringAndExtract(sig)
+	if err != nil {
+		return nil, "", "", newFakeAPIError(SCGeneric, "BAD_SIGNATURE", "%s", err)
+	}
+	if jw, err = jsonw.Unmarshal(msg); err != nil {
+		return nil, "", "", err
+	}
+	return jw, string(msg), sigID, nil
+}
+
+// appendLink adds a signed link to the tail of u's sigchain, and applies
+// any device updates that it carries.
+func (f *FakeAPIServer) appendLink(u *fakeAPIUser, jw *jsonw.Wrapper, payload string, sig string, sigID keybase1.SigID, kid keybase1.KID, proofText string) error {
+	var seqno, ctime int64
+	var uid keybase1.UID
+	var typ string
+	var err error
+	jw.AtKey("seqno").GetInt64Void(&seqno, &err)
+	jw.AtKey("ctime").GetInt64Void(&ctime, &err)
+	jw.AtPath("body.type").GetStringVoid(&typ, &err)
+	GetUIDVoid(jw.AtPath("body.key.uid"), &uid, &err)
+	if err != nil {
+		return err
+	}
+	if uid.NotEqual(u.uid) {
+		return newFakeAPIError(SCGeneric, "BAD_LINK", "link is for %s, not %s", uid, u.uid)
+	}
+	if Seqno(seqno) != Seqno(len(u.links)+1) {
+		return newFakeAPIError(SCGeneric, "BAD_LINK", "link has seqno %d, wanted %d", seqno, len(u.links)+1)
+	}
+	prev, _ := jw.AtKey("prev").GetString()
+	if n := len(u.links); n > 0 && prev != u.links[n-1].PayloadHash {
+		return newFakeAPIError(SCGeneric, "BAD_LINK", "link doesn't follow the chain tail")
+	}
+
+	h := sha256.Sum256([]byte(payload))
+	u.links = append(u.links, fakeAPILink{
+		Seqno:         Seqno(seqno),
+		PayloadHash:   hex.EncodeToString(h[:]),
+		SigID:         sigID,
+		SigIDShort:    sigID.ToShortID(),
+		KID:           kid,
+		Sig:           sig,
+		PayloadJSON:   payload,
+		CTime:         ctime,
+		ProofTextFull: proofText,
+	})
+	if typ == string(EldestType) || seqno == 1 {
+		u.eldest = kid
+	}
+	u.updateDevice(jw.AtPath("body.device"), ctime)
+	u.touch()
+	return f.updateMerkle()
+}
+
+func (u *fakeAPIUser) updateDevice(jw *jsonw.Wrapper, ctime int64) {
+	var d Device
+	if jw.IsNil() || jw.UnmarshalAgain(&d) != nil || len(d.ID) == 0 {
+		return
+	}
+	dk := u.devices[d.ID]
+	if len(d.Type) > 0 {
+		dk.Type = d.Type
+	}
+	if d.Description != nil {
+		dk.Description = *d.Description
+	}
+	if d.Status != nil {
+		dk.Status = *d.Status
+	}
+	if dk.CTime == 0 {
+		dk.CTime = ctime
+	}
+	dk.MTime = ctime
+	u.devices[d.ID] = dk
+	u.secretsVers++
+}
+
+// addKeySig handles one key delegation, as sent to key/add or as one of
+// the sigs in key/multi.
+func (f *FakeAPIServer) addKeySig(u *fakeAPIUser, args map[string]string) error {
+	newKey := args["public_key"]
+	jw, payload, sigID, err := u.verify(keybase1.KIDFromString(args["signing_kid"]), args["sig"], newKey)
+	if err != nil {
+		return err
+	}
+	if err = f.appendLink(u, jw, payload, args["sig"], sigID, keybase1.KIDFromString(args["signing_kid"]), ""); err != nil {
+		return err
+	}
+
+	if len(newKey) > 0 {
+		key, err := ParseGenericKey(newKey)
+		if err != nil {
+			return err
+		}
+		u.bundles = append(u.bundles, newKey)
+		f.byKID[key.GetKID()] = u
+	}
+	now := time.Now().Unix()
+	if priv := args["private_key"]; len(priv) > 0 {
+		kid, err := GetKID(jw.AtPath("body.sibkey.kid"))
+		if err != nil {
+			kid = keybase1.KIDFromString(args["signing_kid"])
+		}
+		u.privateKeys[kid.String()] = ServerPrivateKey{
+			Kid:     kid.String(),
+			KeyType: KeyTypeP3skbPrivate,
+			Bundle:  priv,
+			Ctime:   int(now),
+			Mtime:   int(now),
+		}
+		u.secretsVers++
+	}
+	if half := args["server_half"]; len(half) > 0 {
+		if id, err := jw.AtPath("body.device.id").GetString(); err == nil {
+			dk := u.devices[keybase1.DeviceID(id)]
+			dk.LksServerHalf = half
+			dk.PPGen = PassphraseGeneration(u.ppGen)
+			u.devices[keybase1.DeviceID(id)] = dk
+			u.secretsVers++
+		}
+	}
+	return nil
+}
+
+func (f *FakeAPIServer) keyAdd(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.me(r)
+	if err != nil {
+		return nil, err
+	}
+	return nil, f.addKeySig(u, r.args())
+}
+
+func (f *FakeAPIServer) keyMulti(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.me(r)
+	if err != nil {
+		return nil, err
+	}
+	var sigs []map[string]string
+	if err = json.Unmarshal(r.payload["sigs"], &sigs); err != nil {
+		return nil, err
+	}
+	for _, args := range sigs {
+		if err = f.addKeySig(u, args); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (f *FakeAPIServer) keyFetchPrivate(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.me(r)
+	if err != nil {
+		return nil, err
+	}
+	return fakeAPIBody{
+		"version":      u.secretsVers,
+		"private_keys": u.privateKeys,
+		"devices":      u.devices,
+	}, nil
+}
+
+func (f *FakeAPIServer) keyOwner(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u := f.byKID[keybase1.KIDFromString(r.arg("kid"))]
+	if u == nil {
+		return nil, newFakeAPIError(SCKeyNotFound, "KEY_NOT_FOUND", "no such key")
+	}
+	return fakeAPIBody{"uid": u.uid}, nil
+}
+
+func (f *FakeAPIServer) keyBasics(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u := f.byKID[keybase1.KIDFromString(r.arg("kid"))]
+	if fp := r.arg("fingerprint"); u == nil && len(fp) > 0 {
+		for _, v := range f.users {
+			for _, b := range v.bundles {
+				if k, err := ParseGenericKey(b); err == nil && k.GetFingerprintP() != nil && k.GetFingerprintP().String() == strings.ToLower(fp) {
+					u = v
+				}
+			}
+		}
+	}
+	if u == nil {
+		return nil, newFakeAPIError(SCKeyNotFound, "KEY_NOT_FOUND", "no such key")
+	}
+	return fakeAPIBody{"username": u.username, "uid": u.uid}, nil
+}
+
+func (f *FakeAPIServer) deviceUpdate(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.me(r)
+	if err != nil {
+		return nil, err
+	}
+	ppGen, err := strconv.Atoi(r.arg("ppgen"))
+	if err != nil {
+		return nil, err
+	}
+	id := keybase1.DeviceID(r.arg("device_id"))
+	dk := u.devices[id]
+	if len(dk.Type) == 0 {
+		dk.Type = r.arg("type")
+	}
+	dk.LksServerHalf = r.arg("lks_server_half")
+	dk.PPGen = PassphraseGeneration(ppGen)
+	u.devices[id] = dk
+	u.secretsVers++
+
+	if ctext := r.arg("lks_client_half"); len(ctext) > 0 {
+		kid := keybase1.KIDFromString(r.arg("kid"))
+		u.recovery[kid] = fakeAPIRecovery{ctext: ctext, ppGen: ppGen}
+		f.byKID[kid] = u
+	}
+	return nil, nil
+}
+
+func (f *FakeAPIServer) passphraseRecover(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.me(r)
+	if err != nil {
+		return nil, err
+	}
+	rec, found := u.recovery[keybase1.KIDFromString(r.arg("kid"))]
+	if !found {
+		return nil, newFakeAPIError(SCKeyNotFound, "KEY_NOT_FOUND", "no recovery for that key")
+	}
+	return fakeAPIBody{"ctext": rec.ctext, "passphrase_generation": rec.ppGen}, nil
+}
+
+// postLink handles the endpoints that just take a signed link.
+func (f *FakeAPIServer) postLink(r *fakeAPIRequest, proofText func(keybase1.SigID) string) (*fakeAPIUser, *jsonw.Wrapper, keybase1.SigID, error) {
+	u, err := f.me(r)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	kid := keybase1.KIDFromString(r.arg("signing_kid"))
+	jw, payload, sigID, err := u.verify(kid, r.arg("sig"), "")
+	if err != nil {
+		return nil, nil, "", err
+	}
+	var text string
+	if proofText != nil {
+		text = proofText(sigID)
+	}
+	if err = f.appendLink(u, jw, payload, r.arg("sig"), sigID, kid, text); err != nil {
+		return nil, nil, "", err
+	}
+	return u, jw, sigID, nil
+}
+
+func (f *FakeAPIServer) sigPost(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, jw, sigID, err := f.postLink(r, keybase1.SigID.ToMediumID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := fakeAPIRandHex(16)
+	if err != nil {
+		return nil, err
+	}
+	service, _ := jw.AtPath("body.service.name").GetString()
+	remote, _ := jw.AtPath("body.service.username").GetString()
+	f.proofs[id] = &fakeAPIProof{
+		uid:            u.uid,
+		sigID:          sigID,
+		service:        service,
+		remoteUsername: strings.ToLower(remote),
+	}
+	u.hintsVers++
+	return fakeAPIBody{
+		"proof_text":     sigID.ToMediumID(),
+		"proof_id":       id,
+		"proof_metadata": fakeAPIBody{},
+	}, nil
+}
+
+func (f *FakeAPIServer) sigPosted(r *fakeAPIRequest) (fakeAPIBody, error) {
+	p := f.proofs[r.arg("proof_id")]
+	if sigID := r.arg("sig_id"); p == nil && len(sigID) > 0 {
+		for _, q := range f.proofs {
+			if q.sigID.ToString(true) == sigID {
+				p = q
+			}
+		}
+	}
+	if p == nil {
+		return nil, newFakeAPIError(SCNotFound, "NOT_FOUND", "no such proof")
+	}
+	if len(p.postID) == 0 {
+		for id, t := range f.toots {
+			if t.username == p.remoteUsername && strings.Contains(t.post, p.sigID.ToMediumID()) {
+				p.postID = id
+				f.users[p.uid].hintsVers++
+			}
+		}
+	}
+	status := keybase1.ProofStatus_NOT_FOUND
+	if len(p.postID) > 0 {
+		status = keybase1.ProofStatus_OK
+	}
+	return fakeAPIBody{
+		"proof_ok":  len(p.postID) > 0,
+		"proof_res": fakeAPIBody{"status": status},
+	}, nil
+}
+
+func (f *FakeAPIServer) sigRevoke(r *fakeAPIRequest) (fakeAPIBody, error) {
+	_, _, _, err := f.postLink(r, nil)
+	return nil, err
+}
+
+func (f *FakeAPIServer) follow(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, _, _, err := f.postLink(r, nil)
+	if err != nil {
+		return nil, err
+	}
+	them, err := UIDFromHex(r.arg("uid"))
+	if err != nil {
+		return nil, err
+	}
+	if r.arg("type") == "untrack" {
+		delete(u.following, them)
+	} else {
+		u.following[them] = true
+	}
+	return nil, nil
+}
+
+func (f *FakeAPIServer) sigGet(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.userByUID(r.arg("uid"))
+	if err != nil {
+		return nil, err
+	}
+	low, _ := strconv.Atoi(r.arg("low"))
+	sigs := []fakeAPILink{}
+	for _, l := range u.links {
+		if int(l.Seqno) > low {
+			sigs = append(sigs, l)
+		}
+	}
+	return fakeAPIBody{"sigs": sigs}, nil
+}
+
+func (f *FakeAPIServer) sigHints(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.userByUID(r.arg("uid"))
+	if err != nil {
+		return nil, err
+	}
+	hints := []fakeAPIBody{}
+	if low, _ := strconv.Atoi(r.arg("low")); low < u.hintsVers {
+		for _, p := range f.proofs {
+			if p.uid.NotEqual(u.uid) {
+				continue
+			}
+			url := fmt.Sprintf("%s%s/%s/%s/%s.json", f.URL(), APIURIPathPrefix, p.service, p.remoteUsername, p.postID)
+			hints = append(hints, fakeAPIBody{
+				"sig_id":           p.sigID,
+				"remote_id":        p.postID,
+				"api_url":          url,
+				"human_url":        url,
+				"proof_text_check": p.sigID.ToMediumID(),
+			})
+		}
+	}
+	return fakeAPIBody{"version": u.hintsVers, "hints": hints}, nil
+}
+
+//=============================================================================
+// Users
+
+func (f *FakeAPIServer) userLookup(r *fakeAPIRequest) (fakeAPIBody, error) {
+	var them []*fakeAPIUser
+	add := func(u *fakeAPIUser) {
+		if u != nil {
+			them = append(them, u)
+		}
+	}
+	args := r.args()
+	switch {
+	case len(args["uid"]) > 0:
+		u, err := f.userByUID(args["uid"])
+		if err != nil {
+			return nil, err
+		}
+		add(u)
+	case len(args["username"]) > 0:
+		add(f.userByName(args["username"]))
+	case len(args["email"]) > 0:
+		add(f.byEmail[strings.ToLower(args["email"])])
+	default:
+		// Look up by a proven social identity, like rooter=name.
+		for k, v := range args {
+			for _, p := range f.proofs {
+				if p.service == k && p.remoteUsername == strings.ToLower(v) {
+					add(f.users[p.uid])
+				}
+			}
+		}
+	}
+
+	if args["multi"] == "1" {
+		list := []fakeAPIBody{}
+		for _, u := range them {
+			list = append(list, u.export())
+		}
+		return fakeAPIBody{"them": list}, nil
+	}
+	if len(them) == 0 {
+		return nil, newFakeAPIError(SCNotFound, "NOT_FOUND", "user not found")
+	}
+	return fakeAPIBody{"them": them[0].export()}, nil
+}
+
+func (f *FakeAPIServer) userCard(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.userByUID(r.arg("uid"))
+	if err != nil {
+		return nil, err
+	}
+	me, _ := f.me(r)
+	var followers int
+	for _, v := range f.users {
+		if v.following[u.uid] {
+			followers++
+		}
+	}
+	return fakeAPIBody{
+		"follow_summary":  fakeAPIBody{"following": len(u.following), "followers": followers},
+		"profile":         fakeAPIBody{},
+		"you_follow_them": me != nil && me.following[u.uid],
+		"they_follow_you": me != nil && u.following[me.uid],
+	}, nil
+}
+
+//=============================================================================
+// Merkle tree
+
+// merkleLeaf is the user's leaf, in the v2 format that parseV2 reads.
+func (u *fakeAPIUser) merkleLeaf() []interface{} {
+	var tail, eldest interface{}
+	if n := len(u.links); n > 0 {
+		l := u.links[n-1]
+		tail = []interface{}{l.Seqno, l.PayloadHash, l.SigID.ToString(false)}
+	}
+	if u.eldest.Exists() {
+		eldest = u.eldest
+	}
+	return []interface{}{2, tail, nil, eldest}
+}
+
+// updateMerkle makes and signs a new root. The whole tree is a single leaf
+// node, which is plenty for the handful of users that a test makes.
+func (f *FakeAPIServer) updateMerkle() error {
+	tab := make(map[string]interface{})
+	for uid, u := range f.users {
+		tab[uid.String()] = u.merkleLeaf()
+	}
+	node, err := json.Marshal(fakeAPIBody{"type": MerkleTreeLeaf, "tab": tab})
+	if err != nil {
+		return err
+	}
+	hash := sha512.Sum512(node)
+
+	kid := f.merkleKey.GetKID()
+	fp := sha256.Sum256(kid.ToBytes())
+	f.merkleSeqno++
+	payload, err := json.Marshal(fakeAPIBody{
+		"body": fakeAPIBody{
+			"key": fakeAPIBody{
+				"fingerprint": hex.EncodeToString(fp[:PGPFingerprintLen]),
+				"kid":         kid,
+			},
+			"root":    hex.EncodeToString(hash[:]),
+			"seqno":   f.merkleSeqno,
+			"type":    "merkle_root",
+			"version": 1,
+		},
+		"ctime": time.Now().Unix(),
+		"tag":   "signature",
+	})
+	if err != nil {
+		return err
+	}
+	sig, _, err := f.merkleKey.SignToString(payload)
+	if err != nil {
+		return err
+	}
+
+	f.merkleNode = string(node)
+	f.merkleRoot = fakeAPIBody{
+		"sigs":         fakeAPIBody{kid.String(): fakeAPIBody{"sig": sig}},
+		"payload_json": string(payload),
+	}
+	return nil
+}
+
+func (f *FakeAPIServer) merklePath(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.userByUID(r.arg("uid"))
+	if err != nil {
+		return nil, err
+	}
+	return fakeAPIBody{
+		"root":           f.merkleRoot,
+		"uid":            u.uid,
+		"id_version":     u.idVersion,
+		"username":       strings.ToLower(u.username),
+		"username_cased": u.username,
+		"path": []fakeAPIBody{
+			{"prefix": "", "node": fakeAPIBody{"val": f.merkleNode}},
+		},
+		"uid_proof_path": []fakeAPIBody{},
+	}, nil
+}
+
+//=============================================================================
+// Rooter
+
+func (f *FakeAPIServer) rooterPost(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.me(r)
+	if err != nil {
+		return nil, err
+	}
+	id, err := fakeAPIRandHex(8)
+	if err != nil {
+		return nil, err
+	}
+	f.toots[id] = fakeAPIToot{username: strings.ToLower(u.username), post: r.arg("post")}
+	return fakeAPIBody{"post_id": id}, nil
+}
+
+func (f *FakeAPIServer) rooterDelete(r *fakeAPIRequest) (fakeAPIBody, error) {
+	u, err := f.me(r)
+	if err != nil {
+		return nil, err
+	}
+	id := r.arg("post_id")
+	if t, found := f.toots[id]; !found || t.username != strings.ToLower(u.username) {
+		return nil, newFakeAPIError(SCNotFound, "NOT_FOUND", "no such toot")
+	}
+	delete(f.toots, id)
+	return nil, nil
+}
+
+// rooterGet serves rooter/<username>/<post id>, which is where the rooter
+// hints point.
+func (f *FakeAPIServer) rooterGet(endpoint string) (fakeAPIBody, error) {
+	parts := strings.Split(endpoint, "/")
+	if len(parts) == 3 {
+		if t, found := f.toots[parts[2]]; found && t.username == strings.ToLower(parts[1]) {
+			return fakeAPIBody{"toot": fakeAPIBody{"post": t.post}}, nil
+		}
+	}
+	return nil, newFakeAPIError(SCNotFound, "NOT_FOUND", "no such toot")
+}
+
+//=============================================================================
+// Kex2
+
+func (f *FakeAPIServer) kexSend(r *fakeAPIRequest) (fakeAPIBody, error) {
+	seqno, err := strconv.Atoi(r.arg("seqno"))
+	if err != nil {
+		return nil, err
+	}
+	sess := r.arg("I")
+	f.kex[sess] = append(f.kex[sess], fakeAPIKexMsg{sender: r.arg("sender"), seqno: seqno, msg: r.arg("msg")})
+	f.kexCond.Broadcast()
+	return nil, nil
+}
+
+// kexReceive long-polls for messages to the receiver, like the real
+// server. It's called with f locked, which kexCond.Wait releases.
+func (f *FakeAPIServer) kexReceive(r *fakeAPIRequest) (fakeAPIBody, error) {
+	sess, receiver := r.arg("I"), r.arg("receiver")
+	low, err := strconv.Atoi(r.arg("low"))
+	if err != nil {
+		return nil, err
+	}
+	poll, _ := strconv.Atoi(r.arg("poll"))
+	deadline := time.Now().Add(time.Duration(poll) * time.Millisecond)
+
+	for {
+		var msgs []fakeAPIKexMsg
+		for _, m := range f.kex[sess] {
+			if m.sender != receiver && m.seqno >= low {
+				msgs = append(msgs, m)
+			}
+		}
+		if len(msgs) > 0 || !time.Now().Before(deadline) {
+			sort.Sort(fakeAPIKexMsgs(msgs))
+			ret := []fakeAPIBody{}
+			for _, m := range msgs {
+				ret = append(ret, fakeAPIBody{"msg": m.msg})
+			}
+			return fakeAPIBody{"msgs": ret}, nil
+		}
+		t := time.AfterFunc(deadline.Sub(time.Now()), func() {
+			f.Lock()
+			f.kexCond.Broadcast()
+			f.Unlock()
+		})
+		f.kexCond.Wait()
+		t.Stop()
+	}
+}
+
+type fakeAPIKexMsgs []fakeAPIKexMsg
+
+func (m fakeAPIKexMsgs) Len() int           { return len(m) }
+func (m fakeAPIKexMsgs) Less(i, j int) bool { return m[i].seqno < m[j].seqno }
+func (m fakeAPIKexMsgs) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
@@ -54,6 +54,8 @@ func CanEncrypt(key GenericKey) bool {
 	switch key.(type) {
 	case NaclDHKeyPair:
 		return true
+	case *PKCS11DHKey:
+		return true
 	case *PGPKeyBundle:
 		return true
 	default:
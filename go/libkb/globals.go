@@ -58,6 +58,7 @@ type GlobalContext struct {
 	shutdownOnce      sync.Once          // whether we've shut down or not
 	loginStateMu      sync.RWMutex       // protects loginState pointer, which gets destroyed on logout
 	loginState        *LoginState        // What phase of login the user's in
+	actingUsers       *actingUsers       // users logged in besides the current one
 	ConnectionManager *ConnectionManager // keep tabs on all active client connections
 	NotifyRouter      *NotifyRouter      // How to route notifications
 	// How to route UIs. Nil if we're in standalone mode or in
@@ -68,6 +69,7 @@ type GlobalContext struct {
 	RateLimits          *RateLimits         // tracks the last time certain actions were taken
 	Clock               clockwork.Clock     // RealClock unless we're testing
 	SecretStoreAll      SecretStoreAll      // nil except for tests and supported platforms
+	Metrics             *Metrics            // counts for the metrics endpoint
 }
 
 func NewGlobalContext() *GlobalContext {
@@ -77,6 +79,8 @@ func NewGlobalContext() *GlobalContext {
 		VDL:                 NewVDebugLog(log),
 		ProofCheckerFactory: defaultProofCheckerFactory,
 		Clock:               clockwork.NewRealClock(),
+		actingUsers:         newActingUsers(),
+		Metrics:             NewMetrics(),
 	}
 }
 
@@ -171,6 +175,7 @@ func (g *GlobalContext) ConfigureLogging() error {
 		g.Log.Configure(style, debug, g.Env.GetDefaultLogFile())
 	} else {
 		g.Log.Configure(style, debug, logFile)
+		g.Log.SetRotation(*g.Env.GetLogFileConfig(logFile))
 		g.Log.RotateLogFile()
 	}
 	g.Output = os.Stdout
@@ -192,6 +197,12 @@ func (g *GlobalContext) ConfigureConfig() error {
 		return err
 	}
 	g.Env.SetConfig(*c, c)
+	RegisterGenericServiceTypes(g)
+	// The config might have set up a secret store that wasn't there
+	// before (like a secret_store_key_file on Linux).
+	if g.SecretStoreAll == nil {
+		g.SecretStoreAll = NewSecretStoreAll(g)
+	}
 	return nil
 }
 
@@ -236,6 +247,9 @@ func (g *GlobalContext) ConfigureCaches() error {
 	g.ProofCache = NewProofCache(g, g.Env.GetProofCacheSize())
 	g.LinkCache = NewLinkCache(g.Env.GetLinkCacheSize(), g.Env.GetLinkCacheCleanDur())
 	g.Log.Debug("Created LinkCache, max size: %d, clean dur: %s", g.Env.GetLinkCacheSize(), g.Env.GetLinkCacheCleanDur())
+	if g.Env.GetMetricsAddr() != "" {
+		g.countCacheStats()
+	}
 
 	// We consider the local DB as a cache; it's caching our
 	// fetches from the server after all (and also our cryptographic
@@ -289,6 +303,7 @@ func (g *GlobalContext) Shutdown() error {
 		if g.LoginState() != nil {
 			epick.Push(g.LoginState().Shutdown())
 		}
+		epick.Push(g.shutdownActingUsers())
 
 		if g.TrackCache != nil {
 			g.TrackCache.Shutdown()
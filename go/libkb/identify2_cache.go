@@ -15,11 +15,13 @@ import (
 // time.
 type Identify2Cache struct {
 	cache *ramcache.Ramcache
+	cacheCounter
 }
 
 type Identify2Cacher interface {
 	Get(keybase1.UID, GetCheckTimeFunc, time.Duration) (*keybase1.UserPlusKeys, error)
 	Insert(up *keybase1.UserPlusKeys) error
+	Delete(uid keybase1.UID) error
 	Shutdown()
 }
 
@@ -38,7 +40,8 @@ func NewIdentify2Cache(maxAge time.Duration) *Identify2Cache {
 }
 
 // Get returns a user object.  If none exists for uid, it will return nil.
-func (c *Identify2Cache) Get(uid keybase1.UID, gctf GetCheckTimeFunc, timeout time.Duration) (*keybase1.UserPlusKeys, error) {
+func (c *Identify2Cache) Get(uid keybase1.UID, gctf GetCheckTimeFunc, timeout time.Duration) (up *keybase1.UserPlusKeys, err error) {
+	defer func() { c.record(up != nil) }()
 	v, err := c.cache.Get(string(uid))
 	if err != nil {
 		if err == ramcache.ErrNotFound {
@@ -74,6 +77,16 @@ func (c *Identify2Cache) Insert(up *keybase1.UserPlusKeys) error {
 	return c.cache.Set(string(up.Uid), copy)
 }
 
+// Delete removes a user from the cache, so that the next identify of them
+// will load them fresh.
+func (c *Identify2Cache) Delete(uid keybase1.UID) error {
+	err := c.cache.Delete(string(uid))
+	if err == ramcache.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
 // Shutdown stops any goroutines in the cache.
 func (c *Identify2Cache) Shutdown() {
 	c.cache.Shutdown()
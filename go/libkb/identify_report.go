@@ -0,0 +1,86 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// IdentifyReportVersion is the version of keybase1.IdentifyReport that
+// we make.
+const IdentifyReportVersion = 1
+
+// SignedIdentifyReport is the portable form of an identify report. Report
+// is the report's JSON, and Sig is a NaCl signature over it (with any
+// whitespace removed), so the file can be pretty-printed or re-indented
+// without breaking the signature.
+type SignedIdentifyReport struct {
+	Report json.RawMessage `json:"report"`
+	Sig    string          `json:"sig"`
+}
+
+// ExportProofChecks exports the proof checks in the order that
+// ProofChecksSorted puts them, along with the proof each was for.
+func (i *IdentifyOutcome) ExportProofChecks() []keybase1.IdentifyReportProofCheck {
+	ret := []keybase1.IdentifyReportProofCheck{}
+	for _, p := range i.ProofChecksSorted() {
+		ret = append(ret, keybase1.IdentifyReportProofCheck{
+			Proof:  ExportRemoteProof(p.link),
+			Result: p.Export(),
+		})
+	}
+	return ret
+}
+
+// SignIdentifyReport signs report with key, and returns the indented
+// JSON of the SignedIdentifyReport.
+func SignIdentifyReport(report keybase1.IdentifyReport, key GenericKey) ([]byte, error) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	sig, _, err := key.SignToString(body)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(SignedIdentifyReport{Report: body, Sig: sig}, "", "    ")
+}
+
+// OpenIdentifyReport checks that the signature in a SignedIdentifyReport
+// is good and covers the report, and that the report says it was made
+// with the key that signed it. It's up to the caller to check that the
+// key belonged to the signer.
+func OpenIdentifyReport(data []byte) (report keybase1.IdentifyReport, err error) {
+	var signed SignedIdentifyReport
+	if err = json.Unmarshal(data, &signed); err != nil {
+		return report, err
+	}
+	var body bytes.Buffer
+	if err = json.Compact(&body, signed.Report); err != nil {
+		return report, err
+	}
+
+	key, payload, _, err := NaclVerifyAndExtract(signed.Sig)
+	if err != nil {
+		return report, BadIdentifyReportError{Msg: err.Error()}
+	}
+	if !bytes.Equal(payload, body.Bytes()) {
+		return report, BadIdentifyReportError{Msg: "signature doesn't match the report"}
+	}
+
+	if err = json.Unmarshal(body.Bytes(), &report); err != nil {
+		return report, err
+	}
+	if report.Version != IdentifyReportVersion {
+		return report, BadIdentifyReportError{Msg: fmt.Sprintf("unknown report version %d", report.Version)}
+	}
+	if key.GetKID().NotEqual(report.SigningKID) {
+		return report, BadIdentifyReportError{Msg: fmt.Sprintf("signed by %s, but the report says %s", key.GetKID(), report.SigningKID)}
+	}
+	return report, nil
+}
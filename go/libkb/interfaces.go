@@ -64,6 +64,7 @@ type CommandLine interface {
 	GetTorHiddenAddress() string
 	GetTorProxy() string
 	GetLocalTrackMaxAge() (time.Duration, bool)
+	GetActingUser() string
 
 	// Lower-level functions
 	GetGString(string) string
@@ -90,6 +91,7 @@ type LocalDb interface {
 	Delete(id DbKey) error
 	Get(id DbKey) ([]byte, bool, error)
 	Lookup(alias DbKey) ([]byte, bool, error)
+	KeysWithType(typ ObjType) ([]DbKey, error)
 }
 
 type ConfigReader interface {
@@ -106,6 +108,9 @@ type ConfigReader interface {
 	GetProxy() string
 	GetLogFormat() string
 	GetGpgHome() string
+	GetSecretStoreKeyFile() string
+	GetKex2DirectAddress() string
+	GetPKCS11Module() string
 	GetBundledCA(host string) string
 	GetStringAtPath(string) (string, bool)
 	GetInterfaceAtPath(string) (interface{}, error)
@@ -119,8 +124,18 @@ type ConfigReader interface {
 	GetProofCacheShortDur() (time.Duration, bool)
 	GetLinkCacheSize() (int, bool)
 	GetLinkCacheCleanDur() (time.Duration, bool)
+	GetKeyExpiryWindow() (time.Duration, bool)
+	GetPassphrasePolicyMinLength() (int, bool)
+	GetPassphrasePolicyMinEntropyBits() (int, bool)
+	GetPassphrasePolicyBannedWords() []string
+	GetPassphrasePolicyAllowUserInfo() (bool, bool)
+	GetLogMaxSize() (int, bool)
+	GetLogMaxAge() (time.Duration, bool)
+	GetLogMaxKeepFiles() (int, bool)
+	GetMetricsAddr() string
 	GetMerkleKIDs() []string
 	GetCodeSigningKIDs() []string
+	GetProofServices() ([]GenericServiceConfig, error)
 	GetPinentry() string
 	GetNoPinentry() (bool, bool)
 	GetGpg() string
@@ -147,6 +162,7 @@ type ConfigReader interface {
 	GetUpdatePreferenceSkip() string
 	GetUpdatePreferenceSnoozeUntil() keybase1.Time
 	GetUpdateLastChecked() keybase1.Time
+	GetUpdateInstallID() string
 	GetUpdateURL() string
 	GetLocalTrackMaxAge() (time.Duration, bool)
 	GetAppStartMode() AppStartMode
@@ -177,6 +193,7 @@ type ConfigWriter interface {
 	SetUpdatePreferenceSkip(string) error
 	SetUpdatePreferenceSnoozeUntil(keybase1.Time) error
 	SetUpdateLastChecked(keybase1.Time) error
+	SetUpdateInstallID(string) error
 	Reset()
 	BeginTransaction() (ConfigWriterTransacter, error)
 }
@@ -317,6 +334,10 @@ type SaltpackUI interface {
 	SaltpackVerifySuccess(context.Context, keybase1.SaltpackVerifySuccessArg) error
 }
 
+type IdentifyBatchUI interface {
+	Identify2BatchResult(context.Context, keybase1.Identify2BatchResultArg) error
+}
+
 type LogUI interface {
 	Debug(format string, args ...interface{})
 	Info(format string, args ...interface{})
@@ -399,6 +399,12 @@ func KeyProof(arg Delegator) (ret *jsonw.Wrapper, err error) {
 		body.SetKey(string(arg.DelegationType), kp)
 	}
 
+	if len(arg.RevokeKIDs) > 0 {
+		revokeSection := jsonw.NewDictionary()
+		revokeSection.SetKey("kids", jsonw.NewWrapper(arg.RevokeKIDs))
+		body.SetKey("revoke", revokeSection)
+	}
+
 	return
 }
 
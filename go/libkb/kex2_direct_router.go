@@ -0,0 +1,364 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keybase/client/go/kex2"
+	"github.com/ugorji/go/codec"
+)
+
+// Kex2DirectCode tells a device how to reach the other device directly
+// for kex2, without relaying through the server. The device that listens
+// shows it (as a QR code, or for `keybase device add --direct`), and the
+// other device dials.
+type Kex2DirectCode struct {
+	Network string // "tcp" or "unix"
+	Address string
+	Secret  kex2.Secret
+}
+
+const kex2DirectCodePrefix = "keybase-kex2+"
+
+// String encodes the code as keybase-kex2+<network>://<address>#<secret>.
+func (c Kex2DirectCode) String() string {
+	return kex2DirectCodePrefix + c.Network + "://" + c.Address + "#" + base64.RawURLEncoding.EncodeToString(c.Secret[:])
+}
+
+// IsKex2DirectCode is true if s looks like a Kex2DirectCode, rather than a
+// secret phrase.
+func IsKex2DirectCode(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), kex2DirectCodePrefix)
+}
+
+// ParseKex2DirectCode parses the output of Kex2DirectCode.String.
+func ParseKex2DirectCode(s string) (*Kex2DirectCode, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, kex2DirectCodePrefix) {
+		return nil, InvalidArgumentError{Msg: "not a direct kex2 code"}
+	}
+	s = s[len(kex2DirectCodePrefix):]
+	parts := strings.SplitN(s, "://", 2)
+	if len(parts) != 2 || (parts[0] != "tcp" && parts[0] != "unix") {
+		return nil, InvalidArgumentError{Msg: "direct kex2 code has a bad network"}
+	}
+	i := strings.LastIndex(parts[1], "#")
+	if i <= 0 {
+		return nil, InvalidArgumentError{Msg: "direct kex2 code has no address or secret"}
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(parts[1][i+1:])
+	if err != nil || len(secret) != kex2.SecretLen {
+		return nil, InvalidArgumentError{Msg: "direct kex2 code has a bad secret"}
+	}
+	ret := &Kex2DirectCode{Network: parts[0], Address: parts[1][:i]}
+	copy(ret.Secret[:], secret)
+	return ret, nil
+}
+
+const (
+	kexDirectHelloText = "Kex v2 direct router hello"
+	kexDirectMaxFrame  = 1 << 20
+	kexDirectTimeout   = 10 * time.Second
+)
+
+// ErrKexDirectClosed is returned by KexDirectRouter.Get after the router has
+// been closed.
+var ErrKexDirectClosed = errors.New("direct kex2 router closed")
+
+// kexDirectHello is what the dialer sends first, to show that it has the
+// secret. Until then, the listener keeps waiting for the right connection.
+func kexDirectHello(s kex2.Secret) []byte {
+	mac := hmac.New(sha256.New, s[:])
+	mac.Write([]byte(kexDirectHelloText))
+	return mac.Sum(nil)
+}
+
+type kexDirectFrame struct {
+	_struct   bool           `codec:",toarray"`
+	SessionID kex2.SessionID `codec:"sessionID"`
+	Sender    kex2.DeviceID  `codec:"sender"`
+	Seqno     kex2.Seqno     `codec:"seqno"`
+	Msg       []byte         `codec:"msg"`
+}
+
+// KexDirectRouter implements the kex2.MessageRouter interface over a direct
+// TCP or Unix socket connection between the two devices. Messages are still
+// boxed by kex2.Conn, so the connection itself doesn't need to be secure.
+type KexDirectRouter struct {
+	Contextified
+
+	writeMu sync.Mutex // serializes writes to conn
+
+	sync.Mutex
+	listener net.Listener
+	conn     net.Conn
+	outbox   [][]byte // frames posted before the other device connected
+	inbox    map[kex2.SessionID][]kexDirectFrame
+	changed  chan struct{} // closed and replaced whenever inbox or err changes
+	err      error
+}
+
+func newKexDirectRouter(g *GlobalContext) *KexDirectRouter {
+	return &KexDirectRouter{
+		Contextified: NewContextified(g),
+		inbox:        make(map[kex2.SessionID][]kexDirectFrame),
+		changed:      make(chan struct{}),
+	}
+}
+
+// ListenKexDirect starts listening on address for the other device, and
+// returns the code it needs to connect. address is a TCP address, or
+// "unix:" and a socket path. Only a device that has secret can connect.
+func ListenKexDirect(g *GlobalContext, address string, secret kex2.Secret) (*KexDirectRouter, *Kex2DirectCode, error) {
+	network := "tcp"
+	if strings.HasPrefix(address, "unix:") {
+		network, address = "unix", address[len("unix:"):]
+	}
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, nil, err
+	}
+	code := &Kex2DirectCode{Network: network, Address: l.Addr().String(), Secret: secret}
+	if network == "tcp" {
+		if code.Address, err = kexDirectAdvertiseAddr(l.Addr()); err != nil {
+			l.Close()
+			return nil, nil, err
+		}
+	}
+
+	r := newKexDirectRouter(g)
+	r.listener = l
+	go r.acceptLoop(l, kexDirectHello(secret))
+	g.Log.Debug("| KexDirectRouter listening on %s", code.Address)
+	return r, code, nil
+}
+
+// DialKexDirect connects to the device that showed code.
+func DialKexDirect(g *GlobalContext, code *Kex2DirectCode) (*KexDirectRouter, error) {
+	g.Log.Debug("| KexDirectRouter dialing %s %s", code.Network, code.Address)
+	conn, err := net.DialTimeout(code.Network, code.Address, kexDirectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetWriteDeadline(time.Now().Add(kexDirectTimeout))
+	if _, err = conn.Write(kexDirectHello(code.Secret)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetWriteDeadline(time.Time{})
+	r := newKexDirectRouter(g)
+	r.setConn(conn)
+	return r, nil
+}
+
+// kexDirectAdvertiseAddr picks an address the other device can use to reach
+// a listener. If it's listening on all interfaces, that's the first
+// non-loopback IPv4 address this machine has.
+func kexDirectAdvertiseAddr(addr net.Addr) (string, error) {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok || !tcpAddr.IP.IsUnspecified() {
+		return addr.String(), nil
+	}
+	port := fmt.Sprintf("%d", tcpAddr.Port)
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			return net.JoinHostPort(ipnet.IP.String(), port), nil
+		}
+	}
+	return net.JoinHostPort("127.0.0.1", port), nil
+}
+
+func (r *KexDirectRouter) acceptLoop(l net.Listener, hello []byte) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			r.G().Log.Debug("| KexDirectRouter stopped listening: %s", err)
+			return
+		}
+		// Wait for each hello separately, so that a connection that never
+		// sends one doesn't hold up the others.
+		go r.checkHello(conn, hello)
+	}
+}
+
+func (r *KexDirectRouter) checkHello(conn net.Conn, hello []byte) {
+	buf := make([]byte, len(hello))
+	conn.SetReadDeadline(time.Now().Add(kexDirectTimeout))
+	if _, err := io.ReadFull(conn, buf); err != nil || !hmac.Equal(buf, hello) {
+		r.G().Log.Debug("| KexDirectRouter rejected a connection from %s", conn.RemoteAddr())
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	r.setConn(conn)
+}
+
+func (r *KexDirectRouter) setConn(conn net.Conn) {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+	r.Lock()
+	if r.err != nil || r.conn != nil {
+		r.Unlock()
+		conn.Close()
+		return
+	}
+	r.conn = conn
+	if r.listener != nil {
+		// Only one device gets to connect.
+		r.listener.Close()
+		r.listener = nil
+	}
+	outbox := r.outbox
+	r.outbox = nil
+	r.Unlock()
+
+	for _, frame := range outbox {
+		if _, err := conn.Write(frame); err != nil {
+			r.setErr(err)
+			break
+		}
+	}
+	go r.readLoop(conn)
+}
+
+func (r *KexDirectRouter) setErr(err error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.err == nil {
+		r.err = err
+		close(r.changed)
+		r.changed = make(chan struct{})
+	}
+}
+
+func (r *KexDirectRouter) readLoop(conn net.Conn) {
+	var hdr [4]byte
+	for {
+		if _, err := io.ReadFull(conn, hdr[:]); err != nil {
+			r.setErr(err)
+			return
+		}
+		n := binary.BigEndian.Uint32(hdr[:])
+		if n > kexDirectMaxFrame {
+			r.setErr(fmt.Errorf("direct kex2 frame too big (%d bytes)", n))
+			return
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			r.setErr(err)
+			return
+		}
+		var frame kexDirectFrame
+		if err := MsgpackDecodeAll(buf, codecHandle(), &frame); err != nil {
+			r.setErr(err)
+			return
+		}
+		r.Lock()
+		r.inbox[frame.SessionID] = append(r.inbox[frame.SessionID], frame)
+		close(r.changed)
+		r.changed = make(chan struct{})
+		r.Unlock()
+	}
+}
+
+// Post implements Post in the kex2.MessageRouter interface. If the other
+// device hasn't connected yet, the message is sent when it does.
+func (r *KexDirectRouter) Post(sessID kex2.SessionID, sender kex2.DeviceID, seqno kex2.Seqno, msg []byte) (err error) {
+	r.G().Log.Debug("+ KexDirectRouter.Post(%x, %x, %d, ...)", sessID, sender, seqno)
+	defer func() {
+		r.G().Log.Debug("- KexDirectRouter.Post(%x, %x, %d) -> %s", sessID, sender, seqno, ErrToOk(err))
+	}()
+
+	var body []byte
+	frame := kexDirectFrame{SessionID: sessID, Sender: sender, Seqno: seqno, Msg: msg}
+	if err = codec.NewEncoderBytes(&body, codecHandle()).Encode(frame); err != nil {
+		return err
+	}
+	buf := make([]byte, 4, 4+len(body))
+	binary.BigEndian.PutUint32(buf, uint32(len(body)))
+	buf = append(buf, body...)
+
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+	r.Lock()
+	conn, err := r.conn, r.err
+	if conn == nil && err == nil {
+		r.outbox = append(r.outbox, buf)
+	}
+	r.Unlock()
+	if conn == nil || err != nil {
+		return err
+	}
+	if _, err = conn.Write(buf); err != nil {
+		r.setErr(err)
+	}
+	return err
+}
+
+// Get implements Get in the kex2.MessageRouter interface.
+func (r *KexDirectRouter) Get(sessID kex2.SessionID, receiver kex2.DeviceID, low kex2.Seqno, poll time.Duration) (msgs [][]byte, err error) {
+	r.G().Log.Debug("+ KexDirectRouter.Get(%x, %x, %d, %s)", sessID, receiver, low, poll)
+	defer func() {
+		r.G().Log.Debug("- KexDirectRouter.Get(%x, %x, %d, %s) -> %s (messages: %d)", sessID, receiver, low, poll, ErrToOk(err), len(msgs))
+	}()
+
+	deadline := time.Now().Add(poll)
+	for {
+		r.Lock()
+		for _, frame := range r.inbox[sessID] {
+			if frame.Seqno >= low && !frame.Sender.Eq(receiver) {
+				msgs = append(msgs, frame.Msg)
+			}
+		}
+		delete(r.inbox, sessID)
+		err, changed := r.err, r.changed
+		r.Unlock()
+
+		if len(msgs) > 0 {
+			return msgs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		wait := deadline.Sub(time.Now())
+		if wait <= 0 {
+			return nil, nil
+		}
+		select {
+		case <-changed:
+		case <-time.After(wait):
+			return nil, nil
+		}
+	}
+}
+
+// Close stops listening, and closes the connection to the other device.
+func (r *KexDirectRouter) Close() error {
+	r.setErr(ErrKexDirectClosed)
+	r.Lock()
+	defer r.Unlock()
+	var errs []error
+	if r.listener != nil {
+		errs = append(errs, r.listener.Close())
+	}
+	if r.conn != nil {
+		errs = append(errs, r.conn.Close())
+	}
+	return CombineErrors(errs...)
+}
@@ -0,0 +1,153 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keybase/client/go/kex2"
+	"golang.org/x/net/context"
+)
+
+func TestKex2DirectCode(t *testing.T) {
+	var secret kex2.Secret
+	if _, err := rand.Read(secret[:]); err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []Kex2DirectCode{
+		{Network: "tcp", Address: "192.168.1.5:40157", Secret: secret},
+		{Network: "tcp", Address: "[fe80::1]:40157", Secret: secret},
+		{Network: "unix", Address: "/tmp/kex#2.sock", Secret: secret},
+	} {
+		s := code.String()
+		if !IsKex2DirectCode(s) {
+			t.Errorf("%q isn't a direct code", s)
+		}
+		parsed, err := ParseKex2DirectCode(s)
+		if err != nil {
+			t.Errorf("%q: %s", s, err)
+			continue
+		}
+		if *parsed != code {
+			t.Errorf("round trip: %+v, expected %+v", parsed, code)
+		}
+	}
+
+	for _, bad := range []string{
+		"shrimp cocktail zebra",
+		"keybase-kex2+udp://1.2.3.4:5#AAAA",
+		"keybase-kex2+tcp://1.2.3.4:5",
+		"keybase-kex2+tcp://1.2.3.4:5#AAAA",
+	} {
+		if _, err := ParseKex2DirectCode(bad); err == nil {
+			t.Errorf("expected an error parsing %q", bad)
+		}
+	}
+}
+
+// testKex2Direct runs a kex2.Conn in each direction over a pair of direct
+// routers.
+func testKex2Direct(t *testing.T, tc TestContext, address string) {
+	var secret kex2.Secret
+	if _, err := rand.Read(secret[:]); err != nil {
+		t.Fatal(err)
+	}
+	listener, code, err := ListenKexDirect(tc.G, address, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// Someone who doesn't have the secret can't get in the way.
+	wrong := *code
+	wrong.Secret[0] ^= 1
+	intruder, err := DialKexDirect(tc.G, &wrong)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = intruder.Get(kex2.SessionID{}, kex2.DeviceID{}, 0, 5*time.Second); err == nil {
+		t.Error("expected the intruder to be hung up on")
+	}
+	intruder.Close()
+
+	// Nor can someone who connects and then says nothing.
+	idle, err := net.Dial(code.Network, code.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idle.Close()
+
+	parsed, err := ParseKex2DirectCode(code.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dialer, err := DialKexDirect(tc.G, parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dialer.Close()
+
+	var x, y kex2.DeviceID
+	x[0], y[0] = 'x', 'y'
+	connX, err := kex2.NewConn(context.Background(), listener, secret, x, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	connY, err := kex2.NewConn(context.Background(), dialer, parsed.Secret, y, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range []struct {
+		from, to net.Conn
+		text     string
+	}{
+		{connY, connX, "hello from y"},
+		{connX, connY, "hello back from x"},
+		{connY, connX, "goodbye"},
+	} {
+		if _, err = m.from.Write([]byte(m.text)); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 100)
+		n, err := m.to.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf[:n]) != m.text {
+			t.Errorf("read %q, expected %q", buf[:n], m.text)
+		}
+	}
+
+	if err = connX.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = connY.Read(make([]byte, 100)); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestKex2DirectTCP(t *testing.T) {
+	tc := SetupTest(t, "kex2 direct tcp")
+	defer tc.Cleanup()
+	testKex2Direct(t, tc, "127.0.0.1:0")
+}
+
+func TestKex2DirectUnix(t *testing.T) {
+	tc := SetupTest(t, "kex2 direct unix")
+	defer tc.Cleanup()
+	dir, err := ioutil.TempDir("", "kex2direct")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	testKex2Direct(t, tc, "unix:"+filepath.Join(dir, "kex.sock"))
+}
@@ -0,0 +1,78 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"sort"
+	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// ExpiringKeys returns the active keys in the key family that expire
+// within window of now, soonest first. A PGP key expires when either its
+// delegation or its own self signature does.
+func (ckf *ComputedKeyFamily) ExpiringKeys(now time.Time, window time.Duration) []keybase1.ExpiringKey {
+	var ret []keybase1.ExpiringKey
+	keys := append(ckf.GetAllActiveSibkeysAtTime(now), ckf.GetAllActiveSubkeysAtTime(now)...)
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		kid := key.GetKID()
+		info, found := ckf.cki.Infos[kid]
+		if !found {
+			continue
+		}
+		etime := info.GetETime()
+		res := keybase1.ExpiringKey{
+			Kid:      kid,
+			IsSibkey: info.Sibkey,
+		}
+		if pgp, ok := key.(*PGPKeyBundle); ok {
+			res.PGPFingerprint = pgp.GetFingerprint().String()
+			if pgpETime := pgp.ExpirationTime(); !pgpETime.IsZero() && (etime.IsZero() || pgpETime.Before(etime)) {
+				etime = pgpETime
+			}
+		}
+		if etime.IsZero() || etime.Sub(now) > window {
+			continue
+		}
+		if device, err := ckf.GetDeviceForKID(kid); err == nil && device != nil && device.Description != nil {
+			res.DeviceName = *device.Description
+		}
+		res.Etime = keybase1.ToTime(etime)
+		ret = append(ret, res)
+	}
+	sort.Sort(expiringKeysByETime(ret))
+	return ret
+}
+
+type expiringKeysByETime []keybase1.ExpiringKey
+
+func (e expiringKeysByETime) Len() int           { return len(e) }
+func (e expiringKeysByETime) Less(i, j int) bool { return e[i].Etime < e[j].Etime }
+func (e expiringKeysByETime) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+// CheckKeyExpiry looks for keys of the current user that will expire
+// within the configured window, and sends a KeysExpiring notification if
+// there are any.
+func CheckKeyExpiry(g *GlobalContext) ([]keybase1.ExpiringKey, error) {
+	arg := NewLoadUserArg(g)
+	arg.PublicKeyOptional = true
+	me, err := LoadMe(arg)
+	if err != nil {
+		return nil, err
+	}
+	ckf := me.GetComputedKeyFamily()
+	if ckf == nil {
+		return nil, nil
+	}
+	keys := ckf.ExpiringKeys(g.GetClock().Now(), g.Env.GetKeyExpiryWindow())
+	if len(keys) > 0 {
+		g.Log.Debug("%d key(s) expiring soon", len(keys))
+		g.NotifyRouter.HandleKeysExpiring(me.GetUID(), keys)
+	}
+	return keys, nil
+}
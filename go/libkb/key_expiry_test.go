@@ -0,0 +1,129 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+const day = 24 * time.Hour
+
+func TestExpiringKeys(t *testing.T) {
+	tc := SetupTest(t, "expiring keys")
+	defer tc.Cleanup()
+
+	now := time.Now()
+	arg := PGPGenArg{
+		PrimaryBits:     768,
+		SubkeyBits:      768,
+		Ids:             Identities{Identity{Email: "expiry@keybase.io"}},
+		PrimaryLifetime: int(5 * day / time.Second),
+		SubkeyLifetime:  int(5 * day / time.Second),
+	}
+	if err := arg.Init(); err != nil {
+		t.Fatal(err)
+	}
+	pgp, err := GeneratePGPKeyBundle(arg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := pgp.ExpirationTime().Sub(now.Add(5 * day)); d > time.Minute || d < -time.Minute {
+		t.Fatalf("PGP key expires at %s, expected 5 days from now", pgp.ExpirationTime())
+	}
+
+	soon, err := GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	later, err := GenerateNaclDHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	never, err := GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, err := GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kf := &KeyFamily{
+		AllKIDs:      make(map[keybase1.KID]bool),
+		SingleKeys:   make(map[keybase1.KID]GenericKey),
+		Contextified: NewContextified(tc.G),
+	}
+	cki := NewComputedKeyInfos(tc.G)
+	add := func(key GenericKey, sibkey bool, status KeyStatus, etime time.Time) {
+		kf.AllKIDs[key.GetKID()] = true
+		kf.SingleKeys[key.GetKID()] = key
+		var e int64
+		if !etime.IsZero() {
+			e = etime.Unix()
+		}
+		info := NewComputedKeyInfo(false, sibkey, status, now.Unix(), e, "")
+		cki.Insert(key.GetKID(), &info)
+	}
+	add(pgp, true, KeyUncancelled, now.Add(16*365*day))
+	add(soon, true, KeyUncancelled, now.Add(10*day))
+	add(later, false, KeyUncancelled, now.Add(60*day))
+	add(never, true, KeyUncancelled, time.Time{})
+	add(revoked, true, KeyRevoked, now.Add(day))
+	ckf := ComputedKeyFamily{kf: kf, cki: cki, Contextified: NewContextified(tc.G)}
+
+	keys := ckf.ExpiringKeys(now, 30*day)
+	if len(keys) != 2 {
+		t.Fatalf("got %d expiring keys, expected 2: %+v", len(keys), keys)
+	}
+	if !keys[0].Kid.Equal(pgp.GetKID()) || keys[0].PGPFingerprint != pgp.GetFingerprint().String() {
+		t.Errorf("first expiring key is %+v, expected the PGP key", keys[0])
+	}
+	if !keys[1].Kid.Equal(soon.GetKID()) || !keys[1].IsSibkey {
+		t.Errorf("second expiring key is %+v, expected the sibkey expiring in 10 days", keys[1])
+	}
+	if keys := ckf.ExpiringKeys(now, 90*day); len(keys) != 3 {
+		t.Errorf("got %d keys expiring within 90 days, expected 3", len(keys))
+	}
+
+	// Extending the PGP key's expiration takes it off the list, and the new
+	// self signatures survive a round trip.
+	etime := now.Add(365 * day).Truncate(time.Second)
+	skipped, err := pgp.ExtendExpiration(etime, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("skipped subkeys %v", skipped)
+	}
+	if got := pgp.ExpirationTime(); !got.Equal(etime) {
+		t.Errorf("PGP key expires at %s, expected %s", got, etime)
+	}
+	if keys := ckf.ExpiringKeys(now, 30*day); len(keys) != 1 || !keys[0].Kid.Equal(soon.GetKID()) {
+		t.Errorf("after extending, expiring keys are %+v", keys)
+	}
+
+	armored, err := pgp.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ReadOneKeyFromString(armored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.ExpirationTime(); !got.Equal(etime) {
+		t.Errorf("parsed PGP key expires at %s, expected %s", got, etime)
+	}
+	for _, subkey := range parsed.Subkeys {
+		if subkey.Sig.KeyLifetimeSecs == nil {
+			t.Errorf("subkey %x lost its expiration", subkey.PublicKey.KeyId)
+		}
+	}
+
+	if _, err := pgp.ExtendExpiration(now.Add(-day), now); err == nil {
+		t.Error("expected an error making the key expire in the past")
+	}
+}
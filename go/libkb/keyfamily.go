@@ -303,6 +303,7 @@ func (ckf ComputedKeyFamily) InsertEldestLink(tcl TypedChainLink, username Norma
 	}
 
 	eldestCki := NewComputedKeyInfo(true, true, KeyUncancelled, ctime, etime, tcl.GetPGPFullHash())
+	eldestCki.DelegatedAt = TclToKeybaseTime(tcl)
 
 	ckf.cki.Insert(kid, &eldestCki)
 	return nil
@@ -437,6 +438,37 @@ func (ckf ComputedKeyFamily) FindActiveSibkeyAtTime(kid keybase1.KID, t time.Tim
 	return
 }
 
+// FindSibkeyAtMerkleSeqno finds the sibkey with the given KID as it stood
+// at the given merkle root: it must have been delegated in a link that
+// points at an earlier root, and not revoked in one. A link that points at
+// the root itself was signed after that root was published, so it doesn't
+// count. Links with no merkle seqno count as delegations before the root,
+// but as revocations before it too.
+func (ckf ComputedKeyFamily) FindSibkeyAtMerkleSeqno(kid keybase1.KID, seqno Seqno) (key GenericKey, cki ComputedKeyInfo, err error) {
+	ki := ckf.cki.Infos[kid]
+	switch {
+	case ki == nil:
+		err = NoKeyError{fmt.Sprintf("The key '%s' wasn't found", kid)}
+	case !ki.Sibkey:
+		err = BadKeyError{fmt.Sprintf("The key '%s' wasn't delegated as a sibkey", kid)}
+	case ki.DelegatedAt == nil || Seqno(ki.DelegatedAt.Chain) >= seqno:
+		err = NoKeyError{fmt.Sprintf("The key '%s' wasn't delegated as of merkle root %d", kid, seqno)}
+	case ki.Status != KeyUncancelled && (ki.RevokedAt == nil || Seqno(ki.RevokedAt.Chain) < seqno):
+		err = KeyRevokedError{fmt.Sprintf("The key '%s' was revoked as of merkle root %d", kid, seqno)}
+	default:
+		key, err = ckf.FindKeyWithKIDUnsafe(kid)
+		cki = *ki
+	}
+	return
+}
+
+// IsRevokedSubkey returns whether kid is one of the user's subkeys that
+// has been revoked.
+func (ckf ComputedKeyFamily) IsRevokedSubkey(kid keybase1.KID) bool {
+	ki := ckf.cki.Infos[kid]
+	return ki != nil && !ki.Sibkey && ki.Status == KeyRevoked
+}
+
 // FindActiveEncryptionSubkey takes a given KID and finds the corresponding
 // active encryption subkey in the current key family.  If for any reason it
 // cannot find the key, it will return an error saying why.  Otherwise, it will
@@ -0,0 +1,72 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+func TestFindSibkeyAtMerkleSeqno(t *testing.T) {
+	tc := SetupTest(t, "sibkey at merkle seqno")
+	defer tc.Cleanup()
+
+	kf := &KeyFamily{
+		AllKIDs:      make(map[keybase1.KID]bool),
+		SingleKeys:   make(map[keybase1.KID]GenericKey),
+		Contextified: NewContextified(tc.G),
+	}
+	cki := NewComputedKeyInfos(tc.G)
+	add := func(delegated, revoked int) keybase1.KID {
+		key, err := GenerateNaclSigningKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		kf.AllKIDs[key.GetKID()] = true
+		kf.SingleKeys[key.GetKID()] = key
+		status := KeyUncancelled
+		if revoked > 0 {
+			status = KeyRevoked
+		}
+		info := NewComputedKeyInfo(false, true, status, 0, 0, "")
+		info.DelegatedAt = &KeybaseTime{Chain: delegated}
+		if revoked > 0 {
+			info.RevokedAt = &KeybaseTime{Chain: revoked}
+		}
+		cki.Insert(key.GetKID(), &info)
+		return key.GetKID()
+	}
+	ckf := ComputedKeyFamily{kf: kf, cki: cki, Contextified: NewContextified(tc.G)}
+
+	const root = Seqno(100)
+	tests := []struct {
+		name      string
+		kid       keybase1.KID
+		delegated bool
+		revoked   bool
+	}{
+		{"delegated before the root", add(99, 0), true, false},
+		{"delegated at the root", add(100, 0), false, false},
+		{"delegated after the root", add(101, 0), false, false},
+		{"revoked before the root", add(50, 99), true, true},
+		{"revoked at the root", add(50, 100), true, false},
+		{"revoked after the root", add(50, 101), true, false},
+	}
+	for _, test := range tests {
+		_, _, err := ckf.FindSibkeyAtMerkleSeqno(test.kid, root)
+		switch {
+		case !test.delegated:
+			if _, ok := err.(NoKeyError); !ok {
+				t.Errorf("%s: got %v, expected a NoKeyError", test.name, err)
+			}
+		case test.revoked:
+			if _, ok := err.(KeyRevokedError); !ok {
+				t.Errorf("%s: got %v, expected a KeyRevokedError", test.name, err)
+			}
+		case err != nil:
+			t.Errorf("%s: %s", test.name, err)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/syndtr/goleveldb/leveldb"
 	errors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 type LevelDb struct {
@@ -170,3 +171,24 @@ func (l *LevelDb) Delete(id DbKey) error {
 	err := l.db.Delete(id.ToBytes("kv"), nil)
 	return err
 }
+
+// KeysWithType returns the keys of all the objects of the given type.
+func (l *LevelDb) KeysWithType(typ ObjType) ([]DbKey, error) {
+	// Lazy Open
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	prefix := DbKey{Typ: typ}.ToBytes("kv")
+	iter := l.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+	var ret []DbKey
+	for iter.Next() {
+		_, key, err := DbKeyParse(string(iter.Key()))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, *key)
+	}
+	return ret, iter.Error()
+}
@@ -27,6 +27,8 @@ type LinkCache struct {
 	maxSize   int
 
 	accessOrder *list.List
+
+	cacheCounter
 }
 
 // NewLinkCache creates a LinkCache. When finished using this
@@ -62,6 +64,7 @@ func (c *LinkCache) Get(id LinkID) (link ChainLink, ok bool) {
 	c.gets <- req
 
 	res := <-req.result
+	c.record(res.ok)
 	return res.link, res.ok
 }
 
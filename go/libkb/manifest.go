@@ -0,0 +1,158 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestEntry is the SHA256 hash of one file in a Manifest. Path is
+// relative to the manifest's directory, with forward slashes.
+type ManifestEntry struct {
+	Path string
+	Hash string
+}
+
+// Manifest lists the hashes of all the files under a directory, sorted
+// by path. It's what `keybase sign --manifest` signs, so that a whole
+// directory can be covered by one detached signature.
+type Manifest []ManifestEntry
+
+// MakeManifest hashes every regular file under dir. Any paths in exclude
+// are skipped (so that a manifest can be kept inside the directory it
+// covers). Symlinks and other special files are an error, since their
+// contents can't be pinned down by a hash.
+func MakeManifest(dir string, exclude ...string) (Manifest, error) {
+	skip := make(map[string]bool)
+	for _, p := range exclude {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		skip[abs] = true
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || skip[path] {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return BadManifestError{Msg: fmt.Sprintf("%s isn't a regular file", path)}
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.ContainsAny(rel, "\r\n") {
+			return BadManifestError{Msg: fmt.Sprintf("can't list %q, since it has a newline in it", rel)}
+		}
+		hash, err := DigestForFileAtPath(path)
+		if err != nil {
+			return err
+		}
+		m = append(m, ManifestEntry{Path: rel, Hash: hash})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(m)
+	return m, nil
+}
+
+func (m Manifest) Len() int           { return len(m) }
+func (m Manifest) Less(i, j int) bool { return m[i].Path < m[j].Path }
+func (m Manifest) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+
+// Encode returns the canonical form of the manifest, which is what gets
+// signed. It's one "<sha256 hex>  <path>" line per file, sorted by path,
+// the same as the output of sha256sum.
+func (m Manifest) Encode() []byte {
+	var buf bytes.Buffer
+	for _, e := range m {
+		fmt.Fprintf(&buf, "%s  %s\n", e.Hash, e.Path)
+	}
+	return buf.Bytes()
+}
+
+// ParseManifest parses the output of Manifest.Encode. It insists on the
+// canonical form, so that there's only one encoding of any manifest.
+func ParseManifest(b []byte) (Manifest, error) {
+	var m Manifest
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for i := 1; scanner.Scan(); i++ {
+		line := scanner.Text()
+		parts := strings.SplitN(line, "  ", 2)
+		if len(parts) != 2 || len(parts[0]) != 2*sha256.Size || len(parts[1]) == 0 {
+			return nil, BadManifestError{Msg: fmt.Sprintf("line %d is malformed", i)}
+		}
+		if _, err := hex.DecodeString(parts[0]); err != nil {
+			return nil, BadManifestError{Msg: fmt.Sprintf("line %d has a bad hash: %s", i, err)}
+		}
+		e := ManifestEntry{Path: parts[1], Hash: parts[0]}
+		if len(m) > 0 && e.Path <= m[len(m)-1].Path {
+			return nil, BadManifestError{Msg: fmt.Sprintf("line %d is out of order, or a duplicate", i)}
+		}
+		m = append(m, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(m.Encode(), b) {
+		return nil, BadManifestError{Msg: "not in canonical form"}
+	}
+	return m, nil
+}
+
+// ManifestDiff is the difference between a signed manifest and the
+// files that are there now.
+type ManifestDiff struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// Empty is true if nothing has changed.
+func (d ManifestDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// Diff compares m with current, both of which must be sorted.
+func (m Manifest) Diff(current Manifest) (d ManifestDiff) {
+	i, j := 0, 0
+	for i < len(m) || j < len(current) {
+		switch {
+		case j == len(current) || (i < len(m) && m[i].Path < current[j].Path):
+			d.Removed = append(d.Removed, m[i].Path)
+			i++
+		case i == len(m) || current[j].Path < m[i].Path:
+			d.Added = append(d.Added, current[j].Path)
+			j++
+		default:
+			if m[i].Hash != current[j].Hash {
+				d.Modified = append(d.Modified, m[i].Path)
+			}
+			i++
+			j++
+		}
+	}
+	return d
+}
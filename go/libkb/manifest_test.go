@@ -0,0 +1,102 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, contents string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("b.txt", "bee")
+	write("a.txt", "ay")
+	write("sub/c.txt", "sea")
+	write("sub/d.txt", "dee")
+	write("release.manifest", "not part of it")
+
+	m, err := MakeManifest(dir, filepath.Join(dir, "release.manifest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, e := range m {
+		paths = append(paths, e.Path)
+	}
+	if expected := []string{"a.txt", "b.txt", "sub/c.txt", "sub/d.txt"}; !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("paths: %v, expected %v", paths, expected)
+	}
+	if sum := sha256.Sum256([]byte("ay")); m[0].Hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("bad hash %q", m[0].Hash)
+	}
+
+	encoded := m.Encode()
+	parsed, err := ParseManifest(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, m) {
+		t.Errorf("round trip: %v, expected %v", parsed, m)
+	}
+	if d := m.Diff(parsed); !d.Empty() {
+		t.Errorf("expected no differences, got %+v", d)
+	}
+
+	write("b.txt", "bea")
+	write("sub/e.txt", "ee")
+	if err := os.Remove(filepath.Join(dir, "sub", "c.txt")); err != nil {
+		t.Fatal(err)
+	}
+	current, err := MakeManifest(dir, filepath.Join(dir, "release.manifest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := m.Diff(current)
+	expected := ManifestDiff{
+		Added:    []string{"sub/e.txt"},
+		Removed:  []string{"sub/c.txt"},
+		Modified: []string{"b.txt"},
+	}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("diff: %+v, expected %+v", d, expected)
+	}
+}
+
+func TestParseManifestNonCanonical(t *testing.T) {
+	hash := "0000000000000000000000000000000000000000000000000000000000000000"
+	bad := []string{
+		hash + "  b\n" + hash + "  a\n",
+		hash + "  a\n" + hash + "  a\n",
+		hash + " a\n",
+		hash + "  a",
+		hash[1:] + "  a\n",
+		hash + "  a\r\n",
+	}
+	for _, b := range bad {
+		if _, err := ParseManifest([]byte(b)); err == nil {
+			t.Errorf("expected an error parsing %q", b)
+		} else if _, ok := err.(BadManifestError); !ok {
+			t.Errorf("expected a BadManifestError parsing %q, got %T", b, err)
+		}
+	}
+}
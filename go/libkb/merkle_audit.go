@@ -0,0 +1,194 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+func merkleForksKey() DbKey {
+	return DbKey{
+		Typ: DBMerkleAudit,
+		Key: "forks",
+	}
+}
+
+func (mr *MerkleRoot) payloadHash() string {
+	h := sha256.Sum256([]byte(mr.payloadJSONString))
+	return hex.EncodeToString(h[:])
+}
+
+// Summary exports the parts of the root that the audit reports on.
+func (mr *MerkleRoot) Summary() keybase1.MerkleRootSummary {
+	return keybase1.MerkleRootSummary{
+		Seqno: int(mr.seqno),
+		Hash:  mr.payloadHash(),
+		Ctime: keybase1.TimeFromSeconds(mr.ctime),
+	}
+}
+
+// loadStoredRoot loads a root that we stored earlier, along with the seqno
+// of the HEAD it replaced. It returns a nil root if there isn't one. A
+// prevSeqno of -1 means the chain of stored roots ends here.
+func (mc *MerkleClient) loadStoredRoot(key DbKey, lookup bool) (mr *MerkleRoot, prevSeqno Seqno, err error) {
+	prevSeqno = -1
+	get := mc.G().LocalDb.Get
+	if lookup {
+		get = mc.G().LocalDb.Lookup
+	}
+	jw, err := get(key)
+	if err != nil || jw == nil {
+		return nil, prevSeqno, err
+	}
+	if mr, err = NewMerkleRootFromJSON(jw, mc.G()); err != nil {
+		return nil, prevSeqno, err
+	}
+	if pw := jw.AtKey("prev_seqno"); !pw.IsNil() {
+		var i int64
+		if i, err = pw.GetInt64(); err != nil {
+			return nil, prevSeqno, err
+		}
+		prevSeqno = Seqno(i)
+	}
+	return mr, prevSeqno, nil
+}
+
+// auditRoot checks a freshly-verified root against the roots we've already
+// stored, and stores it if it moves us forward. A root whose seqno we've
+// seen before with a different payload, or one that's older than our HEAD,
+// is recorded as a possible fork. Must be called with mc locked.
+func (mc *MerkleClient) auditRoot(root *MerkleRoot) {
+	prev, _, err := mc.loadStoredRoot(merkleRootKey(root.seqno), false)
+	if err != nil {
+		mc.G().Log.Warning("Cannot load Merkle root %d from local DB: %s", root.seqno, err)
+		return
+	}
+	if prev != nil {
+		if prev.payloadHash() != root.payloadHash() {
+			mc.recordFork(keybase1.MerkleForkType_EQUIVOCATION, prev, root)
+		}
+		return
+	}
+
+	head, _, err := mc.loadStoredRoot(merkleHeadKey(), true)
+	if err != nil {
+		mc.G().Log.Warning("Cannot load Merkle HEAD from local DB: %s", err)
+		return
+	}
+	if head != nil && head.seqno > root.seqno {
+		mc.recordFork(keybase1.MerkleForkType_ROLLBACK, head, root)
+		return
+	}
+
+	if err := root.Store(head); err != nil {
+		mc.G().Log.Errorf("Cannot commit Merkle root to local DB: %s", err)
+	}
+}
+
+func (mc *MerkleClient) loadForks() (forks []keybase1.MerkleFork, err error) {
+	_, err = mc.G().LocalDb.GetInto(&forks, merkleForksKey())
+	return forks, err
+}
+
+// recordFork saves the two conflicting roots as evidence, and lets
+// listeners on the audit channel know. The same conflict is only ever
+// recorded once.
+func (mc *MerkleClient) recordFork(typ keybase1.MerkleForkType, prev, root *MerkleRoot) {
+	fork := keybase1.MerkleFork{
+		Type:   typ,
+		Prev:   prev.Summary(),
+		Root:   root.Summary(),
+		SeenAt: keybase1.ToTime(time.Now()),
+	}
+	mc.G().Log.Warning("Possible Merkle tree fork (%s): had root %d (%s), got root %d (%s)",
+		typ, fork.Prev.Seqno, fork.Prev.Hash, fork.Root.Seqno, fork.Root.Hash)
+
+	forks, err := mc.loadForks()
+	if err != nil {
+		mc.G().Log.Errorf("Cannot load Merkle forks from local DB: %s", err)
+		return
+	}
+	for _, f := range forks {
+		if f.Type == fork.Type && f.Prev == fork.Prev && f.Root == fork.Root {
+			return
+		}
+	}
+
+	prevJSON, err := prev.ToJSON().Marshal()
+	if err != nil {
+		mc.G().Log.Errorf("Cannot export Merkle root: %s", err)
+		return
+	}
+	rootJSON, err := root.ToJSON().Marshal()
+	if err != nil {
+		mc.G().Log.Errorf("Cannot export Merkle root: %s", err)
+		return
+	}
+	fork.PrevJSON = string(prevJSON)
+	fork.RootJSON = string(rootJSON)
+	if err = mc.G().LocalDb.PutObj(merkleForksKey(), nil, append(forks, fork)); err != nil {
+		mc.G().Log.Errorf("Cannot commit Merkle fork to local DB: %s", err)
+	}
+	mc.G().NotifyRouter.HandleMerkleFork(fork)
+}
+
+// Audit walks back through the chain of roots that this client has stored,
+// starting at HEAD. It checks every root's signature again, that seqnos
+// strictly decrease as we go, and that the roots' ctimes never run
+// backwards. Roots that were stored before we kept a link to the previous
+// HEAD end the walk. It also returns all of the forks recorded so far.
+func (mc *MerkleClient) Audit() (res keybase1.MerkleAuditResult, err error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	if res.Forks, err = mc.loadForks(); err != nil {
+		return res, err
+	}
+
+	var next *MerkleRoot
+	key := merkleHeadKey()
+	lookup := true
+	for {
+		mr, prevSeqno, err := mc.loadStoredRoot(key, lookup)
+		if err != nil {
+			return res, err
+		}
+		if mr == nil {
+			if next != nil {
+				res.Problems = append(res.Problems, fmt.Sprintf("root %s is missing from the local DB", key.Key))
+			}
+			break
+		}
+
+		s := mr.Summary()
+		if res.Latest == nil {
+			res.Latest = &s
+		}
+		res.Oldest = &s
+		res.NumRoots++
+
+		if e2 := mc.verifyRootSig(mr); e2 != nil {
+			res.Problems = append(res.Problems, fmt.Sprintf("root %d: bad signature: %s", mr.seqno, e2))
+		}
+		if next != nil && next.ctime < mr.ctime {
+			res.Problems = append(res.Problems, fmt.Sprintf("root %d is older than root %d", next.seqno, mr.seqno))
+		}
+		if prevSeqno < 0 {
+			break
+		}
+		if prevSeqno >= mr.seqno {
+			res.Problems = append(res.Problems, fmt.Sprintf("root %d links back to root %d", mr.seqno, prevSeqno))
+			break
+		}
+		next = mr
+		key = merkleRootKey(prevSeqno)
+		lookup = false
+	}
+	return res, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+// fakeMerkleRoot makes an unsigned root; auditRoot only runs after the
+// signature has been checked, so it doesn't look at the sigs.
+func fakeMerkleRoot(t *testing.T, g *GlobalContext, seqno int, ctime int64, root string) *MerkleRoot {
+	payload := fmt.Sprintf(`{"body":{"key":{"fingerprint":"%s"},"seqno":%d,"root":"%s"},"ctime":%d}`,
+		strings.Repeat("ab", 20), seqno, strings.Repeat(root, 64), ctime)
+	jw := jsonw.NewDictionary()
+	jw.SetKey("sigs", jsonw.NewDictionary())
+	jw.SetKey("payload_json", jsonw.NewString(payload))
+	mr, err := NewMerkleRootFromJSON(jw, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mr
+}
+
+func TestMerkleAuditRoots(t *testing.T) {
+	tc := SetupTest(t, "merkle_audit")
+	defer tc.Cleanup()
+
+	mc := NewMerkleClient(tc.G)
+	mc.auditRoot(fakeMerkleRoot(t, tc.G, 5, 1000, "a"))
+	mc.auditRoot(fakeMerkleRoot(t, tc.G, 8, 1010, "b"))
+	mc.auditRoot(fakeMerkleRoot(t, tc.G, 8, 1010, "b"))
+
+	head, prev, err := mc.loadStoredRoot(merkleHeadKey(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head == nil || head.seqno != 8 || prev != 5 {
+		t.Fatalf("bad HEAD after two roots: %v, prev=%d", head, prev)
+	}
+	if forks, _ := mc.loadForks(); len(forks) != 0 {
+		t.Fatalf("unexpected forks: %+v", forks)
+	}
+
+	// A different root 8, and then an older root, twice each.
+	for i := 0; i < 2; i++ {
+		mc.auditRoot(fakeMerkleRoot(t, tc.G, 8, 1010, "c"))
+		mc.auditRoot(fakeMerkleRoot(t, tc.G, 6, 1005, "d"))
+	}
+	forks, err := mc.loadForks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(forks) != 2 {
+		t.Fatalf("got %d forks, wanted 2", len(forks))
+	}
+	if forks[0].Type != keybase1.MerkleForkType_EQUIVOCATION || forks[0].Prev.Seqno != 8 || forks[0].Root.Seqno != 8 {
+		t.Errorf("bad equivocation: %+v", forks[0])
+	}
+	if forks[1].Type != keybase1.MerkleForkType_ROLLBACK || forks[1].Prev.Seqno != 8 || forks[1].Root.Seqno != 6 {
+		t.Errorf("bad rollback: %+v", forks[1])
+	}
+
+	// The first root 8 is still HEAD, and the rollback wasn't stored.
+	head, _, err = mc.loadStoredRoot(merkleHeadKey(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.payloadHash() != fakeMerkleRoot(t, tc.G, 8, 1010, "b").payloadHash() {
+		t.Errorf("HEAD was overwritten")
+	}
+
+	res, err := mc.Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.NumRoots != 2 || res.Latest.Seqno != 8 || res.Oldest.Seqno != 5 || len(res.Forks) != 2 {
+		t.Errorf("bad audit result: %+v", res)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	keybase1 "github.com/keybase/client/go/protocol"
 	jsonw "github.com/keybase/go-jsonw"
@@ -53,12 +54,16 @@ type MerkleClient struct {
 
 	keyring *SpecialKeyRing
 
-	// Blocks that have been verified
-	verified map[Seqno]bool
+	// Blocks that have been verified, mapped to their payload hashes
+	verified map[Seqno]string
 
 	// The most recently-available root
 	lastRoot *MerkleRoot
 
+	// The newest root verified, and when, for the metrics endpoint
+	latestRoot         *MerkleRoot
+	latestRootVerified time.Time
+
 	// protects whole object
 	sync.RWMutex
 }
@@ -88,6 +93,7 @@ type MerkleUserLeaf struct {
 	username  string
 	uid       keybase1.UID
 	eldest    keybase1.KID // may be empty
+	root      Seqno        // the merkle root this leaf was found in
 }
 
 type PathSteps []*PathStep
@@ -149,7 +155,7 @@ func GetNodeHashVoid(w *jsonw.Wrapper, nhp *NodeHash, errp *error) {
 func NewMerkleClient(g *GlobalContext) *MerkleClient {
 	return &MerkleClient{
 		keyring:      NewSpecialKeyRing(g.Env.GetMerkleKIDs(), g),
-		verified:     make(map[Seqno]bool),
+		verified:     make(map[Seqno]string),
 		lastRoot:     nil,
 		Contextified: NewContextified(g),
 	}
@@ -187,15 +193,22 @@ func (mc *MerkleClient) LoadRoot() error {
 	return nil
 }
 
-func (mr *MerkleRoot) Store() error {
-	err := mr.G().LocalDb.Put(DbKey{
+func merkleRootKey(seqno Seqno) DbKey {
+	return DbKey{
 		Typ: DBMerkleRoot,
-		Key: fmt.Sprintf("%d", mr.seqno),
-	},
-		[]DbKey{merkleHeadKey()},
-		mr.ToJSON(),
-	)
-	return err
+		Key: fmt.Sprintf("%d", seqno),
+	}
+}
+
+// Store writes the root to the local DB and makes it the new HEAD. The
+// stored root links back to prev, the HEAD it replaces, so that the roots
+// we've seen form a chain that MerkleClient.Audit can walk.
+func (mr *MerkleRoot) Store(prev *MerkleRoot) error {
+	jw := mr.ToJSON()
+	if prev != nil {
+		jw.SetKey("prev_seqno", jsonw.NewInt64(int64(prev.seqno)))
+	}
+	return mr.G().LocalDb.Put(merkleRootKey(mr.seqno), []DbKey{merkleHeadKey()}, jw)
 }
 
 func (mr *MerkleRoot) ToJSON() (jw *jsonw.Wrapper) {
@@ -290,12 +303,19 @@ func (mc *MerkleClient) LookupPath(q HTTPArgs) (vp *VerificationPath, err error)
 		return
 	}
 
-	root, err := NewMerkleRootFromJSON(res.Body.AtKey("root"), mc.G())
+	return NewVerificationPathFromJSON(mc.G(), res.Body)
+}
+
+// NewVerificationPathFromJSON imports the body of a merkle/path reply. The
+// result is unverified; call VerifyUser and VerifyUsername on it, after the
+// root has been checked.
+func NewVerificationPathFromJSON(g *GlobalContext, body *jsonw.Wrapper) (vp *VerificationPath, err error) {
+	root, err := NewMerkleRootFromJSON(body.AtKey("root"), g)
 	if err != nil {
 		return
 	}
 
-	uid, err := GetUID(res.Body.AtKey("uid"))
+	uid, err := GetUID(body.AtKey("uid"))
 	if err != nil {
 		return
 	}
@@ -303,26 +323,26 @@ func (mc *MerkleClient) LookupPath(q HTTPArgs) (vp *VerificationPath, err error)
 	// We don't trust this version, but it's useful to tell us if there
 	// are new versions unsigned data, like basics, and maybe uploaded
 	// keys
-	idv, err := res.Body.AtKey("id_version").GetInt64()
+	idv, err := body.AtKey("id_version").GetInt64()
 	if err != nil {
 		return
 	}
 
-	pathOut, err := importPathFromJSON(res.Body.AtKey("path"))
+	pathOut, err := importPathFromJSON(body.AtKey("path"))
 	if err != nil {
 		return
 	}
 
-	uidPathOut, err := importPathFromJSON(res.Body.AtKey("uid_proof_path"))
+	uidPathOut, err := importPathFromJSON(body.AtKey("uid_proof_path"))
 	if err != nil {
 		return
 	}
 
-	username, err := res.Body.AtKey("username").GetString()
+	username, err := body.AtKey("username").GetString()
 	if err != nil {
 		return
 	}
-	usernameCased, _ := res.Body.AtKey("username_cased").GetString()
+	usernameCased, _ := body.AtKey("username_cased").GetString()
 
 	vp = &VerificationPath{
 		uid:           uid,
@@ -332,7 +352,7 @@ func (mc *MerkleClient) LookupPath(q HTTPArgs) (vp *VerificationPath, err error)
 		idVersion:     idv,
 		username:      username,
 		usernameCased: usernameCased,
-		Contextified:  NewContextified(mc.G()),
+		Contextified:  NewContextified(g),
 	}
 	return
 }
@@ -365,13 +385,28 @@ func (mc *MerkleClient) LastSeqno() Seqno {
 	return -1
 }
 
+// LatestRoot returns the seqno and ctime of the newest merkle root that's
+// been verified, and when it was. The seqno is -1 if none has been.
+func (mc *MerkleClient) LatestRoot() (seqno Seqno, ctime time.Time, verified time.Time) {
+	mc.RLock()
+	defer mc.RUnlock()
+	if mc.latestRoot == nil {
+		return -1, ctime, verified
+	}
+	return mc.latestRoot.seqno, time.Unix(mc.latestRoot.ctime, 0), mc.latestRootVerified
+}
+
 func (mc *MerkleClient) findValidKIDAndSig(root *MerkleRoot) (keybase1.KID, string, error) {
-	if v, err := root.sigs.Keys(); err == nil {
+	return root.findValidKIDAndSig(mc.keyring)
+}
+
+func (mr *MerkleRoot) findValidKIDAndSig(keyring *SpecialKeyRing) (keybase1.KID, string, error) {
+	if v, err := mr.sigs.Keys(); err == nil {
 		for _, s := range v {
 			kid := keybase1.KIDFromString(s)
-			if !mc.keyring.IsValidKID(kid) {
+			if !keyring.IsValidKID(kid) {
 				continue
-			} else if sig, err := root.sigs.AtKey(s).AtKey("sig").GetString(); err == nil {
+			} else if sig, err := mr.sigs.AtKey(s).AtKey("sig").GetString(); err == nil {
 				return kid, sig, nil
 			}
 		}
@@ -382,24 +417,41 @@ func (mc *MerkleClient) findValidKIDAndSig(root *MerkleRoot) (keybase1.KID, stri
 
 func (mc *MerkleClient) VerifyRoot(root *MerkleRoot) error {
 
-	// First make sure it's not a rollback
 	q := mc.LastSeqno()
+
+	mc.Lock()
+	defer mc.Unlock()
+
+	// Maybe we've already verified (and audited) it before.
+	if hash, found := mc.verified[root.seqno]; !found || hash != root.payloadHash() {
+		if err := mc.verifyRootSig(root); err != nil {
+			return err
+		}
+
+		// Only audit signed roots, since they're the ones that count as
+		// evidence against the server.
+		mc.auditRoot(root)
+
+		mc.verified[root.seqno] = root.payloadHash()
+	}
+
+	// Make sure it's not a rollback
 	if q >= 0 && q > root.seqno {
 		return fmt.Errorf("Server rolled back Merkle tree: %d > %d",
 			q, root.seqno)
 	}
 
-	mc.G().Log.Debug("| Merkle root: got back %d, >= cached %d", int(root.seqno), int(q))
+	if mc.latestRoot == nil || root.seqno >= mc.latestRoot.seqno {
+		mc.latestRoot = root
+		mc.latestRootVerified = mc.G().GetClock().Now()
+	}
 
-	mc.Lock()
-	defer mc.Unlock()
+	mc.G().Log.Debug("| Merkle root: got back %d, >= cached %d", int(root.seqno), int(q))
 
-	// Maybe we've already verified it before.
-	verified, found := mc.verified[root.seqno]
-	if verified && found {
-		return nil
-	}
+	return nil
+}
 
+func (mc *MerkleClient) verifyRootSig(root *MerkleRoot) error {
 	kid, sig, err := mc.findValidKIDAndSig(root)
 	if err != nil {
 		return err
@@ -411,8 +463,6 @@ func (mc *MerkleClient) VerifyRoot(root *MerkleRoot) error {
 		return err
 	}
 
-	mc.G().Log.Debug("- Merkle: server sig verified")
-
 	if key == nil {
 		return MerkleClientError{"no known verifying key"}
 	}
@@ -423,15 +473,24 @@ func (mc *MerkleClient) VerifyRoot(root *MerkleRoot) error {
 		return err
 	}
 
-	if e2 := root.Store(); e2 != nil {
-		mc.G().Log.Errorf("Cannot commit Merkle root to local DB: %s", e2)
-	}
-
-	mc.verified[root.seqno] = true
+	mc.G().Log.Debug("- Merkle: server sig verified")
 
 	return nil
 }
 
+// RootSigningKey returns the blessed key that signed the given root, fetching
+// it from the server if it isn't cached.
+func (mc *MerkleClient) RootSigningKey(root *MerkleRoot) (GenericKey, error) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	kid, _, err := mc.findValidKIDAndSig(root)
+	if err != nil {
+		return nil, err
+	}
+	return mc.keyring.Load(kid)
+}
+
 func parseTriple(jw *jsonw.Wrapper) (*MerkleTriple, error) {
 	if jw.IsNil() {
 		return nil, nil
@@ -557,6 +616,11 @@ func parseMerkleUserLeaf(jw *jsonw.Wrapper, g *GlobalContext) (user *MerkleUserL
 	return
 }
 
+// Root returns the (as yet unverified) root that this path leads up to.
+func (vp *VerificationPath) Root() *MerkleRoot {
+	return vp.root
+}
+
 func (vp *VerificationPath) VerifyUsername() (username string, err error) {
 	if CheckUIDAgainstUsername(vp.uid, vp.username) == nil {
 		vp.G().Log.Debug("| Username %s mapped to %s via direct hash", vp.username, vp.uid)
@@ -715,6 +779,7 @@ func (mc *MerkleClient) LookupUser(q HTTPArgs) (u *MerkleUserLeaf, err error) {
 	}
 
 	u.idVersion = path.idVersion
+	u.root = path.root.seqno
 
 	mc.G().Log.Debug("- MerkleClient.LookupUser(%v) -> OK", q)
 	return
@@ -0,0 +1,296 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// metricsBuckets are the upper bounds, in seconds, of the latency
+// histograms.
+var metricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// latencyMetric counts calls to one RPC method or API endpoint.
+type latencyMetric struct {
+	count   uint64
+	errors  uint64
+	sum     float64
+	buckets []uint64
+}
+
+func newLatencyMetric() *latencyMetric {
+	return &latencyMetric{buckets: make([]uint64, len(metricsBuckets))}
+}
+
+func (m *latencyMetric) record(d time.Duration, failed bool) {
+	secs := d.Seconds()
+	m.count++
+	m.sum += secs
+	if failed {
+		m.errors++
+	}
+	for i, le := range metricsBuckets {
+		if secs <= le {
+			m.buckets[i]++
+		}
+	}
+}
+
+// Metrics counts RPCs and API calls for the local metrics endpoint, which
+// serves them with the state of the caches, client connections and merkle
+// root, in the Prometheus text format. It is safe to use concurrently,
+// and a nil Metrics records nothing.
+type Metrics struct {
+	sync.Mutex
+	rpcs     map[string]*latencyMetric
+	apiCalls map[string]*latencyMetric
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		rpcs:     make(map[string]*latencyMetric),
+		apiCalls: make(map[string]*latencyMetric),
+	}
+}
+
+func (m *Metrics) record(set map[string]*latencyMetric, name string, d time.Duration, failed bool) {
+	m.Lock()
+	defer m.Unlock()
+	lm, ok := set[name]
+	if !ok {
+		lm = newLatencyMetric()
+		set[name] = lm
+	}
+	lm.record(d, failed)
+}
+
+// RecordRPC records an RPC served by the service.
+func (m *Metrics) RecordRPC(method string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.record(m.rpcs, method, d, err != nil)
+}
+
+// RecordAPICall records a request to the API server.
+func (m *Metrics) RecordAPICall(endpoint string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.record(m.apiCalls, endpoint, d, err != nil)
+}
+
+// CacheStats counts the lookups in a cache that found something, and the
+// ones that didn't.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// cacheCounter keeps CacheStats for a cache, once countStats has been
+// called. Caches only count lookups when metrics are served.
+type cacheCounter struct {
+	hits   uint64
+	misses uint64
+	on     bool
+}
+
+// countStats turns counting on. Call it before the cache is shared.
+func (c *cacheCounter) countStats() {
+	c.on = true
+}
+
+func (c *cacheCounter) record(hit bool) {
+	if !c.on {
+		return
+	}
+	if hit {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+}
+
+func (c *cacheCounter) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
+
+type cacheStatser interface {
+	Stats() CacheStats
+	countStats()
+}
+
+type namedCache struct {
+	name  string
+	cache cacheStatser
+}
+
+// statsCaches returns the caches in g that keep CacheStats.
+func (g *GlobalContext) statsCaches() (ret []namedCache) {
+	if g.Identify2Cache != nil {
+		if c, ok := g.Identify2Cache.(cacheStatser); ok {
+			ret = append(ret, namedCache{"identify2", c})
+		}
+	}
+	if g.LinkCache != nil {
+		ret = append(ret, namedCache{"link", g.LinkCache})
+	}
+	if g.ProofCache != nil {
+		ret = append(ret, namedCache{"proof", g.ProofCache})
+	}
+	if g.TrackCache != nil {
+		ret = append(ret, namedCache{"track", g.TrackCache})
+	}
+	return ret
+}
+
+// countCacheStats turns on counting in all the caches that the metrics
+// endpoint reports.
+func (g *GlobalContext) countCacheStats() {
+	for _, c := range g.statsCaches() {
+		c.cache.countStats()
+	}
+}
+
+// metricsWriter writes metrics in the Prometheus text format, keeping the
+// first error.
+type metricsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (mw *metricsWriter) printf(format string, args ...interface{}) {
+	if mw.err != nil {
+		return
+	}
+	_, mw.err = fmt.Fprintf(mw.w, format, args...)
+}
+
+func (mw *metricsWriter) header(name, typ, help string) {
+	mw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (mw *metricsWriter) value(name string, labels string, v interface{}) {
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	mw.printf("%s%s %v\n", name, labels, v)
+}
+
+var metricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func metricsLabel(name, value string) string {
+	return fmt.Sprintf(`%s="%s"`, name, metricsLabelEscaper.Replace(value))
+}
+
+// latencies writes the counts, errors and latency histograms of set, which
+// is labeled by label.
+func (mw *metricsWriter) latencies(prefix, what, label string, set map[string]*latencyMetric) {
+	var names []string
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mw.header(prefix+"_total", "counter", what+", by "+label+".")
+	for _, name := range names {
+		mw.value(prefix+"_total", metricsLabel(label, name), set[name].count)
+	}
+	mw.header(prefix+"_errors_total", "counter", what+" that failed, by "+label+".")
+	for _, name := range names {
+		mw.value(prefix+"_errors_total", metricsLabel(label, name), set[name].errors)
+	}
+	mw.header(prefix+"_duration_seconds", "histogram", "Latency of "+what+", by "+label+".")
+	for _, name := range names {
+		lm := set[name]
+		l := metricsLabel(label, name)
+		for i, le := range metricsBuckets {
+			mw.value(prefix+"_duration_seconds_bucket", l+","+metricsLabel("le", fmt.Sprint(le)), lm.buckets[i])
+		}
+		mw.value(prefix+"_duration_seconds_bucket", l+","+metricsLabel("le", "+Inf"), lm.count)
+		mw.value(prefix+"_duration_seconds_sum", l, lm.sum)
+		mw.value(prefix+"_duration_seconds_count", l, lm.count)
+	}
+}
+
+// WriteTo writes the metrics for the service running in g.
+func (m *Metrics) WriteTo(w io.Writer, g *GlobalContext) error {
+	mw := &metricsWriter{w: bufio.NewWriter(w)}
+
+	m.Lock()
+	mw.latencies("keybase_rpc_calls", "RPCs served", "method", m.rpcs)
+	mw.latencies("keybase_api_requests", "API server requests", "endpoint", m.apiCalls)
+	m.Unlock()
+
+	var cacheNames []string
+	stats := make(map[string]CacheStats)
+	for _, c := range g.statsCaches() {
+		cacheNames = append(cacheNames, c.name)
+		stats[c.name] = c.cache.Stats()
+	}
+	mw.header("keybase_cache_hits_total", "counter", "Cache lookups that found something, by cache.")
+	for _, name := range cacheNames {
+		mw.value("keybase_cache_hits_total", metricsLabel("cache", name), stats[name].Hits)
+	}
+	mw.header("keybase_cache_misses_total", "counter", "Cache lookups that didn't find anything, by cache.")
+	for _, name := range cacheNames {
+		mw.value("keybase_cache_misses_total", metricsLabel("cache", name), stats[name].Misses)
+	}
+	mw.header("keybase_cache_hit_ratio", "gauge", "Share of cache lookups that found something, by cache.")
+	for _, name := range cacheNames {
+		s := stats[name]
+		ratio := 0.0
+		if total := s.Hits + s.Misses; total > 0 {
+			ratio = float64(s.Hits) / float64(total)
+		}
+		mw.value("keybase_cache_hit_ratio", metricsLabel("cache", name), ratio)
+	}
+
+	if g.ConnectionManager != nil {
+		mw.header("keybase_connected_clients", "gauge", "Clients connected to the service.")
+		mw.value("keybase_connected_clients", "", g.ConnectionManager.Count())
+	}
+
+	if g.MerkleClient != nil {
+		if seqno, ctime, fetched := g.MerkleClient.LatestRoot(); seqno >= 0 {
+			now := g.GetClock().Now()
+			mw.header("keybase_merkle_root_seqno", "gauge", "Seqno of the latest merkle root verified.")
+			mw.value("keybase_merkle_root_seqno", "", int64(seqno))
+			mw.header("keybase_merkle_root_age_seconds", "gauge", "Time since the server made the latest merkle root verified.")
+			mw.value("keybase_merkle_root_age_seconds", "", now.Sub(ctime).Seconds())
+			mw.header("keybase_merkle_root_fetch_age_seconds", "gauge", "Time since the latest merkle root was verified.")
+			mw.value("keybase_merkle_root_fetch_age_seconds", "", now.Sub(fetched).Seconds())
+		}
+	}
+
+	if mw.err != nil {
+		return mw.err
+	}
+	return mw.w.Flush()
+}
+
+// IsLocalMetricsAddr says whether addr is on a loopback interface, since
+// the metrics endpoint isn't meant to be reachable from other machines.
+func IsLocalMetricsAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,102 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsWriteTo(t *testing.T) {
+	g := NewGlobalContext()
+	g.LinkCache = NewLinkCache(10, time.Hour)
+	defer g.LinkCache.Shutdown()
+	g.countCacheStats()
+	g.ConnectionManager = NewConnectionManager()
+	defer g.ConnectionManager.Shutdown()
+
+	link := randChainLink()
+	g.LinkCache.Put(link.id, link)
+	g.LinkCache.Get(link.id)
+	g.LinkCache.Get(randChainLink().id)
+	g.LinkCache.Get(randChainLink().id)
+	g.LinkCache.Get(link.id)
+
+	g.Metrics.RecordRPC("keybase.1.test.test", 20*time.Millisecond, nil)
+	g.Metrics.RecordRPC("keybase.1.test.test", 2*time.Second, errors.New("failed"))
+	g.Metrics.RecordAPICall("user/lookup", 300*time.Millisecond, nil)
+
+	var buf bytes.Buffer
+	if err := g.Metrics.WriteTo(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	expected := []string{
+		`keybase_rpc_calls_total{method="keybase.1.test.test"} 2`,
+		`keybase_rpc_calls_errors_total{method="keybase.1.test.test"} 1`,
+		`keybase_rpc_calls_duration_seconds_bucket{method="keybase.1.test.test",le="0.025"} 1`,
+		`keybase_rpc_calls_duration_seconds_bucket{method="keybase.1.test.test",le="2.5"} 2`,
+		`keybase_rpc_calls_duration_seconds_bucket{method="keybase.1.test.test",le="+Inf"} 2`,
+		`keybase_rpc_calls_duration_seconds_count{method="keybase.1.test.test"} 2`,
+		`keybase_api_requests_total{endpoint="user/lookup"} 1`,
+		`keybase_api_requests_errors_total{endpoint="user/lookup"} 0`,
+		`keybase_cache_hits_total{cache="link"} 2`,
+		`keybase_cache_misses_total{cache="link"} 2`,
+		`keybase_cache_hit_ratio{cache="link"} 0.5`,
+		`keybase_connected_clients 0`,
+		`# TYPE keybase_rpc_calls_duration_seconds histogram`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Missing %q in:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "keybase_merkle_root") {
+		t.Errorf("Merkle root metrics without a merkle client:\n%s", out)
+	}
+}
+
+func TestConnectionManagerCountAfterShutdown(t *testing.T) {
+	c := NewConnectionManager()
+	c.AddConnection(nil, nil)
+	c.Shutdown()
+	if n := c.Count(); n != 1 {
+		t.Errorf("Count after Shutdown: %d, expected 1", n)
+	}
+}
+
+func TestCacheStatsOff(t *testing.T) {
+	c := NewLinkCache(10, time.Hour)
+	defer c.Shutdown()
+	link := randChainLink()
+	c.Put(link.id, link)
+	c.Get(link.id)
+	c.Get(randChainLink().id)
+	if s := c.Stats(); s.Hits != 0 || s.Misses != 0 {
+		t.Errorf("counted lookups without metrics: %+v", s)
+	}
+}
+
+func TestIsLocalMetricsAddr(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:9394":  true,
+		"localhost:9394":  true,
+		"[::1]:9394":      true,
+		"0.0.0.0:9394":    false,
+		":9394":           false,
+		"10.0.0.1:9394":   false,
+		"example.com:80":  false,
+		"127.0.0.1":       false,
+		"127.0.0.5:12345": true,
+	}
+	for addr, expected := range tests {
+		if IsLocalMetricsAddr(addr) != expected {
+			t.Errorf("IsLocalMetricsAddr(%q) != %v", addr, expected)
+		}
+	}
+}
@@ -294,8 +294,13 @@ func (k NaclSigningKeyPair) SignToString(msg []byte) (sig string, id keybase1.Si
 	if err != nil {
 		return
 	}
+	return naclSig.toSigString()
+}
 
-	packet, err := naclSig.ToPacket()
+// toSigString encodes the signature as a base64 packet, and computes its
+// ID.
+func (s *NaclSigInfo) toSigString() (sig string, id keybase1.SigID, err error) {
+	packet, err := s.ToPacket()
 	if err != nil {
 		return
 	}
@@ -680,6 +685,14 @@ func (k NaclDHKeyPair) Decrypt(nei *NaclEncryptionInfo) (plaintext []byte, sende
 		err = NoSecretKeyError{}
 		return
 	}
+	return nei.open(k.GetKID(), func(ciphertext []byte, nonce *[NaclDHNonceSize]byte, peer *NaclDHKeyPublic) ([]byte, bool) {
+		return box.Open(nil, ciphertext, nonce, (*[32]byte)(peer), (*[32]byte)(k.Private))
+	})
+}
+
+// open checks that the packet is for the receiver, and opens it with
+// openBox, which has the receiver's secret key.
+func (nei *NaclEncryptionInfo) open(receiver keybase1.KID, openBox func(ciphertext []byte, nonce *[NaclDHNonceSize]byte, peer *NaclDHKeyPublic) ([]byte, bool)) (plaintext []byte, sender keybase1.KID, err error) {
 	if nei.EncryptionType != KIDNaclDH {
 		err = DecryptBadPacketTypeError{}
 		return
@@ -704,13 +717,12 @@ func (k NaclDHKeyPair) Decrypt(nei *NaclEncryptionInfo) (plaintext []byte, sende
 	}
 
 	rkid := keybase1.KIDFromSlice(nei.Receiver)
-	if k.GetKID().NotEqual(rkid) {
+	if receiver.NotEqual(rkid) {
 		err = DecryptWrongReceiverError{}
 		return
 	}
 
-	if plaintext, ok = box.Open(plaintext, nei.Ciphertext, &nonce,
-		((*[32]byte)(&senderDH.Public)), ((*[32]byte)(k.Private))); !ok {
+	if plaintext, ok = openBox(nei.Ciphertext, &nonce, &senderDH.Public); !ok {
 		err = DecryptOpenError{}
 		return
 	}
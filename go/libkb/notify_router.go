@@ -188,6 +188,55 @@ func (n *NotifyRouter) HandleTrackingChanged(uid keybase1.UID, username string)
 	})
 }
 
+// HandleMerkleFork is called whenever the MerkleClient sees a possible fork
+// in the Merkle tree. It will broadcast the messages to all curious listeners.
+func (n *NotifyRouter) HandleMerkleFork(fork keybase1.MerkleFork) {
+	if n == nil {
+		return
+	}
+	// For all connections we currently have open...
+	n.cm.ApplyAll(func(id ConnectionID, xp rpc.Transporter) bool {
+		// If the connection wants the `Audit` notification type
+		if n.getNotificationChannels(id).Audit {
+			// In the background do...
+			go func() {
+				// A send of a `MerkleForkDetected` RPC with the evidence
+				(keybase1.NotifyAuditClient{
+					Cli: rpc.NewClient(xp, ErrorUnwrapper{}),
+				}).MerkleForkDetected(context.TODO(), fork)
+			}()
+		}
+		return true
+	})
+}
+
+// HandleKeysExpiring is called whenever the service finds that some of the
+// current user's keys will expire soon. It will broadcast the messages to
+// all curious listeners.
+func (n *NotifyRouter) HandleKeysExpiring(uid keybase1.UID, keys []keybase1.ExpiringKey) {
+	if n == nil {
+		return
+	}
+	arg := keybase1.KeysExpiringArg{
+		Uid:  uid,
+		Keys: keys,
+	}
+	// For all connections we currently have open...
+	n.cm.ApplyAll(func(id ConnectionID, xp rpc.Transporter) bool {
+		// If the connection wants the `Keys` notification type
+		if n.getNotificationChannels(id).Keys {
+			// In the background do...
+			go func() {
+				// A send of a `KeysExpiring` RPC with the expiring keys
+				(keybase1.NotifyKeysClient{
+					Cli: rpc.NewClient(xp, ErrorUnwrapper{}),
+				}).KeysExpiring(context.TODO(), arg)
+			}()
+		}
+		return true
+	})
+}
+
 // HandleFSActivity is called for any KBFS notification. It will broadcast the messages
 // to all curious listeners.
 func (n *NotifyRouter) HandleFSActivity(activity keybase1.FSNotification) {
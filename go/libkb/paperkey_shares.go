@@ -0,0 +1,298 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// PaperKeyShare is one share of a paper key phrase that has been split
+// with Shamir's secret sharing. Any threshold of the shares can rebuild
+// the phrase, but fewer say nothing about it.
+//
+// The sharing is done over GF(2^11), so that each word of the phrase
+// (an 11-bit index into secwords) is one field element, and each share
+// is a phrase of secwords too:
+//
+//	<header> <one word per phrase word...> <checksum> <version>
+//
+// The header word has the threshold and the share's index (both 1-16),
+// the checksum word catches typos, and the last word is a version word,
+// like a paper key phrase's, but for PaperKeyShareVersion.
+type PaperKeyShare string
+
+// NewPaperKeyShare converts a string into a PaperKeyShare.
+func NewPaperKeyShare(s string) PaperKeyShare {
+	return PaperKeyShare(strings.Join(strings.Fields(strings.ToLower(s)), " "))
+}
+
+// String returns a string representation of the share.
+func (s PaperKeyShare) String() string {
+	return string(s)
+}
+
+// Threshold is how many shares (this one included) are needed to rebuild
+// the phrase.
+func (s PaperKeyShare) Threshold() (int, error) {
+	p, err := parsePaperKeyShare(s)
+	if err != nil {
+		return 0, err
+	}
+	return p.threshold, nil
+}
+
+// IsPaperKeyShare is true if s is a valid PaperKeyShare, rather than a
+// paper key phrase.
+func IsPaperKeyShare(s string) bool {
+	_, err := parsePaperKeyShare(NewPaperKeyShare(s))
+	return err == nil
+}
+
+// SplitPaperKeyPhrase splits p into count shares, any threshold of which
+// can rebuild it with CombinePaperKeyShares.
+func SplitPaperKeyPhrase(p PaperKeyPhrase, threshold, count int) ([]PaperKeyShare, error) {
+	if err := CheckPaperKeyShareCount(threshold, count); err != nil {
+		return nil, err
+	}
+	secret, err := secWordIndexes(p.words())
+	if err != nil {
+		return nil, err
+	}
+
+	// One random polynomial per word, with the word as the constant term.
+	coeffs := make([][]uint16, len(secret))
+	for i, w := range secret {
+		coeffs[i] = make([]uint16, threshold)
+		coeffs[i][0] = w
+		for j := 1; j < threshold; j++ {
+			if coeffs[i][j], err = randGF2048(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	shares := make([]PaperKeyShare, count)
+	for x := 1; x <= count; x++ {
+		share := paperKeyShare{threshold: threshold, x: x, data: make([]uint16, len(secret))}
+		for i := range secret {
+			share.data[i] = gf2048Eval(coeffs[i], uint16(x))
+		}
+		if shares[x-1], err = share.encode(); err != nil {
+			return nil, err
+		}
+	}
+	return shares, nil
+}
+
+// CheckPaperKeyShareCount checks that a paper key can be split into count
+// shares with the given threshold.
+func CheckPaperKeyShareCount(threshold, count int) error {
+	if threshold < 2 || threshold > count || count > PaperKeyMaxShares {
+		return PaperKeyShareError{Msg: fmt.Sprintf("can't split into %d shares with a threshold of %d (at most %d shares, and a threshold of at least 2)", count, threshold, PaperKeyMaxShares)}
+	}
+	return nil
+}
+
+// CombinePaperKeyShares rebuilds a paper key phrase from at least a
+// threshold of its shares. If there are more shares than needed, they
+// all have to agree.
+func CombinePaperKeyShares(shares []PaperKeyShare) (PaperKeyPhrase, error) {
+	if len(shares) == 0 {
+		return "", PaperKeyShareError{Msg: "no shares"}
+	}
+	var parsed []paperKeyShare
+	seen := make(map[int]bool)
+	for _, s := range shares {
+		p, err := parsePaperKeyShare(NewPaperKeyShare(s.String()))
+		if err != nil {
+			return "", err
+		}
+		if len(parsed) > 0 && (p.threshold != parsed[0].threshold || len(p.data) != len(parsed[0].data)) {
+			return "", PaperKeyShareError{Msg: "shares are from different paper keys"}
+		}
+		if seen[p.x] {
+			// The same share entered twice doesn't count twice.
+			continue
+		}
+		seen[p.x] = true
+		parsed = append(parsed, p)
+	}
+	threshold := parsed[0].threshold
+	if len(parsed) < threshold {
+		return "", PaperKeyShareError{Msg: fmt.Sprintf("need %d shares, but only have %d", threshold, len(parsed))}
+	}
+
+	base, extra := parsed[:threshold], parsed[threshold:]
+	words := make([]string, len(base[0].data))
+	for i := range words {
+		w := gf2048Interpolate(base, i, 0)
+		for _, e := range extra {
+			if gf2048Interpolate(base, i, uint16(e.x)) != e.data[i] {
+				return "", PaperKeyShareError{Msg: "shares don't agree; they may be from different paper keys"}
+			}
+		}
+		words[i] = secwords[w]
+	}
+	return NewPaperKeyPhrase(strings.Join(words, " ")), nil
+}
+
+type paperKeyShare struct {
+	threshold int
+	x         int
+	data      []uint16
+}
+
+func (p paperKeyShare) header() uint16 {
+	return uint16((p.threshold-1)<<4 | (p.x - 1))
+}
+
+// checksum is the first 11 bits of the SHA256 of the header and data.
+func (p paperKeyShare) checksum() uint16 {
+	buf := make([]byte, 2*(len(p.data)+1))
+	binary.BigEndian.PutUint16(buf, p.header())
+	for i, w := range p.data {
+		binary.BigEndian.PutUint16(buf[2*(i+1):], w)
+	}
+	h := sha256.Sum256(buf)
+	return binary.BigEndian.Uint16(h[:]) >> 5
+}
+
+func (p paperKeyShare) encode() (PaperKeyShare, error) {
+	words := []string{secwords[p.header()]}
+	for _, w := range p.data {
+		words = append(words, secwords[w])
+	}
+	words = append(words, secwords[p.checksum()])
+	for i := 0; i < 1000; i++ {
+		v, err := secWordListN(1)
+		if err != nil {
+			return "", err
+		}
+		if wordVersion(v[0]) == PaperKeyShareVersion {
+			return PaperKeyShare(strings.Join(append(words, v[0]), " ")), nil
+		}
+	}
+	return "", KeyGenError{Msg: "exhausted attempts to generate a paper key share"}
+}
+
+func parsePaperKeyShare(s PaperKeyShare) (p paperKeyShare, err error) {
+	words := strings.Fields(s.String())
+	if len(words) < 4 {
+		return p, PaperKeyShareError{Msg: "too few words"}
+	}
+	if wordVersion(words[len(words)-1]) != PaperKeyShareVersion {
+		return p, PaperKeyShareError{Msg: "not a paper key share, or the wrong version"}
+	}
+	indexes, err := secWordIndexes(words[:len(words)-1])
+	if err != nil {
+		return p, err
+	}
+	header := indexes[0]
+	if header>>8 != 0 {
+		return p, PaperKeyShareError{Msg: "bad header word"}
+	}
+	p.threshold = int(header>>4) + 1
+	p.x = int(header&0xf) + 1
+	p.data = indexes[1 : len(indexes)-1]
+	if p.threshold < 2 || p.checksum() != indexes[len(indexes)-1] {
+		return p, PaperKeyShareError{Msg: "bad checksum; check for a typo"}
+	}
+	return p, nil
+}
+
+var secwordIndex map[string]uint16
+
+func init() {
+	secwordIndex = make(map[string]uint16)
+	for i, w := range secwords {
+		secwordIndex[w] = uint16(i)
+	}
+}
+
+func secWordIndexes(words []string) ([]uint16, error) {
+	ret := make([]uint16, len(words))
+	for i, w := range words {
+		x, ok := secwordIndex[w]
+		if !ok {
+			return nil, PaperKeyShareError{Msg: fmt.Sprintf("word %q is not a valid word", w)}
+		}
+		ret[i] = x
+	}
+	return ret, nil
+}
+
+// Arithmetic in GF(2^11), with x^11 + x^2 + 1 as the modulus. len(secwords)
+// is 2048, so every word is a field element.
+
+const gf2048Modulus = 0x805
+
+var gf2048Exp [2 * 2047]uint16
+var gf2048Log [2048]uint16
+
+func init() {
+	x := uint16(1)
+	for i := 0; i < 2047; i++ {
+		gf2048Exp[i] = x
+		gf2048Exp[i+2047] = x
+		gf2048Log[x] = uint16(i)
+		x <<= 1
+		if x&0x800 != 0 {
+			x ^= gf2048Modulus
+		}
+	}
+}
+
+func gf2048Mul(a, b uint16) uint16 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf2048Exp[int(gf2048Log[a])+int(gf2048Log[b])]
+}
+
+func gf2048Div(a, b uint16) uint16 {
+	if a == 0 {
+		return 0
+	}
+	return gf2048Exp[int(gf2048Log[a])+2047-int(gf2048Log[b])]
+}
+
+// gf2048Eval evaluates the polynomial with the given coefficients (lowest
+// degree first) at x.
+func gf2048Eval(coeffs []uint16, x uint16) uint16 {
+	var y uint16
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = gf2048Mul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+// gf2048Interpolate evaluates, at x, the polynomial that goes through
+// word i of each of the shares.
+func gf2048Interpolate(shares []paperKeyShare, i int, x uint16) uint16 {
+	var y uint16
+	for j, sj := range shares {
+		num, den := uint16(1), uint16(1)
+		for k, sk := range shares {
+			if k == j {
+				continue
+			}
+			num = gf2048Mul(num, x^uint16(sk.x))
+			den = gf2048Mul(den, uint16(sj.x)^uint16(sk.x))
+		}
+		y ^= gf2048Mul(sj.data[i], gf2048Div(num, den))
+	}
+	return y
+}
+
+func randGF2048() (uint16, error) {
+	var b [2]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b[:]) & 0x7ff, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGF2048(t *testing.T) {
+	for a := uint16(1); a < 2048; a++ {
+		if gf2048Mul(a, gf2048Div(1, a)) != 1 {
+			t.Fatalf("%d has no inverse", a)
+		}
+	}
+}
+
+func TestPaperKeyShares(t *testing.T) {
+	phrase, err := MakePaperKeyPhrase(PaperKeyVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := SplitPaperKeyPhrase(phrase, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("got %d shares, expected 5", len(shares))
+	}
+	for _, s := range shares {
+		if !IsPaperKeyShare(s.String()) {
+			t.Errorf("%q isn't a share", s)
+		}
+		if n, err := s.Threshold(); err != nil || n != 3 {
+			t.Errorf("threshold: %d, %v", n, err)
+		}
+		if len(strings.Fields(s.String())) != len(strings.Fields(phrase.String()))+3 {
+			t.Errorf("share has the wrong number of words: %q", s)
+		}
+	}
+	if IsPaperKeyShare(phrase.String()) {
+		t.Errorf("paper key phrase %q looks like a share", phrase)
+	}
+
+	for _, pick := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}, {0, 0, 1, 2}} {
+		var some []PaperKeyShare
+		for _, i := range pick {
+			// Shares get typed in, so make sure case and spacing don't matter.
+			some = append(some, PaperKeyShare("  "+strings.ToUpper(shares[i].String())))
+		}
+		combined, err := CombinePaperKeyShares(some)
+		if err != nil {
+			t.Errorf("%v: %s", pick, err)
+			continue
+		}
+		if combined != phrase {
+			t.Errorf("%v: got %q, expected %q", pick, combined, phrase)
+		}
+	}
+
+	if _, err := CombinePaperKeyShares([]PaperKeyShare{shares[0], shares[1], shares[1]}); err == nil {
+		t.Error("expected an error combining too few shares")
+	}
+
+	other, err := SplitPaperKeyPhrase(phrase, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CombinePaperKeyShares([]PaperKeyShare{shares[0], shares[1], shares[2], other[3]}); err == nil {
+		t.Error("expected an error combining shares from different splits")
+	}
+
+	// A typo is caught by the checksum (unless it happens to have the same
+	// checksum, so pick one that doesn't).
+	words := strings.Fields(shares[0].String())
+	parsed, err := parsePaperKeyShare(shares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{"zoo", "abandon", "ability"} {
+		typo := paperKeyShare{threshold: parsed.threshold, x: parsed.x, data: append([]uint16{}, parsed.data...)}
+		typo.data[0] = secwordIndex[w]
+		if w != words[1] && typo.checksum() != parsed.checksum() {
+			words[1] = w
+			break
+		}
+	}
+	if _, err := CombinePaperKeyShares([]PaperKeyShare{PaperKeyShare(strings.Join(words, " ")), shares[1], shares[2]}); err == nil {
+		t.Error("expected an error combining a share with a typo")
+	} else if _, ok := err.(PaperKeyShareError); !ok {
+		t.Errorf("expected a PaperKeyShareError, got %T", err)
+	}
+}
+
+func TestSplitPaperKeyPhraseBadArgs(t *testing.T) {
+	phrase, err := MakePaperKeyPhrase(PaperKeyVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range [][2]int{{1, 3}, {4, 3}, {2, PaperKeyMaxShares + 1}} {
+		if _, err := SplitPaperKeyPhrase(phrase, c[0], c[1]); err == nil {
+			t.Errorf("expected an error splitting %d of %d", c[0], c[1])
+		}
+	}
+	if _, err := SplitPaperKeyPhrase(NewPaperKeyPhrase("not secwords"), 2, 3); err == nil {
+		t.Error("expected an error splitting a phrase that isn't secwords")
+	}
+}
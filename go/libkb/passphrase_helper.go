@@ -46,9 +46,65 @@ func GetPaperKeyPassphrase(ui SecretUI, username string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if IsPaperKeyShare(res.Passphrase) {
+		return getPaperKeyShares(ui, arg, NewPaperKeyShare(res.Passphrase))
+	}
+	return res.Passphrase, nil
+}
+
+// GetPKCS11PIN prompts for the PIN of a PKCS#11 token.
+func GetPKCS11PIN(ui SecretUI, token, retryMsg string) (string, error) {
+	arg := DefaultPassphraseArg(false)
+	arg.WindowTitle = "PKCS#11 token PIN"
+	arg.Type = keybase1.PassphraseType_PASS_PHRASE
+	arg.Prompt = fmt.Sprintf("Please enter the PIN for PKCS#11 token %q", token)
+	arg.RetryLabel = retryMsg
+	arg.Features.StoreSecret.Allow = false
+	arg.Features.StoreSecret.Readonly = true
+	res, err := GetPassphraseUntilCheck(arg, newUIPrompter(ui), &CheckPassphraseSimple)
+	if err != nil {
+		return "", err
+	}
 	return res.Passphrase, nil
 }
 
+// getPaperKeyShares prompts for more shares of a paper key that was split
+// with SplitPaperKeyPhrase, once the first has been entered, and rebuilds
+// the phrase when there are enough of them.
+func getPaperKeyShares(ui SecretUI, arg keybase1.GUIEntryArg, first PaperKeyShare) (string, error) {
+	threshold, err := first.Threshold()
+	if err != nil {
+		return "", err
+	}
+	shares := []PaperKeyShare{first}
+	arg.RetryLabel = ""
+	for i := 0; len(shares) < threshold; i++ {
+		if i >= 2*PaperKeyMaxShares {
+			return "", RetryExhaustedError{}
+		}
+		arg.Prompt = fmt.Sprintf("Please enter another share of the paper key (%d of %d)", len(shares)+1, threshold)
+		res, err := GetPassphraseUntilCheck(arg, newUIPrompter(ui), &CheckPaperKeyShare)
+		if err != nil {
+			return "", err
+		}
+		share := NewPaperKeyShare(res.Passphrase)
+		arg.RetryLabel = ""
+		for _, s := range shares {
+			if s == share {
+				arg.RetryLabel = "You already entered that share"
+			}
+		}
+		if len(arg.RetryLabel) == 0 {
+			shares = append(shares, share)
+		}
+	}
+	phrase, err := CombinePaperKeyShares(shares)
+	if err != nil {
+		return "", err
+	}
+	return phrase.String(), nil
+}
+
 func GetPaperKeyForCryptoPassphrase(ui SecretUI, reason string, devices []*Device) (string, error) {
 	if len(devices) == 0 {
 		return "", errors.New("empty device list")
@@ -0,0 +1,61 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+type scriptedSecretUI struct {
+	answers []string
+	prompts []string
+}
+
+func (s *scriptedSecretUI) GetPassphrase(arg keybase1.GUIEntryArg, _ *keybase1.SecretEntryArg) (res keybase1.GetPassphraseRes, err error) {
+	s.prompts = append(s.prompts, arg.Prompt)
+	if len(s.answers) == 0 {
+		return res, InputCanceledError{}
+	}
+	res.Passphrase, s.answers = s.answers[0], s.answers[1:]
+	return res, nil
+}
+
+func TestGetPaperKeyPassphraseShares(t *testing.T) {
+	phrase, err := MakePaperKeyPhrase(PaperKeyVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := SplitPaperKeyPhrase(phrase, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A plain phrase is returned as is.
+	ui := &scriptedSecretUI{answers: []string{phrase.String()}}
+	if p, err := GetPaperKeyPassphrase(ui, "alice"); err != nil || p != phrase.String() {
+		t.Errorf("got %q, %v", p, err)
+	}
+
+	// Shares are prompted for until there are enough, skipping repeats
+	// and typos.
+	ui = &scriptedSecretUI{answers: []string{
+		shares[3].String(),
+		shares[3].String(),
+		"not a share",
+		shares[0].String(),
+		shares[1].String(),
+	}}
+	p, err := GetPaperKeyPassphrase(ui, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != phrase.String() {
+		t.Errorf("got %q, expected %q", p, phrase)
+	}
+	if len(ui.answers) != 0 || len(ui.prompts) != 5 {
+		t.Errorf("prompts: %v", ui.prompts)
+	}
+}
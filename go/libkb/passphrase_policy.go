@@ -0,0 +1,127 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// PassphraseEntropy is a rough estimate of how many bits of entropy are
+// in passphrase. Each character is worth log2 of the size of the
+// character classes the passphrase draws from, except that repeated
+// characters and runs like "abc" or "321" are worth a bit each. A
+// passphrase made only of paper key words is worth no more than picking
+// that many words from the paper key word list.
+func PassphraseEntropy(passphrase string) int {
+	var lower, upper, digit, punct, other bool
+	runes := []rune(passphrase)
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < 0x80:
+			punct = true
+		default:
+			other = true
+		}
+	}
+	pool := 0
+	if lower {
+		pool += 26
+	}
+	if upper {
+		pool += 26
+	}
+	if digit {
+		pool += 10
+	}
+	if punct {
+		pool += 33
+	}
+	if other {
+		pool += 100
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	perChar := math.Log2(float64(pool))
+	var bits float64
+	for i, r := range runes {
+		if i > 0 {
+			d := r - runes[i-1]
+			if d >= -1 && d <= 1 {
+				bits++
+				continue
+			}
+		}
+		bits += perChar
+	}
+
+	words := strings.Fields(strings.ToLower(passphrase))
+	if len(words) >= 2 {
+		all := true
+		for _, w := range words {
+			if !secwordSet[w] {
+				all = false
+				break
+			}
+		}
+		if all {
+			wordBits := float64(len(words)) * math.Log2(float64(len(secwords)))
+			if wordBits < bits {
+				bits = wordBits
+			}
+		}
+	}
+	return int(bits)
+}
+
+// CheckPassphrasePolicy checks a new passphrase against policy. username
+// and email are those of the account the passphrase is for, and either
+// can be empty.
+func CheckPassphrasePolicy(policy keybase1.PassphrasePolicy, passphrase, username, email string) error {
+	if len(passphrase) < policy.MinLength {
+		return ShortPassphraseError{Length: len(passphrase), Required: policy.MinLength}
+	}
+
+	lower := strings.ToLower(passphrase)
+	for _, word := range policy.BannedWords {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if len(word) > 0 && strings.Contains(lower, word) {
+			return BannedPassphraseWordError{Word: word}
+		}
+	}
+
+	if !policy.AllowUserInfo {
+		checks := []struct {
+			what, s string
+		}{
+			{"username", username},
+			{"email address", email},
+		}
+		if i := strings.IndexByte(email, '@'); i > 0 {
+			checks = append(checks, struct{ what, s string }{"email address", email[:i]})
+		}
+		for _, c := range checks {
+			s := strings.ToLower(strings.TrimFunc(c.s, unicode.IsSpace))
+			if len(s) >= 3 && strings.Contains(lower, s) {
+				return PassphraseHasUserInfoError{What: c.what}
+			}
+		}
+	}
+
+	if bits := PassphraseEntropy(passphrase); bits < policy.MinEntropyBits {
+		return WeakPassphraseError{Bits: bits, Required: policy.MinEntropyBits}
+	}
+	return nil
+}
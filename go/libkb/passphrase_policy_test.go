@@ -0,0 +1,87 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"testing"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+func TestPassphraseEntropy(t *testing.T) {
+	cases := []struct {
+		passphrase string
+		min, max   int
+	}{
+		{"", 0, 0},
+		{"aaaaaaaaaaaa", 0, 16},
+		{"abcdefghijkl", 0, 16},
+		{"qzmxwnvbgtyh", 50, 60},
+		{"Qz7!mX2@wN9#", 70, 85},
+		{"apple apple apple", 0, 34},
+	}
+	for _, c := range cases {
+		bits := PassphraseEntropy(c.passphrase)
+		if bits < c.min || bits > c.max {
+			t.Errorf("%q: %d bits, expected %d-%d", c.passphrase, bits, c.min, c.max)
+		}
+	}
+
+	// Paper key words are worth what the word list says they are.
+	words := secwords[0] + " " + secwords[100] + " " + secwords[1000]
+	if bits := PassphraseEntropy(words); bits != 33 {
+		t.Errorf("%q: %d bits, expected 33", words, bits)
+	}
+}
+
+func TestCheckPassphrasePolicy(t *testing.T) {
+	policy := keybase1.PassphrasePolicy{
+		MinLength:      12,
+		MinEntropyBits: 40,
+		BannedWords:    []string{"Keybase"},
+	}
+	cases := []struct {
+		passphrase string
+		err        error
+	}{
+		{"short", ShortPassphraseError{Length: 5, Required: 12}},
+		{"my keybase passphrase", BannedPassphraseWordError{Word: "keybase"}},
+		{"hello max_power", PassphraseHasUserInfoError{What: "username"}},
+		{"maxp@example.com!!", PassphraseHasUserInfoError{What: "email address"}},
+		{"call me maxp today", PassphraseHasUserInfoError{What: "email address"}},
+		{"aaaaaaaaaaaaaaaa", WeakPassphraseError{Bits: 19, Required: 40}},
+		{"correct horse battery staple", nil},
+	}
+	for _, c := range cases {
+		err := CheckPassphrasePolicy(policy, c.passphrase, "max_power", "maxp@example.com")
+		if err != c.err {
+			t.Errorf("%q: got %v, expected %v", c.passphrase, err, c.err)
+		}
+	}
+
+	policy.AllowUserInfo = true
+	if err := CheckPassphrasePolicy(policy, "hello max_power", "max_power", ""); err != nil {
+		t.Errorf("with user info allowed: %s", err)
+	}
+
+	// The errors make it through RPC intact.
+	for _, c := range cases[1:6] {
+		status := c.err.(ExportableError).ToStatus()
+		if err := ImportStatusAsError(&status); err != c.err {
+			t.Errorf("imported %v, expected %v", err, c.err)
+		}
+	}
+}
+
+func TestPassphrasePolicyDefault(t *testing.T) {
+	// With no policy configured, only the length is checked.
+	env := newEnv(nil, nil, "linux")
+	policy := env.GetPassphrasePolicy()
+	if policy.MinLength != MinPassphraseLength || policy.MinEntropyBits != 0 || !policy.AllowUserInfo {
+		t.Errorf("Unexpected default policy %+v", policy)
+	}
+	if err := CheckPassphrasePolicy(policy, "hello max_power", "max_power", ""); err != nil {
+		t.Errorf("Default policy: %s", err)
+	}
+}
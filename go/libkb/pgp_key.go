@@ -11,8 +11,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"regexp"
 	"strings"
+	"time"
 
 	keybase1 "github.com/keybase/client/go/protocol"
 	"github.com/keybase/go-crypto/openpgp"
@@ -688,6 +690,89 @@ func (k *PGPKeyBundle) CheckIdentity(kbid Identity) (match bool, ctime int64, et
 	return
 }
 
+// ExpirationTime returns when the key expires, according to the self
+// signature on its primary identity, or the zero time if it never does.
+func (k *PGPKeyBundle) ExpirationTime() time.Time {
+	var primary *openpgp.Identity
+	for _, ident := range k.Identities {
+		if primary == nil {
+			primary = ident
+		}
+		if ident.SelfSignature != nil && ident.SelfSignature.IsPrimaryId != nil && *ident.SelfSignature.IsPrimaryId {
+			primary = ident
+			break
+		}
+	}
+	if primary == nil || primary.SelfSignature == nil {
+		return time.Time{}
+	}
+	lifeSeconds := primary.SelfSignature.KeyLifetimeSecs
+	if lifeSeconds == nil || *lifeSeconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(k.PrimaryKey.CreationTime.Unix()+int64(*lifeSeconds), 0)
+}
+
+// ExtendExpiration makes new self signatures for the key's identities and
+// subkeys that say they expire at etime (or never, if etime is zero). The
+// key's private key has to be unlocked. Signing subkeys are left alone,
+// since their binding signatures need a back signature from the subkey
+// itself; their key IDs are returned so the caller can say so.
+func (k *PGPKeyBundle) ExtendExpiration(etime, now time.Time) (skipped []uint64, err error) {
+	if k.PrivateKey == nil || k.PrivateKey.Encrypted {
+		return nil, NoSecretKeyError{}
+	}
+	lifetime := func(ctime time.Time) (*uint32, error) {
+		if etime.IsZero() {
+			return nil, nil
+		}
+		secs := etime.Unix() - ctime.Unix()
+		if secs <= 0 || secs > math.MaxUint32 || !etime.After(now) {
+			return nil, BadKeyError{Msg: fmt.Sprintf("can't make a key created %s expire at %s", ctime, etime)}
+		}
+		ret := uint32(secs)
+		return &ret, nil
+	}
+
+	for _, ident := range k.Identities {
+		if ident.SelfSignature == nil {
+			continue
+		}
+		sig := *ident.SelfSignature
+		sig.CreationTime = now
+		if sig.KeyLifetimeSecs, err = lifetime(k.PrimaryKey.CreationTime); err != nil {
+			return nil, err
+		}
+		if err = sig.SignUserId(ident.UserId.Id, k.PrimaryKey, k.PrivateKey, nil); err != nil {
+			return nil, err
+		}
+		ident.SelfSignature = &sig
+	}
+
+	for i, subkey := range k.Subkeys {
+		if subkey.Sig == nil || subkey.Revocation != nil {
+			continue
+		}
+		if subkey.Sig.FlagSign {
+			skipped = append(skipped, subkey.PublicKey.KeyId)
+			continue
+		}
+		sig := *subkey.Sig
+		sig.CreationTime = now
+		if sig.KeyLifetimeSecs, err = lifetime(subkey.PublicKey.CreationTime); err != nil {
+			return nil, err
+		}
+		if err = sig.SignKey(subkey.PublicKey, k.PrivateKey, nil); err != nil {
+			return nil, err
+		}
+		k.Subkeys[i].Sig = &sig
+	}
+
+	// The cached export is out of date now.
+	k.ArmoredPublicKey = ""
+	return skipped, nil
+}
+
 // EncryptToString fails for this type of key, since we haven't implemented it yet
 func (k *PGPKeyBundle) EncryptToString(plaintext []byte, sender GenericKey) (ciphertext string, err error) {
 	err = KeyCannotEncryptError{}
@@ -0,0 +1,405 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"fmt"
+	"sync"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	"github.com/keybase/saltpack"
+	"github.com/ugorji/go/codec"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/salsa20/salsa"
+)
+
+// PKCS11Version is the SKBPriv.Encryption of a device key whose secret
+// half is on a PKCS#11 token. Priv.Data is then a PKCS11KeyRef, locked
+// with local key security just like a NaCl secret key would be.
+const PKCS11Version = 101
+
+const pkcs11KeyIDLen = 16
+
+// PKCS11KeyRef says which token a key is on, and which key it is. The
+// token's PIN isn't kept with it; the user is prompted for that the first
+// time the token is used, and the token stays logged in after that.
+type PKCS11KeyRef struct {
+	Module string `codec:"module"`
+	Token  string `codec:"token"`
+	ID     []byte `codec:"id"`
+}
+
+func (r PKCS11KeyRef) toLksSKB(lks *LKSec, kid keybase1.KID, typ AlgoType) (*SKB, error) {
+	var encoded []byte
+	if err := codec.NewEncoderBytes(&encoded, codecHandle()).Encode(r); err != nil {
+		return nil, err
+	}
+	data, err := lks.Encrypt(encoded)
+	if err != nil {
+		return nil, err
+	}
+	ret := NewSKB(lks.G())
+	ret.Pub = kid.ToBytes()
+	ret.Type = typ
+	ret.Priv.Encryption = PKCS11Version
+	ret.Priv.Data = data
+	ret.Priv.PassphraseGeneration = int(lks.Generation())
+	return ret, nil
+}
+
+// importPKCS11Key makes the device key whose public half is pub and
+// whose secret half is on the token that the unlocked PKCS11KeyRef
+// says. If that token hasn't been logged in to yet, it returns a
+// PKCS11TokenLoggedOutError, so the caller can prompt for the PIN.
+func importPKCS11Key(typ AlgoType, pub []byte, unlocked []byte) (GenericKey, error) {
+	var ref PKCS11KeyRef
+	if err := MsgpackDecodeAll(unlocked, codecHandle(), &ref); err != nil {
+		return nil, err
+	}
+	token := findPKCS11Token(ref.Module, ref.Token)
+	if token == nil {
+		return nil, PKCS11TokenLoggedOutError{Module: ref.Module, Token: ref.Token}
+	}
+	switch typ {
+	case KIDNaclEddsa:
+		kp, err := ImportNaclSigningKeyPairFromBytes(pub, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &PKCS11SigningKey{NaclSigningKeyPair: kp, token: token, ref: ref}, nil
+	case KIDNaclDH:
+		kp, err := ImportNaclDHKeyPairFromBytes(pub, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &PKCS11DHKey{NaclDHKeyPair: kp, token: token, ref: ref}, nil
+	}
+	return nil, UnknownKeyTypeError{typ}
+}
+
+// OpenPKCS11TokenWithPrompt opens the token with the given label, in the
+// configured PKCS#11 module, prompting for its PIN.
+func OpenPKCS11TokenWithPrompt(g *GlobalContext, ui SecretUI, label string) (*PKCS11Token, error) {
+	return openPKCS11TokenWithPrompt(ui, g.Env.GetPKCS11Module(), label)
+}
+
+func openPKCS11TokenWithPrompt(ui SecretUI, module, label string) (*PKCS11Token, error) {
+	if t := findPKCS11Token(module, label); t != nil {
+		return t, nil
+	}
+	var retryMsg string
+	for i := 0; i < 3; i++ {
+		pin, err := GetPKCS11PIN(ui, label, retryMsg)
+		if err != nil {
+			return nil, err
+		}
+		token, err := OpenPKCS11Token(module, label, pin)
+		if _, ok := err.(PKCS11BadPINError); ok {
+			retryMsg = err.Error()
+			continue
+		}
+		return token, err
+	}
+	return nil, RetryExhaustedError{}
+}
+
+func (t *PKCS11Token) newKeyRef() (PKCS11KeyRef, error) {
+	id, err := RandBytes(pkcs11KeyIDLen)
+	if err != nil {
+		return PKCS11KeyRef{}, err
+	}
+	return PKCS11KeyRef{Module: t.module, Token: t.label, ID: id}, nil
+}
+
+// Label is the token's label.
+func (t *PKCS11Token) Label() string {
+	return t.label
+}
+
+// GenerateSigningKey makes a new device signing key on the token.
+func (t *PKCS11Token) GenerateSigningKey() (*PKCS11SigningKey, error) {
+	ref, err := t.newKeyRef()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := t.generateEd25519(ref.ID)
+	if err != nil {
+		return nil, err
+	}
+	ret := &PKCS11SigningKey{token: t, ref: ref}
+	if len(pub) != len(ret.Public) {
+		return nil, PKCS11Error{Msg: fmt.Sprintf("token made a %d-byte EdDSA public key", len(pub))}
+	}
+	copy(ret.Public[:], pub)
+	return ret, nil
+}
+
+// GenerateDHKey makes a new device encryption key on the token.
+func (t *PKCS11Token) GenerateDHKey() (*PKCS11DHKey, error) {
+	ref, err := t.newKeyRef()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := t.generateX25519(ref.ID)
+	if err != nil {
+		return nil, err
+	}
+	ret := &PKCS11DHKey{token: t, ref: ref}
+	if len(pub) != len(ret.Public) {
+		return nil, PKCS11Error{Msg: fmt.Sprintf("token made a %d-byte X25519 public key", len(pub))}
+	}
+	copy(ret.Public[:], pub)
+	return ret, nil
+}
+
+// PKCS11SigningKey is a device signing key whose secret half is on a
+// PKCS#11 token. Its KID and signatures are the same as a NaCl signing
+// key's, so only this device can tell the difference.
+type PKCS11SigningKey struct {
+	NaclSigningKeyPair // the public half only
+	token              *PKCS11Token
+	ref                PKCS11KeyRef
+}
+
+var _ GenericKey = (*PKCS11SigningKey)(nil)
+
+func (k *PKCS11SigningKey) VerboseDescription() string {
+	return fmt.Sprintf("%s on PKCS#11 token %q", k.NaclSigningKeyPair.VerboseDescription(), k.ref.Token)
+}
+
+func (k *PKCS11SigningKey) CheckSecretKey() error {
+	if k.token == nil {
+		return NoSecretKeyError{}
+	}
+	return nil
+}
+
+func (k *PKCS11SigningKey) HasSecretKey() bool { return k.token != nil }
+func (k *PKCS11SigningKey) CanSign() bool      { return k.token != nil }
+
+// SignED25519 signs msg on the token.
+func (k *PKCS11SigningKey) SignED25519(msg []byte) (ret NaclSignature, err error) {
+	if k.token == nil {
+		return ret, NoSecretKeyError{}
+	}
+	sig, err := k.token.signEd25519(k.ref.ID, msg)
+	if err != nil {
+		return ret, err
+	}
+	if len(sig) != len(ret) {
+		return ret, PKCS11Error{Msg: fmt.Sprintf("token made a %d-byte EdDSA signature", len(sig))}
+	}
+	copy(ret[:], sig)
+	if !k.Public.Verify(msg, &ret) {
+		return ret, PKCS11Error{Msg: "token made a bad EdDSA signature"}
+	}
+	return ret, nil
+}
+
+func (k *PKCS11SigningKey) SignToString(msg []byte) (sig string, id keybase1.SigID, err error) {
+	naclSig, err := k.SignED25519(msg)
+	if err != nil {
+		return
+	}
+	info := &NaclSigInfo{
+		Kid:      k.GetKID().ToBytes(),
+		Payload:  msg,
+		Sig:      naclSig,
+		SigType:  SigKbEddsa,
+		HashType: HashPGPSha512,
+		Detached: true,
+	}
+	return info.toSigString()
+}
+
+func (k *PKCS11SigningKey) ToLksSKB(lks *LKSec) (*SKB, error) {
+	return k.ref.toLksSKB(lks, k.GetKID(), KIDNaclEddsa)
+}
+
+var _ saltpack.SigningSecretKey = (*PKCS11SigningKey)(nil)
+
+// Sign signs msg on the token, for saltpack. Unlike
+// NaclSigningKeyPair.Sign, it returns just the signature.
+func (k *PKCS11SigningKey) Sign(msg []byte) ([]byte, error) {
+	sig, err := k.SignED25519(msg)
+	if err != nil {
+		return nil, err
+	}
+	return sig[:], nil
+}
+
+// GetPublicKey returns the public half, for saltpack.
+func (k *PKCS11SigningKey) GetPublicKey() saltpack.SigningPublicKey {
+	return saltSignerPublic{key: k.Public}
+}
+
+// PKCS11DHKey is a device encryption key whose secret half is on a
+// PKCS#11 token. The token computes the X25519 shared secret with the
+// other side's key, and the rest of NaCl box happens here.
+type PKCS11DHKey struct {
+	NaclDHKeyPair // the public half only
+	token         *PKCS11Token
+	ref           PKCS11KeyRef
+
+	// saltpack's Box and Precompute can't fail, so when the token
+	// does, the error is kept here for takeErr.
+	errMu sync.Mutex
+	err   error
+}
+
+var _ GenericKey = (*PKCS11DHKey)(nil)
+
+func (k *PKCS11DHKey) VerboseDescription() string {
+	return fmt.Sprintf("%s on PKCS#11 token %q", k.NaclDHKeyPair.VerboseDescription(), k.ref.Token)
+}
+
+func (k *PKCS11DHKey) CheckSecretKey() error {
+	if k.token == nil {
+		return NoSecretKeyError{}
+	}
+	return nil
+}
+
+func (k *PKCS11DHKey) HasSecretKey() bool { return k.token != nil }
+func (k *PKCS11DHKey) CanDecrypt() bool   { return k.token != nil }
+
+// precompute is box.Precompute, with the X25519 part done on the token.
+func (k *PKCS11DHKey) precompute(peer *NaclDHKeyPublic) (*[32]byte, error) {
+	if k.token == nil {
+		return nil, NoSecretKeyError{}
+	}
+	shared, err := k.token.x25519(k.ref.ID, peer[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(shared) != 32 {
+		return nil, PKCS11Error{Msg: fmt.Sprintf("token made a %d-byte X25519 shared secret", len(shared))}
+	}
+	var in, out [32]byte
+	var zeros [16]byte
+	copy(in[:], shared)
+	salsa.HSalsa20(&out, &zeros, &in, &salsa.Sigma)
+	return &out, nil
+}
+
+// OpenBox opens a NaCl box from peer.
+func (k *PKCS11DHKey) OpenBox(ciphertext []byte, nonce *[NaclDHNonceSize]byte, peer *NaclDHKeyPublic) ([]byte, error) {
+	key, err := k.precompute(peer)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, ok := box.OpenAfterPrecomputation(nil, ciphertext, nonce, key)
+	if !ok {
+		return nil, DecryptionError{}
+	}
+	return plaintext, nil
+}
+
+// DecryptFromString decrypts the output of EncryptToString, and returns
+// the KID of the other end.
+func (k *PKCS11DHKey) DecryptFromString(ciphertext string) (msg []byte, sender keybase1.KID, err error) {
+	kbp, err := DecodeArmoredPacket(ciphertext)
+	if err != nil {
+		return
+	}
+	nei, ok := kbp.Body.(*NaclEncryptionInfo)
+	if !ok {
+		err = UnmarshalError{"NaCl Encryption"}
+		return
+	}
+	return k.Decrypt(nei)
+}
+
+// Decrypt a NaclEncryptionInfo packet, and on success return the plaintext
+// and the KID of the sender (which might be an ephemeral key).
+func (k *PKCS11DHKey) Decrypt(nei *NaclEncryptionInfo) (plaintext []byte, sender keybase1.KID, err error) {
+	if k.token == nil {
+		err = NoSecretKeyError{}
+		return
+	}
+	var tokenErr error
+	plaintext, sender, err = nei.open(k.GetKID(), func(ciphertext []byte, nonce *[NaclDHNonceSize]byte, peer *NaclDHKeyPublic) ([]byte, bool) {
+		key, err := k.precompute(peer)
+		if err != nil {
+			tokenErr = err
+			return nil, false
+		}
+		return box.OpenAfterPrecomputation(nil, ciphertext, nonce, key)
+	})
+	if tokenErr != nil {
+		return nil, sender, tokenErr
+	}
+	return plaintext, sender, err
+}
+
+func (k *PKCS11DHKey) ToLksSKB(lks *LKSec) (*SKB, error) {
+	return k.ref.toLksSKB(lks, k.GetKID(), KIDNaclDH)
+}
+
+var _ saltpack.BoxSecretKey = (*PKCS11DHKey)(nil)
+
+// GetPublicKey returns the public half, for saltpack.
+func (k *PKCS11DHKey) GetPublicKey() saltpack.BoxPublicKey {
+	return naclBoxPublicKey(k.Public)
+}
+
+// Box seals msg for receiver, for saltpack. If the token fails, the box
+// is all zeros and won't open, and the error is kept for takeErr.
+func (k *PKCS11DHKey) Box(receiver saltpack.BoxPublicKey, nonce saltpack.Nonce, msg []byte) []byte {
+	key, err := k.precompute((*NaclDHKeyPublic)(receiver.ToRawBoxKeyPointer()))
+	if err != nil {
+		k.setErr(err)
+		return make([]byte, len(msg)+box.Overhead)
+	}
+	return box.SealAfterPrecomputation(nil, msg, (*[24]byte)(&nonce), key)
+}
+
+// Unbox opens a box from sender, for saltpack.
+func (k *PKCS11DHKey) Unbox(sender saltpack.BoxPublicKey, nonce saltpack.Nonce, msg []byte) ([]byte, error) {
+	return k.OpenBox(msg, (*[24]byte)(&nonce), (*NaclDHKeyPublic)(sender.ToRawBoxKeyPointer()))
+}
+
+// Precompute computes the shared key with peer on the token, for
+// saltpack. If the token fails, the error is kept for takeErr, and the
+// key returned can't open anything.
+func (k *PKCS11DHKey) Precompute(peer saltpack.BoxPublicKey) saltpack.BoxPrecomputedSharedKey {
+	key, err := k.precompute((*NaclDHKeyPublic)(peer.ToRawBoxKeyPointer()))
+	if err != nil {
+		k.setErr(err)
+		return pkcs11FailedSharedKey{err: err}
+	}
+	return naclBoxPrecomputedSharedKey(*key)
+}
+
+func (k *PKCS11DHKey) setErr(err error) {
+	k.errMu.Lock()
+	defer k.errMu.Unlock()
+	if k.err == nil {
+		k.err = err
+	}
+}
+
+// takeErr returns and clears the first error the token had in Box or
+// Precompute.
+func (k *PKCS11DHKey) takeErr() error {
+	k.errMu.Lock()
+	defer k.errMu.Unlock()
+	err := k.err
+	k.err = nil
+	return err
+}
+
+// pkcs11FailedSharedKey stands in for a shared key that the token
+// couldn't compute.
+type pkcs11FailedSharedKey struct {
+	err error
+}
+
+func (k pkcs11FailedSharedKey) Unbox(nonce saltpack.Nonce, msg []byte) ([]byte, error) {
+	return nil, k.err
+}
+
+func (k pkcs11FailedSharedKey) Box(nonce saltpack.Nonce, msg []byte) []byte {
+	return make([]byte, len(msg)+box.Overhead)
+}
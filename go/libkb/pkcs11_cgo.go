@@ -0,0 +1,262 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+// +build cgo
+
+package libkb
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// Mechanisms and key types from PKCS#11 v3.0, which the vendored headers
+// predate.
+const (
+	ckkECEdwards              = 0x40
+	ckkECMontgomery           = 0x41
+	ckmECEdwardsKeyPairGen    = 0x1055
+	ckmECMontgomeryKeyPairGen = 0x1056
+	ckmEdDSA                  = 0x1057
+)
+
+// DER-encoded OIDs for the curves, for CKA_EC_PARAMS.
+var (
+	pkcs11OIDEd25519 = []byte{0x06, 0x03, 0x2b, 0x65, 0x70}
+	pkcs11OIDX25519  = []byte{0x06, 0x03, 0x2b, 0x65, 0x6e}
+)
+
+// PKCS11Token is a logged-in session on a PKCS#11 token. Tokens are
+// shared by all the keys on them, and operations on a token are
+// serialized, since a PKCS#11 session can only do one at a time.
+type PKCS11Token struct {
+	module  string
+	label   string
+	pin     string
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	sync.Mutex
+}
+
+var pkcs11Mu sync.Mutex
+var pkcs11Modules = make(map[string]*pkcs11.Ctx)
+var pkcs11Tokens = make(map[string]*PKCS11Token)
+
+func loadPKCS11Module(module string) (*pkcs11.Ctx, error) {
+	if ctx, ok := pkcs11Modules[module]; ok {
+		return ctx, nil
+	}
+	if len(module) == 0 {
+		return nil, PKCS11Error{Msg: "no PKCS#11 module; set pkcs11_module in the config file"}
+	}
+	ctx := pkcs11.New(module)
+	if ctx == nil {
+		return nil, PKCS11Error{Msg: fmt.Sprintf("can't load PKCS#11 module %s", module)}
+	}
+	if err := ctx.Initialize(); err != nil && err != pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		ctx.Destroy()
+		return nil, pkcs11Err("initializing "+module, err)
+	}
+	pkcs11Modules[module] = ctx
+	return ctx, nil
+}
+
+// OpenPKCS11Token opens a session on the token with the given label, in
+// the given PKCS#11 module, and logs in to it with pin. Sessions are
+// kept open and reused.
+func OpenPKCS11Token(module, label, pin string) (*PKCS11Token, error) {
+	pkcs11Mu.Lock()
+	defer pkcs11Mu.Unlock()
+
+	key := module + "\x00" + label
+	if t, ok := pkcs11Tokens[key]; ok && t.pin == pin {
+		return t, nil
+	}
+
+	ctx, err := loadPKCS11Module(module)
+	if err != nil {
+		return nil, err
+	}
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return nil, pkcs11Err("listing slots", err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return nil, pkcs11Err("getting token info", err)
+		}
+		if strings.TrimRight(info.Label, " \x00") != label {
+			continue
+		}
+		session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			return nil, pkcs11Err("opening a session", err)
+		}
+		err = ctx.Login(session, pkcs11.CKU_USER, pin)
+		if err == pkcs11.Error(pkcs11.CKR_PIN_INCORRECT) {
+			ctx.CloseSession(session)
+			return nil, PKCS11BadPINError{Token: label}
+		}
+		if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+			ctx.CloseSession(session)
+			return nil, pkcs11Err("logging in", err)
+		}
+		t := &PKCS11Token{
+			module:  module,
+			label:   label,
+			pin:     pin,
+			ctx:     ctx,
+			session: session,
+		}
+		pkcs11Tokens[key] = t
+		return t, nil
+	}
+	return nil, PKCS11Error{Msg: fmt.Sprintf("no token labeled %q in %s", label, module)}
+}
+
+// findPKCS11Token returns the token with the given label, in the given
+// module, if it's already been opened, or nil.
+func findPKCS11Token(module, label string) *PKCS11Token {
+	pkcs11Mu.Lock()
+	defer pkcs11Mu.Unlock()
+	return pkcs11Tokens[module+"\x00"+label]
+}
+
+func pkcs11Err(doing string, err error) error {
+	return PKCS11Error{Msg: fmt.Sprintf("%s: %s", doing, err)}
+}
+
+func (t *PKCS11Token) generateKeyPair(id []byte, mech uint, keyType uint, oid []byte, usage uint, label string) (pub []byte, err error) {
+	t.Lock()
+	defer t.Unlock()
+
+	public := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, oid),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	private := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(usage, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	pubHandle, _, err := t.ctx.GenerateKeyPair(t.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mech, nil)}, public, private)
+	if err != nil {
+		return nil, pkcs11Err("generating a key", err)
+	}
+	attrs, err := t.ctx.GetAttributeValue(t.session, pubHandle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, pkcs11Err("getting the public key", err)
+	}
+	return decodePKCS11ECPoint(attrs[0].Value)
+}
+
+// decodePKCS11ECPoint gets the public key out of a CKA_EC_POINT, which
+// tokens are supposed to DER-encode as an octet string, but some don't.
+func decodePKCS11ECPoint(b []byte) ([]byte, error) {
+	switch {
+	case len(b) == 32:
+		return b, nil
+	case len(b) == 34 && b[0] == 0x04 && b[1] == 32:
+		return b[2:], nil
+	}
+	return nil, PKCS11Error{Msg: fmt.Sprintf("unexpected public key encoding %x", b)}
+}
+
+func (t *PKCS11Token) generateEd25519(id []byte) ([]byte, error) {
+	return t.generateKeyPair(id, ckmECEdwardsKeyPairGen, ckkECEdwards, pkcs11OIDEd25519, pkcs11.CKA_SIGN, "keybase device signing key")
+}
+
+func (t *PKCS11Token) generateX25519(id []byte) ([]byte, error) {
+	return t.generateKeyPair(id, ckmECMontgomeryKeyPairGen, ckkECMontgomery, pkcs11OIDX25519, pkcs11.CKA_DERIVE, "keybase device encryption key")
+}
+
+// findPrivateKey finds the private key with the given CKA_ID. Call it
+// with t locked.
+func (t *PKCS11Token) findPrivateKey(id []byte) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+	}
+	if err := t.ctx.FindObjectsInit(t.session, template); err != nil {
+		return 0, pkcs11Err("finding a key", err)
+	}
+	objs, _, err := t.ctx.FindObjects(t.session, 2)
+	if ferr := t.ctx.FindObjectsFinal(t.session); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		return 0, pkcs11Err("finding a key", err)
+	}
+	if len(objs) != 1 {
+		return 0, PKCS11Error{Msg: fmt.Sprintf("found %d keys with ID %x on token %q, expected 1", len(objs), id, t.label)}
+	}
+	return objs[0], nil
+}
+
+func (t *PKCS11Token) signEd25519(id, msg []byte) ([]byte, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	priv, err := t.findPrivateKey(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.ctx.SignInit(t.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(ckmEdDSA, nil)}, priv); err != nil {
+		return nil, pkcs11Err("signing", err)
+	}
+	sig, err := t.ctx.Sign(t.session, msg)
+	if err != nil {
+		return nil, pkcs11Err("signing", err)
+	}
+	return sig, nil
+}
+
+// x25519 computes the X25519 shared secret of the key with the given ID
+// and peer, and returns it. The shared secret is a session object that's
+// destroyed right after it's read.
+func (t *PKCS11Token) x25519(id, peer []byte) ([]byte, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	priv, err := t.findPrivateKey(id)
+	if err != nil {
+		return nil, err
+	}
+	params := pkcs11.NewECDH1DeriveParams(pkcs11.CKD_NULL, nil, peer)
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_GENERIC_SECRET),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, 32),
+	}
+	shared, err := t.ctx.DeriveKey(t.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDH1_DERIVE, params)}, priv, template)
+	if err != nil {
+		return nil, pkcs11Err("computing a shared secret", err)
+	}
+	defer t.ctx.DestroyObject(t.session, shared)
+	attrs, err := t.ctx.GetAttributeValue(t.session, shared, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+	})
+	if err != nil {
+		return nil, pkcs11Err("reading a shared secret", err)
+	}
+	return attrs[0].Value, nil
+}
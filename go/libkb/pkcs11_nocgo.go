@@ -0,0 +1,40 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+// +build !cgo
+
+package libkb
+
+// PKCS11Token is a PKCS#11 token. Without cgo, PKCS#11 modules can't be
+// loaded, so there aren't any.
+type PKCS11Token struct {
+	module string
+	label  string
+	pin    string
+}
+
+var errPKCS11Unsupported = PKCS11Error{Msg: "PKCS#11 tokens aren't supported in this build"}
+
+func OpenPKCS11Token(module, label, pin string) (*PKCS11Token, error) {
+	return nil, errPKCS11Unsupported
+}
+
+func findPKCS11Token(module, label string) *PKCS11Token {
+	return nil
+}
+
+func (t *PKCS11Token) generateEd25519(id []byte) ([]byte, error) {
+	return nil, errPKCS11Unsupported
+}
+
+func (t *PKCS11Token) generateX25519(id []byte) ([]byte, error) {
+	return nil, errPKCS11Unsupported
+}
+
+func (t *PKCS11Token) signEd25519(id, msg []byte) ([]byte, error) {
+	return nil, errPKCS11Unsupported
+}
+
+func (t *PKCS11Token) x25519(id, peer []byte) ([]byte, error) {
+	return nil, errPKCS11Unsupported
+}
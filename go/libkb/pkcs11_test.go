@@ -0,0 +1,105 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+// +build cgo
+
+package libkb
+
+import (
+	"os"
+	"testing"
+)
+
+// TestPKCS11Keys needs a PKCS#11 module with Ed25519 and X25519 support,
+// like SoftHSM, and an initialized token in it:
+//
+//   KEYBASE_TEST_PKCS11_MODULE=/usr/lib/softhsm/libsofthsm2.so
+//   KEYBASE_TEST_PKCS11_TOKEN=<token label>
+//   KEYBASE_TEST_PKCS11_PIN=<user PIN>
+func TestPKCS11Keys(t *testing.T) {
+	module := os.Getenv("KEYBASE_TEST_PKCS11_MODULE")
+	if len(module) == 0 {
+		t.Skip("KEYBASE_TEST_PKCS11_MODULE not set")
+	}
+	tc := SetupTest(t, "pkcs11")
+	defer tc.Cleanup()
+
+	label := os.Getenv("KEYBASE_TEST_PKCS11_TOKEN")
+	if _, err := OpenPKCS11Token(module, label, "not the pin"); err == nil {
+		t.Fatal("opened the token with the wrong PIN")
+	} else if _, ok := err.(PKCS11BadPINError); !ok {
+		t.Fatalf("wrong PIN error: %T %s", err, err)
+	}
+	token, err := OpenPKCS11Token(module, label, os.Getenv("KEYBASE_TEST_PKCS11_PIN"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := token.GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("signed on a token")
+	sig, _, err := signer.SignToString(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ImportKeypairFromKID(signer.GetKID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _, err := pub.VerifyStringAndExtract(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != string(msg) {
+		t.Errorf("signed payload %q, expected %q", payload, msg)
+	}
+
+	dh, err := token.GenerateDHKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender, err := GenerateNaclDHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := dh.EncryptToString(msg, sender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, kid, err := dh.DecryptFromString(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != string(msg) {
+		t.Errorf("decrypted %q, expected %q", plaintext, msg)
+	}
+	if !kid.Equal(sender.GetKID()) {
+		t.Errorf("sender %s, expected %s", kid, sender.GetKID())
+	}
+
+	// Both keys survive a trip through the local keyring.
+	secret, err := RandBytes(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lks := NewLKSecWithFullSecret(secret, "1111111111111111111111111111111111111111111111111111111111111119", tc.G)
+	for _, key := range []GenericKey{signer, dh} {
+		skb, err := key.ToLksSKB(lks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unlocked, _, err := lks.Decrypt(nil, skb.Priv.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := skb.parseUnlocked(unlocked)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.GetKID().Equal(key.GetKID()) || !parsed.HasSecretKey() {
+			t.Errorf("unlocked %s, expected %s with its secret half", parsed.VerboseDescription(), key.VerboseDescription())
+		}
+	}
+}
@@ -16,6 +16,11 @@ type PostProofRes struct {
 	Text     string
 	ID       string
 	Metadata *jsonw.Wrapper
+
+	// Echoed back from the PostProofArg, for services that format
+	// their own proof text.
+	SigID          keybase1.SigID
+	RemoteUsername string
 }
 
 type PostProofArg struct {
@@ -49,7 +54,7 @@ func PostProof(arg PostProofArg) (*PostProofRes, error) {
 	if err != nil {
 		return nil, err
 	}
-	var tmp PostProofRes
+	tmp := PostProofRes{SigID: arg.ID, RemoteUsername: arg.RemoteUsername}
 	res.Body.AtKey("proof_text").GetStringVoid(&tmp.Text, &err)
 	res.Body.AtKey("proof_id").GetStringVoid(&tmp.ID, &err)
 	tmp.Metadata = res.Body.AtKey("proof_metadata")
@@ -89,6 +89,7 @@ type ProofCache struct {
 	capac int
 	lru   *lru.Cache
 	sync.RWMutex
+	cacheCounter
 }
 
 func NewProofCache(g *GlobalContext, capac int) *ProofCache {
@@ -153,6 +154,7 @@ func (pc *ProofCache) Get(sid keybase1.SigID) *CheckResult {
 	if cr == nil {
 		cr = pc.dbGet(sid)
 	}
+	pc.record(cr != nil)
 	return cr
 }
 
@@ -0,0 +1,395 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	keybase1 "github.com/keybase/client/go/protocol"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+//=============================================================================
+// Generic proof services, declared in the "proof_services" section of
+// config.json rather than in code. For example:
+//
+//   "proof_services" : [{
+//     "name" : "forge",
+//     "display_name" : "Forge",
+//     "username_regexp" : "^[a-z0-9_-]{1,32}$",
+//     "profile_url" : "https://forge.example.com/{username}",
+//     "check_url" : "https://forge.example.com/api/v1/users/{username}",
+//     "check_type" : "json",
+//     "check_path" : "$.bio",
+//     "username_path" : "$.login",
+//     "proof_text" : "I am {kb_username} on Keybase: {sig_id}"
+//   }]
+//
+// URL and proof text templates can use {username}, {kb_username},
+// {sig_id}, {sig_id_short} and {proof_text} (the text the server suggested).
+//
+
+// Ways that a GenericServiceConfig can scrape a proof.
+const (
+	GenericCheckTypeJSON = "json" // check_path is a JSONPath like $.a.b[0].c or $.posts[*].text
+	GenericCheckTypeHTML = "html" // check_path is a CSS selector
+	GenericCheckTypeText = "text" // the whole body is searched
+)
+
+type GenericServiceConfig struct {
+	Name             string `json:"name"`
+	DisplayName      string `json:"display_name"`
+	UsernameRegexp   string `json:"username_regexp"`
+	UsernameHint     string `json:"username_hint"`
+	CaseSensitive    bool   `json:"case_sensitive"`
+	ProfileURL       string `json:"profile_url"`
+	CheckURL         string `json:"check_url"`
+	CheckType        string `json:"check_type"`
+	CheckPath        string `json:"check_path"`
+	UsernamePath     string `json:"username_path"`
+	ProofText        string `json:"proof_text"`
+	PostInstructions string `json:"post_instructions"`
+}
+
+func (c GenericServiceConfig) checkURL() string {
+	if len(c.CheckURL) > 0 {
+		return c.CheckURL
+	}
+	return c.ProfileURL
+}
+
+// usesShortSigID is true if the proof text only has room for the short
+// sig ID, in which case that's what we look for when scraping.
+func (c GenericServiceConfig) usesShortSigID() bool {
+	return strings.Contains(c.ProofText, "{sig_id_short}") &&
+		!strings.Contains(c.ProofText, "{sig_id}") &&
+		!strings.Contains(c.ProofText, "{proof_text}")
+}
+
+// Validate checks that the service config is complete and doesn't clash
+// with one of the built-in services.
+func (c GenericServiceConfig) Validate() error {
+	bad := func(format string, args ...interface{}) error {
+		return fmt.Errorf("proof service %q: %s", c.Name, fmt.Sprintf(format, args...))
+	}
+	if !regexp.MustCompile(`^[a-z0-9_.-]+$`).MatchString(c.Name) {
+		return bad("name must be lowercase alphanumeric")
+	}
+	if _, found := RemoteServiceTypes[c.Name]; found {
+		if st, ok := GetServiceType(c.Name).(GenericServiceType); !ok || st.cfg.Name != c.Name {
+			return bad("name clashes with a built-in service")
+		}
+	}
+	if _, err := regexp.Compile(c.UsernameRegexp); err != nil || len(c.UsernameRegexp) == 0 {
+		return bad("bad username_regexp: %v", err)
+	}
+	if !strings.Contains(c.checkURL(), "{username}") {
+		return bad("check_url (or profile_url) must contain {username}")
+	}
+	switch c.CheckType {
+	case GenericCheckTypeJSON, GenericCheckTypeHTML:
+		if len(c.CheckPath) == 0 {
+			return bad("check_type %q needs a check_path", c.CheckType)
+		}
+	case GenericCheckTypeText:
+	default:
+		return bad("unknown check_type %q", c.CheckType)
+	}
+	if len(c.ProofText) > 0 && !strings.Contains(c.ProofText, "{sig_id}") &&
+		!strings.Contains(c.ProofText, "{sig_id_short}") && !strings.Contains(c.ProofText, "{proof_text}") {
+		return bad("proof_text must include {sig_id}, {sig_id_short} or {proof_text}")
+	}
+	return nil
+}
+
+func (c GenericServiceConfig) expand(tmpl string, un string, sigID keybase1.SigID, proofText string) string {
+	pairs := []string{
+		"{username}", un,
+		"{kb_username}", G.Env.GetUsername().String(),
+		"{proof_text}", proofText,
+	}
+	if len(sigID) > 0 {
+		pairs = append(pairs, "{sig_id}", sigID.ToMediumID(), "{sig_id_short}", sigID.ToShortID())
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+//=============================================================================
+
+type GenericChecker struct {
+	cfg   GenericServiceConfig
+	proof RemoteProofChainLink
+}
+
+func NewGenericChecker(cfg GenericServiceConfig, p RemoteProofChainLink) (*GenericChecker, ProofError) {
+	return &GenericChecker{cfg: cfg, proof: p}, nil
+}
+
+func (rc *GenericChecker) GetTorError() ProofError { return nil }
+
+func (rc *GenericChecker) apiURL() string {
+	return rc.cfg.expand(rc.cfg.checkURL(), rc.proof.GetRemoteUsername(), "", "")
+}
+
+func (rc *GenericChecker) wantedSigID() string {
+	if rc.cfg.usesShortSigID() {
+		return rc.proof.GetSigID().ToShortID()
+	}
+	return rc.proof.GetSigID().ToMediumID()
+}
+
+func (rc *GenericChecker) CheckHint(h SigHint) ProofError {
+	wanted := rc.apiURL()
+	if !Cicmp(wanted, h.apiURL) {
+		return NewProofError(keybase1.ProofStatus_BAD_API_URL,
+			"Bad hint from server; URL should be '%s'; got '%s'", wanted, h.apiURL)
+	}
+	return nil
+}
+
+func (rc *GenericChecker) ScreenNameCompare(s1, s2 string) bool {
+	if rc.cfg.CaseSensitive {
+		return s1 == s2
+	}
+	return Cicmp(s1, s2)
+}
+
+func (rc *GenericChecker) checkUsername(found []string) ProofError {
+	if len(found) == 0 {
+		return NewProofError(keybase1.ProofStatus_BAD_USERNAME, "Username not found at %q", rc.cfg.UsernamePath)
+	}
+	wanted := rc.proof.GetRemoteUsername()
+	if !rc.ScreenNameCompare(wanted, found[0]) {
+		return NewProofError(keybase1.ProofStatus_BAD_USERNAME,
+			"Bad proof author; wanted %q but got %q", wanted, found[0])
+	}
+	return nil
+}
+
+func (rc *GenericChecker) checkText(texts []string) ProofError {
+	if len(texts) == 0 {
+		return NewProofError(keybase1.ProofStatus_CONTENT_MISSING, "Nothing found at %q", rc.cfg.CheckPath)
+	}
+	wanted := rc.wantedSigID()
+	for _, t := range texts {
+		if strings.Contains(t, wanted) {
+			return nil
+		}
+	}
+	return NewProofError(keybase1.ProofStatus_TEXT_NOT_FOUND,
+		"Posted text does not include signature '%s'", wanted)
+}
+
+func (rc *GenericChecker) CheckStatus(h SigHint) ProofError {
+	u := rc.apiURL()
+	G.Log.Debug("+ Checking %s proof at %s", rc.cfg.Name, u)
+
+	arg := APIArg{Endpoint: u, NeedSession: false}
+
+	switch rc.cfg.CheckType {
+	case GenericCheckTypeJSON:
+		res, err := G.XAPI.Get(arg)
+		if err != nil {
+			return XapiError(err, u)
+		}
+		if len(rc.cfg.UsernamePath) > 0 {
+			if perr := rc.checkUsername(JSONPathStrings(res.Body, rc.cfg.UsernamePath)); perr != nil {
+				return perr
+			}
+		}
+		return rc.checkText(JSONPathStrings(res.Body, rc.cfg.CheckPath))
+
+	case GenericCheckTypeHTML:
+		res, err := G.XAPI.GetHTML(arg)
+		if err != nil {
+			return XapiError(err, u)
+		}
+		if len(rc.cfg.UsernamePath) > 0 {
+			if perr := rc.checkUsername(selectionStrings(res.GoQuery.Find(rc.cfg.UsernamePath))); perr != nil {
+				return perr
+			}
+		}
+		return rc.checkText(selectionStrings(res.GoQuery.Find(rc.cfg.CheckPath)))
+
+	default:
+		res, err := G.XAPI.GetText(arg)
+		if err != nil {
+			return XapiError(err, u)
+		}
+		return rc.checkText([]string{res.Body})
+	}
+}
+
+func selectionStrings(s *goquery.Selection) (ret []string) {
+	s.Each(func(_ int, e *goquery.Selection) {
+		ret = append(ret, strings.TrimSpace(e.Text()))
+	})
+	return ret
+}
+
+// JSONPathStrings evaluates a simple JSONPath expression (dotted keys,
+// [n] indices and [*] wildcards, with an optional leading $) against w,
+// and returns all of the string values it selects.
+func JSONPathStrings(w *jsonw.Wrapper, path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.Replace(path, "[", ".", -1)
+	path = strings.Replace(path, "]", "", -1)
+	var bits []string
+	if len(path) > 0 {
+		bits = strings.Split(path, ".")
+	}
+	var ret []string
+	jsonPathCollect(w, bits, &ret)
+	return ret
+}
+
+func jsonPathCollect(w *jsonw.Wrapper, bits []string, ret *[]string) {
+	if w == nil || w.IsNil() {
+		return
+	}
+	if len(bits) == 0 {
+		if s, err := w.GetString(); err == nil {
+			*ret = append(*ret, s)
+		}
+		return
+	}
+	bit, rest := bits[0], bits[1:]
+	if bit == "*" {
+		n, err := w.Len()
+		if err != nil {
+			return
+		}
+		for i := 0; i < n; i++ {
+			jsonPathCollect(w.AtIndex(i), rest, ret)
+		}
+		return
+	}
+	if i, err := strconv.Atoi(bit); err == nil {
+		jsonPathCollect(w.AtIndex(i), rest, ret)
+		return
+	}
+	jsonPathCollect(w.AtKey(bit), rest, ret)
+}
+
+//
+//=============================================================================
+
+type GenericServiceType struct {
+	BaseServiceType
+	cfg GenericServiceConfig
+}
+
+func (t GenericServiceType) AllStringKeys() []string { return t.BaseAllStringKeys(t) }
+
+func (t GenericServiceType) CheckUsername(s string) (err error) {
+	if !regexp.MustCompile(t.cfg.UsernameRegexp).MatchString(s) {
+		err = BadUsernameError{s}
+	}
+	return
+}
+
+func (t GenericServiceType) NormalizeUsername(s string) (string, error) {
+	if t.cfg.CaseSensitive {
+		return s, nil
+	}
+	return strings.ToLower(s), nil
+}
+
+func (t GenericServiceType) CaseSensitiveUsername() bool {
+	return t.cfg.CaseSensitive
+}
+
+func (t GenericServiceType) ToChecker() Checker {
+	hint := t.cfg.UsernameHint
+	if len(hint) == 0 {
+		hint = "must match " + t.cfg.UsernameRegexp
+	}
+	return t.BaseToChecker(t, hint)
+}
+
+func (t GenericServiceType) GetPrompt() string {
+	return "Your username on " + t.DisplayName("")
+}
+
+func (t GenericServiceType) ToServiceJSON(un string) *jsonw.Wrapper {
+	return t.BaseToServiceJSON(t, un)
+}
+
+func (t GenericServiceType) PostInstructions(un string) *Markup {
+	if len(t.cfg.PostInstructions) > 0 {
+		return FmtMarkup("%s", t.cfg.PostInstructions)
+	}
+	profile := t.cfg.expand(t.cfg.ProfileURL, un, "", "")
+	return FmtMarkup("Please post the following on %s, where it's visible at %s:", t.DisplayName(un), profile)
+}
+
+func (t GenericServiceType) DisplayName(un string) string {
+	if len(t.cfg.DisplayName) > 0 {
+		return t.cfg.DisplayName
+	}
+	return t.cfg.Name
+}
+func (t GenericServiceType) GetTypeName() string { return t.cfg.Name }
+
+func (t GenericServiceType) RecheckProofPosting(tryNumber int, status keybase1.ProofStatus, _ string) (warning *Markup, err error) {
+	return t.BaseRecheckProofPosting(tryNumber, status)
+}
+func (t GenericServiceType) GetProofType() string { return t.BaseGetProofType(t) }
+
+// FormatProofText fills in the configured proof text template, if there
+// is one; otherwise the server's suggested text is used as is.
+func (t GenericServiceType) FormatProofText(ppr *PostProofRes) (string, error) {
+	if len(t.cfg.ProofText) == 0 {
+		return ppr.Text, nil
+	}
+	return t.cfg.expand(t.cfg.ProofText, ppr.RemoteUsername, ppr.SigID, ppr.Text), nil
+}
+
+// CheckProofText checks the server's text only if it is going to be
+// posted; otherwise our own template determines what's posted.
+func (t GenericServiceType) CheckProofText(text string, id keybase1.SigID, sig string) (err error) {
+	if len(t.cfg.ProofText) > 0 && !strings.Contains(t.cfg.ProofText, "{proof_text}") {
+		return nil
+	}
+	return t.BaseCheckProofTextShort(text, id, true)
+}
+
+//=============================================================================
+
+// RegisterGenericServiceTypes registers a proof service for each valid
+// entry in the "proof_services" section of the config file, alongside the
+// built-in services. Invalid entries are skipped with a warning.
+func RegisterGenericServiceTypes(g *GlobalContext) {
+	cfgs, err := g.Env.GetProofServices()
+	if err != nil {
+		g.Log.Warning("Error reading proof_services from config: %s", err)
+		return
+	}
+	for _, cfg := range cfgs {
+		if err := cfg.Validate(); err != nil {
+			g.Log.Warning("Skipping proof service: %s", err)
+			continue
+		}
+		RegisterGenericServiceType(cfg)
+		g.Log.Debug("Registered generic proof service %q", cfg.Name)
+	}
+}
+
+// RegisterGenericServiceType registers a single, already validated
+// generic proof service.
+func RegisterGenericServiceType(cfg GenericServiceConfig) {
+	RegisterServiceType(GenericServiceType{cfg: cfg})
+	RegisterSocialNetwork(cfg.Name)
+	RemoteServiceTypes[cfg.Name] = keybase1.ProofType_GENERIC_SOCIAL
+	RegisterMakeProofCheckerFunc(cfg.Name,
+		func(l RemoteProofChainLink) (ProofChecker, ProofError) {
+			return NewGenericChecker(cfg, l)
+		})
+}
+
+//=============================================================================
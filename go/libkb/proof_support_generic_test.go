@@ -0,0 +1,155 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	jsonw "github.com/keybase/go-jsonw"
+)
+
+const genericTestSigID = keybase1.SigID("5ea5b2c4f1c3ba4a4b6cf3ee6bfdcd82f2b9ad4b8b4e4f2e3c7e8a8b6d1a32910f")
+
+func genericTestConfig(checkURL, checkType, checkPath string) GenericServiceConfig {
+	return GenericServiceConfig{
+		Name:           "forge",
+		DisplayName:    "Forge",
+		UsernameRegexp: `^[a-z0-9_-]{1,32}$`,
+		ProfileURL:     "https://forge.example.com/{username}",
+		CheckURL:       checkURL,
+		CheckType:      checkType,
+		CheckPath:      checkPath,
+		ProofText:      "Verifying myself: {sig_id}",
+	}
+}
+
+func TestGenericServiceConfigValidate(t *testing.T) {
+	good := genericTestConfig("https://forge.example.com/api/{username}", GenericCheckTypeJSON, "$.bio")
+	if err := good.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := []func(c *GenericServiceConfig){
+		func(c *GenericServiceConfig) { c.Name = "twitter" },
+		func(c *GenericServiceConfig) { c.Name = "Forge!" },
+		func(c *GenericServiceConfig) { c.UsernameRegexp = "(" },
+		func(c *GenericServiceConfig) { c.CheckURL = "https://forge.example.com/api" },
+		func(c *GenericServiceConfig) { c.CheckType = "xml" },
+		func(c *GenericServiceConfig) { c.CheckPath = "" },
+		func(c *GenericServiceConfig) { c.ProofText = "no sig here" },
+	}
+	for i, f := range bad {
+		c := good
+		f(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%d: expected a validation error", i)
+		}
+	}
+}
+
+func TestJSONPathStrings(t *testing.T) {
+	w, err := jsonw.Unmarshal([]byte(`{"user":{"login":"max","posts":[{"text":"a"},{"text":"b"},{"other":1}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$.user.login", "max"},
+		{"user.login", "max"},
+		{"$.user.posts[1].text", "b"},
+		{"$.user.posts[*].text", "a,b"},
+		{"$.user.nope", ""},
+		{"$.user", ""},
+	}
+	for _, test := range tests {
+		got := strings.Join(JSONPathStrings(w, test.path), ",")
+		if got != test.want {
+			t.Errorf("%s: got %q, wanted %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestGenericServiceType(t *testing.T) {
+	tc := SetupTest(t, "generic_service")
+	defer tc.Cleanup()
+
+	st := GenericServiceType{cfg: genericTestConfig("", GenericCheckTypeText, "")}
+	if err := st.CheckUsername("max"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.CheckUsername("m@x"); err == nil {
+		t.Fatal("expected a bad username")
+	}
+	if st.GetProofType() != "web_service_binding.forge" {
+		t.Fatalf("bad proof type: %s", st.GetProofType())
+	}
+	txt, err := st.FormatProofText(&PostProofRes{SigID: genericTestSigID, RemoteUsername: "max"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txt != "Verifying myself: "+genericTestSigID.ToMediumID() {
+		t.Fatalf("bad proof text: %q", txt)
+	}
+}
+
+type genericTestProof struct {
+	RemoteProofChainLink
+}
+
+func (genericTestProof) GetRemoteUsername() string { return "max" }
+func (genericTestProof) GetSigID() keybase1.SigID  { return genericTestSigID }
+
+func TestGenericCheckerJSON(t *testing.T) {
+	tc := SetupTest(t, "generic_checker")
+	defer tc.Cleanup()
+
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/max" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+
+	cfg := genericTestConfig(ts.URL+"/api/{username}", GenericCheckTypeJSON, "$.bio")
+	cfg.UsernamePath = "$.login"
+	rc, _ := NewGenericChecker(cfg, genericTestProof{})
+
+	if perr := rc.CheckHint(SigHint{apiURL: ts.URL + "/api/max"}); perr != nil {
+		t.Fatal(perr)
+	}
+	if perr := rc.CheckHint(SigHint{apiURL: ts.URL + "/api/evil"}); perr == nil {
+		t.Fatal("expected a bad hint")
+	}
+
+	tests := []struct {
+		body   string
+		status keybase1.ProofStatus
+	}{
+		{fmt.Sprintf(`{"login":"Max","bio":"Verifying myself: %s"}`, genericTestSigID.ToMediumID()), keybase1.ProofStatus_OK},
+		{`{"login":"max","bio":"nothing to see"}`, keybase1.ProofStatus_TEXT_NOT_FOUND},
+		{fmt.Sprintf(`{"login":"eve","bio":"%s"}`, genericTestSigID.ToMediumID()), keybase1.ProofStatus_BAD_USERNAME},
+		{`{"login":"max"}`, keybase1.ProofStatus_CONTENT_MISSING},
+	}
+	for i, test := range tests {
+		body = test.body
+		perr := rc.CheckStatus(SigHint{})
+		status := keybase1.ProofStatus_OK
+		if perr != nil {
+			status = perr.GetProofStatus()
+		}
+		if status != test.status {
+			t.Errorf("%d: got status %v (%v), wanted %v", i, status, perr, test.status)
+		}
+	}
+}
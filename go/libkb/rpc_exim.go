@@ -9,6 +9,7 @@ import (
 	"errors"
 	"io"
 	"sort"
+	"strconv"
 	"strings"
 
 	keybase1 "github.com/keybase/client/go/protocol"
@@ -321,6 +322,34 @@ func ImportStatusAsError(s *keybase1.Status) error {
 		return DeviceAlreadyProvisionedError{}
 	case SCDeviceNoProvision:
 		return ProvisionUnavailableError{}
+	case SCWeakPassphrase:
+		var kind, word, what string
+		var bits, length, required int
+		for _, field := range s.Fields {
+			switch field.Key {
+			case "kind":
+				kind = field.Value
+			case "word":
+				word = field.Value
+			case "what":
+				what = field.Value
+			case "bits":
+				bits, _ = strconv.Atoi(field.Value)
+			case "length":
+				length, _ = strconv.Atoi(field.Value)
+			case "required":
+				required, _ = strconv.Atoi(field.Value)
+			}
+		}
+		switch kind {
+		case "too_short":
+			return ShortPassphraseError{Length: length, Required: required}
+		case "banned_word":
+			return BannedPassphraseWordError{Word: word}
+		case "user_info":
+			return PassphraseHasUserInfoError{What: what}
+		}
+		return WeakPassphraseError{Bits: bits, Required: required}
 	default:
 		ase := AppStatusError{
 			Code:   s.Code,
@@ -1087,3 +1116,53 @@ func (e ProvisionUnavailableError) ToStatus() keybase1.Status {
 		Name: "SC_DEVICE_NO_PROVISION",
 	}
 }
+
+func (e ShortPassphraseError) ToStatus() keybase1.Status {
+	return keybase1.Status{
+		Code: SCWeakPassphrase,
+		Name: "SC_WEAK_PASSPHRASE",
+		Desc: e.Error(),
+		Fields: []keybase1.StringKVPair{
+			{Key: "kind", Value: "too_short"},
+			{Key: "length", Value: strconv.Itoa(e.Length)},
+			{Key: "required", Value: strconv.Itoa(e.Required)},
+		},
+	}
+}
+
+func (e WeakPassphraseError) ToStatus() keybase1.Status {
+	return keybase1.Status{
+		Code: SCWeakPassphrase,
+		Name: "SC_WEAK_PASSPHRASE",
+		Desc: e.Error(),
+		Fields: []keybase1.StringKVPair{
+			{Key: "kind", Value: "entropy"},
+			{Key: "bits", Value: strconv.Itoa(e.Bits)},
+			{Key: "required", Value: strconv.Itoa(e.Required)},
+		},
+	}
+}
+
+func (e BannedPassphraseWordError) ToStatus() keybase1.Status {
+	return keybase1.Status{
+		Code: SCWeakPassphrase,
+		Name: "SC_WEAK_PASSPHRASE",
+		Desc: e.Error(),
+		Fields: []keybase1.StringKVPair{
+			{Key: "kind", Value: "banned_word"},
+			{Key: "word", Value: e.Word},
+		},
+	}
+}
+
+func (e PassphraseHasUserInfoError) ToStatus() keybase1.Status {
+	return keybase1.Status{
+		Code: SCWeakPassphrase,
+		Name: "SC_WEAK_PASSPHRASE",
+		Desc: e.Error(),
+		Fields: []keybase1.StringKVPair{
+			{Key: "kind", Value: "user_info"},
+			{Key: "what", Value: e.What},
+		},
+	}
+}
@@ -65,18 +65,18 @@ type naclBoxPrecomputedSharedKey [32]byte
 
 var _ saltpack.BoxPrecomputedSharedKey = naclBoxPrecomputedSharedKey{}
 
-func (k naclBoxPrecomputedSharedKey) Unbox(nonce *saltpack.Nonce, msg []byte) (
+func (k naclBoxPrecomputedSharedKey) Unbox(nonce saltpack.Nonce, msg []byte) (
 	[]byte, error) {
 	ret, ok := box.OpenAfterPrecomputation(
-		[]byte{}, msg, (*[24]byte)(nonce), (*[32]byte)(&k))
+		[]byte{}, msg, (*[24]byte)(&nonce), (*[32]byte)(&k))
 	if !ok {
 		return nil, DecryptionError{}
 	}
 	return ret, nil
 }
 
-func (k naclBoxPrecomputedSharedKey) Box(nonce *saltpack.Nonce, msg []byte) []byte {
-	ret := box.SealAfterPrecomputation([]byte{}, msg, (*[24]byte)(nonce), (*[32]byte)(&k))
+func (k naclBoxPrecomputedSharedKey) Box(nonce saltpack.Nonce, msg []byte) []byte {
+	ret := box.SealAfterPrecomputation([]byte{}, msg, (*[24]byte)(&nonce), (*[32]byte)(&k))
 	return ret
 }
 
@@ -85,17 +85,17 @@ type naclBoxSecretKey NaclDHKeyPair
 var _ saltpack.BoxSecretKey = naclBoxSecretKey{}
 
 func (n naclBoxSecretKey) Box(
-	receiver saltpack.BoxPublicKey, nonce *saltpack.Nonce, msg []byte) []byte {
-	ret := box.Seal([]byte{}, msg, (*[24]byte)(nonce),
+	receiver saltpack.BoxPublicKey, nonce saltpack.Nonce, msg []byte) []byte {
+	ret := box.Seal([]byte{}, msg, (*[24]byte)(&nonce),
 		(*[32]byte)(receiver.ToRawBoxKeyPointer()),
 		(*[32]byte)(n.Private))
 	return ret
 }
 
 func (n naclBoxSecretKey) Unbox(
-	sender saltpack.BoxPublicKey, nonce *saltpack.Nonce, msg []byte) (
+	sender saltpack.BoxPublicKey, nonce saltpack.Nonce, msg []byte) (
 	[]byte, error) {
-	ret, ok := box.Open([]byte{}, msg, (*[24]byte)(nonce),
+	ret, ok := box.Open([]byte{}, msg, (*[24]byte)(&nonce),
 		(*[32]byte)(sender.ToRawBoxKeyPointer()),
 		(*[32]byte)(n.Private))
 	if !ok {
@@ -117,18 +117,22 @@ func (n naclBoxSecretKey) Precompute(
 	return res
 }
 
-// A secret key also functions as a keyring with a single key.
-type naclKeyring naclBoxSecretKey
+// naclKeyring is a keyring of the secret keys a message can be decrypted
+// with: the device's encryption key, and any it had before.
+type naclKeyring struct {
+	keys []saltpack.BoxSecretKey
+}
 
 var _ saltpack.Keyring = naclKeyring{}
 
 func (n naclKeyring) LookupBoxSecretKey(
 	kids [][]byte) (int, saltpack.BoxSecretKey) {
-	sk := (naclBoxSecretKey)(n)
-	pkKid := sk.GetPublicKey().ToKID()
-	for i, kid := range kids {
-		if bytes.Compare(pkKid, kid) == 0 {
-			return i, sk
+	for _, key := range n.keys {
+		pkKid := key.GetPublicKey().ToKID()
+		for i, kid := range kids {
+			if bytes.Compare(pkKid, kid) == 0 {
+				return i, key
+			}
 		}
 	}
 
@@ -145,13 +149,60 @@ func (n naclKeyring) LookupBoxPublicKey(kid []byte) saltpack.BoxPublicKey {
 }
 
 func (n naclKeyring) GetAllBoxSecretKeys() []saltpack.BoxSecretKey {
-	return []saltpack.BoxSecretKey{naclBoxSecretKey(n)}
+	return n.keys
 }
 
 func (n naclKeyring) ImportBoxEphemeralKey(kid []byte) saltpack.BoxPublicKey {
 	return n.LookupBoxPublicKey(kid)
 }
 
+func (n naclKeyring) CreateEphemeralKey() (saltpack.BoxSecretKey, error) {
+	return ephemeralKeyCreator{}.CreateEphemeralKey()
+}
+
+// ephemeralKeyCreator makes the one-time keys that saltpack encrypts the
+// payload key to.
+type ephemeralKeyCreator struct{}
+
+func (ephemeralKeyCreator) CreateEphemeralKey() (saltpack.BoxSecretKey, error) {
+	kp, err := GenerateNaclDHKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	return naclBoxSecretKey(kp), nil
+}
+
+// saltpackBoxSecretKey returns key as a saltpack.BoxSecretKey. Keys on
+// PKCS#11 tokens do the box operations themselves; plain NaCl keys are
+// wrapped.
+func saltpackBoxSecretKey(key GenericKey) (saltpack.BoxSecretKey, error) {
+	if key == nil || !key.CanDecrypt() {
+		return nil, KeyCannotDecryptError{}
+	}
+	switch k := key.(type) {
+	case saltpack.BoxSecretKey:
+		return k, nil
+	case NaclDHKeyPair:
+		return naclBoxSecretKey(k), nil
+	case *NaclDHKeyPair:
+		return naclBoxSecretKey(*k), nil
+	}
+	return nil, KeyCannotDecryptError{}
+}
+
+// saltpackKeyErr returns the error that key's token had during a
+// saltpack operation, if any, in place of err. saltpack only sees the
+// box that failed to open, not why.
+func saltpackKeyErr(key saltpack.BoxSecretKey, err error) error {
+	if k, ok := key.(*PKCS11DHKey); ok {
+		if kerr := k.takeErr(); kerr != nil {
+			return kerr
+		}
+	}
+	return err
+}
+
 func BoxPublicKeyToKeybaseKID(k saltpack.BoxPublicKey) (ret keybase1.KID) {
 	if k == nil {
 		return ret
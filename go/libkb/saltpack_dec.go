@@ -5,14 +5,26 @@ package libkb
 
 import (
 	"io"
+	"time"
 
 	"github.com/keybase/saltpack"
 )
 
+// SaltpackMessageKeyInfo is saltpack's MessageKeyInfo plus the sender's
+// signing key. A signcrypted message names its sender by SenderSigningKey
+// instead of SenderKey, and doesn't say anything about its receivers.
+type SaltpackMessageKeyInfo struct {
+	saltpack.MessageKeyInfo
+	SenderSigningKey saltpack.SigningPublicKey
+}
+
+// SaltpackDecrypt decrypts the saltpack message in source to sink with
+// deviceEncryptionKey, or with one of revokedKeys, the device's earlier
+// encryption keys, if the message was encrypted to one of those.
 func SaltpackDecrypt(
 	g *GlobalContext, source io.Reader, sink io.WriteCloser,
-	deviceEncryptionKey NaclDHKeyPair,
-	checkSender func(*saltpack.MessageKeyInfo) error) (*saltpack.MessageKeyInfo, error) {
+	deviceEncryptionKey GenericKey, revokedKeys []GenericKey,
+	checkSender func(*SaltpackMessageKeyInfo) error) (*SaltpackMessageKeyInfo, error) {
 
 	sc, newSource, err := ClassifyStream(source)
 	if err != nil {
@@ -29,17 +41,52 @@ func SaltpackDecrypt(
 
 	source = newSource
 
-	var mki *saltpack.MessageKeyInfo
-	var plainsource io.Reader
+	bsk, err := saltpackBoxSecretKey(deviceEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	keyring := naclKeyring{keys: []saltpack.BoxSecretKey{bsk}}
+	for _, key := range revokedKeys {
+		if rbsk, err := saltpackBoxSecretKey(key); err == nil {
+			keyring.keys = append(keyring.keys, rbsk)
+		}
+	}
+
+	// Encrypted and signcrypted messages share the same armor frame, so
+	// armored input has to be decoded before we can tell them apart.
+	armored := sc.Armored
 	var frame saltpack.Frame
-	if sc.Armored {
-		mki, plainsource, frame, err = saltpack.NewDearmor62DecryptStream(source, naclKeyring(deviceEncryptionKey))
+	if armored {
+		source, frame, err = saltpack.NewArmor62DecoderStream(source, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		sc, source, err = ClassifyStream(source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mki := &SaltpackMessageKeyInfo{}
+	var plainsource io.Reader
+	if sc.Type == CryptoMessageTypeSigncryption {
+		kr := signcryptKeyring{
+			naclKeyring: keyring,
+			echoKeyring: echoKeyring{Contextified: NewContextified(g)},
+		}
+		mki.SenderSigningKey, plainsource, err = saltpack.NewSigncryptOpenStream(source, kr, nil)
+		mki.SenderIsAnon = err == nil && mki.SenderSigningKey == nil
+		mki.ReceiverIsAnon = true
 	} else {
-		mki, plainsource, err = saltpack.NewDecryptStream(source, naclKeyring(deviceEncryptionKey))
+		var smki *saltpack.MessageKeyInfo
+		smki, plainsource, err = saltpack.NewDecryptStream(saltpack.CheckKnownMajorVersion, source, keyring)
+		if smki != nil {
+			mki.MessageKeyInfo = *smki
+		}
 	}
 
 	if err != nil {
-		return mki, err
+		return mki, keyring.keyErr(err)
 	}
 
 	if checkSender != nil {
@@ -50,12 +97,12 @@ func SaltpackDecrypt(
 
 	n, err := io.Copy(sink, plainsource)
 	if err != nil {
-		return mki, err
+		return mki, keyring.keyErr(err)
 	}
 
 	// TODO: Check header inline, and only warn if the footer
 	// doesn't match.
-	if sc.Armored {
+	if armored {
 		var brand string
 		brand, err = saltpack.CheckArmor62Frame(frame, saltpack.MessageTypeEncryption)
 		if err != nil {
@@ -71,5 +118,29 @@ func SaltpackDecrypt(
 	if err := sink.Close(); err != nil {
 		return mki, err
 	}
+	if mki.SenderSigningKey != nil {
+		noteKeySeenSigning(g, SigningPublicKeyToKeybaseKID(mki.SenderSigningKey), time.Now())
+	}
 	return mki, nil
 }
+
+// keyErr returns the error that one of n's keys had on its token during a
+// saltpack operation, if any, in place of err.
+func (n naclKeyring) keyErr(err error) error {
+	for _, key := range n.keys {
+		if kerr := saltpackKeyErr(key, nil); kerr != nil {
+			return kerr
+		}
+	}
+	return err
+}
+
+// signcryptKeyring opens signcrypted messages with the device encryption
+// key, and takes the sender's signing key from the message itself, as
+// echoKeyring does for signatures.
+type signcryptKeyring struct {
+	naclKeyring
+	echoKeyring
+}
+
+var _ saltpack.SigncryptKeyring = signcryptKeyring{}
@@ -13,15 +13,25 @@ type SaltpackEncryptArg struct {
 	Source         io.Reader
 	Sink           io.WriteCloser
 	Receivers      []NaclDHKeyPublic
-	Sender         NaclDHKeyPair
+	Sender         GenericKey
 	Binary         bool
 	HideRecipients bool
+
+	// If Signcrypt is true, the message is signed by SigningKey rather
+	// than authenticated by Sender. A nil Sender or SigningKey makes the
+	// sender anonymous.
+	Signcrypt  bool
+	SigningKey GenericKey
 }
 
 // SaltpackEncrypt reads from the given source, encrypts it for the given
 // receivers from the given sender, and writes it to sink.  If
 // Binary is false, the data written to sink will be armored.
 func SaltpackEncrypt(g *GlobalContext, arg *SaltpackEncryptArg) error {
+	if arg.Signcrypt {
+		return saltpackSigncrypt(g, arg)
+	}
+
 	var receiverBoxKeys []saltpack.BoxPublicKey
 	for _, k := range arg.Receivers {
 		if arg.HideRecipients {
@@ -32,27 +42,66 @@ func SaltpackEncrypt(g *GlobalContext, arg *SaltpackEncryptArg) error {
 	}
 
 	var bsk saltpack.BoxSecretKey
-	if !arg.Sender.IsNil() {
-		bsk = naclBoxSecretKey(arg.Sender)
+	if arg.Sender != nil {
+		var err error
+		if bsk, err = saltpackBoxSecretKey(arg.Sender); err != nil {
+			return err
+		}
+	}
+
+	var plainsink io.WriteCloser
+	var err error
+	if arg.Binary {
+		plainsink, err = saltpack.NewEncryptStream(saltpack.Version1(), arg.Sink, bsk, receiverBoxKeys)
+	} else {
+		plainsink, err = saltpack.NewEncryptArmor62Stream(saltpack.Version1(), arg.Sink, bsk, receiverBoxKeys, KeybaseSaltpackBrand)
+	}
+	if err != nil {
+		return err
+	}
+
+	return saltpackKeyErr(bsk, saltpackCopyAndClose(g, arg, plainsink, "Encrypt"))
+}
+
+// saltpackSigncrypt is like SaltpackEncrypt, but every chunk of the
+// message is signed with the sender's signing key before it is encrypted,
+// so the receivers can verify which device sent it. Receivers are always
+// hidden in a signcrypted message.
+func saltpackSigncrypt(g *GlobalContext, arg *SaltpackEncryptArg) error {
+	var receiverBoxKeys []saltpack.BoxPublicKey
+	for _, k := range arg.Receivers {
+		receiverBoxKeys = append(receiverBoxKeys, naclBoxPublicKey(k))
+	}
+
+	var signer saltpack.SigningSecretKey
+	if arg.SigningKey != nil {
+		var err error
+		if signer, err = saltpackSigningSecretKey(arg.SigningKey); err != nil {
+			return err
+		}
 	}
 
 	var plainsink io.WriteCloser
 	var err error
 	if arg.Binary {
-		plainsink, err = saltpack.NewEncryptStream(arg.Sink, bsk, receiverBoxKeys)
+		plainsink, err = saltpack.NewSigncryptSealStream(arg.Sink, ephemeralKeyCreator{}, signer, receiverBoxKeys, nil)
 	} else {
-		plainsink, err = saltpack.NewEncryptArmor62Stream(arg.Sink, bsk, receiverBoxKeys, KeybaseSaltpackBrand)
+		plainsink, err = saltpack.NewSigncryptArmor62SealStream(arg.Sink, ephemeralKeyCreator{}, signer, receiverBoxKeys, nil, KeybaseSaltpackBrand)
 	}
 	if err != nil {
 		return err
 	}
 
+	return saltpackCopyAndClose(g, arg, plainsink, "Signcrypt")
+}
+
+func saltpackCopyAndClose(g *GlobalContext, arg *SaltpackEncryptArg, plainsink io.WriteCloser, op string) error {
 	n, err := io.Copy(plainsink, arg.Source)
 	if err != nil {
 		return err
 	}
 
-	g.Log.Debug("Encrypt: wrote %d bytes", n)
+	g.Log.Debug("%s: wrote %d bytes", op, n)
 
 	if err := plainsink.Close(); err != nil {
 		return err
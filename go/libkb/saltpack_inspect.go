@@ -0,0 +1,128 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"io"
+	"strings"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+	"github.com/keybase/saltpack"
+	"github.com/ugorji/go/codec"
+)
+
+// SaltpackHeaderInfo is what can be learned about a message from its
+// header alone, without any secret keys.
+type SaltpackHeaderInfo struct {
+	Classification StreamClassification
+	Brand          string // from the armor frame, if armored
+	Version        saltpack.Version
+	NumReceivers   int
+	// ReceiverKIDs are the receivers that aren't anonymous.
+	ReceiverKIDs     []keybase1.KID
+	NumAnonReceivers int
+	// SenderKID is only visible for signatures; the sender of an encrypted
+	// or signcrypted message is in the encrypted part of the header.
+	SenderKID keybase1.KID
+}
+
+// String returns a short name for a message type.
+func (t CryptoMessageType) String() string {
+	switch t {
+	case CryptoMessageTypeEncryption:
+		return "encryption"
+	case CryptoMessageTypeAttachedSignature:
+		return "attached signature"
+	case CryptoMessageTypeDetachedSignature:
+		return "detached signature"
+	case CryptoMessageTypeClearSignature:
+		return "clear signature"
+	case CryptoMessageTypeAmbiguous:
+		return "ambiguous"
+	case CryptoMessageTypeSignature:
+		return "signature"
+	case CryptoMessageTypeSigncryption:
+		return "signcryption"
+	default:
+		return "unknown"
+	}
+}
+
+// SaltpackInspect reads the header of the message in source and reports
+// what's in it. Only the header is read. For other formats, only the
+// Classification is filled in.
+func SaltpackInspect(source io.Reader) (info SaltpackHeaderInfo, err error) {
+	sc, source, err := ClassifyStream(source)
+	if err != nil {
+		return info, err
+	}
+	info.Classification = sc
+	if sc.Format != CryptoMessageFormatSaltpack {
+		return info, nil
+	}
+
+	if sc.Armored {
+		var frame saltpack.Frame
+		source, frame, err = saltpack.NewArmor62DecoderStream(source, nil, nil)
+		if err != nil {
+			return info, err
+		}
+		// The armor type doesn't tell signatures apart, or encryption
+		// from signcryption, so go by the binary header instead.
+		if sc, source, err = ClassifyStream(source); err != nil {
+			return info, err
+		}
+		sc.Armored = true
+		info.Classification = sc
+		var hdr string
+		if hdr, err = frame.GetHeader(); err != nil {
+			return info, err
+		}
+		info.Brand = saltpackArmorBrand(hdr)
+	}
+
+	// The header is double-encoded, as a msgpack bin of the header array.
+	var mh codec.MsgpackHandle
+	var raw []byte
+	if err = codec.NewDecoder(source, &mh).Decode(&raw); err != nil {
+		return info, err
+	}
+
+	switch sc.Type {
+	case CryptoMessageTypeEncryption, CryptoMessageTypeSigncryption:
+		var hdr saltpack.EncryptionHeader
+		if err = codec.NewDecoderBytes(raw, &mh).Decode(&hdr); err != nil {
+			return info, err
+		}
+		info.Version = hdr.Version
+		info.NumReceivers = len(hdr.Receivers)
+		for _, r := range hdr.Receivers {
+			// Receivers are always hidden in signcrypted messages, and
+			// there the field holds an identifier rather than a key.
+			if r.ReceiverKID == nil || sc.Type == CryptoMessageTypeSigncryption {
+				info.NumAnonReceivers++
+				continue
+			}
+			info.ReceiverKIDs = append(info.ReceiverKIDs, keybase1.KIDFromRawKey(r.ReceiverKID, KIDNaclDH))
+		}
+	default:
+		var hdr saltpack.SignatureHeader
+		if err = codec.NewDecoderBytes(raw, &mh).Decode(&hdr); err != nil {
+			return info, err
+		}
+		info.Version = hdr.Version
+		info.SenderKID = keybase1.KIDFromRawKey(hdr.SenderPublic, KIDNaclEddsa)
+	}
+	return info, nil
+}
+
+// saltpackArmorBrand gets the brand out of an armor header like
+// "BEGIN KEYBASE SALTPACK ENCRYPTED MESSAGE", or "" if there isn't one.
+func saltpackArmorBrand(hdr string) string {
+	words := strings.Fields(hdr)
+	if len(words) == 5 {
+		return words[1]
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package libkb
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+func TestSaltpackInspectEncrypted(t *testing.T) {
+	senderKP, err := GenerateNaclDHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var receiverPKs []NaclDHKeyPublic
+	for i := 0; i < 3; i++ {
+		kp, err := GenerateNaclDHKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		receiverPKs = append(receiverPKs, kp.Public)
+	}
+	sigKP, err := GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		arg       SaltpackEncryptArg
+		typ       CryptoMessageType
+		brand     string
+		major     int
+		anonymous bool
+	}{
+		{"armored", SaltpackEncryptArg{}, CryptoMessageTypeEncryption, KeybaseSaltpackBrand, 1, false},
+		{"binary hidden", SaltpackEncryptArg{Binary: true, HideRecipients: true}, CryptoMessageTypeEncryption, "", 1, true},
+		{"signcrypted", SaltpackEncryptArg{Signcrypt: true, SigningKey: sigKP}, CryptoMessageTypeSigncryption, KeybaseSaltpackBrand, 2, true},
+	}
+	for _, test := range tests {
+		var buf outputBuffer
+		arg := test.arg
+		arg.Source = strings.NewReader("the message")
+		arg.Sink = &buf
+		arg.Receivers = receiverPKs
+		arg.Sender = senderKP
+		if err := SaltpackEncrypt(G, &arg); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := SaltpackInspect(&buf)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		sc := info.Classification
+		if sc.Format != CryptoMessageFormatSaltpack || sc.Type != test.typ || sc.Armored == arg.Binary {
+			t.Errorf("%s: classification %+v", test.name, sc)
+		}
+		if info.Brand != test.brand {
+			t.Errorf("%s: brand %q, expected %q", test.name, info.Brand, test.brand)
+		}
+		if info.Version.Major != test.major {
+			t.Errorf("%s: version %+v", test.name, info.Version)
+		}
+		if info.NumReceivers != len(receiverPKs) {
+			t.Errorf("%s: %d receivers, expected %d", test.name, info.NumReceivers, len(receiverPKs))
+		}
+		if !info.SenderKID.IsNil() {
+			t.Errorf("%s: sender should be hidden, got %s", test.name, info.SenderKID)
+		}
+		if test.anonymous {
+			if info.NumAnonReceivers != len(receiverPKs) || len(info.ReceiverKIDs) != 0 {
+				t.Errorf("%s: expected anonymous receivers, got %+v", test.name, info)
+			}
+			continue
+		}
+		if info.NumAnonReceivers != 0 || len(info.ReceiverKIDs) != len(receiverPKs) {
+			t.Fatalf("%s: expected visible receivers, got %+v", test.name, info)
+		}
+		// saltpack shuffles the receivers, so don't depend on their order.
+		found := make(map[keybase1.KID]bool)
+		for _, kid := range info.ReceiverKIDs {
+			found[kid] = true
+		}
+		for i, pk := range receiverPKs {
+			if !found[pk.GetKID()] {
+				t.Errorf("%s: receiver %d (%s) not in %v", test.name, i, pk.GetKID(), info.ReceiverKIDs)
+			}
+		}
+	}
+}
+
+func TestSaltpackInspectSigned(t *testing.T) {
+	kp, err := GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf outputBuffer
+	if err := SaltpackSign(G, ioutil.NopCloser(strings.NewReader("the message")), &buf, kp, false); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := SaltpackInspect(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Classification.Type != CryptoMessageTypeAttachedSignature || !info.Classification.Armored {
+		t.Errorf("classification %+v", info.Classification)
+	}
+	if info.SenderKID.NotEqual(kp.GetKID()) {
+		t.Errorf("sender %s, expected %s", info.SenderKID, kp.GetKID())
+	}
+	if info.NumReceivers != 0 {
+		t.Errorf("%d receivers for a signature", info.NumReceivers)
+	}
+}
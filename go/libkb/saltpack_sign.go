@@ -15,31 +15,35 @@ import (
 
 type streamfn func(io.Writer, saltpack.SigningSecretKey, string) (io.WriteCloser, error)
 
-func SaltpackSign(g *GlobalContext, source io.ReadCloser, sink io.WriteCloser, key NaclSigningKeyPair, binary bool) error {
+func SaltpackSign(g *GlobalContext, source io.ReadCloser, sink io.WriteCloser, key GenericKey, binary bool) error {
 	var s streamfn
 	if binary {
 		s = func(w io.Writer, k saltpack.SigningSecretKey, _ string) (io.WriteCloser, error) {
-			return saltpack.NewSignStream(w, k)
+			return saltpack.NewSignStream(saltpack.Version1(), w, k)
 		}
 	} else {
-		s = saltpack.NewSignArmor62Stream
+		s = func(w io.Writer, k saltpack.SigningSecretKey, brand string) (io.WriteCloser, error) {
+			return saltpack.NewSignArmor62Stream(saltpack.Version1(), w, k, brand)
+		}
 	}
 	return saltpackSign(g, source, sink, key, s)
 }
 
-func SaltpackSignDetached(g *GlobalContext, source io.ReadCloser, sink io.WriteCloser, key NaclSigningKeyPair, binary bool) error {
+func SaltpackSignDetached(g *GlobalContext, source io.ReadCloser, sink io.WriteCloser, key GenericKey, binary bool) error {
 	var s streamfn
 	if binary {
 		s = func(w io.Writer, k saltpack.SigningSecretKey, _ string) (io.WriteCloser, error) {
-			return saltpack.NewSignDetachedStream(w, k)
+			return saltpack.NewSignDetachedStream(saltpack.Version1(), w, k)
 		}
 	} else {
-		s = saltpack.NewSignDetachedArmor62Stream
+		s = func(w io.Writer, k saltpack.SigningSecretKey, brand string) (io.WriteCloser, error) {
+			return saltpack.NewSignDetachedArmor62Stream(saltpack.Version1(), w, k, brand)
+		}
 	}
 	return saltpackSign(g, source, sink, key, s)
 }
 
-func saltpackSign(g *GlobalContext, source io.ReadCloser, sink io.WriteCloser, key NaclSigningKeyPair, streamer streamfn) error {
+func saltpackSign(g *GlobalContext, source io.ReadCloser, sink io.WriteCloser, key GenericKey, streamer streamfn) error {
 	defer func() {
 		if err := source.Close(); err != nil {
 			g.Log.Warning("error closing source: %s", err)
@@ -49,7 +53,12 @@ func saltpackSign(g *GlobalContext, source io.ReadCloser, sink io.WriteCloser, k
 		}
 	}()
 
-	stream, err := streamer(sink, saltSigner{key}, KeybaseSaltpackBrand)
+	signer, err := saltpackSigningSecretKey(key)
+	if err != nil {
+		return err
+	}
+
+	stream, err := streamer(sink, signer, KeybaseSaltpackBrand)
 	if err != nil {
 		return err
 	}
@@ -65,6 +74,24 @@ func saltpackSign(g *GlobalContext, source io.ReadCloser, sink io.WriteCloser, k
 	return nil
 }
 
+// saltpackSigningSecretKey returns key as a saltpack.SigningSecretKey.
+// Keys on PKCS#11 tokens sign for themselves; plain NaCl keys are
+// wrapped.
+func saltpackSigningSecretKey(key GenericKey) (saltpack.SigningSecretKey, error) {
+	if key == nil || !key.CanSign() {
+		return nil, KeyCannotSignError{}
+	}
+	switch k := key.(type) {
+	case saltpack.SigningSecretKey:
+		return k, nil
+	case NaclSigningKeyPair:
+		return saltSigner{k}, nil
+	case *NaclSigningKeyPair:
+		return saltSigner{*k}, nil
+	}
+	return nil, KeyCannotSignError{}
+}
+
 type saltSigner struct {
 	NaclSigningKeyPair
 }
@@ -70,7 +70,7 @@ func TestSaltpackEncDec(t *testing.T) {
 		buf.Reset()
 		_, err = SaltpackDecrypt(G,
 			strings.NewReader(ciphertext),
-			&buf, key, nil)
+			&buf, key, nil, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -88,9 +88,118 @@ func TestSaltpackEncDec(t *testing.T) {
 	for _, kp := range nonReceiverKPs {
 		buf.Reset()
 		_, err = SaltpackDecrypt(G,
-			strings.NewReader(ciphertext), &buf, kp, nil)
+			strings.NewReader(ciphertext), &buf, kp, nil, nil)
 		if err != saltpack.ErrNoDecryptionKey {
 			t.Fatal(err)
 		}
 	}
 }
+
+// Signcrypt a message, and make sure recipients can open it and see who
+// signed it, and non-recipients can't open it.
+func TestSaltpackSigncryptOpen(t *testing.T) {
+	signingKP, err := GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var receiverKPs []NaclDHKeyPair
+	var receiverPKs []NaclDHKeyPublic
+	for i := 0; i < 4; i++ {
+		kp, err := GenerateNaclDHKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		receiverKPs = append(receiverKPs, kp)
+		receiverPKs = append(receiverPKs, kp.Public)
+	}
+
+	nonReceiverKP, err := GenerateNaclDHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := "The Magic Words are Squeamish Ossifrage"
+
+	var buf outputBuffer
+
+	arg := SaltpackEncryptArg{
+		Source:     strings.NewReader(message),
+		Sink:       &buf,
+		Receivers:  receiverPKs,
+		Signcrypt:  true,
+		SigningKey: signingKP,
+	}
+	if err := SaltpackEncrypt(G, &arg); err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := buf.String()
+	if !strings.HasPrefix(ciphertext, saltpack.MakeArmorHeader(saltpack.MessageTypeSigncryption, KeybaseSaltpackBrand)) {
+		t.Errorf("ciphertext doesn't have header: %s", ciphertext)
+	}
+
+	for _, key := range receiverKPs {
+		buf.Reset()
+		mki, err := SaltpackDecrypt(G,
+			strings.NewReader(ciphertext),
+			&buf, key, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		plaintext := buf.String()
+		if plaintext != message {
+			t.Errorf("expected %s, got %s",
+				message, plaintext)
+		}
+
+		if mki.SenderIsAnon {
+			t.Errorf("sender should not be anonymous")
+		}
+		if kid := SigningPublicKeyToKeybaseKID(mki.SenderSigningKey); !kid.Equal(signingKP.GetKID()) {
+			t.Errorf("sender signing key: %s, expected %s", kid, signingKP.GetKID())
+		}
+	}
+
+	buf.Reset()
+	_, err = SaltpackDecrypt(G,
+		strings.NewReader(ciphertext), &buf, nonReceiverKP, nil, nil)
+	if err != saltpack.ErrNoDecryptionKey {
+		t.Fatal(err)
+	}
+}
+
+// A signcrypted message that has been tampered with shouldn't open.
+func TestSaltpackSigncryptTampered(t *testing.T) {
+	signingKP, err := GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiverKP, err := GenerateNaclDHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf outputBuffer
+
+	arg := SaltpackEncryptArg{
+		Source:     strings.NewReader("attack at dawn"),
+		Sink:       &buf,
+		Receivers:  []NaclDHKeyPublic{receiverKP.Public},
+		Binary:     true,
+		Signcrypt:  true,
+		SigningKey: signingKP,
+	}
+	if err := SaltpackEncrypt(G, &arg); err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext := buf.Bytes()
+	ciphertext[len(ciphertext)-20] ^= 0x01
+
+	buf.Reset()
+	if _, err := SaltpackDecrypt(G, bytes.NewReader(ciphertext), &buf, receiverKP, nil, nil); err == nil {
+		t.Fatal("expected an error opening a tampered message")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"crypto/hmac"
 	"io"
+	"time"
 
 	"github.com/keybase/saltpack"
 )
@@ -29,11 +30,11 @@ func SaltpackVerify(g *GlobalContext, source io.Reader, sink io.WriteCloser, che
 
 	var skey saltpack.SigningPublicKey
 	var vs io.Reader
-	var frame saltpack.Frame
+	var brand string
 	if sc.Armored {
-		skey, vs, frame, err = saltpack.NewDearmor62VerifyStream(source, kr)
+		skey, vs, brand, err = saltpack.NewDearmor62VerifyStream(saltpack.CheckKnownMajorVersion, source, kr)
 	} else {
-		skey, vs, err = saltpack.NewVerifyStream(source, kr)
+		skey, vs, err = saltpack.NewVerifyStream(saltpack.CheckKnownMajorVersion, source, kr)
 	}
 	if err != nil {
 		g.Log.Debug("saltpack.NewDearmor62VerifyStream error: %s", err)
@@ -52,9 +53,7 @@ func SaltpackVerify(g *GlobalContext, source io.Reader, sink io.WriteCloser, che
 	}
 
 	if sc.Armored {
-		if brand, err := saltpack.CheckArmor62Frame(frame, saltpack.MessageTypeAttachedSignature); err != nil {
-			return err
-		} else if err = checkSaltpackBrand(brand); err != nil {
+		if err = checkSaltpackBrand(brand); err != nil {
 			return err
 		}
 	}
@@ -65,6 +64,7 @@ func SaltpackVerify(g *GlobalContext, source io.Reader, sink io.WriteCloser, che
 		return err
 	}
 
+	noteKeySeenSigning(g, SigningPublicKeyToKeybaseKID(skey), time.Now())
 	return nil
 }
 
@@ -86,7 +86,7 @@ func SaltpackVerifyDetached(g *GlobalContext, message io.Reader, signature []byt
 	var skey saltpack.SigningPublicKey
 	if sc.Armored {
 		var brand string
-		skey, brand, err = saltpack.Dearmor62VerifyDetachedReader(message, string(signature), kr)
+		skey, brand, err = saltpack.Dearmor62VerifyDetachedReader(saltpack.CheckKnownMajorVersion, message, string(signature), kr)
 		if err != nil {
 			g.Log.Debug("saltpack.Dearmor62VerifyDetachedReader error: %s", err)
 			return err
@@ -95,7 +95,7 @@ func SaltpackVerifyDetached(g *GlobalContext, message io.Reader, signature []byt
 			return err
 		}
 	} else {
-		skey, err = saltpack.VerifyDetachedReader(message, signature, kr)
+		skey, err = saltpack.VerifyDetachedReader(saltpack.CheckKnownMajorVersion, message, signature, kr)
 		if err != nil {
 			g.Log.Debug("saltpack.VerifyDetachedReader error: %s", err)
 			return err
@@ -108,6 +108,7 @@ func SaltpackVerifyDetached(g *GlobalContext, message io.Reader, signature []byt
 		}
 	}
 
+	noteKeySeenSigning(g, SigningPublicKeyToKeybaseKID(skey), time.Now())
 	return nil
 }
 
@@ -0,0 +1,467 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+// +build linux,!android
+
+package libkb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/net/context"
+)
+
+// SecretStoreLinux keeps secrets in the freedesktop Secret Service (as
+// provided by gnome-keyring or KWallet) when there's one on the session
+// bus. Otherwise, if a key file is configured with secret_store_key_file,
+// it keeps them in files in the data dir, encrypted with that key.
+type SecretStoreLinux struct {
+	Contextified
+	busAddress string
+	dial       func(address string) (secretServiceBus, error)
+}
+
+var _ SecretStoreAll = (*SecretStoreLinux)(nil)
+
+// NewSecretStoreAll returns a SecretStoreLinux, if there's a session bus
+// or a key file to use.
+func NewSecretStoreAll(g *GlobalContext) SecretStoreAll {
+	s := newSecretStoreLinux(g, os.Getenv("DBUS_SESSION_BUS_ADDRESS"))
+	if len(s.busAddress) == 0 && len(g.Env.GetSecretStoreKeyFile()) == 0 {
+		return nil
+	}
+	return s
+}
+
+func newSecretStoreLinux(g *GlobalContext, busAddress string) *SecretStoreLinux {
+	return &SecretStoreLinux{
+		Contextified: NewContextified(g),
+		busAddress:   busAddress,
+		dial:         dialSessionBus,
+	}
+}
+
+func GetTerminalPrompt() string {
+	return "Store your key in the local secret store?"
+}
+
+type secretStoreBackend interface {
+	retrieve(username NormalizedUsername) ([]byte, error)
+	store(username NormalizedUsername, secret []byte) error
+	clear(username NormalizedUsername) error
+	users() ([]string, error)
+	close()
+}
+
+func (s *SecretStoreLinux) backend() (secretStoreBackend, error) {
+	var msg string
+	if len(s.busAddress) > 0 {
+		bus, err := s.dial(s.busAddress)
+		if err == nil {
+			var ss *secretServiceClient
+			if ss, err = openSecretService(bus, s.G().GetStoredSecretServiceName()); err == nil {
+				return ss, nil
+			}
+		}
+		s.G().Log.Debug("| Secret Service isn't available: %s", err)
+		msg = fmt.Sprintf("the Secret Service isn't available (%s)", err)
+	} else {
+		msg = "there's no session bus for the Secret Service"
+	}
+	if keyFile := s.G().Env.GetSecretStoreKeyFile(); len(keyFile) > 0 {
+		return newSecretFileStore(filepath.Join(s.G().Env.GetDataDir(), "secretstore"), keyFile)
+	}
+	return nil, SecretStoreError{Msg: msg + ", and no secret_store_key_file is set"}
+}
+
+func (s *SecretStoreLinux) RetrieveSecret(username NormalizedUsername) ([]byte, error) {
+	b, err := s.backend()
+	if err != nil {
+		return nil, err
+	}
+	defer b.close()
+	return b.retrieve(username)
+}
+
+func (s *SecretStoreLinux) StoreSecret(username NormalizedUsername, secret []byte) error {
+	b, err := s.backend()
+	if err != nil {
+		return err
+	}
+	defer b.close()
+	return b.store(username, secret)
+}
+
+func (s *SecretStoreLinux) ClearSecret(username NormalizedUsername) error {
+	b, err := s.backend()
+	if err != nil {
+		return err
+	}
+	defer b.close()
+	return b.clear(username)
+}
+
+func (s *SecretStoreLinux) GetUsersWithStoredSecrets() ([]string, error) {
+	b, err := s.backend()
+	if err != nil {
+		return nil, err
+	}
+	defer b.close()
+	return b.users()
+}
+
+//=============================================================================
+
+const (
+	secretServiceName       = "org.freedesktop.secrets"
+	secretServicePath       = dbus.ObjectPath("/org/freedesktop/secrets")
+	secretServiceCollection = dbus.ObjectPath("/org/freedesktop/secrets/aliases/default")
+	secretServiceIface      = "org.freedesktop.Secret.Service"
+	secretCollectionIface   = "org.freedesktop.Secret.Collection"
+	secretItemIface         = "org.freedesktop.Secret.Item"
+	secretPromptIface       = "org.freedesktop.Secret.Prompt"
+	secretSessionIface      = "org.freedesktop.Secret.Session"
+
+	secretServiceTimeout = 10 * time.Second
+	// The user may have to type their keyring password at a prompt.
+	secretServicePromptTimeout = 2 * time.Minute
+)
+
+// secretServiceBus is the part of a *dbus.Conn that secretServiceClient
+// uses, so that tests can stand in for the session bus.
+type secretServiceBus interface {
+	Object(dest string, path dbus.ObjectPath) dbus.BusObject
+	BusObject() dbus.BusObject
+	Signal(ch chan<- *dbus.Signal)
+	Close() error
+}
+
+// dialSessionBus connects to the bus at address, as in
+// $DBUS_SESSION_BUS_ADDRESS.
+func dialSessionBus(address string) (secretServiceBus, error) {
+	conn, err := dbus.Dial(address)
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.Auth(nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err = conn.Hello(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// secretServiceSecret is the Secret struct from the Secret Service API.
+type secretServiceSecret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// secretServiceClient keeps secrets in the default collection, as items
+// with "service" and "username" attributes. Secrets are sent with the
+// "plain" algorithm, since the session bus is private to the user.
+type secretServiceClient struct {
+	bus         secretServiceBus
+	session     dbus.ObjectPath
+	serviceName string
+	// signals gets the bus's signals until it's closed; the bus can't
+	// stop sending them to a channel.
+	signals chan *dbus.Signal
+}
+
+func openSecretService(bus secretServiceBus, serviceName string) (*secretServiceClient, error) {
+	s := &secretServiceClient{bus: bus, serviceName: serviceName, signals: make(chan *dbus.Signal, 10)}
+	bus.Signal(s.signals)
+	var output dbus.Variant
+	if err := s.call(secretServicePath, secretServiceIface+".OpenSession", []interface{}{"plain", dbus.MakeVariant("")}, &output, &s.session); err != nil {
+		bus.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// call calls a method on one of the Secret Service's objects, and stores
+// the reply in ret.
+func (s *secretServiceClient) call(path dbus.ObjectPath, method string, args []interface{}, ret ...interface{}) error {
+	return callWithTimeout(s.bus.Object(secretServiceName, path), method, args, ret...)
+}
+
+// callWithTimeout calls a method on obj, and stores the reply in ret, if
+// it comes in time.
+func callWithTimeout(obj dbus.BusObject, method string, args []interface{}, ret ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), secretServiceTimeout)
+	defer cancel()
+	call := obj.Go(method, 0, make(chan *dbus.Call, 1), args...)
+	select {
+	case call = <-call.Done:
+		return call.Store(ret...)
+	case <-ctx.Done():
+		return SecretStoreError{Msg: fmt.Sprintf("timed out calling %s", method)}
+	}
+}
+
+func (s *secretServiceClient) close() {
+	s.call(s.session, secretSessionIface+".Close", nil)
+	s.bus.Close()
+}
+
+func (s *secretServiceClient) attributes(username NormalizedUsername) map[string]string {
+	attrs := map[string]string{"service": s.serviceName}
+	if len(username) > 0 {
+		attrs["username"] = string(username)
+	}
+	return attrs
+}
+
+// prompt shows a prompt (such as for the keyring password), and waits for
+// the user to deal with it. A path of "/" means no prompt was needed.
+func (s *secretServiceClient) prompt(path dbus.ObjectPath) error {
+	if path == "/" || len(path) == 0 {
+		return nil
+	}
+	rule := fmt.Sprintf("type='signal',interface='%s',member='Completed',path='%s'", secretPromptIface, path)
+	if err := callWithTimeout(s.bus.BusObject(), "org.freedesktop.DBus.AddMatch", []interface{}{rule}); err != nil {
+		return err
+	}
+	defer callWithTimeout(s.bus.BusObject(), "org.freedesktop.DBus.RemoveMatch", []interface{}{rule})
+	if err := s.call(path, secretPromptIface+".Prompt", []interface{}{""}); err != nil {
+		return err
+	}
+	timeout := time.After(secretServicePromptTimeout)
+	for {
+		select {
+		case sig, ok := <-s.signals:
+			if !ok {
+				return SecretStoreError{Msg: "the session bus closed while waiting for the keyring prompt"}
+			}
+			if sig.Path != path || sig.Name != secretPromptIface+".Completed" {
+				continue
+			}
+			if len(sig.Body) > 0 {
+				if dismissed, _ := sig.Body[0].(bool); dismissed {
+					return SecretStoreError{Msg: "the keyring prompt was dismissed"}
+				}
+			}
+			return nil
+		case <-timeout:
+			return SecretStoreError{Msg: "timed out waiting for the keyring prompt"}
+		}
+	}
+}
+
+func (s *secretServiceClient) unlock(paths []dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	if err := s.call(secretServicePath, secretServiceIface+".Unlock", []interface{}{paths}, &unlocked, &prompt); err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+// search finds our items that match username (or all of them, if it's
+// empty), and unlocks them.
+func (s *secretServiceClient) search(username NormalizedUsername) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	if err := s.call(secretServicePath, secretServiceIface+".SearchItems", []interface{}{s.attributes(username)}, &unlocked, &locked); err != nil {
+		return nil, err
+	}
+	if len(locked) > 0 {
+		if err := s.unlock(locked); err != nil {
+			return nil, err
+		}
+	}
+	return append(unlocked, locked...), nil
+}
+
+func (s *secretServiceClient) retrieve(username NormalizedUsername) ([]byte, error) {
+	items, err := s.search(username)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, SecretStoreError{Msg: "No secret for " + string(username)}
+	}
+	var secret secretServiceSecret
+	if err = s.call(items[0], secretItemIface+".GetSecret", []interface{}{s.session}, &secret); err != nil {
+		return nil, err
+	}
+	return secret.Value, nil
+}
+
+func (s *secretServiceClient) store(username NormalizedUsername, secret []byte) error {
+	if err := s.unlock([]dbus.ObjectPath{secretServiceCollection}); err != nil {
+		return err
+	}
+	props := map[string]dbus.Variant{
+		secretItemIface + ".Label":      dbus.MakeVariant(fmt.Sprintf("Keybase (%s)", username)),
+		secretItemIface + ".Attributes": dbus.MakeVariant(s.attributes(username)),
+	}
+	value := secretServiceSecret{Session: s.session, Parameters: []byte{}, Value: secret, ContentType: "application/octet-stream"}
+	var item, prompt dbus.ObjectPath
+	if err := s.call(secretServiceCollection, secretCollectionIface+".CreateItem", []interface{}{props, value, true}, &item, &prompt); err != nil {
+		return err
+	}
+	return s.prompt(prompt)
+}
+
+func (s *secretServiceClient) clear(username NormalizedUsername) error {
+	items, err := s.search(username)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var prompt dbus.ObjectPath
+		if err = s.call(item, secretItemIface+".Delete", nil, &prompt); err != nil {
+			return err
+		}
+		if err = s.prompt(prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *secretServiceClient) users() (ret []string, err error) {
+	items, err := s.search("")
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		v, err := s.bus.Object(secretServiceName, item).GetProperty(secretItemIface + ".Attributes")
+		if err != nil {
+			return nil, err
+		}
+		attrs, _ := v.Value().(map[string]string)
+		if username, ok := attrs["username"]; ok {
+			ret = append(ret, username)
+		}
+	}
+	return ret, nil
+}
+
+//=============================================================================
+
+// secretFileStore keeps each user's secret in its own file, in a
+// secretbox keyed with an HMAC of the username by the contents of a key
+// file that the user supplies.
+type secretFileStore struct {
+	dir     string
+	keyData []byte
+}
+
+const secretFileSuffix = ".secret"
+
+// secretStoreMinKeyFileLen is the smallest key file we'll take; 32 bytes
+// from /dev/urandom will do.
+const secretStoreMinKeyFileLen = 32
+
+func newSecretFileStore(dir, keyFile string) (*secretFileStore, error) {
+	keyData, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, SecretStoreError{Msg: fmt.Sprintf("can't read secret_store_key_file: %s", err)}
+	}
+	if len(keyData) < secretStoreMinKeyFileLen {
+		return nil, SecretStoreError{Msg: fmt.Sprintf("secret_store_key_file %s is too short; it needs at least %d bytes", keyFile, secretStoreMinKeyFileLen)}
+	}
+	return &secretFileStore{dir: dir, keyData: keyData}, nil
+}
+
+func (f *secretFileStore) close() {}
+
+func (f *secretFileStore) key(username NormalizedUsername) *[32]byte {
+	mac := hmac.New(sha256.New, f.keyData)
+	mac.Write([]byte("Keybase secret store: " + username))
+	var key [32]byte
+	copy(key[:], mac.Sum(nil))
+	return &key
+}
+
+func (f *secretFileStore) filename(username NormalizedUsername) (string, error) {
+	if len(username) == 0 || strings.ContainsAny(string(username), `/\.`) {
+		return "", SecretStoreError{Msg: fmt.Sprintf("bad username %q", username)}
+	}
+	return filepath.Join(f.dir, string(username)+secretFileSuffix), nil
+}
+
+func (f *secretFileStore) retrieve(username NormalizedUsername) ([]byte, error) {
+	fn, err := f.filename(username)
+	if err != nil {
+		return nil, err
+	}
+	boxed, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		return nil, SecretStoreError{Msg: "No secret for " + string(username)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(boxed) < 24 {
+		return nil, SecretStoreError{Msg: "secret file is too short"}
+	}
+	var nonce [24]byte
+	copy(nonce[:], boxed)
+	secret, ok := secretbox.Open(nil, boxed[24:], &nonce, f.key(username))
+	if !ok {
+		return nil, SecretStoreError{Msg: "can't decrypt the secret for " + string(username) + "; was secret_store_key_file changed?"}
+	}
+	return secret, nil
+}
+
+func (f *secretFileStore) store(username NormalizedUsername, secret []byte) error {
+	fn, err := f.filename(username)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(f.dir, PermDir); err != nil {
+		return err
+	}
+	nonceBytes, err := RandBytes(24)
+	if err != nil {
+		return err
+	}
+	var nonce [24]byte
+	copy(nonce[:], nonceBytes)
+	boxed := secretbox.Seal(nonce[:], secret, &nonce, f.key(username))
+	return NewFile(fn, boxed, PermFile).Save()
+}
+
+func (f *secretFileStore) clear(username NormalizedUsername) error {
+	fn, err := f.filename(username)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(fn); os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (f *secretFileStore) users() (ret []string, err error) {
+	infos, err := ioutil.ReadDir(f.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if name := info.Name(); strings.HasSuffix(name, secretFileSuffix) {
+			ret = append(ret, strings.TrimSuffix(name, secretFileSuffix))
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,327 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+// +build linux,!android
+
+package libkb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godbus/dbus"
+)
+
+// fakeSecretService is a stand-in for a session bus with a Secret Service
+// on it.
+type fakeSecretService struct {
+	sync.Mutex
+	items    map[dbus.ObjectPath]*fakeSecretItem
+	nextItem int
+	locked   bool // if set, the collection needs a prompt to unlock
+	prompts  int
+	signals  []chan<- *dbus.Signal
+}
+
+type fakeSecretItem struct {
+	attrs  map[string]string
+	secret []byte
+}
+
+const fakeSecretPrompt = dbus.ObjectPath("/org/freedesktop/secrets/prompt/1")
+
+var _ secretServiceBus = (*fakeSecretService)(nil)
+
+func newFakeSecretService() *fakeSecretService {
+	return &fakeSecretService{items: make(map[dbus.ObjectPath]*fakeSecretItem)}
+}
+
+func (f *fakeSecretService) dial(address string) (secretServiceBus, error) {
+	return f, nil
+}
+
+func (f *fakeSecretService) Object(dest string, path dbus.ObjectPath) dbus.BusObject {
+	return &fakeSecretObject{f: f, dest: dest, path: path}
+}
+
+func (f *fakeSecretService) BusObject() dbus.BusObject {
+	return &fakeSecretObject{f: f, dest: "org.freedesktop.DBus", path: "/org/freedesktop/DBus"}
+}
+
+func (f *fakeSecretService) Signal(ch chan<- *dbus.Signal) {
+	f.Lock()
+	defer f.Unlock()
+	f.signals = append(f.signals, ch)
+}
+
+func (f *fakeSecretService) Close() error { return nil }
+
+func (f *fakeSecretService) find(attrs map[string]string) (ret []dbus.ObjectPath) {
+	for path, item := range f.items {
+		match := true
+		for k, v := range attrs {
+			if item.attrs[k] != v {
+				match = false
+			}
+		}
+		if match {
+			ret = append(ret, path)
+		}
+	}
+	return ret
+}
+
+// call handles a method call, and returns the reply.
+func (f *fakeSecretService) call(path dbus.ObjectPath, method string, args []interface{}) ([]interface{}, error) {
+	f.Lock()
+	defer f.Unlock()
+	noSuchObject := dbus.Error{Name: "org.freedesktop.Secret.Error.NoSuchObject"}
+	isLocked := dbus.Error{Name: "org.freedesktop.Secret.Error.IsLocked"}
+	switch method {
+	case secretServiceIface + ".OpenSession":
+		if args[0].(string) != "plain" {
+			return nil, dbus.Error{Name: "org.freedesktop.DBus.Error.NotSupported"}
+		}
+		return []interface{}{dbus.MakeVariant(""), dbus.ObjectPath("/org/freedesktop/secrets/session/1")}, nil
+	case secretSessionIface + ".Close":
+		return nil, nil
+	case secretServiceIface + ".SearchItems":
+		found := f.find(args[0].(map[string]string))
+		if f.locked {
+			return []interface{}{[]dbus.ObjectPath{}, found}, nil
+		}
+		return []interface{}{found, []dbus.ObjectPath{}}, nil
+	case secretServiceIface + ".Unlock":
+		if f.locked {
+			return []interface{}{[]dbus.ObjectPath{}, fakeSecretPrompt}, nil
+		}
+		return []interface{}{args[0], dbus.ObjectPath("/")}, nil
+	case secretPromptIface + ".Prompt":
+		f.locked = false
+		f.prompts++
+		completed := &dbus.Signal{
+			Path: path,
+			Name: secretPromptIface + ".Completed",
+			Body: []interface{}{false, dbus.MakeVariant([]dbus.ObjectPath{})},
+		}
+		// Like the bus, drop signals for channels that are full.
+		for _, ch := range f.signals {
+			select {
+			case ch <- completed:
+			default:
+			}
+		}
+		return nil, nil
+	case secretCollectionIface + ".CreateItem":
+		if path != secretServiceCollection {
+			return nil, noSuchObject
+		}
+		if f.locked {
+			return nil, isLocked
+		}
+		props := args[0].(map[string]dbus.Variant)
+		attrs := props[secretItemIface+".Attributes"].Value().(map[string]string)
+		item := &fakeSecretItem{attrs: attrs, secret: args[1].(secretServiceSecret).Value}
+		var itemPath dbus.ObjectPath
+		if found := f.find(attrs); len(found) > 0 && args[2].(bool) {
+			itemPath = found[0]
+		} else {
+			f.nextItem++
+			itemPath = dbus.ObjectPath(fmt.Sprintf("%s/%d", secretServiceCollection, f.nextItem))
+		}
+		f.items[itemPath] = item
+		return []interface{}{itemPath, dbus.ObjectPath("/")}, nil
+	case secretItemIface + ".GetSecret":
+		item, ok := f.items[path]
+		if !ok {
+			return nil, noSuchObject
+		}
+		if f.locked {
+			return nil, isLocked
+		}
+		return []interface{}{secretServiceSecret{Session: args[0].(dbus.ObjectPath), Parameters: []byte{}, Value: item.secret, ContentType: "application/octet-stream"}}, nil
+	case secretItemIface + ".Delete":
+		if _, ok := f.items[path]; !ok {
+			return nil, noSuchObject
+		}
+		delete(f.items, path)
+		return []interface{}{dbus.ObjectPath("/")}, nil
+	case "org.freedesktop.DBus.Properties.Get":
+		item, ok := f.items[path]
+		if !ok || args[1].(string) != "Attributes" {
+			return nil, noSuchObject
+		}
+		return []interface{}{dbus.MakeVariant(item.attrs)}, nil
+	}
+	return nil, dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownMethod"}
+}
+
+// fakeSecretObject is an object on a fakeSecretService.
+type fakeSecretObject struct {
+	f    *fakeSecretService
+	dest string
+	path dbus.ObjectPath
+}
+
+func (o *fakeSecretObject) Call(method string, flags dbus.Flags, args ...interface{}) *dbus.Call {
+	call := &dbus.Call{Destination: o.dest, Path: o.path, Method: method, Args: args}
+	switch o.dest {
+	case "org.freedesktop.DBus":
+		// AddMatch and RemoveMatch: every signal gets sent anyway.
+	case secretServiceName:
+		call.Body, call.Err = o.f.call(o.path, method, args)
+	default:
+		call.Err = dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"}
+	}
+	return call
+}
+
+func (o *fakeSecretObject) Go(method string, flags dbus.Flags, ch chan *dbus.Call, args ...interface{}) *dbus.Call {
+	call := o.Call(method, flags, args...)
+	call.Done = ch
+	ch <- call
+	return call
+}
+
+func (o *fakeSecretObject) GetProperty(p string) (v dbus.Variant, err error) {
+	i := strings.LastIndex(p, ".")
+	err = o.Call("org.freedesktop.DBus.Properties.Get", 0, p[:i], p[i+1:]).Store(&v)
+	return v, err
+}
+
+func (o *fakeSecretObject) Destination() string   { return o.dest }
+func (o *fakeSecretObject) Path() dbus.ObjectPath { return o.path }
+
+type testSecretStoreContext []NormalizedUsername
+
+func (c testSecretStoreContext) GetAllUserNames() (NormalizedUsername, []NormalizedUsername, error) {
+	return c[0], c[1:], nil
+}
+func (c testSecretStoreContext) GetStoredSecretServiceName() string { return "" }
+func (c testSecretStoreContext) GetStoredSecretAccessGroup() string { return "" }
+
+// testSecretStoreLinux runs through the SecretStoreAll methods, and the
+// helpers that use them.
+func testSecretStoreLinux(t *testing.T, tc TestContext, s SecretStoreAll) {
+	alice, bob := NewNormalizedUsername("alice"), NewNormalizedUsername("bob")
+
+	if _, err := s.RetrieveSecret(alice); err == nil {
+		t.Fatal("retrieved a secret before storing one")
+	}
+	if err := s.StoreSecret(alice, []byte("alice secret 1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreSecret(alice, []byte("alice secret 2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreSecret(bob, []byte{0, 1, 2, 0xff}); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := s.RetrieveSecret(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret) != "alice secret 2" {
+		t.Errorf("alice's secret: %q", secret)
+	}
+	if secret, err = s.RetrieveSecret(bob); err != nil || !reflect.DeepEqual(secret, []byte{0, 1, 2, 0xff}) {
+		t.Errorf("bob's secret: %v, %v", secret, err)
+	}
+
+	users, err := s.GetUsersWithStoredSecrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(users)
+	if !reflect.DeepEqual(users, []string{"alice", "bob"}) {
+		t.Errorf("users with stored secrets: %v", users)
+	}
+
+	accounts, err := GetConfiguredAccounts(testSecretStoreContext{alice, NewNormalizedUsername("carol")}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range accounts {
+		if expected := a.Username == "alice"; a.HasStoredSecret != expected {
+			t.Errorf("%s: HasStoredSecret is %v", a.Username, a.HasStoredSecret)
+		}
+	}
+
+	tc.G.SecretStoreAll = s
+	if err = ClearStoredSecret(tc.G, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.RetrieveSecret(alice); err == nil {
+		t.Error("retrieved alice's secret after clearing it")
+	}
+	if users, err = s.GetUsersWithStoredSecrets(); err != nil || !reflect.DeepEqual(users, []string{"bob"}) {
+		t.Errorf("users after clearing alice: %v, %v", users, err)
+	}
+	// Clearing what isn't there is fine.
+	if err = s.ClearSecret(alice); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSecretStoreLinuxSecretService(t *testing.T) {
+	tc := SetupTest(t, "secret service")
+	defer tc.Cleanup()
+
+	f := newFakeSecretService()
+	f.locked = true
+
+	s := newSecretStoreLinux(tc.G, "unix:path=/fake")
+	s.dial = f.dial
+	testSecretStoreLinux(t, tc, s)
+
+	if f.prompts != 1 {
+		t.Errorf("expected one unlock prompt, got %d", f.prompts)
+	}
+	for _, item := range f.items {
+		if item.attrs["service"] != tc.G.GetStoredSecretServiceName() {
+			t.Errorf("item attributes: %v", item.attrs)
+		}
+	}
+}
+
+func TestSecretStoreLinuxFileFallback(t *testing.T) {
+	tc := SetupTest(t, "secret file store")
+	defer tc.Cleanup()
+
+	s := newSecretStoreLinux(tc.G, "unix:path="+filepath.Join(tc.Tp.Home, "nobus"))
+	if _, err := s.RetrieveSecret("alice"); err == nil {
+		t.Fatal("expected an error with no Secret Service and no key file")
+	} else if _, ok := err.(SecretStoreError); !ok {
+		t.Fatalf("expected a SecretStoreError, got %T: %s", err, err)
+	}
+
+	keyFile := filepath.Join(tc.Tp.Home, "secret.key")
+	if err := ioutil.WriteFile(keyFile, []byte("too short"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("KEYBASE_SECRET_STORE_KEY_FILE", keyFile)
+	defer os.Unsetenv("KEYBASE_SECRET_STORE_KEY_FILE")
+	if err := s.StoreSecret("alice", []byte("secret")); err == nil {
+		t.Fatal("expected an error with a short key file")
+	}
+
+	if err := ioutil.WriteFile(keyFile, []byte(strings.Repeat("k", 32)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	testSecretStoreLinux(t, tc, s)
+
+	// A different key file can't open the secrets.
+	if err := ioutil.WriteFile(keyFile, []byte(strings.Repeat("K", 32)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RetrieveSecret("bob"); err == nil {
+		t.Error("opened bob's secret with the wrong key file")
+	}
+}
@@ -400,6 +400,9 @@ func (sc *SigChain) verifySubchain(kf KeyFamily, links []*ChainLink) (cached boo
 	cki = NewComputedKeyInfos(sc.G())
 	ckf := ComputedKeyFamily{kf: &kf, cki: cki, Contextified: sc.Contextified}
 
+	// When each key signed the last link we verified its signature on.
+	signed := make(map[keybase1.KID]time.Time)
+
 	first := true
 
 	for linkIndex, link := range links {
@@ -448,6 +451,7 @@ func (sc *SigChain) verifySubchain(kf KeyFamily, links []*ChainLink) (cached boo
 				sc.G().Log.Debug("| Failure in VerifySigWithKeyFamily: %s", err)
 				return
 			}
+			signed[newKID] = link.GetCTime()
 		}
 
 		if isDelegating {
@@ -477,6 +481,9 @@ func (sc *SigChain) verifySubchain(kf KeyFamily, links []*ChainLink) (cached boo
 	}
 
 	last.PutSigCheckCache(cki)
+	for kid, t := range signed {
+		noteKeySeenSigning(sc.G(), kid, t)
+	}
 	return
 }
 
@@ -260,7 +260,7 @@ func (s *SKB) unlockSecretKeyFromSecretRetriever(lctx LoginContext, secretRetrie
 	switch s.Priv.Encryption {
 	case 0:
 		unlocked = s.Priv.Data
-	case LKSecVersion:
+	case LKSecVersion, PKCS11Version:
 		unlocked, err = s.lksUnlockWithSecretRetriever(lctx, secretRetriever)
 	default:
 		err = BadKeyError{fmt.Sprintf("Can't unlock secret from secret retriever with protection type %d", int(s.Priv.Encryption))}
@@ -323,7 +323,7 @@ func (s *SKB) UnlockSecretKey(lctx LoginContext, passphrase string, tsec *triple
 			}
 		}
 		unlocked, err = s.tsecUnlock(tsec)
-	case LKSecVersion:
+	case LKSecVersion, PKCS11Version:
 		ppsIn := pps
 		if pps == nil {
 			tsec, pps, err = s.unverifiedPassphraseStream(lctx, passphrase)
@@ -359,6 +359,8 @@ func (s *SKB) UnlockSecretKey(lctx LoginContext, passphrase string, tsec *triple
 func (s *SKB) parseUnlocked(unlocked []byte) (key GenericKey, err error) {
 
 	switch {
+	case s.Priv.Encryption == PKCS11Version:
+		key, err = importPKCS11Key(s.Type, s.Pub, unlocked)
 	case IsPGPAlgo(s.Type):
 		key, err = ReadOneKeyFromBytes(unlocked)
 	case s.Type == KIDNaclEddsa:
@@ -367,7 +369,7 @@ func (s *SKB) parseUnlocked(unlocked []byte) (key GenericKey, err error) {
 		key, err = ImportNaclDHKeyPairFromBytes(s.Pub, unlocked)
 	}
 
-	if key == nil {
+	if key == nil && err == nil {
 		err = BadKeyError{"can't parse secret key after unlock"}
 	}
 	if err != nil {
@@ -618,6 +620,30 @@ func (k *SKBKeyringFile) Push(skb *SKB) error {
 	return nil
 }
 
+// Remove drops the key with the given KID from the keyring, if it's there.
+// Call Save afterwards to write the keyring back out without it.
+func (k *SKBKeyringFile) Remove(kid keybase1.KID) bool {
+	var blocks []*SKB
+	found := false
+	for _, b := range k.Blocks {
+		key, err := b.GetPubKey()
+		if err == nil && key != nil && key.GetKID().Equal(kid) {
+			if fp := key.GetFingerprintP(); fp != nil {
+				delete(k.fpIndex, *fp)
+			}
+			found = true
+			continue
+		}
+		blocks = append(blocks, b)
+	}
+	if found {
+		delete(k.kidIndex, kid)
+		k.Blocks = blocks
+		k.dirty = true
+	}
+	return found
+}
+
 func (k SKBKeyringFile) GetFilename() string { return k.filename }
 
 func (k SKBKeyringFile) WriteTo(w io.Writer) (int64, error) {
@@ -691,6 +717,10 @@ func (s *SKB) UnlockNoPrompt(lctx LoginContext, secretStore SecretStore) (Generi
 		if err == nil {
 			return key, nil
 		}
+		if _, ok := err.(PKCS11TokenLoggedOutError); ok {
+			// the secret was fine, but the key's token needs its PIN
+			return nil, err
+		}
 		// fall through if we failed to unlock with retrieved secret...
 	}
 
@@ -783,6 +813,9 @@ func (s *SKB) PromptAndUnlock(arg SecretKeyPromptArg, which string, secretStore
 
 	// First try to unlock without prompting the user.
 	ret, err = s.UnlockNoPrompt(arg.LoginContext, secretStore)
+	if lerr, ok := err.(PKCS11TokenLoggedOutError); ok {
+		ret, err = s.unlockWithPKCS11PIN(arg, secretStore, lerr)
+	}
 	if err == nil {
 		return
 	}
@@ -792,9 +825,23 @@ func (s *SKB) PromptAndUnlock(arg SecretKeyPromptArg, which string, secretStore
 
 	// Prompt necessary:
 	ret, err = s.unlockPrompt(arg, which, secretStore, me)
+	if lerr, ok := err.(PKCS11TokenLoggedOutError); ok {
+		// The passphrase was right and is cached now; only the
+		// token's PIN is missing.
+		ret, err = s.unlockWithPKCS11PIN(arg, secretStore, lerr)
+	}
 	return
 }
 
+// unlockWithPKCS11PIN prompts for the PIN of the token that the key is
+// on, and tries the unlock again once the token is logged in.
+func (s *SKB) unlockWithPKCS11PIN(arg SecretKeyPromptArg, secretStore SecretStore, lerr PKCS11TokenLoggedOutError) (GenericKey, error) {
+	if _, err := openPKCS11TokenWithPrompt(arg.SecretUI, lerr.Module, lerr.Token); err != nil {
+		return nil, err
+	}
+	return s.UnlockNoPrompt(arg.LoginContext, secretStore)
+}
+
 func (k *SKBKeyringFile) PushAndSave(skb *SKB) error {
 	if err := k.Push(skb); err != nil {
 		return err
@@ -6,6 +6,9 @@ package libkb
 import (
 	"bytes"
 	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -287,3 +290,59 @@ func testErrUnlock(t *testing.T, skb *SKB, ui *TestCancelSecretUI) error {
 	}
 	return err
 }
+
+func TestSKBKeyringFileRemove(t *testing.T) {
+	tc := SetupTest(t, "skb_keyring_remove")
+	defer tc.Cleanup()
+
+	lks := makeTestLKSec(t, tc.G)
+	sig, err := GenerateNaclSigningKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := GenerateNaclDHKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "skb_keyring_remove")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ring := NewSKBKeyringFile(filepath.Join(dir, "secretkeys.mpack"))
+	for _, k := range []GenericKey{sig, enc} {
+		skb, err := k.ToLksSKB(lks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ring.PushAndSave(skb); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !ring.Remove(sig.GetKID()) {
+		t.Fatal("expected to remove the signing key")
+	}
+	if ring.Remove(sig.GetKID()) {
+		t.Error("removed the signing key twice")
+	}
+	if err = ring.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewSKBKeyringFile(ring.GetFilename())
+	if err = reloaded.LoadAndIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Blocks) != 1 {
+		t.Fatalf("got %d keys after removal, expected 1", len(reloaded.Blocks))
+	}
+	if reloaded.LookupByKid(sig.GetKID()) != nil {
+		t.Error("removed signing key is still in the keyring")
+	}
+	if reloaded.LookupByKid(enc.GetKID()) == nil {
+		t.Error("encryption key is missing from the keyring")
+	}
+}
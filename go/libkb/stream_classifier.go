@@ -87,6 +87,10 @@ const (
 	CryptoMessageTypeAmbiguous CryptoMessageType = 4
 	// CryptoMessageTypeSignature is for a sig that can be either attached or detached
 	CryptoMessageTypeSignature CryptoMessageType = 5
+	// CryptoMessageTypeSigncryption is for a message that is both encrypted and signed.
+	// Armored signcrypted messages can't be told apart from encrypted ones until
+	// they are dearmored.
+	CryptoMessageTypeSigncryption CryptoMessageType = 6
 )
 
 // StreamClassification tells what Format the stream is, if it's a Public signature or a Private
@@ -164,7 +168,7 @@ func isSaltpackBinary(b []byte, sc *StreamClassification) bool {
 	if err := codec.NewDecoderBytes(tmp, &mh).Decode(&sphp); err != nil {
 		return false
 	}
-	if sphp.FormatName != saltpack.SaltpackFormatName {
+	if sphp.FormatName != saltpack.FormatName {
 		return false
 	}
 	switch sphp.Type {
@@ -174,6 +178,8 @@ func isSaltpackBinary(b []byte, sc *StreamClassification) bool {
 		sc.Type = CryptoMessageTypeAttachedSignature
 	case saltpack.MessageTypeDetachedSignature:
 		sc.Type = CryptoMessageTypeDetachedSignature
+	case saltpack.MessageTypeSigncryption:
+		sc.Type = CryptoMessageTypeSigncryption
 	default:
 		return false
 	}
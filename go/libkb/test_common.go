@@ -84,6 +84,9 @@ type TestContext struct {
 	Tp         TestParameters
 	// TODO: Rename this to TB.
 	T testing.TB
+	// If non-nil, the server this test talks to instead of the
+	// real API server.
+	FakeAPI *FakeAPIServer
 }
 
 func (tc *TestContext) Cleanup() {
@@ -96,6 +99,9 @@ func (tc *TestContext) Cleanup() {
 		tc.G.Log.Debug("clearing stored secrets:")
 		tc.ClearAllStoredSecrets()
 	}
+	if tc.FakeAPI != nil {
+		tc.FakeAPI.release()
+	}
 	tc.G.Log.Debug("cleanup complete")
 }
 
@@ -182,7 +188,12 @@ func (tc TestContext) ClearAllStoredSecrets() error {
 
 var setupTestMu sync.Mutex
 
-func setupTestContext(tb testing.TB, name string, tcPrev *TestContext) (tc TestContext, err error) {
+// sharedFakeAPI is the server that SetupTest uses for every test when
+// KEYBASE_TEST_FAKE_API is set, so that tests with several users or
+// devices see one another.
+var sharedFakeAPI *FakeAPIServer
+
+func setupTestContext(tb testing.TB, name string, tcPrev *TestContext, fake *FakeAPIServer) (tc TestContext, err error) {
 	setupTestMu.Lock()
 	defer setupTestMu.Unlock()
 
@@ -219,6 +230,15 @@ func setupTestContext(tb testing.TB, name string, tcPrev *TestContext) (tc TestC
 	tc.Tp.Devel = true
 	tc.Tp.DevelName = name
 
+	if tcPrev != nil {
+		fake = tcPrev.FakeAPI
+	}
+	if fake != nil {
+		tc.FakeAPI = fake.retain()
+		tc.Tp.ServerURI = fake.URL()
+		tc.Tp.MerkleKIDs = []string{fake.MerkleKID().String()}
+	}
+
 	g.Env.Test = tc.Tp
 
 	g.ConfigureLogging()
@@ -255,16 +275,52 @@ func setupTestContext(tb testing.TB, name string, tcPrev *TestContext) (tc TestC
 	tc.G = g
 	tc.T = tb
 
-	if G.SecretStoreAll == nil {
-		G.SecretStoreAll = NewTestSecretStoreAll(G, G)
+	// Use the mock secret store where there is one, rather than the
+	// platform's (such as the Secret Service on the session bus).
+	if s := NewTestSecretStoreAll(G, G); s != nil {
+		G.SecretStoreAll = s
 	}
 
 	return
 }
 
 func SetupTest(tb testing.TB, name string) (tc TestContext) {
+	var fake *FakeAPIServer
+	if val, _ := getEnvBool("KEYBASE_TEST_FAKE_API"); val {
+		setupTestMu.Lock()
+		if sharedFakeAPI == nil {
+			f, err := NewFakeAPIServer()
+			if err != nil {
+				setupTestMu.Unlock()
+				tb.Fatal(err)
+			}
+			// Never let go of the shared one.
+			sharedFakeAPI = f.retain()
+		}
+		fake = sharedFakeAPI
+		setupTestMu.Unlock()
+	}
 	var err error
-	tc, err = setupTestContext(tb, name, nil)
+	tc, err = setupTestContext(tb, name, nil, fake)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return tc
+}
+
+// SetupTestWithFakeAPI is like SetupTest, but talks to fake instead of
+// the API server. If fake is nil, it starts a new one, which goes away
+// when the last TestContext using it is cleaned up. Pass tc.FakeAPI to
+// set up another user or device on the same server.
+func SetupTestWithFakeAPI(tb testing.TB, name string, fake *FakeAPIServer) (tc TestContext) {
+	var err error
+	if fake == nil {
+		if fake, err = NewFakeAPIServer(); err != nil {
+			tb.Fatal(err)
+		}
+		defer fake.release()
+	}
+	tc, err = setupTestContext(tb, name, nil, fake)
 	if err != nil {
 		tb.Fatal(err)
 	}
@@ -278,7 +334,7 @@ func (tc *TestContext) SetRuntimeDir(s string) {
 
 func (tc TestContext) Clone() (ret TestContext) {
 	var err error
-	ret, err = setupTestContext(tc.T, "", &tc)
+	ret, err = setupTestContext(tc.T, "", &tc, nil)
 	if err != nil {
 		tc.T.Fatal(err)
 	}
@@ -14,6 +14,7 @@ import (
 
 type TrackCache struct {
 	cache *ramcache.Ramcache
+	cacheCounter
 }
 
 func NewTrackCache() *TrackCache {
@@ -25,7 +26,8 @@ func NewTrackCache() *TrackCache {
 	return res
 }
 
-func (c *TrackCache) Get(key keybase1.TrackToken) (*IdentifyOutcome, error) {
+func (c *TrackCache) Get(key keybase1.TrackToken) (outcome *IdentifyOutcome, err error) {
+	defer func() { c.record(outcome != nil) }()
 	v, err := c.cache.Get(string(key))
 	if err != nil {
 		if err == ramcache.ErrNotFound {
@@ -387,6 +387,12 @@ func (u *User) GetEldestKID() (ret keybase1.KID) {
 	return u.leaf.eldest
 }
 
+// GetMerkleSeqno returns the seqno of the merkle root the user was last
+// looked up in, or 0 if they haven't been.
+func (u *User) GetMerkleSeqno() Seqno {
+	return u.leaf.root
+}
+
 func (u *User) GetPublicChainTail() *MerkleTriple {
 	if u.sigChainMem == nil {
 		return nil
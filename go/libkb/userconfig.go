@@ -42,6 +42,10 @@ type UserConfig struct {
 	Salt   string             `json:"salt"`
 	Device *string            `json:"device"`
 
+	// RecipientGroups are named lists of assertions that can be used as
+	// recipients for `keybase encrypt --group`.
+	RecipientGroups map[string][]string `json:"recipient_groups,omitempty"`
+
 	importedID       keybase1.UID
 	importedSalt     []byte
 	importedDeviceID keybase1.DeviceID
@@ -54,6 +58,15 @@ func (u UserConfig) GetUsername() NormalizedUsername { return u.Name }
 func (u UserConfig) GetSalt() []byte                 { return u.importedSalt }
 func (u UserConfig) GetDeviceID() keybase1.DeviceID  { return u.importedDeviceID }
 
+// GetRecipientGroup returns the assertions in the named recipient group.
+func (u UserConfig) GetRecipientGroup(name string) ([]string, error) {
+	members, ok := u.RecipientGroups[name]
+	if !ok {
+		return nil, RecipientGroupNotFoundError{Name: name}
+	}
+	return members, nil
+}
+
 //==================================================================
 
 func NewUserConfig(id keybase1.UID, name NormalizedUsername, salt []byte, dev keybase1.DeviceID) *UserConfig {
@@ -1,8 +1,15 @@
 package logger
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +24,9 @@ type LogFileConfig struct {
 	MaxSize int64
 	// MaxAge is th duration before log rotation, zero value for infinite.
 	MaxAge time.Duration
+	// MaxKeepFiles is the number of rotated log files to keep, 0 for all.
+	// Rotated files are gzipped.
+	MaxKeepFiles int
 }
 
 // SetLogFileConfig sets the log file config to be used globally.
@@ -30,7 +40,7 @@ func SetLogFileConfig(lfc *LogFileConfig) error {
 		first = false
 		w.lock.Lock()
 		defer w.lock.Unlock()
-		w.Close()
+		w.closeLocked()
 	} else {
 		w = &logFileWriter{}
 	}
@@ -57,6 +67,7 @@ type logFileWriter struct {
 	file         *os.File
 	currentSize  int64
 	currentStart time.Time
+	compressing  sync.WaitGroup
 }
 
 func (lfw *logFileWriter) Open(at time.Time) error {
@@ -81,6 +92,13 @@ func (lfw *logFileWriter) Close() error {
 	}
 	lfw.lock.Lock()
 	defer lfw.lock.Unlock()
+	return lfw.closeLocked()
+}
+
+func (lfw *logFileWriter) closeLocked() error {
+	// Let rotated files finish compressing, so none are left half done.
+	// Compressing doesn't take the lock.
+	lfw.compressing.Wait()
 	if lfw.file == nil {
 		return nil
 	}
@@ -121,5 +139,120 @@ func (lfw *logFileWriter) Write(bs []byte) (int, error) {
 		return n, err
 	}
 	err = lfw.Open(now)
+
+	// Compress in the background, since logging waits on the lock.
+	lfw.compressing.Add(1)
+	go func(config LogFileConfig) {
+		defer lfw.compressing.Done()
+		compressAndPrune(tgt, config)
+	}(lfw.config)
 	return n, err
 }
+
+// rotatedLogFileSuffixRE matches what follows the log file's name in the
+// names of its rotated segments: the start and end times of the segment,
+// and ".gz" once it's compressed.
+var rotatedLogFileSuffixRE = regexp.MustCompile(`^-\d{8}T\d{6}-\d{8}T\d{6}(\.gz)?$`)
+
+// RotatedLogFiles lists the rotated segments of the log file at path,
+// oldest first.
+func RotatedLogFiles(path string) ([]string, error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ret []string
+	for _, fi := range infos {
+		name := fi.Name()
+		if strings.HasPrefix(name, base) && rotatedLogFileSuffixRE.MatchString(name[len(base):]) {
+			ret = append(ret, filepath.Join(dir, name))
+		}
+	}
+	// The times in the names sort in order.
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// compressMu keeps compressions and pruning from running over each other
+// if the log rotates quickly.
+var compressMu sync.Mutex
+
+// compressAndPrune gzips the rotated log segment at path, and removes the
+// oldest segments over config.MaxKeepFiles.
+func compressAndPrune(path string, config LogFileConfig) {
+	compressMu.Lock()
+	defer compressMu.Unlock()
+
+	// The file's gone if an earlier prune already removed it.
+	if err := compressLogFile(path); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error compressing log file %s: %s\n", path, err)
+	}
+	removeStaleCompressions(config.Path)
+	if config.MaxKeepFiles <= 0 {
+		return
+	}
+	files, err := RotatedLogFiles(config.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing rotated log files: %s\n", err)
+		return
+	}
+	for len(files) > config.MaxKeepFiles {
+		if err := os.Remove(files[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing old log file %s: %s\n", files[0], err)
+		}
+		files = files[1:]
+	}
+}
+
+// removeStaleCompressions removes the temporary files of compressions that
+// were cut off, say by the process exiting. It must be called with
+// compressMu held, so that no compression is running.
+func removeStaleCompressions(path string) {
+	tmps, err := filepath.Glob(path + "-*.gz.tmp")
+	if err != nil {
+		return
+	}
+	for _, tmp := range tmps {
+		base := strings.TrimSuffix(tmp, ".gz.tmp")
+		if rotatedLogFileSuffixRE.MatchString(base[len(path):]) {
+			os.Remove(tmp)
+		}
+	}
+}
+
+// compressLogFile replaces the file at path with a gzipped copy at
+// path.gz.
+func compressLogFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := path + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if err == nil {
+		err = gz.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(path)
+}
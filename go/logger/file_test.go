@@ -0,0 +1,110 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogFileRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	w := &logFileWriter{config: LogFileConfig{
+		Path:         path,
+		MaxSize:      10,
+		MaxKeepFiles: 2,
+	}}
+	start := time.Now().Add(-time.Hour)
+	if err := w.Open(start); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		// Rotated files are named by time, so make each one start at a
+		// different second.
+		w.currentStart = start.Add(time.Duration(i) * time.Minute)
+		if _, err := fmt.Fprintf(w, "log line %d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.compressing.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := RotatedLogFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 2 {
+		t.Fatalf("Expected 2 rotated files, got %v", rotated)
+	}
+	for i, name := range rotated {
+		if !strings.HasSuffix(name, ".gz") {
+			t.Fatalf("Rotated file %s wasn't compressed", name)
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(gz)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := fmt.Sprintf("log line %d\n", i+2); string(data) != expected {
+			t.Errorf("%s has %q, expected %q", name, data, expected)
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0 {
+		t.Errorf("Current log file has %q, expected nothing", data)
+	}
+}
+
+func TestLogFileStaleCompression(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	stale := path + "-20160101T000000-20160102T000000.gz.tmp"
+	if err := ioutil.WriteFile(stale, []byte("partial"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rotated := path + "-20160102T000000-20160103T000000"
+	if err := ioutil.WriteFile(rotated, []byte("log line\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	compressAndPrune(rotated, LogFileConfig{Path: path, MaxKeepFiles: 5})
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Stale compression %s wasn't removed: %v", stale, err)
+	}
+	if _, err := os.Stat(rotated + ".gz"); err != nil {
+		t.Errorf("Rotated file wasn't compressed: %s", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync/atomic"
+
+	logging "github.com/keybase/go-logging"
+	"golang.org/x/net/context"
+)
+
+// jsonTimeFormat is the layout of the timestamp field in the JSON style.
+const jsonTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// jsonStyle is 1 while the JSON style is configured, in which case the
+// C* methods pass log tags to the formatter rather than adding them to
+// the message.
+var jsonStyle int32
+
+func useJSONStyle() bool {
+	return atomic.LoadInt32(&jsonStyle) == 1
+}
+
+func setJSONStyle(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&jsonStyle, v)
+}
+
+// logTagFields carries the log tags of a C* call to jsonFormatter, as the
+// last argument of the log record.
+type logTagFields map[string]string
+
+func logTagFieldsFromContext(ctx context.Context) logTagFields {
+	if ctx == nil {
+		return nil
+	}
+	logTags, ok := LogTagsFromContext(ctx)
+	if !ok || len(logTags) == 0 {
+		return nil
+	}
+	fields := make(logTagFields)
+	for key, tag := range logTags {
+		if v := ctx.Value(key); v != nil {
+			fields[tag] = fmt.Sprintf("%v", v)
+		}
+	}
+	return fields
+}
+
+// jsonFormatter writes each log record as a JSON object on one line, with
+// the log tags of C* calls as fields of their own.
+type jsonFormatter struct{}
+
+var _ logging.Formatter = jsonFormatter{}
+
+func (jsonFormatter) Format(calldepth int, r *logging.Record, w io.Writer) error {
+	rec := *r
+	var tags logTagFields
+	if n := len(rec.Args); n > 0 {
+		if t, ok := rec.Args[n-1].(logTagFields); ok {
+			tags = t
+			rec.Args = rec.Args[:n-1]
+		}
+	}
+
+	caller := "???:0"
+	if _, file, line, ok := runtime.Caller(calldepth + 1); ok {
+		caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	fields := []struct {
+		name  string
+		value interface{}
+	}{
+		{"timestamp", rec.Time.Format(jsonTimeFormat)},
+		{"level", rec.Level.String()},
+		{"module", rec.Module},
+		{"caller", caller},
+		{"id", rec.ID},
+		{"message", rec.Message()},
+	}
+	reserved := make(map[string]bool)
+	for _, f := range fields {
+		reserved[f.name] = true
+	}
+
+	var names []string
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	write := func(name string, value interface{}) error {
+		if buf.Len() == 0 {
+			buf.WriteByte('{')
+		} else {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+		return nil
+	}
+	for _, f := range fields {
+		if err := write(f.name, f.value); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		// Tags can't hide the standard fields.
+		field := name
+		if reserved[field] {
+			field = "tag_" + field
+		}
+		if err := write(field, tags[name]); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,77 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	logging "github.com/keybase/go-logging"
+	"golang.org/x/net/context"
+)
+
+type testLogTagKey int
+
+const (
+	testOpKey testLogTagKey = iota
+	testLevelKey
+)
+
+func TestJSONStyle(t *testing.T) {
+	var buf bytes.Buffer
+	logging.SetBackend(logging.NewLogBackend(&buf, "", 0))
+	defer logging.SetBackend(logging.NewLogBackend(ErrorWriter(), "", 0))
+
+	log := New("jsontest")
+	log.Configure("json", true, "")
+	defer log.Configure("", false, "")
+
+	ctx := NewContextWithLogTags(context.Background(), CtxLogTags{
+		testOpKey:    "op",
+		testLevelKey: "level",
+	})
+	ctx = context.WithValue(ctx, testOpKey, "abc")
+	ctx = context.WithValue(ctx, testLevelKey, 3)
+	log.CInfof(ctx, "hello %s", "world")
+	log.Debug("plain %d", 5)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	var objs []map[string]interface{}
+	for _, line := range lines {
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatalf("Line %q isn't JSON: %s", line, err)
+		}
+		objs = append(objs, obj)
+	}
+
+	expected := map[string]interface{}{
+		"level":     "INFO",
+		"module":    "jsontest",
+		"message":   "hello world",
+		"op":        "abc",
+		"tag_level": "3",
+	}
+	for k, v := range expected {
+		if objs[0][k] != v {
+			t.Errorf("%s: %v, expected %v", k, objs[0][k], v)
+		}
+	}
+	if objs[1]["message"] != "plain 5" || objs[1]["level"] != "DEBUG" {
+		t.Errorf("Unexpected record %v", objs[1])
+	}
+	for _, obj := range objs {
+		if caller, _ := obj["caller"].(string); !strings.HasPrefix(caller, "json_format_test.go:") {
+			t.Errorf("caller is %q", caller)
+		}
+		if _, ok := obj["timestamp"].(string); !ok {
+			t.Errorf("No timestamp in %v", obj)
+		}
+	}
+}
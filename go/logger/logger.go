@@ -57,6 +57,9 @@ type Logger interface {
 	// RotateLogFile rotates the log file, if the underlying logger is
 	// writing to a file.
 	RotateLogFile() error
+	// SetRotation sets when RotateLogFile rotates the log file, and how
+	// many old ones it keeps.
+	SetRotation(config LogFileConfig)
 
 	// SetExternalHandler sets a handler that will be called with every log message.
 	SetExternalHandler(handler ExternalHandler)
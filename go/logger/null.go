@@ -32,5 +32,6 @@ func (l *Null) CErrorf(ctx context.Context, fmt string, arg ...interface{})    {
 func (l *Null) Error(fmt string, arg ...interface{})                           {}
 func (l *Null) Configure(style string, debug bool, filename string)            {}
 func (l *Null) RotateLogFile() error                                           { return nil }
+func (l *Null) SetRotation(config LogFileConfig)                               {}
 func (l *Null) SetExternalHandler(handler ExternalHandler)                     {}
 func (l *Null) Shutdown()                                                      {}
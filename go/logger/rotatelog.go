@@ -8,15 +8,34 @@ import (
 	"time"
 )
 
+// SetRotation sets when RotateLogFile rotates the log file, and how many
+// old ones it keeps. The path in config is ignored, the filename set from
+// .Configure is used instead.
+func (log *Standard) SetRotation(config LogFileConfig) {
+	log.configureMutex.Lock()
+	defer log.configureMutex.Unlock()
+	log.rotation = &config
+}
+
 // RotateLogFile is the old style of logging to a file. It uses a default
-// config for log rotation and uses the filename set from .Configure.
+// config for log rotation, unless one was set with SetRotation, and uses
+// the filename set from .Configure.
 func (log *Standard) RotateLogFile() error {
-	if log.filename == "" {
+	log.configureMutex.Lock()
+	filename := log.filename
+	rotation := log.rotation
+	log.configureMutex.Unlock()
+
+	if filename == "" {
 		return errors.New("No log filename specified")
 	}
-	return SetLogFileConfig(&LogFileConfig{
-		Path:    log.filename,
+	config := LogFileConfig{
 		MaxAge:  30 * 24 * time.Hour, // 30 days
 		MaxSize: 128 * 1024 * 1024,   // 128mb
-	})
+	}
+	if rotation != nil {
+		config = *rotation
+	}
+	config.Path = filename
+	return SetLogFileConfig(&config)
 }
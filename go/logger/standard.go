@@ -72,7 +72,9 @@ type entry struct {
 
 type Standard struct {
 	internal       *logging.Logger
+	ctxInternal    *logging.Logger
 	filename       string
+	rotation       *LogFileConfig
 	configureMutex sync.Mutex
 	module         string
 
@@ -93,10 +95,14 @@ func New(module string) *Standard {
 func NewWithCallDepth(module string, extraCallDepth int) *Standard {
 	log := logging.MustGetLogger(module)
 	log.ExtraCalldepth = 1 + extraCallDepth
+	// The C* methods go through clog as well.
+	ctxLog := logging.MustGetLogger(module)
+	ctxLog.ExtraCalldepth = 2 + extraCallDepth
 
 	ret := &Standard{
-		internal: log,
-		module:   module,
+		internal:    log,
+		ctxInternal: ctxLog,
+		module:      module,
 	}
 	ret.setLogLevelInfo()
 	return ret
@@ -130,6 +136,41 @@ func (log *Standard) prepareString(
 	return fmts + " [tags:" + strings.Join(tags, ",") + "]"
 }
 
+// clog logs a C* call. The log tags in ctx are added to the message, or
+// in the JSON style, passed to the formatter to write as fields.
+func (log *Standard) clog(ctx context.Context, level logging.Level,
+	extLevel keybase1.LogLevel, fmts string, arg []interface{}) {
+	if !log.internal.IsEnabledFor(level) {
+		return
+	}
+	tagged := log.prepareString(ctx, fmts)
+	internalFmts, internalArg := tagged, arg
+	if useJSONStyle() {
+		if fields := logTagFieldsFromContext(ctx); fields != nil {
+			internalFmts = fmts
+			internalArg = append(arg[:len(arg):len(arg)], fields)
+		}
+	}
+
+	switch level {
+	case logging.DEBUG:
+		log.ctxInternal.Debugf(internalFmts, internalArg...)
+	case logging.INFO:
+		log.ctxInternal.Infof(internalFmts, internalArg...)
+	case logging.NOTICE:
+		log.ctxInternal.Noticef(internalFmts, internalArg...)
+	case logging.WARNING:
+		log.ctxInternal.Warningf(internalFmts, internalArg...)
+	case logging.ERROR:
+		log.ctxInternal.Errorf(internalFmts, internalArg...)
+	case logging.CRITICAL:
+		log.ctxInternal.Criticalf(internalFmts, internalArg...)
+	}
+	if log.externalHandler != nil {
+		log.externalHandler.Log(extLevel, tagged, arg)
+	}
+}
+
 func (log *Standard) Debug(fmt string, arg ...interface{}) {
 	log.internal.Debugf(fmt, arg...)
 	if log.externalHandler != nil {
@@ -139,9 +180,7 @@ func (log *Standard) Debug(fmt string, arg ...interface{}) {
 
 func (log *Standard) CDebugf(ctx context.Context, fmt string,
 	arg ...interface{}) {
-	if log.internal.IsEnabledFor(logging.DEBUG) {
-		log.Debug(log.prepareString(ctx, fmt), arg...)
-	}
+	log.clog(ctx, logging.DEBUG, keybase1.LogLevel_DEBUG, fmt, arg)
 }
 
 func (log *Standard) Info(fmt string, arg ...interface{}) {
@@ -153,9 +192,7 @@ func (log *Standard) Info(fmt string, arg ...interface{}) {
 
 func (log *Standard) CInfof(ctx context.Context, fmt string,
 	arg ...interface{}) {
-	if log.internal.IsEnabledFor(logging.INFO) {
-		log.Info(log.prepareString(ctx, fmt), arg...)
-	}
+	log.clog(ctx, logging.INFO, keybase1.LogLevel_INFO, fmt, arg)
 }
 
 func (log *Standard) Notice(fmt string, arg ...interface{}) {
@@ -167,9 +204,7 @@ func (log *Standard) Notice(fmt string, arg ...interface{}) {
 
 func (log *Standard) CNoticef(ctx context.Context, fmt string,
 	arg ...interface{}) {
-	if log.internal.IsEnabledFor(logging.NOTICE) {
-		log.Notice(log.prepareString(ctx, fmt), arg...)
-	}
+	log.clog(ctx, logging.NOTICE, keybase1.LogLevel_NOTICE, fmt, arg)
 }
 
 func (log *Standard) Warning(fmt string, arg ...interface{}) {
@@ -181,9 +216,7 @@ func (log *Standard) Warning(fmt string, arg ...interface{}) {
 
 func (log *Standard) CWarningf(ctx context.Context, fmt string,
 	arg ...interface{}) {
-	if log.internal.IsEnabledFor(logging.WARNING) {
-		log.Warning(log.prepareString(ctx, fmt), arg...)
-	}
+	log.clog(ctx, logging.WARNING, keybase1.LogLevel_WARN, fmt, arg)
 }
 
 func (log *Standard) Error(fmt string, arg ...interface{}) {
@@ -199,9 +232,7 @@ func (log *Standard) Errorf(fmt string, arg ...interface{}) {
 
 func (log *Standard) CErrorf(ctx context.Context, fmt string,
 	arg ...interface{}) {
-	if log.internal.IsEnabledFor(logging.ERROR) {
-		log.Error(log.prepareString(ctx, fmt), arg...)
-	}
+	log.clog(ctx, logging.ERROR, keybase1.LogLevel_ERROR, fmt, arg)
 }
 
 func (log *Standard) Critical(fmt string, arg ...interface{}) {
@@ -213,9 +244,7 @@ func (log *Standard) Critical(fmt string, arg ...interface{}) {
 
 func (log *Standard) CCriticalf(ctx context.Context, fmt string,
 	arg ...interface{}) {
-	if log.internal.IsEnabledFor(logging.CRITICAL) {
-		log.Critical(log.prepareString(ctx, fmt), arg...)
-	}
+	log.clog(ctx, logging.CRITICAL, keybase1.LogLevel_CRITICAL, fmt, arg)
 }
 
 func (log *Standard) Fatalf(fmt string, arg ...interface{}) {
@@ -277,6 +306,13 @@ func (log *Standard) Configure(style string, debug bool, filename string) {
 		logging.SetLevel(logging.DEBUG, log.module)
 	}
 
+	if style == "json" {
+		// One JSON object per line, for log pipelines
+		setJSONStyle(true)
+		logging.SetFormatter(jsonFormatter{})
+		return
+	}
+	setJSONStyle(false)
 	logging.SetFormatter(logging.MustStringFormatter(logfmt))
 
 }
@@ -129,5 +129,9 @@ func (log *TestLogger) RotateLogFile() error {
 	return nil
 }
 
+func (log *TestLogger) SetRotation(config LogFileConfig) {
+	// no-op
+}
+
 // no-op stubs to fulfill the Logger interface
 func (log *TestLogger) SetExternalHandler(_ ExternalHandler) {}
@@ -20,9 +20,28 @@ type PassphrasePromptArg struct {
 	GuiArg    GUIEntryArg `codec:"guiArg" json:"guiArg"`
 }
 
+type GetPassphrasePolicyArg struct {
+	SessionID int `codec:"sessionID" json:"sessionID"`
+}
+
+type AccountExportArg struct {
+	SessionID  int    `codec:"sessionID" json:"sessionID"`
+	Passphrase string `codec:"passphrase" json:"passphrase"`
+}
+
+type AccountImportArg struct {
+	SessionID  int    `codec:"sessionID" json:"sessionID"`
+	Archive    []byte `codec:"archive" json:"archive"`
+	Passphrase string `codec:"passphrase" json:"passphrase"`
+	Force      bool   `codec:"force" json:"force"`
+}
+
 type AccountInterface interface {
 	PassphraseChange(context.Context, PassphraseChangeArg) error
 	PassphrasePrompt(context.Context, PassphrasePromptArg) (GetPassphraseRes, error)
+	GetPassphrasePolicy(context.Context, int) (PassphrasePolicy, error)
+	AccountExport(context.Context, AccountExportArg) ([]byte, error)
+	AccountImport(context.Context, AccountImportArg) error
 }
 
 func AccountProtocol(i AccountInterface) rpc.Protocol {
@@ -61,6 +80,54 @@ func AccountProtocol(i AccountInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"getPassphrasePolicy": {
+				MakeArg: func() interface{} {
+					ret := make([]GetPassphrasePolicyArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]GetPassphrasePolicyArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]GetPassphrasePolicyArg)(nil), args)
+						return
+					}
+					ret, err = i.GetPassphrasePolicy(ctx, (*typedArgs)[0].SessionID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"accountExport": {
+				MakeArg: func() interface{} {
+					ret := make([]AccountExportArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]AccountExportArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]AccountExportArg)(nil), args)
+						return
+					}
+					ret, err = i.AccountExport(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"accountImport": {
+				MakeArg: func() interface{} {
+					ret := make([]AccountImportArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]AccountImportArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]AccountImportArg)(nil), args)
+						return
+					}
+					err = i.AccountImport(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -78,3 +145,19 @@ func (c AccountClient) PassphrasePrompt(ctx context.Context, __arg PassphrasePro
 	err = c.Cli.Call(ctx, "keybase.1.account.passphrasePrompt", []interface{}{__arg}, &res)
 	return
 }
+
+func (c AccountClient) GetPassphrasePolicy(ctx context.Context, sessionID int) (res PassphrasePolicy, err error) {
+	__arg := GetPassphrasePolicyArg{SessionID: sessionID}
+	err = c.Cli.Call(ctx, "keybase.1.account.getPassphrasePolicy", []interface{}{__arg}, &res)
+	return
+}
+
+func (c AccountClient) AccountExport(ctx context.Context, __arg AccountExportArg) (res []byte, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.account.accountExport", []interface{}{__arg}, &res)
+	return
+}
+
+func (c AccountClient) AccountImport(ctx context.Context, __arg AccountImportArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.account.accountImport", []interface{}{__arg}, nil)
+	return
+}
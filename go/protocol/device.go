@@ -8,17 +8,46 @@ import (
 	context "golang.org/x/net/context"
 )
 
+type DeviceDetail struct {
+	Device              Device   `codec:"device" json:"device"`
+	AddedTime           Time     `codec:"addedTime" json:"addedTime"`
+	AddedSeqno          int      `codec:"addedSeqno" json:"addedSeqno"`
+	Provisioner         DeviceID `codec:"provisioner" json:"provisioner"`
+	ProvisionerName     string   `codec:"provisionerName" json:"provisionerName"`
+	LastSeenSigningTime Time     `codec:"lastSeenSigningTime" json:"lastSeenSigningTime"`
+	Note                string   `codec:"note" json:"note"`
+}
+
 type DeviceListArg struct {
 	SessionID int `codec:"sessionID" json:"sessionID"`
 }
 
-type DeviceAddArg struct {
+type DeviceDetailListArg struct {
 	SessionID int `codec:"sessionID" json:"sessionID"`
 }
 
+type SetDeviceNoteArg struct {
+	SessionID int      `codec:"sessionID" json:"sessionID"`
+	DeviceID  DeviceID `codec:"deviceID" json:"deviceID"`
+	Note      string   `codec:"note" json:"note"`
+}
+
+type DeviceAddArg struct {
+	SessionID  int    `codec:"sessionID" json:"sessionID"`
+	DirectCode string `codec:"directCode" json:"directCode"`
+}
+
+type RotateDeviceKeysArg struct {
+	SessionID   int    `codec:"sessionID" json:"sessionID"`
+	Pkcs11Token string `codec:"pkcs11Token" json:"pkcs11Token"`
+}
+
 type DeviceInterface interface {
 	DeviceList(context.Context, int) ([]Device, error)
-	DeviceAdd(context.Context, int) error
+	DeviceDetailList(context.Context, int) ([]DeviceDetail, error)
+	SetDeviceNote(context.Context, SetDeviceNoteArg) error
+	DeviceAdd(context.Context, DeviceAddArg) error
+	RotateDeviceKeys(context.Context, RotateDeviceKeysArg) error
 }
 
 func DeviceProtocol(i DeviceInterface) rpc.Protocol {
@@ -41,6 +70,38 @@ func DeviceProtocol(i DeviceInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"deviceDetailList": {
+				MakeArg: func() interface{} {
+					ret := make([]DeviceDetailListArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]DeviceDetailListArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]DeviceDetailListArg)(nil), args)
+						return
+					}
+					ret, err = i.DeviceDetailList(ctx, (*typedArgs)[0].SessionID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"setDeviceNote": {
+				MakeArg: func() interface{} {
+					ret := make([]SetDeviceNoteArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]SetDeviceNoteArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]SetDeviceNoteArg)(nil), args)
+						return
+					}
+					err = i.SetDeviceNote(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 			"deviceAdd": {
 				MakeArg: func() interface{} {
 					ret := make([]DeviceAddArg, 1)
@@ -52,7 +113,23 @@ func DeviceProtocol(i DeviceInterface) rpc.Protocol {
 						err = rpc.NewTypeError((*[]DeviceAddArg)(nil), args)
 						return
 					}
-					err = i.DeviceAdd(ctx, (*typedArgs)[0].SessionID)
+					err = i.DeviceAdd(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"rotateDeviceKeys": {
+				MakeArg: func() interface{} {
+					ret := make([]RotateDeviceKeysArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]RotateDeviceKeysArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]RotateDeviceKeysArg)(nil), args)
+						return
+					}
+					err = i.RotateDeviceKeys(ctx, (*typedArgs)[0])
 					return
 				},
 				MethodType: rpc.MethodCall,
@@ -71,8 +148,23 @@ func (c DeviceClient) DeviceList(ctx context.Context, sessionID int) (res []Devi
 	return
 }
 
-func (c DeviceClient) DeviceAdd(ctx context.Context, sessionID int) (err error) {
-	__arg := DeviceAddArg{SessionID: sessionID}
+func (c DeviceClient) DeviceDetailList(ctx context.Context, sessionID int) (res []DeviceDetail, err error) {
+	__arg := DeviceDetailListArg{SessionID: sessionID}
+	err = c.Cli.Call(ctx, "keybase.1.device.deviceDetailList", []interface{}{__arg}, &res)
+	return
+}
+
+func (c DeviceClient) SetDeviceNote(ctx context.Context, __arg SetDeviceNoteArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.device.setDeviceNote", []interface{}{__arg}, nil)
+	return
+}
+
+func (c DeviceClient) DeviceAdd(ctx context.Context, __arg DeviceAddArg) (err error) {
 	err = c.Cli.Call(ctx, "keybase.1.device.deviceAdd", []interface{}{__arg}, nil)
 	return
 }
+
+func (c DeviceClient) RotateDeviceKeys(ctx context.Context, __arg RotateDeviceKeysArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.device.rotateDeviceKeys", []interface{}{__arg}, nil)
+	return
+}
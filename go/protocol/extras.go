@@ -563,3 +563,33 @@ func (r BlockReference) String() string {
 func (r BlockReferenceCount) String() string {
 	return fmt.Sprintf("%s,%d", r.Ref.String(), r.LiveCount)
 }
+
+func (t MerkleForkType) String() string {
+	switch t {
+	case MerkleForkType_ROLLBACK:
+		return "rollback"
+	case MerkleForkType_EQUIVOCATION:
+		return "equivocation"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+func (s TrackStatus) String() string {
+	switch s {
+	case TrackStatus_NEW_OK:
+		return "new, ok"
+	case TrackStatus_NEW_ZERO_PROOFS:
+		return "new, no proofs"
+	case TrackStatus_NEW_FAIL_PROOFS:
+		return "new, some proofs failed"
+	case TrackStatus_UPDATE_BROKEN:
+		return "tracked, broken"
+	case TrackStatus_UPDATE_NEW_PROOFS:
+		return "tracked, new proofs"
+	case TrackStatus_UPDATE_OK:
+		return "tracked, ok"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
@@ -8,6 +8,26 @@ import (
 	context "golang.org/x/net/context"
 )
 
+type IdentifyReportProofCheck struct {
+	Proof  RemoteProof     `codec:"proof" json:"proof"`
+	Result LinkCheckResult `codec:"result" json:"result"`
+}
+
+type IdentifyReport struct {
+	Version        int                        `codec:"version" json:"version"`
+	CTime          Time                       `codec:"cTime" json:"cTime"`
+	Assertion      string                     `codec:"assertion" json:"assertion"`
+	User           *User                      `codec:"user,omitempty" json:"user,omitempty"`
+	PublicKeys     []PublicKey                `codec:"publicKeys" json:"publicKeys"`
+	Outcome        IdentifyOutcome            `codec:"outcome" json:"outcome"`
+	ProofChecks    []IdentifyReportProofCheck `codec:"proofChecks" json:"proofChecks"`
+	MerkleSeqno    int                        `codec:"merkleSeqno" json:"merkleSeqno"`
+	SignerUID      UID                        `codec:"signerUID" json:"signerUID"`
+	SignerUsername string                     `codec:"signerUsername" json:"signerUsername"`
+	SignerDeviceID DeviceID                   `codec:"signerDeviceID" json:"signerDeviceID"`
+	SigningKID     KID                        `codec:"signingKID" json:"signingKID"`
+}
+
 type Identify2Res struct {
 	Upk UserPlusKeys `codec:"upk" json:"upk"`
 }
@@ -30,6 +50,17 @@ type IdentifyArg struct {
 	Source           ClientType     `codec:"source" json:"source"`
 }
 
+type IdentifyReportArg struct {
+	SessionID        int    `codec:"sessionID" json:"sessionID"`
+	UserAssertion    string `codec:"userAssertion" json:"userAssertion"`
+	ForceRemoteCheck bool   `codec:"forceRemoteCheck" json:"forceRemoteCheck"`
+}
+
+type VerifyIdentifyReportArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	Report    string `codec:"report" json:"report"`
+}
+
 type Identify2Arg struct {
 	SessionID             int            `codec:"sessionID" json:"sessionID"`
 	Uid                   UID            `codec:"uid" json:"uid"`
@@ -42,11 +73,23 @@ type Identify2Arg struct {
 	NeedProofSet          bool           `codec:"needProofSet" json:"needProofSet"`
 }
 
+type Identify2BatchArg struct {
+	SessionID        int            `codec:"sessionID" json:"sessionID"`
+	Assertions       []string       `codec:"assertions" json:"assertions"`
+	Reason           IdentifyReason `codec:"reason" json:"reason"`
+	Parallelism      int            `codec:"parallelism" json:"parallelism"`
+	ForceRemoteCheck bool           `codec:"forceRemoteCheck" json:"forceRemoteCheck"`
+	NeedProofSet     bool           `codec:"needProofSet" json:"needProofSet"`
+}
+
 type IdentifyInterface interface {
 	Resolve(context.Context, string) (UID, error)
 	Resolve2(context.Context, string) (User, error)
 	Identify(context.Context, IdentifyArg) (IdentifyRes, error)
+	IdentifyReport(context.Context, IdentifyReportArg) (string, error)
+	VerifyIdentifyReport(context.Context, VerifyIdentifyReportArg) (IdentifyReport, error)
 	Identify2(context.Context, Identify2Arg) (Identify2Res, error)
+	Identify2Batch(context.Context, Identify2BatchArg) error
 }
 
 func IdentifyProtocol(i IdentifyInterface) rpc.Protocol {
@@ -101,6 +144,38 @@ func IdentifyProtocol(i IdentifyInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"identifyReport": {
+				MakeArg: func() interface{} {
+					ret := make([]IdentifyReportArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]IdentifyReportArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]IdentifyReportArg)(nil), args)
+						return
+					}
+					ret, err = i.IdentifyReport(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+			"verifyIdentifyReport": {
+				MakeArg: func() interface{} {
+					ret := make([]VerifyIdentifyReportArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]VerifyIdentifyReportArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]VerifyIdentifyReportArg)(nil), args)
+						return
+					}
+					ret, err = i.VerifyIdentifyReport(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 			"identify2": {
 				MakeArg: func() interface{} {
 					ret := make([]Identify2Arg, 1)
@@ -117,6 +192,22 @@ func IdentifyProtocol(i IdentifyInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"identify2Batch": {
+				MakeArg: func() interface{} {
+					ret := make([]Identify2BatchArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]Identify2BatchArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]Identify2BatchArg)(nil), args)
+						return
+					}
+					err = i.Identify2Batch(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -142,7 +233,22 @@ func (c IdentifyClient) Identify(ctx context.Context, __arg IdentifyArg) (res Id
 	return
 }
 
+func (c IdentifyClient) IdentifyReport(ctx context.Context, __arg IdentifyReportArg) (res string, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.identify.identifyReport", []interface{}{__arg}, &res)
+	return
+}
+
+func (c IdentifyClient) VerifyIdentifyReport(ctx context.Context, __arg VerifyIdentifyReportArg) (res IdentifyReport, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.identify.verifyIdentifyReport", []interface{}{__arg}, &res)
+	return
+}
+
 func (c IdentifyClient) Identify2(ctx context.Context, __arg Identify2Arg) (res Identify2Res, err error) {
 	err = c.Cli.Call(ctx, "keybase.1.identify.identify2", []interface{}{__arg}, &res)
 	return
 }
+
+func (c IdentifyClient) Identify2Batch(ctx context.Context, __arg Identify2BatchArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.identify.identify2Batch", []interface{}{__arg}, nil)
+	return
+}
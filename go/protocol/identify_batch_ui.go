@@ -0,0 +1,59 @@
+// Auto-generated by avdl-compiler v1.1.1 (https://github.com/keybase/node-avdl-compiler)
+//   Input file: avdl/identify_batch_ui.avdl
+
+package keybase1
+
+import (
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	context "golang.org/x/net/context"
+)
+
+type Identify2BatchResult struct {
+	Assertion string        `codec:"assertion" json:"assertion"`
+	Uid       UID           `codec:"uid" json:"uid"`
+	Username  string        `codec:"username" json:"username"`
+	Upk       *UserPlusKeys `codec:"upk,omitempty" json:"upk,omitempty"`
+	Status    *Status       `codec:"status,omitempty" json:"status,omitempty"`
+}
+
+type Identify2BatchResultArg struct {
+	SessionID int                  `codec:"sessionID" json:"sessionID"`
+	Result    Identify2BatchResult `codec:"result" json:"result"`
+}
+
+type IdentifyBatchUiInterface interface {
+	Identify2BatchResult(context.Context, Identify2BatchResultArg) error
+}
+
+func IdentifyBatchUiProtocol(i IdentifyBatchUiInterface) rpc.Protocol {
+	return rpc.Protocol{
+		Name: "keybase.1.identifyBatchUi",
+		Methods: map[string]rpc.ServeHandlerDescription{
+			"identify2BatchResult": {
+				MakeArg: func() interface{} {
+					ret := make([]Identify2BatchResultArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]Identify2BatchResultArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]Identify2BatchResultArg)(nil), args)
+						return
+					}
+					err = i.Identify2BatchResult(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+		},
+	}
+}
+
+type IdentifyBatchUiClient struct {
+	Cli rpc.GenericClient
+}
+
+func (c IdentifyBatchUiClient) Identify2BatchResult(ctx context.Context, __arg Identify2BatchResultArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.identifyBatchUi.identify2BatchResult", []interface{}{__arg}, nil)
+	return
+}
@@ -22,6 +22,7 @@ type LoginArg struct {
 	DeviceType      string     `codec:"deviceType" json:"deviceType"`
 	UsernameOrEmail string     `codec:"usernameOrEmail" json:"usernameOrEmail"`
 	ClientType      ClientType `codec:"clientType" json:"clientType"`
+	Pkcs11Token     string     `codec:"pkcs11Token" json:"pkcs11Token"`
 }
 
 type ClearStoredSecretArg struct {
@@ -44,7 +45,9 @@ type RecoverAccountFromEmailAddressArg struct {
 }
 
 type PaperKeyArg struct {
-	SessionID int `codec:"sessionID" json:"sessionID"`
+	SessionID      int `codec:"sessionID" json:"sessionID"`
+	ShareThreshold int `codec:"shareThreshold" json:"shareThreshold"`
+	ShareCount     int `codec:"shareCount" json:"shareCount"`
 }
 
 type UnlockArg struct {
@@ -63,7 +66,7 @@ type LoginInterface interface {
 	Logout(context.Context, int) error
 	Deprovision(context.Context, DeprovisionArg) error
 	RecoverAccountFromEmailAddress(context.Context, string) error
-	PaperKey(context.Context, int) error
+	PaperKey(context.Context, PaperKeyArg) error
 	Unlock(context.Context, int) error
 	UnlockWithPassphrase(context.Context, UnlockWithPassphraseArg) error
 }
@@ -179,7 +182,7 @@ func LoginProtocol(i LoginInterface) rpc.Protocol {
 						err = rpc.NewTypeError((*[]PaperKeyArg)(nil), args)
 						return
 					}
-					err = i.PaperKey(ctx, (*typedArgs)[0].SessionID)
+					err = i.PaperKey(ctx, (*typedArgs)[0])
 					return
 				},
 				MethodType: rpc.MethodCall,
@@ -257,8 +260,7 @@ func (c LoginClient) RecoverAccountFromEmailAddress(ctx context.Context, email s
 	return
 }
 
-func (c LoginClient) PaperKey(ctx context.Context, sessionID int) (err error) {
-	__arg := PaperKeyArg{SessionID: sessionID}
+func (c LoginClient) PaperKey(ctx context.Context, __arg PaperKeyArg) (err error) {
 	err = c.Cli.Call(ctx, "keybase.1.login.paperKey", []interface{}{__arg}, nil)
 	return
 }
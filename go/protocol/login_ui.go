@@ -19,8 +19,10 @@ type PromptRevokePaperKeysArg struct {
 }
 
 type DisplayPaperKeyPhraseArg struct {
-	SessionID int    `codec:"sessionID" json:"sessionID"`
-	Phrase    string `codec:"phrase" json:"phrase"`
+	SessionID      int      `codec:"sessionID" json:"sessionID"`
+	Phrase         string   `codec:"phrase" json:"phrase"`
+	Shares         []string `codec:"shares" json:"shares"`
+	ShareThreshold int      `codec:"shareThreshold" json:"shareThreshold"`
 }
 
 type DisplayPrimaryPaperKeyArg struct {
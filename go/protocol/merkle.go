@@ -0,0 +1,81 @@
+// Auto-generated by avdl-compiler v1.1.1 (https://github.com/keybase/node-avdl-compiler)
+//   Input file: avdl/merkle.avdl
+
+package keybase1
+
+import (
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	context "golang.org/x/net/context"
+)
+
+type MerkleRootSummary struct {
+	Seqno int    `codec:"seqno" json:"seqno"`
+	Hash  string `codec:"hash" json:"hash"`
+	Ctime Time   `codec:"ctime" json:"ctime"`
+}
+
+type MerkleForkType int
+
+const (
+	MerkleForkType_ROLLBACK     MerkleForkType = 0
+	MerkleForkType_EQUIVOCATION MerkleForkType = 1
+)
+
+type MerkleFork struct {
+	Type     MerkleForkType    `codec:"type" json:"type"`
+	Prev     MerkleRootSummary `codec:"prev" json:"prev"`
+	Root     MerkleRootSummary `codec:"root" json:"root"`
+	PrevJSON string            `codec:"prevJSON" json:"prevJSON"`
+	RootJSON string            `codec:"rootJSON" json:"rootJSON"`
+	SeenAt   Time              `codec:"seenAt" json:"seenAt"`
+}
+
+type MerkleAuditResult struct {
+	NumRoots int                `codec:"numRoots" json:"numRoots"`
+	Oldest   *MerkleRootSummary `codec:"oldest,omitempty" json:"oldest,omitempty"`
+	Latest   *MerkleRootSummary `codec:"latest,omitempty" json:"latest,omitempty"`
+	Problems []string           `codec:"problems" json:"problems"`
+	Forks    []MerkleFork       `codec:"forks" json:"forks"`
+}
+
+type MerkleAuditArg struct {
+	SessionID int `codec:"sessionID" json:"sessionID"`
+}
+
+type MerkleInterface interface {
+	MerkleAudit(context.Context, int) (MerkleAuditResult, error)
+}
+
+func MerkleProtocol(i MerkleInterface) rpc.Protocol {
+	return rpc.Protocol{
+		Name: "keybase.1.merkle",
+		Methods: map[string]rpc.ServeHandlerDescription{
+			"merkleAudit": {
+				MakeArg: func() interface{} {
+					ret := make([]MerkleAuditArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]MerkleAuditArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]MerkleAuditArg)(nil), args)
+						return
+					}
+					ret, err = i.MerkleAudit(ctx, (*typedArgs)[0].SessionID)
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
+		},
+	}
+}
+
+type MerkleClient struct {
+	Cli rpc.GenericClient
+}
+
+func (c MerkleClient) MerkleAudit(ctx context.Context, sessionID int) (res MerkleAuditResult, err error) {
+	__arg := MerkleAuditArg{SessionID: sessionID}
+	err = c.Cli.Call(ctx, "keybase.1.merkle.merkleAudit", []interface{}{__arg}, &res)
+	return
+}
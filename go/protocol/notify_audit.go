@@ -0,0 +1,51 @@
+// Auto-generated by avdl-compiler v1.1.1 (https://github.com/keybase/node-avdl-compiler)
+//   Input file: avdl/notify_audit.avdl
+
+package keybase1
+
+import (
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	context "golang.org/x/net/context"
+)
+
+type MerkleForkDetectedArg struct {
+	Fork MerkleFork `codec:"fork" json:"fork"`
+}
+
+type NotifyAuditInterface interface {
+	MerkleForkDetected(context.Context, MerkleFork) error
+}
+
+func NotifyAuditProtocol(i NotifyAuditInterface) rpc.Protocol {
+	return rpc.Protocol{
+		Name: "keybase.1.NotifyAudit",
+		Methods: map[string]rpc.ServeHandlerDescription{
+			"merkleForkDetected": {
+				MakeArg: func() interface{} {
+					ret := make([]MerkleForkDetectedArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]MerkleForkDetectedArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]MerkleForkDetectedArg)(nil), args)
+						return
+					}
+					err = i.MerkleForkDetected(ctx, (*typedArgs)[0].Fork)
+					return
+				},
+				MethodType: rpc.MethodNotify,
+			},
+		},
+	}
+}
+
+type NotifyAuditClient struct {
+	Cli rpc.GenericClient
+}
+
+func (c NotifyAuditClient) MerkleForkDetected(ctx context.Context, fork MerkleFork) (err error) {
+	__arg := MerkleForkDetectedArg{Fork: fork}
+	err = c.Cli.Notify(ctx, "keybase.1.NotifyAudit.merkleForkDetected", []interface{}{__arg})
+	return
+}
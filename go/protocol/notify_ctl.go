@@ -13,6 +13,8 @@ type NotificationChannels struct {
 	Users    bool `codec:"users" json:"users"`
 	Kbfs     bool `codec:"kbfs" json:"kbfs"`
 	Tracking bool `codec:"tracking" json:"tracking"`
+	Audit    bool `codec:"audit" json:"audit"`
+	Keys     bool `codec:"keys" json:"keys"`
 }
 
 type SetNotificationsArg struct {
@@ -0,0 +1,59 @@
+// Auto-generated by avdl-compiler v1.1.1 (https://github.com/keybase/node-avdl-compiler)
+//   Input file: avdl/notify_keys.avdl
+
+package keybase1
+
+import (
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	context "golang.org/x/net/context"
+)
+
+type ExpiringKey struct {
+	Kid            KID    `codec:"kid" json:"kid"`
+	IsSibkey       bool   `codec:"isSibkey" json:"isSibkey"`
+	PGPFingerprint string `codec:"pgpFingerprint" json:"pgpFingerprint"`
+	DeviceName     string `codec:"deviceName" json:"deviceName"`
+	Etime          Time   `codec:"etime" json:"etime"`
+}
+
+type KeysExpiringArg struct {
+	Uid  UID           `codec:"uid" json:"uid"`
+	Keys []ExpiringKey `codec:"keys" json:"keys"`
+}
+
+type NotifyKeysInterface interface {
+	KeysExpiring(context.Context, KeysExpiringArg) error
+}
+
+func NotifyKeysProtocol(i NotifyKeysInterface) rpc.Protocol {
+	return rpc.Protocol{
+		Name: "keybase.1.NotifyKeys",
+		Methods: map[string]rpc.ServeHandlerDescription{
+			"keysExpiring": {
+				MakeArg: func() interface{} {
+					ret := make([]KeysExpiringArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]KeysExpiringArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]KeysExpiringArg)(nil), args)
+						return
+					}
+					err = i.KeysExpiring(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodNotify,
+			},
+		},
+	}
+}
+
+type NotifyKeysClient struct {
+	Cli rpc.GenericClient
+}
+
+func (c NotifyKeysClient) KeysExpiring(ctx context.Context, __arg KeysExpiringArg) (err error) {
+	err = c.Cli.Notify(ctx, "keybase.1.NotifyKeys.keysExpiring", []interface{}{__arg})
+	return
+}
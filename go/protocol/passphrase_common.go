@@ -43,6 +43,13 @@ type GetPassphraseRes struct {
 	StoreSecret bool   `codec:"storeSecret" json:"storeSecret"`
 }
 
+type PassphrasePolicy struct {
+	MinLength      int      `codec:"minLength" json:"minLength"`
+	MinEntropyBits int      `codec:"minEntropyBits" json:"minEntropyBits"`
+	BannedWords    []string `codec:"bannedWords" json:"bannedWords"`
+	AllowUserInfo  bool     `codec:"allowUserInfo" json:"allowUserInfo"`
+}
+
 type PassphraseCommonInterface interface {
 }
 
@@ -146,6 +146,13 @@ type PGPUpdateArg struct {
 	Fingerprints []string `codec:"fingerprints" json:"fingerprints"`
 }
 
+type PGPExtendArg struct {
+	SessionID    int      `codec:"sessionID" json:"sessionID"`
+	All          bool     `codec:"all" json:"all"`
+	Fingerprints []string `codec:"fingerprints" json:"fingerprints"`
+	Days         int      `codec:"days" json:"days"`
+}
+
 type PGPInterface interface {
 	PGPSign(context.Context, PGPSignArg) error
 	PGPPull(context.Context, PGPPullArg) error
@@ -160,6 +167,7 @@ type PGPInterface interface {
 	PGPDeletePrimary(context.Context, int) error
 	PGPSelect(context.Context, PGPSelectArg) error
 	PGPUpdate(context.Context, PGPUpdateArg) error
+	PGPExtend(context.Context, PGPExtendArg) error
 }
 
 func PGPProtocol(i PGPInterface) rpc.Protocol {
@@ -374,6 +382,22 @@ func PGPProtocol(i PGPInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"pgpExtend": {
+				MakeArg: func() interface{} {
+					ret := make([]PGPExtendArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]PGPExtendArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]PGPExtendArg)(nil), args)
+						return
+					}
+					err = i.PGPExtend(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -447,3 +471,8 @@ func (c PGPClient) PGPUpdate(ctx context.Context, __arg PGPUpdateArg) (err error
 	err = c.Cli.Call(ctx, "keybase.1.pgp.pgpUpdate", []interface{}{__arg}, nil)
 	return
 }
+
+func (c PGPClient) PGPExtend(ctx context.Context, __arg PGPExtendArg) (err error) {
+	err = c.Cli.Call(ctx, "keybase.1.pgp.pgpExtend", []interface{}{__arg}, nil)
+	return
+}
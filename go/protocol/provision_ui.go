@@ -76,6 +76,7 @@ type DisplayAndPromptSecretArg struct {
 	Secret          []byte     `codec:"secret" json:"secret"`
 	Phrase          string     `codec:"phrase" json:"phrase"`
 	OtherDeviceType DeviceType `codec:"otherDeviceType" json:"otherDeviceType"`
+	DirectCode      string     `codec:"directCode" json:"directCode"`
 }
 
 type DisplaySecretExchangedArg struct {
@@ -9,11 +9,14 @@ import (
 )
 
 type SaltpackEncryptOptions struct {
-	Recipients     []string `codec:"recipients" json:"recipients"`
-	HideSelf       bool     `codec:"hideSelf" json:"hideSelf"`
-	NoSelfEncrypt  bool     `codec:"noSelfEncrypt" json:"noSelfEncrypt"`
-	Binary         bool     `codec:"binary" json:"binary"`
-	HideRecipients bool     `codec:"hideRecipients" json:"hideRecipients"`
+	Recipients      []string `codec:"recipients" json:"recipients"`
+	HideSelf        bool     `codec:"hideSelf" json:"hideSelf"`
+	NoSelfEncrypt   bool     `codec:"noSelfEncrypt" json:"noSelfEncrypt"`
+	Binary          bool     `codec:"binary" json:"binary"`
+	HideRecipients  bool     `codec:"hideRecipients" json:"hideRecipients"`
+	Signcrypt       bool     `codec:"signcrypt" json:"signcrypt"`
+	RecipientsFile  string   `codec:"recipientsFile" json:"recipientsFile"`
+	RecipientGroups []string `codec:"recipientGroups" json:"recipientGroups"`
 }
 
 type SaltpackDecryptOptions struct {
@@ -31,10 +34,36 @@ type SaltpackVerifyOptions struct {
 	Signature []byte `codec:"signature" json:"signature"`
 }
 
+type SaltpackEncryptedRecipient struct {
+	Uid        UID         `codec:"uid" json:"uid"`
+	Username   string      `codec:"username" json:"username"`
+	DeviceKeys []PublicKey `codec:"deviceKeys" json:"deviceKeys"`
+}
+
+type SaltpackEncryptResult struct {
+	Recipients []SaltpackEncryptedRecipient `codec:"recipients" json:"recipients"`
+}
+
 type SaltpackEncryptedMessageInfo struct {
-	Devices          []Device `codec:"devices" json:"devices"`
+	Devices          []Device       `codec:"devices" json:"devices"`
+	NumAnonReceivers int            `codec:"numAnonReceivers" json:"numAnonReceivers"`
+	ReceiverIsAnon   bool           `codec:"receiverIsAnon" json:"receiverIsAnon"`
+	Sender           SaltpackSender `codec:"sender" json:"sender"`
+	SigningKID       KID            `codec:"signingKID" json:"signingKID"`
+}
+
+type SaltpackHeaderInfo struct {
+	Format           string   `codec:"format" json:"format"`
+	Mode             string   `codec:"mode" json:"mode"`
+	Armored          bool     `codec:"armored" json:"armored"`
+	Brand            string   `codec:"brand" json:"brand"`
+	VersionMajor     int      `codec:"versionMajor" json:"versionMajor"`
+	VersionMinor     int      `codec:"versionMinor" json:"versionMinor"`
+	NumReceivers     int      `codec:"numReceivers" json:"numReceivers"`
 	NumAnonReceivers int      `codec:"numAnonReceivers" json:"numAnonReceivers"`
-	ReceiverIsAnon   bool     `codec:"receiverIsAnon" json:"receiverIsAnon"`
+	ReceiverKIDs     []KID    `codec:"receiverKIDs" json:"receiverKIDs"`
+	SenderKID        KID      `codec:"senderKID" json:"senderKID"`
+	Devices          []Device `codec:"devices" json:"devices"`
 }
 
 type SaltpackEncryptArg struct {
@@ -65,11 +94,17 @@ type SaltpackVerifyArg struct {
 	Opts      SaltpackVerifyOptions `codec:"opts" json:"opts"`
 }
 
+type SaltpackInspectArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	Source    Stream `codec:"source" json:"source"`
+}
+
 type SaltpackInterface interface {
-	SaltpackEncrypt(context.Context, SaltpackEncryptArg) error
+	SaltpackEncrypt(context.Context, SaltpackEncryptArg) (SaltpackEncryptResult, error)
 	SaltpackDecrypt(context.Context, SaltpackDecryptArg) (SaltpackEncryptedMessageInfo, error)
 	SaltpackSign(context.Context, SaltpackSignArg) error
 	SaltpackVerify(context.Context, SaltpackVerifyArg) error
+	SaltpackInspect(context.Context, SaltpackInspectArg) (SaltpackHeaderInfo, error)
 }
 
 func SaltpackProtocol(i SaltpackInterface) rpc.Protocol {
@@ -87,7 +122,7 @@ func SaltpackProtocol(i SaltpackInterface) rpc.Protocol {
 						err = rpc.NewTypeError((*[]SaltpackEncryptArg)(nil), args)
 						return
 					}
-					err = i.SaltpackEncrypt(ctx, (*typedArgs)[0])
+					ret, err = i.SaltpackEncrypt(ctx, (*typedArgs)[0])
 					return
 				},
 				MethodType: rpc.MethodCall,
@@ -140,6 +175,22 @@ func SaltpackProtocol(i SaltpackInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"saltpackInspect": {
+				MakeArg: func() interface{} {
+					ret := make([]SaltpackInspectArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]SaltpackInspectArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]SaltpackInspectArg)(nil), args)
+						return
+					}
+					ret, err = i.SaltpackInspect(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 		},
 	}
 }
@@ -148,8 +199,8 @@ type SaltpackClient struct {
 	Cli rpc.GenericClient
 }
 
-func (c SaltpackClient) SaltpackEncrypt(ctx context.Context, __arg SaltpackEncryptArg) (err error) {
-	err = c.Cli.Call(ctx, "keybase.1.saltpack.saltpackEncrypt", []interface{}{__arg}, nil)
+func (c SaltpackClient) SaltpackEncrypt(ctx context.Context, __arg SaltpackEncryptArg) (res SaltpackEncryptResult, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.saltpack.saltpackEncrypt", []interface{}{__arg}, &res)
 	return
 }
 
@@ -167,3 +218,8 @@ func (c SaltpackClient) SaltpackVerify(ctx context.Context, __arg SaltpackVerify
 	err = c.Cli.Call(ctx, "keybase.1.saltpack.saltpackVerify", []interface{}{__arg}, nil)
 	return
 }
+
+func (c SaltpackClient) SaltpackInspect(ctx context.Context, __arg SaltpackInspectArg) (res SaltpackHeaderInfo, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.saltpack.saltpackInspect", []interface{}{__arg}, &res)
+	return
+}
@@ -24,13 +24,14 @@ const (
 )
 
 type Update struct {
-	Version      string     `codec:"version" json:"version"`
-	Name         string     `codec:"name" json:"name"`
-	Description  string     `codec:"description" json:"description"`
-	Instructions *string    `codec:"instructions,omitempty" json:"instructions,omitempty"`
-	Type         UpdateType `codec:"type" json:"type"`
-	PublishedAt  *Time      `codec:"publishedAt,omitempty" json:"publishedAt,omitempty"`
-	Asset        *Asset     `codec:"asset,omitempty" json:"asset,omitempty"`
+	Version        string     `codec:"version" json:"version"`
+	Name           string     `codec:"name" json:"name"`
+	Description    string     `codec:"description" json:"description"`
+	Instructions   *string    `codec:"instructions,omitempty" json:"instructions,omitempty"`
+	Type           UpdateType `codec:"type" json:"type"`
+	PublishedAt    *Time      `codec:"publishedAt,omitempty" json:"publishedAt,omitempty"`
+	Asset          *Asset     `codec:"asset,omitempty" json:"asset,omitempty"`
+	RolloutPercent *int       `codec:"rolloutPercent,omitempty" json:"rolloutPercent,omitempty"`
 }
 
 type UpdateCommonInterface interface {
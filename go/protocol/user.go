@@ -101,6 +101,11 @@ type ListTrackingJSONArg struct {
 	Verbose   bool   `codec:"verbose" json:"verbose"`
 }
 
+type ExportChainArg struct {
+	SessionID int    `codec:"sessionID" json:"sessionID"`
+	Username  string `codec:"username" json:"username"`
+}
+
 type SearchArg struct {
 	SessionID int    `codec:"sessionID" json:"sessionID"`
 	Query     string `codec:"query" json:"query"`
@@ -116,6 +121,7 @@ type UserInterface interface {
 	LoadPublicKeys(context.Context, LoadPublicKeysArg) ([]PublicKey, error)
 	ListTracking(context.Context, ListTrackingArg) ([]UserSummary, error)
 	ListTrackingJSON(context.Context, ListTrackingJSONArg) (string, error)
+	ExportChain(context.Context, ExportChainArg) (string, error)
 	Search(context.Context, SearchArg) ([]SearchResult, error)
 }
 
@@ -267,6 +273,22 @@ func UserProtocol(i UserInterface) rpc.Protocol {
 				},
 				MethodType: rpc.MethodCall,
 			},
+			"exportChain": {
+				MakeArg: func() interface{} {
+					ret := make([]ExportChainArg, 1)
+					return &ret
+				},
+				Handler: func(ctx context.Context, args interface{}) (ret interface{}, err error) {
+					typedArgs, ok := args.(*[]ExportChainArg)
+					if !ok {
+						err = rpc.NewTypeError((*[]ExportChainArg)(nil), args)
+						return
+					}
+					ret, err = i.ExportChain(ctx, (*typedArgs)[0])
+					return
+				},
+				MethodType: rpc.MethodCall,
+			},
 			"search": {
 				MakeArg: func() interface{} {
 					ret := make([]SearchArg, 1)
@@ -337,6 +359,11 @@ func (c UserClient) ListTrackingJSON(ctx context.Context, __arg ListTrackingJSON
 	return
 }
 
+func (c UserClient) ExportChain(ctx context.Context, __arg ExportChainArg) (res string, err error) {
+	err = c.Cli.Call(ctx, "keybase.1.user.exportChain", []interface{}{__arg}, &res)
+	return
+}
+
 func (c UserClient) Search(ctx context.Context, __arg SearchArg) (res []SearchResult, err error) {
 	err = c.Cli.Call(ctx, "keybase.1.user.search", []interface{}{__arg}, &res)
 	return
@@ -47,3 +47,29 @@ func (h *AccountHandler) PassphrasePrompt(_ context.Context, arg keybase1.Passph
 
 	return ui.GetPassphrase(arg.GuiArg, nil)
 }
+
+func (h *AccountHandler) AccountExport(_ context.Context, arg keybase1.AccountExportArg) ([]byte, error) {
+	eng := engine.NewAccountExport(h.G(), arg.Passphrase)
+	ctx := &engine.Context{
+		SecretUI:  h.getSecretUI(arg.SessionID, h.G()),
+		SessionID: arg.SessionID,
+	}
+	if err := engine.RunEngine(eng, ctx); err != nil {
+		return nil, err
+	}
+	return eng.Sealed(), nil
+}
+
+func (h *AccountHandler) AccountImport(_ context.Context, arg keybase1.AccountImportArg) error {
+	eng := engine.NewAccountImport(h.G(), arg.Archive, arg.Passphrase, arg.Force)
+	ctx := &engine.Context{
+		LoginUI:   h.getLoginUI(arg.SessionID),
+		SecretUI:  h.getSecretUI(arg.SessionID, h.G()),
+		SessionID: arg.SessionID,
+	}
+	return engine.RunEngine(eng, ctx)
+}
+
+func (h *AccountHandler) GetPassphrasePolicy(_ context.Context, sessionID int) (keybase1.PassphrasePolicy, error) {
+	return h.G().Env.GetPassphrasePolicy(), nil
+}
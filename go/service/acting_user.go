@@ -0,0 +1,71 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"sync"
+
+	"github.com/keybase/client/go/libkb"
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	"golang.org/x/net/context"
+)
+
+// actingUserProtocols sends each call on a connection to handlers for
+// the user the call's libkb.ActingUserRPCTag names, or to the current
+// user's handlers if the call doesn't name one. Another user's handlers
+// are built with that user's GlobalContext the first time a call acts
+// as them, so calls for different users don't wait on each other.
+type actingUserProtocols struct {
+	sync.Mutex
+	g        *libkb.GlobalContext
+	build    func(g *libkb.GlobalContext) []rpc.Protocol
+	handlers map[*libkb.GlobalContext]map[string]rpc.ServeHandlerDescription
+}
+
+func newActingUserProtocols(g *libkb.GlobalContext, build func(g *libkb.GlobalContext) []rpc.Protocol) *actingUserProtocols {
+	return &actingUserProtocols{
+		g:        g,
+		build:    build,
+		handlers: make(map[*libkb.GlobalContext]map[string]rpc.ServeHandlerDescription),
+	}
+}
+
+func (a *actingUserProtocols) handlersFor(ug *libkb.GlobalContext) map[string]rpc.ServeHandlerDescription {
+	a.Lock()
+	defer a.Unlock()
+	if h, ok := a.handlers[ug]; ok {
+		return h
+	}
+	h := make(map[string]rpc.ServeHandlerDescription)
+	for _, proto := range a.build(ug) {
+		for name, desc := range proto.Methods {
+			h[proto.Name+"."+name] = desc
+		}
+	}
+	a.handlers[ug] = h
+	return h
+}
+
+// wrap wraps every method in proto, which was built with the current
+// user's GlobalContext, so that it runs as the acting user.
+func (a *actingUserProtocols) wrap(proto rpc.Protocol) rpc.Protocol {
+	methods := make(map[string]rpc.ServeHandlerDescription, len(proto.Methods))
+	for name, desc := range proto.Methods {
+		handler := desc.Handler
+		method := proto.Name + "." + name
+		desc.Handler = func(ctx context.Context, arg interface{}) (interface{}, error) {
+			ug, err := a.g.ActingUserContext(libkb.ActingUserFromContext(ctx))
+			if err != nil {
+				return nil, err
+			}
+			if ug == a.g {
+				return handler(ctx, arg)
+			}
+			return a.handlersFor(ug)[method].Handler(ctx, arg)
+		}
+		methods[name] = desc
+	}
+	proto.Methods = methods
+	return proto
+}
@@ -38,14 +38,54 @@ func (h *DeviceHandler) DeviceList(_ context.Context, sessionID int) ([]keybase1
 	return eng.List(), nil
 }
 
+// DeviceDetailList returns a list of all the devices for a user, with
+// where they came from and when they were last used.
+func (h *DeviceHandler) DeviceDetailList(_ context.Context, sessionID int) ([]keybase1.DeviceDetail, error) {
+	ctx := &engine.Context{
+		LogUI:     h.getLogUI(sessionID),
+		SessionID: sessionID,
+	}
+	eng := engine.NewDeviceDetailList(h.G())
+	if err := engine.RunEngine(eng, ctx); err != nil {
+		return nil, err
+	}
+	return eng.Details(), nil
+}
+
+// SetDeviceNote sets the local note for one of the user's devices.
+func (h *DeviceHandler) SetDeviceNote(_ context.Context, arg keybase1.SetDeviceNoteArg) error {
+	me, err := libkb.LoadMe(libkb.NewLoadUserArg(h.G()))
+	if err != nil {
+		return err
+	}
+	if _, err := me.GetDevice(arg.DeviceID); err != nil {
+		return err
+	}
+	return libkb.SetDeviceNote(h.G(), arg.DeviceID, arg.Note)
+}
+
 // DeviceAdd starts the kex2 device provisioning on the
 // provisioner (device X/C1)
-func (h *DeviceHandler) DeviceAdd(_ context.Context, sessionID int) error {
+func (h *DeviceHandler) DeviceAdd(_ context.Context, arg keybase1.DeviceAddArg) error {
 	ctx := &engine.Context{
-		ProvisionUI: h.getProvisionUI(sessionID),
-		SecretUI:    h.getSecretUI(sessionID, h.G()),
-		SessionID:   sessionID,
+		ProvisionUI: h.getProvisionUI(arg.SessionID),
+		SecretUI:    h.getSecretUI(arg.SessionID, h.G()),
+		SessionID:   arg.SessionID,
 	}
 	eng := engine.NewDeviceAdd(h.G())
+	if len(arg.DirectCode) > 0 {
+		eng = engine.NewDeviceAddDirect(h.G(), arg.DirectCode)
+	}
+	return engine.RunEngine(eng, ctx)
+}
+
+// RotateDeviceKeys replaces the current device's keys with new ones.
+func (h *DeviceHandler) RotateDeviceKeys(_ context.Context, arg keybase1.RotateDeviceKeysArg) error {
+	ctx := &engine.Context{
+		LogUI:     h.getLogUI(arg.SessionID),
+		SecretUI:  h.getSecretUI(arg.SessionID, h.G()),
+		SessionID: arg.SessionID,
+	}
+	eng := engine.NewDeviceRotateKeys(h.G(), arg.Pkcs11Token)
 	return engine.RunEngine(eng, ctx)
 }
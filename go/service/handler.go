@@ -120,6 +120,10 @@ func (h *BaseHandler) getSaltpackUI(sessionID int) libkb.SaltpackUI {
 	return NewRemoteSaltpackUI(sessionID, h.rpcClient())
 }
 
+func (h *BaseHandler) getIdentifyBatchUI(sessionID int) libkb.IdentifyBatchUI {
+	return NewRemoteIdentifyBatchUI(sessionID, h.rpcClient())
+}
+
 func (h *BaseHandler) NewRemoteIdentifyUI(sessionID int, g *libkb.GlobalContext) *RemoteIdentifyUI {
 	c := h.rpcClient()
 	return &RemoteIdentifyUI{
@@ -63,6 +63,40 @@ func (h *IdentifyHandler) Identify2(_ context.Context, arg keybase1.Identify2Arg
 	return res, err
 }
 
+func (h *IdentifyHandler) Identify2Batch(_ context.Context, arg keybase1.Identify2BatchArg) error {
+	ctx := engine.Context{
+		LogUI:           h.getLogUI(arg.SessionID),
+		IdentifyBatchUI: h.getIdentifyBatchUI(arg.SessionID),
+		SessionID:       arg.SessionID,
+	}
+	eng := engine.NewIdentify2Batch(h.G(), &arg)
+	return engine.RunEngine(eng, &ctx)
+}
+
+func (h *IdentifyHandler) IdentifyReport(_ context.Context, arg keybase1.IdentifyReportArg) (string, error) {
+	ctx := engine.Context{
+		LogUI:      h.getLogUI(arg.SessionID),
+		IdentifyUI: h.NewRemoteIdentifyUI(arg.SessionID, h.G()),
+		SecretUI:   h.getSecretUI(arg.SessionID, h.G()),
+		SessionID:  arg.SessionID,
+	}
+	eng := engine.NewIdentifyReportEngine(&arg, h.G())
+	if err := engine.RunEngine(eng, &ctx); err != nil {
+		return "", err
+	}
+	return string(eng.Signed()), nil
+}
+
+func (h *IdentifyHandler) VerifyIdentifyReport(_ context.Context, arg keybase1.VerifyIdentifyReportArg) (keybase1.IdentifyReport, error) {
+	ctx := engine.Context{
+		LogUI:     h.getLogUI(arg.SessionID),
+		SessionID: arg.SessionID,
+	}
+	eng := engine.NewIdentifyReportVerify([]byte(arg.Report), h.G())
+	err := engine.RunEngine(eng, &ctx)
+	return eng.Report(), err
+}
+
 func (h *IdentifyHandler) Resolve(_ context.Context, arg string) (keybase1.UID, error) {
 	rres := h.G().Resolver.ResolveFullExpression(arg)
 	return rres.GetUID(), rres.GetError()
@@ -181,6 +215,23 @@ func (h *IdentifyHandler) identify(sessionID int, arg keybase1.IdentifyArg) (res
 	return res, err
 }
 
+type RemoteIdentifyBatchUI struct {
+	sessionID int
+	cli       keybase1.IdentifyBatchUiClient
+}
+
+func NewRemoteIdentifyBatchUI(sessionID int, c *rpc.Client) *RemoteIdentifyBatchUI {
+	return &RemoteIdentifyBatchUI{
+		sessionID: sessionID,
+		cli:       keybase1.IdentifyBatchUiClient{Cli: c},
+	}
+}
+
+func (u *RemoteIdentifyBatchUI) Identify2BatchResult(ctx context.Context, arg keybase1.Identify2BatchResultArg) error {
+	arg.SessionID = u.sessionID
+	return u.cli.Identify2BatchResult(ctx, arg)
+}
+
 func (u *RemoteIdentifyUI) FinishWebProofCheck(p keybase1.RemoteProof, lcr keybase1.LinkCheckResult) {
 	u.uicli.FinishWebProofCheck(context.TODO(), keybase1.FinishWebProofCheckArg{
 		SessionID: u.sessionID,
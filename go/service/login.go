@@ -64,14 +64,17 @@ func (h *LoginHandler) ClearStoredSecret(_ context.Context, arg keybase1.ClearSt
 	return libkb.ClearStoredSecret(h.G(), libkb.NewNormalizedUsername(arg.Username))
 }
 
-func (h *LoginHandler) PaperKey(_ context.Context, sessionID int) error {
+func (h *LoginHandler) PaperKey(_ context.Context, arg keybase1.PaperKeyArg) error {
 	ctx := &engine.Context{
-		LogUI:     h.getLogUI(sessionID),
-		LoginUI:   h.getLoginUI(sessionID),
-		SecretUI:  h.getSecretUI(sessionID, h.G()),
-		SessionID: sessionID,
+		LogUI:     h.getLogUI(arg.SessionID),
+		LoginUI:   h.getLoginUI(arg.SessionID),
+		SecretUI:  h.getSecretUI(arg.SessionID, h.G()),
+		SessionID: arg.SessionID,
 	}
 	eng := engine.NewPaperKey(h.G())
+	if arg.ShareCount > 0 {
+		eng = engine.NewPaperKeyShares(h.G(), arg.ShareThreshold, arg.ShareCount)
+	}
 	return engine.RunEngine(eng, ctx)
 }
 
@@ -106,5 +109,6 @@ func (h *LoginHandler) Login(ctx context.Context, arg keybase1.LoginArg) error {
 		SessionID:   arg.SessionID,
 	}
 	eng := engine.NewLogin(h.G(), arg.DeviceType, arg.UsernameOrEmail, arg.ClientType)
+	eng.SetPKCS11Token(arg.Pkcs11Token)
 	return engine.RunEngine(eng, ectx)
 }
@@ -47,7 +47,23 @@ func (d *Service) GetStartChannel() <-chan struct{} {
 }
 
 func (d *Service) RegisterProtocols(srv *rpc.Server, xp rpc.Transporter, connID libkb.ConnectionID, logReg *logRegister, g *libkb.GlobalContext) error {
-	protocols := []rpc.Protocol{
+	acting := newActingUserProtocols(g, func(ug *libkb.GlobalContext) []rpc.Protocol {
+		return d.protocols(xp, connID, logReg, ug)
+	})
+	for _, proto := range d.protocols(xp, connID, logReg, g) {
+		proto = acting.wrap(proto)
+		if g.Env.GetMetricsAddr() != "" {
+			proto = withMetrics(g, proto)
+		}
+		if err := srv.Register(proto); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Service) protocols(xp rpc.Transporter, connID libkb.ConnectionID, logReg *logRegister, g *libkb.GlobalContext) []rpc.Protocol {
+	return []rpc.Protocol{
 		keybase1.AccountProtocol(NewAccountHandler(xp, g)),
 		keybase1.BTCProtocol(NewBTCHandler(xp, g)),
 		keybase1.ConfigProtocol(NewConfigHandler(xp, connID, g, d)),
@@ -61,6 +77,7 @@ func (d *Service) RegisterProtocols(srv *rpc.Server, xp rpc.Transporter, connID
 		keybase1.KbfsProtocol(NewKBFSHandler(xp, g)),
 		keybase1.LogProtocol(NewLogHandler(xp, logReg, g)),
 		keybase1.LoginProtocol(NewLoginHandler(xp, g)),
+		keybase1.MerkleProtocol(NewMerkleHandler(xp, g)),
 		keybase1.NotifyCtlProtocol(NewNotifyCtlHandler(xp, connID, g)),
 		keybase1.PGPProtocol(NewPGPHandler(xp, g)),
 		keybase1.RevokeProtocol(NewRevokeHandler(xp, g)),
@@ -75,12 +92,6 @@ func (d *Service) RegisterProtocols(srv *rpc.Server, xp rpc.Transporter, connID
 		keybase1.UpdateProtocol(NewUpdateHandler(xp, g, d.updateChecker)),
 		keybase1.UserProtocol(NewUserHandler(xp, g)),
 	}
-	for _, proto := range protocols {
-		if err := srv.Register(proto); err != nil {
-			return err
-		}
-	}
-	return nil
 }
 
 func (d *Service) Handle(c net.Conn) {
@@ -179,6 +190,11 @@ func (d *Service) Run() (err error) {
 	}
 
 	d.checkTrackingEveryHour()
+	d.checkKeyExpiryEveryDay()
+
+	if err := d.startMetricsServer(); err != nil {
+		d.G().Log.Warning("Can't serve metrics: %s", err)
+	}
 
 	d.G().ExitCode, err = d.ListenLoopWithStopper(l)
 
@@ -232,6 +248,22 @@ func (d *Service) checkTrackingEveryHour() {
 	}()
 }
 
+// checkKeyExpiryEveryDay looks for the current user's keys that will
+// expire soon, once at startup and then once a day, and sends a
+// notification about them.
+func (d *Service) checkKeyExpiryEveryDay() {
+	ticker := time.NewTicker(libkb.KeyExpiryCheckInterval)
+	go func() {
+		for {
+			d.G().Log.Debug("Checking for expiring keys.")
+			if _, err := libkb.CheckKeyExpiry(d.G()); err != nil {
+				d.G().Log.Debug("Error checking for expiring keys: %s", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
 // ReleaseLock releases the locking pidfile by closing, unlocking and
 // deleting it.
 func (d *Service) ReleaseLock() error {
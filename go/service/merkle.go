@@ -0,0 +1,30 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	"golang.org/x/net/context"
+)
+
+// MerkleHandler is the RPC handler for the merkle interface.
+type MerkleHandler struct {
+	*BaseHandler
+	libkb.Contextified
+}
+
+// NewMerkleHandler creates a MerkleHandler for the xp transport.
+func NewMerkleHandler(xp rpc.Transporter, g *libkb.GlobalContext) *MerkleHandler {
+	return &MerkleHandler{
+		BaseHandler:  NewBaseHandler(xp),
+		Contextified: libkb.NewContextified(g),
+	}
+}
+
+// MerkleAudit checks the history of merkle roots that the service has seen.
+func (h *MerkleHandler) MerkleAudit(_ context.Context, sessionID int) (keybase1.MerkleAuditResult, error) {
+	return h.G().MerkleClient.Audit()
+}
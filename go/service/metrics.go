@@ -0,0 +1,69 @@
+// Copyright 2016 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/keybase/client/go/libkb"
+	rpc "github.com/keybase/go-framed-msgpack-rpc"
+	"golang.org/x/net/context"
+)
+
+// withMetrics wraps every method in proto so that its calls are counted
+// and timed in g.Metrics.
+func withMetrics(g *libkb.GlobalContext, proto rpc.Protocol) rpc.Protocol {
+	methods := make(map[string]rpc.ServeHandlerDescription, len(proto.Methods))
+	for name, desc := range proto.Methods {
+		handler := desc.Handler
+		method := proto.Name + "." + name
+		desc.Handler = func(ctx context.Context, arg interface{}) (interface{}, error) {
+			start := time.Now()
+			res, err := handler(ctx, arg)
+			g.Metrics.RecordRPC(method, time.Since(start), err)
+			return res, err
+		}
+		methods[name] = desc
+	}
+	proto.Methods = methods
+	return proto
+}
+
+// startMetricsServer serves metrics in the Prometheus text format at
+// /metrics on the metrics address from the config, if there is one. It
+// only listens on loopback addresses.
+func (d *Service) startMetricsServer() error {
+	addr := d.G().Env.GetMetricsAddr()
+	if addr == "" {
+		return nil
+	}
+	if !libkb.IsLocalMetricsAddr(addr) {
+		return fmt.Errorf("Metrics address %q isn't a loopback address", addr)
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	d.G().PushShutdownHook(func() error {
+		return l.Close()
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := d.G().Metrics.WriteTo(w, d.G()); err != nil {
+			d.G().Log.Debug("Error writing metrics: %s", err)
+		}
+	})
+	d.G().Log.Info("Serving metrics at http://%s/metrics", l.Addr())
+	go func() {
+		if err := http.Serve(l, mux); err != nil {
+			d.G().Log.Debug("Metrics server stopped: %s", err)
+		}
+	}()
+	return nil
+}
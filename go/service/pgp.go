@@ -240,3 +240,13 @@ func (h *PGPHandler) PGPUpdate(_ context.Context, arg keybase1.PGPUpdateArg) err
 	eng := engine.NewPGPUpdateEngine(arg.Fingerprints, arg.All, h.G())
 	return engine.RunEngine(eng, &ctx)
 }
+
+func (h *PGPHandler) PGPExtend(_ context.Context, arg keybase1.PGPExtendArg) error {
+	ctx := engine.Context{
+		LogUI:     h.getLogUI(arg.SessionID),
+		SecretUI:  h.getSecretUI(arg.SessionID, h.G()),
+		SessionID: arg.SessionID,
+	}
+	eng := engine.NewPGPExtendEngine(arg.Fingerprints, arg.All, arg.Days, h.G())
+	return engine.RunEngine(eng, &ctx)
+}
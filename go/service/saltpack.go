@@ -67,7 +67,7 @@ func (h *SaltpackHandler) SaltpackDecrypt(_ context.Context, arg keybase1.Saltpa
 	return info, err
 }
 
-func (h *SaltpackHandler) SaltpackEncrypt(_ context.Context, arg keybase1.SaltpackEncryptArg) error {
+func (h *SaltpackHandler) SaltpackEncrypt(_ context.Context, arg keybase1.SaltpackEncryptArg) (keybase1.SaltpackEncryptResult, error) {
 	cli := h.getStreamUICli()
 	src := libkb.NewRemoteStreamBuffered(arg.Source, cli, arg.SessionID)
 	snk := libkb.NewRemoteStreamBuffered(arg.Sink, cli, arg.SessionID)
@@ -83,7 +83,8 @@ func (h *SaltpackHandler) SaltpackEncrypt(_ context.Context, arg keybase1.Saltpa
 		SessionID:  arg.SessionID,
 	}
 	eng := engine.NewSaltpackEncrypt(earg, h.G())
-	return engine.RunEngine(eng, ctx)
+	err := engine.RunEngine(eng, ctx)
+	return eng.Result(), err
 }
 
 func (h *SaltpackHandler) SaltpackSign(_ context.Context, arg keybase1.SaltpackSignArg) error {
@@ -124,3 +125,15 @@ func (h *SaltpackHandler) SaltpackVerify(_ context.Context, arg keybase1.Saltpac
 	eng := engine.NewSaltpackVerify(earg, h.G())
 	return engine.RunEngine(eng, ctx)
 }
+
+func (h *SaltpackHandler) SaltpackInspect(_ context.Context, arg keybase1.SaltpackInspectArg) (keybase1.SaltpackHeaderInfo, error) {
+	cli := h.getStreamUICli()
+	src := libkb.NewRemoteStreamBuffered(arg.Source, cli, arg.SessionID)
+
+	ctx := &engine.Context{
+		SessionID: arg.SessionID,
+	}
+	eng := engine.NewSaltpackInspect(src, h.G())
+	err := engine.RunEngine(eng, ctx)
+	return eng.Result(), err
+}
@@ -129,3 +129,17 @@ func (h *UserHandler) LoadPublicKeys(_ context.Context, arg keybase1.LoadPublicK
 	}
 	return publicKeys, nil
 }
+
+// ExportChain builds a self-contained, verified bundle of a user's public
+// identity, suitable for offline checking with `keybase verify-chain`.
+func (h *UserHandler) ExportChain(_ context.Context, arg keybase1.ExportChainArg) (string, error) {
+	eng := engine.NewChainExport(h.G(), arg.Username)
+	if err := engine.RunEngine(eng, &engine.Context{}); err != nil {
+		return "", err
+	}
+	b, err := eng.Bundle().Marshal()
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,75 @@
+package unzip
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UntarGz unpacks the gzipped tarball at src into dest. Only directories
+// and regular files are unpacked, and entries that would land outside of
+// dest are an error.
+func UntarGz(src, dest string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	extractAndWriteFile := func(hdr *tar.Header, r io.Reader) error {
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("Invalid path in archive: %s", hdr.Name)
+		}
+		filePath := filepath.Join(dest, name)
+		fileInfo := hdr.FileInfo()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			return os.MkdirAll(filePath, fileInfo.Mode().Perm())
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+				return err
+			}
+			fileCopy, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, fileInfo.Mode().Perm())
+			if err != nil {
+				return err
+			}
+			defer fileCopy.Close()
+
+			if _, err := io.Copy(fileCopy, r); err != nil {
+				return err
+			}
+			return fileCopy.Close()
+		}
+		return fmt.Errorf("Unsupported file type in archive: %s", hdr.Name)
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := extractAndWriteFile(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/logger"
+)
+
+// rollbackRecord lists the files that the last update swapped into a
+// destination, so that it can be rolled back.
+type rollbackRecord struct {
+	PreviousVersion string         `json:"previousVersion"`
+	Files           []rollbackFile `json:"files"`
+}
+
+// rollbackFile is a file that an update swapped in. Added is set if there
+// was no file at Path before, in which case rolling back removes it;
+// otherwise the file it replaced is kept at its rollback path.
+type rollbackFile struct {
+	Path  string `json:"path"`
+	Added bool   `json:"added"`
+}
+
+// rollbackPath is where the file at path is kept when an update replaces
+// it, so that the update can be rolled back.
+func rollbackPath(path string) string {
+	return path + ".rollback"
+}
+
+// pendingRollbackPath is where the file at path is kept while an update
+// is being applied. It's moved to rollbackPath once the update has been
+// swapped in, so a failed update leaves the last one's rollback copies be.
+func pendingRollbackPath(path string) string {
+	return path + ".rollback.new"
+}
+
+func rollbackRecordPath(destinationPath string) string {
+	return filepath.Join(destinationPath, ".keybase-update.rollback")
+}
+
+// writeRollbackRecord writes r to a temporary file and renames it over
+// the record in destinationPath, so the previous record stays whole until
+// the new one replaces it.
+func writeRollbackRecord(destinationPath string, r rollbackRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	path := rollbackRecordPath(destinationPath)
+	tmp := path + ".new"
+	if err := ioutil.WriteFile(tmp, data, libkb.PermFile); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func readRollbackRecord(destinationPath string) (r rollbackRecord, err error) {
+	data, err := ioutil.ReadFile(rollbackRecordPath(destinationPath))
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
+
+// restoreFiles puts back the files that an update replaced from their
+// copies at keptPath, and removes the files that it added.
+func restoreFiles(files []rollbackFile, keptPath func(string) string, log logger.Logger) error {
+	var lastErr error
+	for _, f := range files {
+		var err error
+		if f.Added {
+			log.Info("Removing %s", f.Path)
+			err = os.Remove(f.Path)
+		} else {
+			log.Info("Restoring %s", f.Path)
+			err = os.Rename(keptPath(f.Path), f.Path)
+		}
+		if err != nil {
+			log.Warning("Error restoring %s: %s", f.Path, err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// keepRollbackFiles makes the pending rollback copies of files the
+// rollback copies, in place of the ones for the last update, which it
+// removes along with that update's record. The new record is written
+// after this, so a record never lists files whose copies are missing
+// or from another update. (If the last record can't be read, its copies
+// are left where they are.)
+func keepRollbackFiles(destinationPath string, files []rollbackFile) error {
+	last, err := readRollbackRecord(destinationPath)
+	if err != nil {
+		last = rollbackRecord{}
+	}
+	if err := os.Remove(rollbackRecordPath(destinationPath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, f := range last.Files {
+		if err := os.Remove(rollbackPath(f.Path)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	for _, f := range files {
+		if f.Added {
+			continue
+		}
+		if err := os.Rename(pendingRollbackPath(f.Path), rollbackPath(f.Path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback puts back the files that the last update to destinationPath
+// replaced, and returns the version that it rolled back to. It doesn't
+// restart anything.
+func Rollback(destinationPath string, log logger.Logger) (previousVersion string, err error) {
+	r, err := readRollbackRecord(destinationPath)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("No update to roll back in %s", destinationPath)
+	}
+	if err != nil {
+		return "", err
+	}
+	log.Info("Rolling back update in %s to %s", destinationPath, r.PreviousVersion)
+	if err := restoreFiles(r.Files, rollbackPath, log); err != nil {
+		return "", err
+	}
+	if err := os.Remove(rollbackRecordPath(destinationPath)); err != nil {
+		return "", err
+	}
+	return r.PreviousVersion, nil
+}
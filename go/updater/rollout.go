@@ -0,0 +1,54 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package updater
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/keybase/client/go/libkb"
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// installID gets the random ID for this install, making one the first time
+// it's asked for.
+func (u *Updater) installID() (string, error) {
+	if id := u.config.GetUpdateInstallID(); id != "" {
+		return id, nil
+	}
+	b, err := libkb.RandBytes(16)
+	if err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(b)
+	u.log.Debug("New install ID: %s", id)
+	if err := u.config.SetUpdateInstallID(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// rolloutBucket puts an install in one of 100 buckets for an update
+// version. The version is hashed in so that it's not always the same
+// installs that get updates first.
+func rolloutBucket(installID string, version string) int {
+	h := sha256.Sum256([]byte(installID + ":" + version))
+	return int(binary.BigEndian.Uint32(h[:4]) % 100)
+}
+
+// inRollout says whether this install is in the percent of installs that
+// the update is offered to so far.
+func (u *Updater) inRollout(update keybase1.Update) (bool, error) {
+	if update.RolloutPercent == nil {
+		return true, nil
+	}
+	id, err := u.installID()
+	if err != nil {
+		return false, err
+	}
+	bucket := rolloutBucket(id, update.Version)
+	u.log.Debug("Rollout bucket for %s: %d (rolled out to %d%%)", update.Version, bucket, *update.RolloutPercent)
+	return bucket < *update.RolloutPercent, nil
+}
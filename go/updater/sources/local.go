@@ -10,6 +10,8 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
+	"strings"
 
 	"github.com/keybase/client/go/logger"
 	keybase1 "github.com/keybase/client/go/protocol"
@@ -58,6 +60,18 @@ func readFile(path string) (string, error) {
 	return string(data), nil
 }
 
+// assetExt is the extension of the asset at URL, which is what the updater
+// uses to decide how to unpack it.
+func assetExt(URL string) string {
+	if strings.HasSuffix(URL, ".tar.gz") {
+		return ".tar.gz"
+	}
+	if ext := path.Ext(URL); ext != "" {
+		return ext
+	}
+	return ".zip"
+}
+
 func (k LocalUpdateSource) FindUpdate(options keybase1.UpdateOptions) (update *keybase1.Update, err error) {
 	digest, err := digest(options.URL)
 	if err != nil {
@@ -74,7 +88,7 @@ func (k LocalUpdateSource) FindUpdate(options keybase1.UpdateOptions) (update *k
 		Version: options.Version,
 		Name:    fmt.Sprintf("v%s", options.Version),
 		Asset: &keybase1.Asset{
-			Name:      fmt.Sprintf("Keybase-%s.zip", options.Version),
+			Name:      fmt.Sprintf("Keybase-%s%s", options.Version, assetExt(options.URL)),
 			Url:       options.URL,
 			Digest:    digest,
 			Signature: signature,
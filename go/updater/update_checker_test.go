@@ -55,6 +55,10 @@ func (u testUpdateCheckUI) AfterUpdateApply(willRestart bool) error {
 	return nil
 }
 
+func (u testUpdateCheckUI) RestartService() error {
+	return nil
+}
+
 func (u testUpdateCheckUI) Verify(r io.Reader, signature string) error {
 	return nil
 }
@@ -38,6 +38,9 @@ type Context interface {
 	GetUpdateUI() (libkb.UpdateUI, error)
 	AfterUpdateApply(willRestart bool) error
 	Verify(r io.Reader, signature string) error
+	// RestartService restarts the service, on platforms where the updater
+	// replaces the service binaries itself.
+	RestartService() error
 }
 
 type Config interface {
@@ -49,6 +52,8 @@ type Config interface {
 	SetUpdatePreferenceSkip(v string) error
 	SetUpdatePreferenceSnoozeUntil(t keybase1.Time) error
 	SetUpdateLastChecked(t keybase1.Time) error
+	GetUpdateInstallID() string
+	SetUpdateInstallID(id string) error
 	GetRunModeAsString() string
 	GetMountDir() string
 }
@@ -149,6 +154,19 @@ func (u *Updater) checkForUpdate(skipAssetDownload bool, force bool, requested b
 		}
 	}
 
+	if update.RolloutPercent != nil && !force {
+		inRollout, rerr := u.inRollout(*update)
+		if rerr != nil {
+			err = rerr
+			return
+		}
+		if !inRollout {
+			u.log.Info("Not in the rollout of %s to %d%% of installs yet", update.Version, *update.RolloutPercent)
+			update = nil
+			return
+		}
+	}
+
 	if !skipAssetDownload && update.Asset != nil {
 		downloadPath, _, dlerr := u.downloadAsset(*update.Asset)
 		if dlerr != nil {
@@ -309,9 +327,13 @@ func (u *Updater) save(savePath string, resp http.Response) error {
 	return err
 }
 
+func isTarGz(filename string) bool {
+	return strings.HasSuffix(filename, ".tar.gz") || strings.HasSuffix(filename, ".tgz")
+}
+
 func (u *Updater) unpack(filename string) (string, error) {
 	u.log.Debug("Unpack %s", filename)
-	if !strings.HasSuffix(filename, ".zip") {
+	if !strings.HasSuffix(filename, ".zip") && !isTarGz(filename) {
 		u.log.Debug("File isn't compressed, so won't unzip: %q", filename)
 		return filename, nil
 	}
@@ -325,8 +347,14 @@ func (u *Updater) unpack(filename string) (string, error) {
 		}
 	}
 
-	u.log.Info("Unzipping %q -> %q", filename, unzipDestination)
-	err := zip.Unzip(filename, unzipDestination)
+	var err error
+	if isTarGz(filename) {
+		u.log.Info("Untarring %q -> %q", filename, unzipDestination)
+		err = zip.UntarGz(filename, unzipDestination)
+	} else {
+		u.log.Info("Unzipping %q -> %q", filename, unzipDestination)
+		err = zip.Unzip(filename, unzipDestination)
+	}
 	if err != nil {
 		u.log.Errorf("Don't know how to unpack: %s", filename)
 		return "", err
@@ -480,7 +508,7 @@ func (u *Updater) update(ctx Context, force bool, requested bool) (update *keyba
 		return
 	}
 
-	_, err = u.restart(ctx, updateQuitResponse)
+	err = u.restartAfterUpdate(ctx, updateQuitResponse)
 
 	if update.Asset != nil {
 		u.cleanup([]string{unzipDestination(update.Asset.LocalPath), update.Asset.LocalPath})
@@ -495,6 +523,13 @@ func (u *Updater) apply(ctx Context, update keybase1.Update) (err error) {
 		return
 	}
 
+	// The download was checked, but a cached download might have changed
+	// since.
+	err = u.checkDigest(update.Asset.Digest, update.Asset.LocalPath)
+	if err != nil {
+		return
+	}
+
 	err = u.applyUpdate(update.Asset.LocalPath)
 	return
 }
@@ -5,9 +5,37 @@
 
 package updater
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
 
+	keybase1 "github.com/keybase/client/go/protocol"
+)
+
+// checkPlatformSpecificUpdate makes sure the unpacked update is only
+// directories and regular files, and has something in it to install.
 func (u *Updater) checkPlatformSpecificUpdate(sourcePath string, destinationPath string) error {
+	found := false
+	walk := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.Mode().IsRegular():
+			found = true
+		case info.IsDir():
+		default:
+			return fmt.Errorf("Unsupported file in update: %s", path)
+		}
+		return nil
+	}
+	if err := filepath.Walk(sourcePath, walk); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("No files in update: %s", sourcePath)
+	}
 	return nil
 }
 
@@ -15,6 +43,155 @@ func openApplication(applicationPath string) error {
 	return fmt.Errorf("Open application not supported on this platform")
 }
 
+// restartAfterUpdate restarts the service on the new binaries. There's no
+// app for the UI to quit here, so the service is restarted whatever the
+// UI said.
+func (u *Updater) restartAfterUpdate(ctx Context, updateQuitResponse keybase1.UpdateQuitRes) error {
+	u.log.Info("Restarting service")
+	return ctx.RestartService()
+}
+
+// applyUpdate unpacks the update (a .tar.gz or .zip) and swaps the files in
+// it into the destination directory.
 func (u *Updater) applyUpdate(localPath string) error {
-	return nil
+	destinationPath := u.options.DestinationPath
+	if destinationPath == "" {
+		return fmt.Errorf("No destination path for update")
+	}
+
+	sourcePath, err := u.unpack(localPath)
+	if err != nil {
+		return err
+	}
+	if sourcePath == localPath {
+		return fmt.Errorf("Unsupported update file type: %s", localPath)
+	}
+	u.log.Info("Unpack path: %s", sourcePath)
+
+	if err := u.checkUpdate(sourcePath, destinationPath); err != nil {
+		return err
+	}
+	return u.swapFiles(sourcePath, destinationPath)
+}
+
+// swapFiles moves each file under sourcePath into place at the same path
+// under destinationPath. If any file can't be swapped in, the ones that
+// were are put back, and the last update's rollback record and copies are
+// left as they were. Otherwise they're recorded in their place, so that
+// Rollback can put them back later.
+func (u *Updater) swapFiles(sourcePath string, destinationPath string) (err error) {
+	var swapped []rollbackFile
+	defer func() {
+		if err != nil {
+			u.log.Warning("Error applying update, rolling back: %s", err)
+			if rerr := restoreFiles(swapped, keptCopyPath, u.log); rerr != nil {
+				u.log.Errorf("Error rolling back update: %s", rerr)
+			}
+		}
+	}()
+
+	walk := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(sourcePath, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(destinationPath, rel)
+		added, err := u.swapFile(path, dest, info.Mode())
+		if err != nil {
+			return err
+		}
+		swapped = append(swapped, rollbackFile{Path: dest, Added: added})
+		return nil
+	}
+	if err := filepath.Walk(sourcePath, walk); err != nil {
+		return err
+	}
+	if err := keepRollbackFiles(destinationPath, swapped); err != nil {
+		return err
+	}
+	return writeRollbackRecord(destinationPath, rollbackRecord{
+		PreviousVersion: u.options.Version,
+		Files:           swapped,
+	})
+}
+
+// swapFile replaces dest with a copy of src, and returns whether there
+// was no file at dest before. The copy is written next to dest and
+// renamed over it, so dest is never partly written, and the file it
+// replaces is kept at its pending rollback path.
+func (u *Updater) swapFile(src string, dest string, mode os.FileMode) (added bool, err error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return false, err
+	}
+
+	tmp := dest + ".new"
+	if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	if err := os.Chmod(tmp, mode.Perm()); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+
+	kept := pendingRollbackPath(dest)
+	if err := os.Remove(kept); err != nil && !os.IsNotExist(err) {
+		os.Remove(tmp)
+		return false, err
+	}
+	if _, err := os.Lstat(dest); os.IsNotExist(err) {
+		added = true
+	} else if err != nil {
+		os.Remove(tmp)
+		return false, err
+	} else {
+		u.log.Info("Keeping %s for rollback", dest)
+		if err := keepFile(dest, kept); err != nil {
+			os.Remove(kept)
+			os.Remove(tmp)
+			return false, err
+		}
+	}
+
+	u.log.Info("Moving (update) %s to %s", tmp, dest)
+	if err := os.Rename(tmp, dest); err != nil {
+		// dest wasn't replaced, so it isn't restored from its kept copy,
+		// and the copy would only be left behind.
+		os.Remove(kept)
+		os.Remove(tmp)
+		return false, err
+	}
+	return added, nil
+}
+
+// keptCopyPath is where the file that an update being applied replaced at
+// path is kept: its pending rollback path, unless keepRollbackFiles has
+// already moved it to its rollback path.
+func keptCopyPath(path string) string {
+	pending := pendingRollbackPath(path)
+	if _, err := os.Lstat(pending); err == nil {
+		return pending
+	}
+	return rollbackPath(path)
+}
+
+// keepFile links (or failing that, copies) the file at path to keepPath.
+func keepFile(path string, keepPath string) error {
+	if err := os.Link(path, keepPath); err == nil {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := copyFile(path, keepPath); err != nil {
+		return err
+	}
+	return os.Chmod(keepPath, info.Mode().Perm())
 }
@@ -6,13 +6,30 @@
 package updater
 
 import (
-	"io/ioutil"
+	"archive/zip"
+	"os"
 	"path/filepath"
 )
 
 func createTestUpdateFile(path string, version string) (name string, err error) {
 	name = filepath.Base(path)
-	data := randString(256)
-	err = ioutil.WriteFile(path, []byte(data), 0644)
+	zipFile, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer zipFile.Close()
+
+	w := zip.NewWriter(zipFile)
+	hdr := &zip.FileHeader{Name: "keybase"}
+	hdr.SetMode(0755)
+	f, err := w.CreateHeader(hdr)
+	if err != nil {
+		return
+	}
+	_, err = f.Write([]byte(randString(256)))
+	if err != nil {
+		return
+	}
+	err = w.Close()
 	return
 }
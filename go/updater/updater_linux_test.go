@@ -0,0 +1,259 @@
+// Copyright 2015 Keybase, Inc. All rights reserved. Use of
+// this source code is governed by the included BSD license.
+
+package updater
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keybase/client/go/libkb"
+	"github.com/keybase/client/go/logger"
+	keybase1 "github.com/keybase/client/go/protocol"
+	"github.com/keybase/client/go/updater/sources"
+)
+
+type testRestartUI struct {
+	testUpdateUI
+	restarted bool
+}
+
+func (u *testRestartUI) RestartService() error {
+	u.restarted = true
+	return nil
+}
+
+func writeTestTarGz(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		hdr := &tar.Header{Name: name, Mode: 0755, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newLocalTestUpdater makes an updater that installs a tarball of files
+// from a LocalUpdateSource into a bin directory with an old keybase in it.
+func newLocalTestUpdater(t *testing.T, files map[string]string) (u *Updater, bin string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "updater")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	bin = filepath.Join(dir, "bin")
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(bin, "keybase"), []byte("old keybase"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "keybase.tar.gz")
+	writeTestTarGz(t, path, files)
+	digest, err := libkb.DigestForFileAtPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// testUpdateUI verifies the digest as the signature.
+	sigPath := filepath.Join(dir, "keybase.tar.gz.sig")
+	if err := ioutil.WriteFile(sigPath, []byte(digest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	options := NewDefaultTestUpdateConfig()
+	options.Source = string(sources.LocalSource)
+	options.DestinationPath = bin
+	options.URL = fmt.Sprintf("file://%s", path)
+	options.SignaturePath = sigPath
+	options.Force = true
+
+	log := logger.NewTestLogger(t)
+	u = NewUpdater(options, sources.NewLocalUpdateSource(log), &testConfig{}, log)
+	return u, bin, cleanup
+}
+
+func readTestFile(t *testing.T, path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestUpdaterApplyTarGz(t *testing.T) {
+	u, bin, cleanup := newLocalTestUpdater(t, map[string]string{
+		"keybase":  "new keybase",
+		"kbfsfuse": "new kbfsfuse",
+	})
+	defer cleanup()
+
+	ui := &testRestartUI{}
+	update, err := u.Update(ui, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update == nil || update.Asset == nil {
+		t.Fatal("No update")
+	}
+
+	if s := readTestFile(t, filepath.Join(bin, "keybase")); s != "new keybase" {
+		t.Errorf("keybase wasn't updated: %q", s)
+	}
+	if s := readTestFile(t, filepath.Join(bin, "kbfsfuse")); s != "new kbfsfuse" {
+		t.Errorf("kbfsfuse wasn't added: %q", s)
+	}
+	if s := readTestFile(t, rollbackPath(filepath.Join(bin, "keybase"))); s != "old keybase" {
+		t.Errorf("rollback copy of keybase is %q", s)
+	}
+	if _, err := os.Stat(pendingRollbackPath(filepath.Join(bin, "keybase"))); !os.IsNotExist(err) {
+		t.Errorf("pending rollback copy of keybase left behind: %v", err)
+	}
+	if _, err := os.Stat(rollbackPath(filepath.Join(bin, "kbfsfuse"))); !os.IsNotExist(err) {
+		t.Errorf("rollback copy of a new file: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(bin, "keybase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("keybase mode is %s", info.Mode())
+	}
+	if !ui.restarted {
+		t.Error("Service wasn't restarted")
+	}
+
+	previousVersion, err := Rollback(bin, logger.NewTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previousVersion != "1.0.0" {
+		t.Errorf("Rolled back to %q", previousVersion)
+	}
+	if s := readTestFile(t, filepath.Join(bin, "keybase")); s != "old keybase" {
+		t.Errorf("keybase wasn't rolled back: %q", s)
+	}
+	if _, err := os.Stat(filepath.Join(bin, "kbfsfuse")); !os.IsNotExist(err) {
+		t.Errorf("kbfsfuse wasn't removed: %v", err)
+	}
+	if _, err := Rollback(bin, logger.NewTestLogger(t)); err == nil {
+		t.Error("Rolled back twice")
+	}
+}
+
+func TestUpdaterApplyRollsBack(t *testing.T) {
+	// The update can't make a directory where there's a file, so it fails
+	// after swapping in keybase.
+	u, bin, cleanup := newLocalTestUpdater(t, map[string]string{
+		"keybase":          "new keybase",
+		"share/keybase.md": "new docs",
+	})
+	defer cleanup()
+	if err := ioutil.WriteFile(filepath.Join(bin, "share"), []byte("in the way"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The last update, from 0.9.0, replaced keybase and added kbnm.
+	keybase := filepath.Join(bin, "keybase")
+	kbnm := filepath.Join(bin, "kbnm")
+	if err := ioutil.WriteFile(rollbackPath(keybase), []byte("older keybase"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(kbnm, []byte("kbnm"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	previous := rollbackRecord{PreviousVersion: "0.9.0", Files: []rollbackFile{
+		{Path: keybase},
+		{Path: kbnm, Added: true},
+	}}
+	if err := writeRollbackRecord(bin, previous); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := &testRestartUI{}
+	if _, err := u.Update(ui, false, false); err == nil {
+		t.Fatal("Update should have failed")
+	}
+
+	if s := readTestFile(t, keybase); s != "old keybase" {
+		t.Errorf("keybase wasn't rolled back: %q", s)
+	}
+	if _, err := os.Stat(pendingRollbackPath(keybase)); !os.IsNotExist(err) {
+		t.Errorf("pending rollback copy of keybase left behind: %v", err)
+	}
+	if r, err := readRollbackRecord(bin); err != nil {
+		t.Errorf("The last update's rollback record is gone: %s", err)
+	} else if r.PreviousVersion != previous.PreviousVersion {
+		t.Errorf("The last update's rollback record was replaced: %+v", r)
+	}
+	if ui.restarted {
+		t.Error("Service was restarted after a failed update")
+	}
+
+	// The last update can still be rolled back.
+	previousVersion, err := Rollback(bin, logger.NewTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previousVersion != "0.9.0" {
+		t.Errorf("Rolled back to %q", previousVersion)
+	}
+	if s := readTestFile(t, keybase); s != "older keybase" {
+		t.Errorf("keybase wasn't rolled back to 0.9.0: %q", s)
+	}
+	if _, err := os.Stat(kbnm); !os.IsNotExist(err) {
+		t.Errorf("kbnm wasn't removed: %v", err)
+	}
+}
+
+func TestUpdaterApplyBadSignature(t *testing.T) {
+	u, bin, cleanup := newLocalTestUpdater(t, map[string]string{
+		"keybase": "new keybase",
+	})
+	defer cleanup()
+	if err := ioutil.WriteFile(u.options.SignaturePath, []byte("bad signature"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := u.Update(&testRestartUI{}, false, false); err == nil {
+		t.Fatal("Update should have failed")
+	}
+	if s := readTestFile(t, filepath.Join(bin, "keybase")); s != "old keybase" {
+		t.Errorf("keybase was updated: %q", s)
+	}
+}
+
+func TestLocalUpdateSourceAssetName(t *testing.T) {
+	source := sources.NewLocalUpdateSource(logger.NewTestLogger(t))
+	path := filepath.Join(os.TempDir(), "Test.zip")
+	if _, err := createTestUpdateFile(path, "1.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	update, err := source.FindUpdate(keybase1.UpdateOptions{Version: "1.0.1", URL: "file://" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.Asset.Name != "Keybase-1.0.1.zip" {
+		t.Errorf("Asset name %q", update.Asset.Name)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"time"
+
+	keybase1 "github.com/keybase/client/go/protocol"
 )
 
 func (u *Updater) checkPlatformSpecificUpdate(sourcePath string, destinationPath string) error {
@@ -68,6 +70,11 @@ func (u *Updater) checkPlatformSpecificUpdate(sourcePath string, destinationPath
 	return nil
 }
 
+func (u *Updater) restartAfterUpdate(ctx Context, updateQuitResponse keybase1.UpdateQuitRes) error {
+	_, err := u.restart(ctx, updateQuitResponse)
+	return err
+}
+
 func openApplication(applicationPath string) error {
 	_, err := exec.Command("/usr/bin/open", applicationPath).Output()
 	return err
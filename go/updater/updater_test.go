@@ -47,6 +47,10 @@ func (u testUpdateUI) AfterUpdateApply(willRestart bool) error {
 	return nil
 }
 
+func (u testUpdateUI) RestartService() error {
+	return nil
+}
+
 func (u testUpdateUI) UpdateAppInUse(context.Context, keybase1.UpdateAppInUseArg) (keybase1.UpdateAppInUseRes, error) {
 	return keybase1.UpdateAppInUseRes{Action: keybase1.UpdateAppInUseAction_CANCEL}, nil
 }
@@ -112,6 +116,7 @@ func (u testUpdateSource) FindUpdate(config keybase1.UpdateOptions) (*keybase1.U
 type testConfig struct {
 	lastChecked  keybase1.Time
 	publicKeyHex string
+	installID    string
 }
 
 func (c testConfig) GetUpdatePreferenceAuto() (bool, bool) {
@@ -147,6 +152,15 @@ func (c *testConfig) SetUpdateLastChecked(t keybase1.Time) error {
 	return nil
 }
 
+func (c testConfig) GetUpdateInstallID() string {
+	return c.installID
+}
+
+func (c *testConfig) SetUpdateInstallID(id string) error {
+	c.installID = id
+	return nil
+}
+
 func (c testConfig) GetRunModeAsString() string {
 	return "test"
 }
@@ -226,6 +240,74 @@ func TestChangeUpdateFailSignature(t *testing.T) {
 	}
 }
 
+func TestUpdateCheckRollout(t *testing.T) {
+	percent := 0
+	rollout := func(u *keybase1.Update, path string) {
+		u.RolloutPercent = &percent
+	}
+	u, err := NewTestUpdater(t, NewDefaultTestUpdateConfig(), rollout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update, err := u.checkForUpdate(true, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update != nil {
+		t.Fatal("Shouldn't have update rolled out to no one")
+	}
+	id := u.config.GetUpdateInstallID()
+	if id == "" {
+		t.Fatal("No install ID")
+	}
+
+	update, err = u.checkForUpdate(true, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update == nil {
+		t.Fatal("Forced update should skip the rollout")
+	}
+
+	percent = 100
+	update, err = u.checkForUpdate(true, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update == nil {
+		t.Fatal("Should have update rolled out to everyone")
+	}
+	if u.config.GetUpdateInstallID() != id {
+		t.Fatal("Install ID changed")
+	}
+
+	// Installs are spread over the buckets, and not in the same order for
+	// every version.
+	buckets := make(map[int]bool)
+	differ := false
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("install%d", i)
+		b := rolloutBucket(id, "1.0.1")
+		if b < 0 || b >= 100 {
+			t.Fatalf("Bucket %d out of range", b)
+		}
+		if b != rolloutBucket(id, "1.0.1") {
+			t.Fatal("Bucket isn't stable")
+		}
+		if b != rolloutBucket(id, "1.0.2") {
+			differ = true
+		}
+		buckets[b] = true
+	}
+	if len(buckets) < 50 {
+		t.Errorf("Only %d buckets used by 100 installs", len(buckets))
+	}
+	if !differ {
+		t.Error("Buckets are the same for every version")
+	}
+}
+
 func randString(n int) string {
 	const alphanum = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 	var bytes = make([]byte, n)
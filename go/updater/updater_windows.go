@@ -9,12 +9,19 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	keybase1 "github.com/keybase/client/go/protocol"
 )
 
 func (u *Updater) checkPlatformSpecificUpdate(sourcePath string, destinationPath string) error {
 	return nil
 }
 
+func (u *Updater) restartAfterUpdate(ctx Context, updateQuitResponse keybase1.UpdateQuitRes) error {
+	_, err := u.restart(ctx, updateQuitResponse)
+	return err
+}
+
 func openApplication(applicationPath string) error {
 	return fmt.Errorf("Open application not supported on this platform")
 }
@@ -145,7 +145,8 @@ export const constants = {
     'scoldversionerror': 1801,
     'scinvalidlocationerror': 1802,
     'scservicestatuserror': 1803,
-    'scinstallerror': 1804
+    'scinstallerror': 1804,
+    'scweakpassphrase': 1900
   }
 }
 
@@ -381,6 +382,7 @@ export const identify = {
     'hackernews': 6,
     'genericWebSite': 1000,
     'dns': 1001,
+    'genericSocial': 1002,
     'rooter': 100001
   },
   'TrackDiffType': {
@@ -412,6 +414,35 @@ export const identify = {
     'decrypt': 4,
     'verify': 5,
     'resource': 6
+  },
+  'CheckResultFreshness': {
+    'fresh': 0,
+    'aged': 1,
+    'rancid': 2
+  }
+}
+
+export const identifyBatchUi = {
+  'LogLevel': {
+    'none': 0,
+    'debug': 1,
+    'info': 2,
+    'notice': 3,
+    'warn': 4,
+    'error': 5,
+    'critical': 6,
+    'fatal': 7
+  },
+  'ClientType': {
+    'none': 0,
+    'cli': 1,
+    'gui': 2,
+    'kbfs': 3
+  },
+  'MerkleTreeID': {
+    'master': 0,
+    'kbfsPublic': 1,
+    'kbfsPrivate': 2
   }
 }
 
@@ -496,6 +527,7 @@ export const identifyUi = {
     'hackernews': 6,
     'genericWebSite': 1000,
     'dns': 1001,
+    'genericSocial': 1002,
     'rooter': 100001
   },
   'TrackDiffType': {
@@ -720,6 +752,34 @@ export const loginUi = {
   }
 }
 
+export const merkle = {
+  'LogLevel': {
+    'none': 0,
+    'debug': 1,
+    'info': 2,
+    'notice': 3,
+    'warn': 4,
+    'error': 5,
+    'critical': 6,
+    'fatal': 7
+  },
+  'ClientType': {
+    'none': 0,
+    'cli': 1,
+    'gui': 2,
+    'kbfs': 3
+  },
+  'MerkleTreeID': {
+    'master': 0,
+    'kbfsPublic': 1,
+    'kbfsPrivate': 2
+  },
+  'MerkleForkType': {
+    'rollback': 0,
+    'equivocation': 1
+  }
+}
+
 export const metadata = {
   'LogLevel': {
     'none': 0,
@@ -768,6 +828,34 @@ export const metadataUpdate = {
   }
 }
 
+export const NotifyAudit = {
+  'LogLevel': {
+    'none': 0,
+    'debug': 1,
+    'info': 2,
+    'notice': 3,
+    'warn': 4,
+    'error': 5,
+    'critical': 6,
+    'fatal': 7
+  },
+  'ClientType': {
+    'none': 0,
+    'cli': 1,
+    'gui': 2,
+    'kbfs': 3
+  },
+  'MerkleTreeID': {
+    'master': 0,
+    'kbfsPublic': 1,
+    'kbfsPrivate': 2
+  },
+  'MerkleForkType': {
+    'rollback': 0,
+    'equivocation': 1
+  }
+}
+
 export const notifyCtl = {
   'LogLevel': {
     'none': 0,
@@ -818,6 +906,30 @@ export const NotifyFS = {
   }
 }
 
+export const NotifyKeys = {
+  'LogLevel': {
+    'none': 0,
+    'debug': 1,
+    'info': 2,
+    'notice': 3,
+    'warn': 4,
+    'error': 5,
+    'critical': 6,
+    'fatal': 7
+  },
+  'ClientType': {
+    'none': 0,
+    'cli': 1,
+    'gui': 2,
+    'kbfs': 3
+  },
+  'MerkleTreeID': {
+    'master': 0,
+    'kbfsPublic': 1,
+    'kbfsPrivate': 2
+  }
+}
+
 export const NotifySession = {}
 
 export const NotifyTracking = {
@@ -949,6 +1061,7 @@ export const pgp = {
     'hackernews': 6,
     'genericWebSite': 1000,
     'dns': 1001,
+    'genericSocial': 1002,
     'rooter': 100001
   },
   'TrackDiffType': {
@@ -1093,6 +1206,7 @@ export const prove = {
     'hackernews': 6,
     'genericWebSite': 1000,
     'dns': 1001,
+    'genericSocial': 1002,
     'rooter': 100001
   },
   'TrackDiffType': {
@@ -1328,6 +1442,7 @@ export const saltpack = {
     'hackernews': 6,
     'genericWebSite': 1000,
     'dns': 1001,
+    'genericSocial': 1002,
     'rooter': 100001
   },
   'TrackDiffType': {
@@ -1359,6 +1474,14 @@ export const saltpack = {
     'decrypt': 4,
     'verify': 5,
     'resource': 6
+  },
+  'SaltpackSenderType': {
+    'notTracked': 0,
+    'unknown': 1,
+    'anonymous': 2,
+    'trackingBroke': 3,
+    'trackingOk': 4,
+    'self': 5
   }
 }
 
@@ -1606,6 +1729,7 @@ export const track = {
     'hackernews': 6,
     'genericWebSite': 1000,
     'dns': 1001,
+    'genericSocial': 1002,
     'rooter': 100001
   },
   'TrackDiffType': {
@@ -1782,6 +1906,7 @@ export default {
   favorite,
   gpgUi,
   identify,
+  identifyBatchUi,
   identifyUi,
   install,
   kbfs,
@@ -1791,10 +1916,13 @@ export default {
   logUi,
   login,
   loginUi,
+  merkle,
   metadata,
   metadataUpdate,
+  NotifyAudit,
   notifyCtl,
   NotifyFS,
+  NotifyKeys,
   NotifySession,
   NotifyTracking,
   NotifyUsers,
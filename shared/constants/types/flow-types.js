@@ -155,6 +155,16 @@ export type Device = {
   status: int;
 }
 
+export type DeviceDetail = {
+  device: Device;
+  addedTime: Time;
+  addedSeqno: int;
+  provisioner: DeviceID;
+  provisionerName: string;
+  lastSeenSigningTime: Time;
+  note: string;
+}
+
 export type DeviceID = string
 
 export type DeviceType =
@@ -182,6 +192,14 @@ export type ExitCode =
   | 2 // NOTOK_2
   | 4 // RESTART_4
 
+export type ExpiringKey = {
+  kid: KID;
+  isSibkey: boolean;
+  pgpFingerprint: string;
+  deviceName: string;
+  etime: Time;
+}
+
 export type ExtendedStatus = {
   standalone: boolean;
   passphraseStreamCached: boolean;
@@ -327,6 +345,14 @@ export type GetPassphraseRes = {
 
 export type HelloRes = string
 
+export type Identify2BatchResult = {
+  assertion: string;
+  uid: UID;
+  username: string;
+  upk?: ?UserPlusKeys;
+  status?: ?Status;
+}
+
 export type Identify2Res = {
   upk: UserPlusKeys;
 }
@@ -370,6 +396,26 @@ export type IdentifyReasonType =
   | 5 // VERIFY_5
   | 6 // RESOURCE_6
 
+export type IdentifyReport = {
+  version: int;
+  cTime: Time;
+  assertion: string;
+  user?: ?User;
+  publicKeys: Array<PublicKey>;
+  outcome: IdentifyOutcome;
+  proofChecks: Array<IdentifyReportProofCheck>;
+  merkleSeqno: int;
+  signerUID: UID;
+  signerUsername: string;
+  signerDeviceID: DeviceID;
+  signingKID: KID;
+}
+
+export type IdentifyReportProofCheck = {
+  proof: RemoteProof;
+  result: LinkCheckResult;
+}
+
 export type IdentifyRes = {
   user?: ?User;
   publicKeys: Array<PublicKey>;
@@ -494,11 +540,38 @@ export type MDBlock = {
   block: bytes;
 }
 
+export type MerkleAuditResult = {
+  numRoots: int;
+  oldest?: ?MerkleRootSummary;
+  latest?: ?MerkleRootSummary;
+  problems: Array<string>;
+  forks: Array<MerkleFork>;
+}
+
+export type MerkleFork = {
+  type: MerkleForkType;
+  prev: MerkleRootSummary;
+  root: MerkleRootSummary;
+  prevJSON: string;
+  rootJSON: string;
+  seenAt: Time;
+}
+
+export type MerkleForkType =
+    0 // ROLLBACK_0
+  | 1 // EQUIVOCATION_1
+
 export type MerkleRoot = {
   version: int;
   root: bytes;
 }
 
+export type MerkleRootSummary = {
+  seqno: int;
+  hash: string;
+  ctime: Time;
+}
+
 export type MerkleTreeID =
     0 // MASTER_0
   | 1 // KBFS_PUBLIC_1
@@ -522,6 +595,19 @@ export type NotificationChannels = {
   users: boolean;
   kbfs: boolean;
   tracking: boolean;
+  audit: boolean;
+  keys: boolean;
+}
+
+export type NotifyAudit_merkleForkDetected_result = void
+
+export type NotifyAudit_merkleForkDetected_rpc = {
+  method: 'NotifyAudit.merkleForkDetected',
+  param: {
+    fork: MerkleFork
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any) => void)
 }
 
 export type NotifyFS_FSActivity_result = void
@@ -535,6 +621,18 @@ export type NotifyFS_FSActivity_rpc = {
   callback: (null | (err: ?any) => void)
 }
 
+export type NotifyKeys_keysExpiring_result = void
+
+export type NotifyKeys_keysExpiring_rpc = {
+  method: 'NotifyKeys.keysExpiring',
+  param: {
+    uid: UID,
+    keys: Array<ExpiringKey>
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any) => void)
+}
+
 export type NotifySession_loggedIn_result = void
 
 export type NotifySession_loggedIn_rpc = {
@@ -627,6 +725,13 @@ export type PGPVerifyOptions = {
   signature: bytes;
 }
 
+export type PassphrasePolicy = {
+  minLength: int;
+  minEntropyBits: int;
+  bannedWords: Array<string>;
+  allowUserInfo: boolean;
+}
+
 export type PassphraseStream = {
   passphraseStream: bytes;
   generation: int;
@@ -724,6 +829,7 @@ export type ProofType =
   | 6 // HACKERNEWS_6
   | 1000 // GENERIC_WEB_SITE_1000
   | 1001 // DNS_1001
+  | 1002 // GENERIC_SOCIAL_1002
   | 100001 // ROOTER_100001
 
 export type Proofs = {
@@ -778,12 +884,41 @@ export type SaltpackEncryptOptions = {
   noSelfEncrypt: boolean;
   binary: boolean;
   hideRecipients: boolean;
+  signcrypt: boolean;
+  recipientsFile: string;
+  recipientGroups: Array<string>;
+}
+
+export type SaltpackEncryptResult = {
+  recipients: Array<SaltpackEncryptedRecipient>;
 }
 
 export type SaltpackEncryptedMessageInfo = {
   devices: Array<Device>;
   numAnonReceivers: int;
   receiverIsAnon: boolean;
+  sender: SaltpackSender;
+  signingKID: KID;
+}
+
+export type SaltpackEncryptedRecipient = {
+  uid: UID;
+  username: string;
+  deviceKeys: Array<PublicKey>;
+}
+
+export type SaltpackHeaderInfo = {
+  format: string;
+  mode: string;
+  armored: boolean;
+  brand: string;
+  versionMajor: int;
+  versionMinor: int;
+  numReceivers: int;
+  numAnonReceivers: int;
+  receiverKIDs: Array<KID>;
+  senderKID: KID;
+  devices: Array<Device>;
 }
 
 export type SaltpackSender = {
@@ -1017,6 +1152,7 @@ export type StatusCode =
   | 1802 // SCInvalidLocationError_1802
   | 1803 // SCServiceStatusError_1803
   | 1804 // SCInstallError_1804
+  | 1900 // SCWeakPassphrase_1900
 
 export type Stream = {
   fd: int;
@@ -1112,6 +1248,7 @@ export type Update = {
   type: UpdateType;
   publishedAt?: ?Time;
   asset?: ?Asset;
+  rolloutPercent?: ?int;
 }
 
 export type UpdateAction =
@@ -1226,6 +1363,39 @@ export type WebProof = {
   protocols: Array<string>;
 }
 
+export type account_accountExport_result = bytes
+
+export type account_accountExport_rpc = {
+  method: 'account.accountExport',
+  param: {
+    passphrase: string
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any, response: account_accountExport_result) => void)
+}
+
+export type account_accountImport_result = void
+
+export type account_accountImport_rpc = {
+  method: 'account.accountImport',
+  param: {
+    archive: bytes,
+    passphrase: string,
+    force: boolean
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any) => void)
+}
+
+export type account_getPassphrasePolicy_result = PassphrasePolicy
+
+export type account_getPassphrasePolicy_rpc = {
+  method: 'account.getPassphrasePolicy',
+  param: {},
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any, response: account_getPassphrasePolicy_result) => void)
+}
+
 export type account_passphraseChange_result = void
 
 export type account_passphraseChange_rpc = {
@@ -1614,11 +1784,22 @@ export type device_deviceAdd_result = void
 
 export type device_deviceAdd_rpc = {
   method: 'device.deviceAdd',
-  param: {},
+  param: {
+    directCode: string
+  },
   incomingCallMap: ?incomingCallMapType,
   callback: (null | (err: ?any) => void)
 }
 
+export type device_deviceDetailList_result = Array<DeviceDetail>
+
+export type device_deviceDetailList_rpc = {
+  method: 'device.deviceDetailList',
+  param: {},
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any, response: device_deviceDetailList_result) => void)
+}
+
 export type device_deviceList_result = Array<Device>
 
 export type device_deviceList_rpc = {
@@ -1628,6 +1809,29 @@ export type device_deviceList_rpc = {
   callback: (null | (err: ?any, response: device_deviceList_result) => void)
 }
 
+export type device_rotateDeviceKeys_result = void
+
+export type device_rotateDeviceKeys_rpc = {
+  method: 'device.rotateDeviceKeys',
+  param: {
+    pkcs11Token: string
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any) => void)
+}
+
+export type device_setDeviceNote_result = void
+
+export type device_setDeviceNote_rpc = {
+  method: 'device.setDeviceNote',
+  param: {
+    deviceID: DeviceID,
+    note: string
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any) => void)
+}
+
 export type favorite_favoriteAdd_result = void
 
 export type favorite_favoriteAdd_rpc = {
@@ -1711,6 +1915,17 @@ export type gpgUi_wantToAddGPGKey_rpc = {
   callback: (null | (err: ?any, response: gpgUi_wantToAddGPGKey_result) => void)
 }
 
+export type identifyBatchUi_identify2BatchResult_result = void
+
+export type identifyBatchUi_identify2BatchResult_rpc = {
+  method: 'identifyBatchUi.identify2BatchResult',
+  param: {
+    result: Identify2BatchResult
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any) => void)
+}
+
 export type identifyUi_confirm_result = ConfirmResult
 
 export type identifyUi_confirm_rpc = {
@@ -1876,6 +2091,21 @@ export type identify_Resolve_rpc = {
   callback: (null | (err: ?any, response: identify_Resolve_result) => void)
 }
 
+export type identify_identify2Batch_result = void
+
+export type identify_identify2Batch_rpc = {
+  method: 'identify.identify2Batch',
+  param: {
+    assertions: Array<string>,
+    reason: IdentifyReason,
+    parallelism: int,
+    forceRemoteCheck: boolean,
+    needProofSet: boolean
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any) => void)
+}
+
 export type identify_identify2_result = Identify2Res
 
 export type identify_identify2_rpc = {
@@ -1894,6 +2124,18 @@ export type identify_identify2_rpc = {
   callback: (null | (err: ?any, response: identify_identify2_result) => void)
 }
 
+export type identify_identifyReport_result = string
+
+export type identify_identifyReport_rpc = {
+  method: 'identify.identifyReport',
+  param: {
+    userAssertion: string,
+    forceRemoteCheck: boolean
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any, response: identify_identifyReport_result) => void)
+}
+
 export type identify_identify_result = IdentifyRes
 
 export type identify_identify_rpc = {
@@ -1910,6 +2152,17 @@ export type identify_identify_rpc = {
   callback: (null | (err: ?any, response: identify_identify_result) => void)
 }
 
+export type identify_verifyIdentifyReport_result = IdentifyReport
+
+export type identify_verifyIdentifyReport_rpc = {
+  method: 'identify.verifyIdentifyReport',
+  param: {
+    report: string
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any, response: identify_verifyIdentifyReport_result) => void)
+}
+
 export type kbfs_FSEvent_result = void
 
 export type kbfs_FSEvent_rpc = {
@@ -1950,7 +2203,9 @@ export type loginUi_displayPaperKeyPhrase_result = void
 export type loginUi_displayPaperKeyPhrase_rpc = {
   method: 'loginUi.displayPaperKeyPhrase',
   param: {
-    phrase: string
+    phrase: string,
+    shares: Array<string>,
+    shareThreshold: int
   },
   incomingCallMap: ?incomingCallMapType,
   callback: (null | (err: ?any) => void)
@@ -2027,7 +2282,8 @@ export type login_login_rpc = {
   param: {
     deviceType: string,
     usernameOrEmail: string,
-    clientType: ClientType
+    clientType: ClientType,
+    pkcs11Token: string
   },
   incomingCallMap: ?incomingCallMapType,
   callback: (null | (err: ?any) => void)
@@ -2046,7 +2302,10 @@ export type login_paperKey_result = void
 
 export type login_paperKey_rpc = {
   method: 'login.paperKey',
-  param: {},
+  param: {
+    shareThreshold: int,
+    shareCount: int
+  },
   incomingCallMap: ?incomingCallMapType,
   callback: (null | (err: ?any) => void)
 }
@@ -2082,6 +2341,15 @@ export type login_unlock_rpc = {
   callback: (null | (err: ?any) => void)
 }
 
+export type merkle_merkleAudit_result = MerkleAuditResult
+
+export type merkle_merkleAudit_rpc = {
+  method: 'merkle.merkleAudit',
+  param: {},
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any, response: merkle_merkleAudit_result) => void)
+}
+
 export type metadataUpdate_folderNeedsRekey_result = void
 
 export type metadataUpdate_folderNeedsRekey_rpc = {
@@ -2413,6 +2681,19 @@ export type pgp_pgpExport_rpc = {
   callback: (null | (err: ?any, response: pgp_pgpExport_result) => void)
 }
 
+export type pgp_pgpExtend_result = void
+
+export type pgp_pgpExtend_rpc = {
+  method: 'pgp.pgpExtend',
+  param: {
+    all: boolean,
+    fingerprints: Array<string>,
+    days: int
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any) => void)
+}
+
 export type pgp_pgpImport_result = void
 
 export type pgp_pgpImport_rpc = {
@@ -2616,7 +2897,8 @@ export type provisionUi_DisplayAndPromptSecret_rpc = {
   param: {
     secret: bytes,
     phrase: string,
-    otherDeviceType: DeviceType
+    otherDeviceType: DeviceType,
+    directCode: string
   },
   incomingCallMap: ?incomingCallMapType,
   callback: (null | (err: ?any, response: provisionUi_DisplayAndPromptSecret_result) => void)
@@ -2804,7 +3086,7 @@ export type saltpack_saltpackDecrypt_rpc = {
   callback: (null | (err: ?any, response: saltpack_saltpackDecrypt_result) => void)
 }
 
-export type saltpack_saltpackEncrypt_result = void
+export type saltpack_saltpackEncrypt_result = SaltpackEncryptResult
 
 export type saltpack_saltpackEncrypt_rpc = {
   method: 'saltpack.saltpackEncrypt',
@@ -2814,7 +3096,18 @@ export type saltpack_saltpackEncrypt_rpc = {
     opts: SaltpackEncryptOptions
   },
   incomingCallMap: ?incomingCallMapType,
-  callback: (null | (err: ?any) => void)
+  callback: (null | (err: ?any, response: saltpack_saltpackEncrypt_result) => void)
+}
+
+export type saltpack_saltpackInspect_result = SaltpackHeaderInfo
+
+export type saltpack_saltpackInspect_rpc = {
+  method: 'saltpack.saltpackInspect',
+  param: {
+    source: Stream
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any, response: saltpack_saltpackInspect_result) => void)
 }
 
 export type saltpack_saltpackSign_result = void
@@ -3132,6 +3425,17 @@ export type update_update_rpc = {
   callback: (null | (err: ?any, response: update_update_result) => void)
 }
 
+export type user_exportChain_result = string
+
+export type user_exportChain_rpc = {
+  method: 'user.exportChain',
+  param: {
+    username: string
+  },
+  incomingCallMap: ?incomingCallMapType,
+  callback: (null | (err: ?any, response: user_exportChain_result) => void)
+}
+
 export type user_listTrackersByName_result = Array<Tracker>
 
 export type user_listTrackersByName_rpc = {
@@ -3246,12 +3550,17 @@ export type rpc =
   | Kex2Provisionee_didCounterSign_rpc
   | Kex2Provisionee_hello_rpc
   | Kex2Provisioner_kexStart_rpc
+  | NotifyAudit_merkleForkDetected_rpc
   | NotifyFS_FSActivity_rpc
+  | NotifyKeys_keysExpiring_rpc
   | NotifySession_loggedIn_rpc
   | NotifySession_loggedOut_rpc
   | NotifyTracking_trackingChanged_rpc
   | NotifyUsers_userChanged_rpc
   | SecretKeys_getSecretKeys_rpc
+  | account_accountExport_rpc
+  | account_accountImport_rpc
+  | account_getPassphrasePolicy_rpc
   | account_passphraseChange_rpc
   | account_passphrasePrompt_rpc
   | block_addReference_rpc
@@ -3288,7 +3597,10 @@ export type rpc =
   | delegateUiCtl_registerSecretUI_rpc
   | delegateUiCtl_registerUpdateUI_rpc
   | device_deviceAdd_rpc
+  | device_deviceDetailList_rpc
   | device_deviceList_rpc
+  | device_rotateDeviceKeys_rpc
+  | device_setDeviceNote_rpc
   | favorite_favoriteAdd_rpc
   | favorite_favoriteDelete_rpc
   | favorite_favoriteList_rpc
@@ -3297,6 +3609,7 @@ export type rpc =
   | gpgUi_selectKey_rpc
   | gpgUi_sign_rpc
   | gpgUi_wantToAddGPGKey_rpc
+  | identifyBatchUi_identify2BatchResult_rpc
   | identifyUi_confirm_rpc
   | identifyUi_delegateIdentifyUI_rpc
   | identifyUi_displayCryptocurrency_rpc
@@ -3312,8 +3625,11 @@ export type rpc =
   | identifyUi_start_rpc
   | identify_Resolve2_rpc
   | identify_Resolve_rpc
+  | identify_identify2Batch_rpc
   | identify_identify2_rpc
+  | identify_identifyReport_rpc
   | identify_identify_rpc
+  | identify_verifyIdentifyReport_rpc
   | kbfs_FSEvent_rpc
   | logUi_log_rpc
   | log_registerLogger_rpc
@@ -3330,6 +3646,7 @@ export type rpc =
   | login_recoverAccountFromEmailAddress_rpc
   | login_unlockWithPassphrase_rpc
   | login_unlock_rpc
+  | merkle_merkleAudit_rpc
   | metadataUpdate_folderNeedsRekey_rpc
   | metadataUpdate_metadataUpdate_rpc
   | metadata_authenticate_rpc
@@ -3358,6 +3675,7 @@ export type rpc =
   | pgp_pgpExportByFingerprint_rpc
   | pgp_pgpExportByKID_rpc
   | pgp_pgpExport_rpc
+  | pgp_pgpExtend_rpc
   | pgp_pgpImport_rpc
   | pgp_pgpKeyGen_rpc
   | pgp_pgpPull_rpc
@@ -3392,6 +3710,7 @@ export type rpc =
   | saltpackUi_saltpackVerifySuccess_rpc
   | saltpack_saltpackDecrypt_rpc
   | saltpack_saltpackEncrypt_rpc
+  | saltpack_saltpackInspect_rpc
   | saltpack_saltpackSign_rpc
   | saltpack_saltpackVerify_rpc
   | secretUi_getPassphrase_rpc
@@ -3419,6 +3738,7 @@ export type rpc =
   | updateUi_updateQuit_rpc
   | update_updateCheck_rpc
   | update_update_rpc
+  | user_exportChain_rpc
   | user_listTrackersByName_rpc
   | user_listTrackersSelf_rpc
   | user_listTrackers_rpc
@@ -3453,6 +3773,37 @@ export type incomingCallMapType = {
       result: (result: account_passphrasePrompt_result) => void
     }
   ) => void,
+  'keybase.1.account.getPassphrasePolicy'?: (
+    params: {
+      sessionID: int
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: (result: account_getPassphrasePolicy_result) => void
+    }
+  ) => void,
+  'keybase.1.account.accountExport'?: (
+    params: {
+      sessionID: int,
+      passphrase: string
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: (result: account_accountExport_result) => void
+    }
+  ) => void,
+  'keybase.1.account.accountImport'?: (
+    params: {
+      sessionID: int,
+      archive: bytes,
+      passphrase: string,
+      force: boolean
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: () => void
+    }
+  ) => void,
   'keybase.1.block.getSessionChallenge'?: (
     params: {},
     response: {
@@ -3789,10 +4140,41 @@ export type incomingCallMapType = {
       result: (result: device_deviceList_result) => void
     }
   ) => void,
-  'keybase.1.device.deviceAdd'?: (
+  'keybase.1.device.deviceDetailList'?: (
     params: {
       sessionID: int
     },
+    response: {
+      error: (err: RPCError) => void,
+      result: (result: device_deviceDetailList_result) => void
+    }
+  ) => void,
+  'keybase.1.device.setDeviceNote'?: (
+    params: {
+      sessionID: int,
+      deviceID: DeviceID,
+      note: string
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: () => void
+    }
+  ) => void,
+  'keybase.1.device.deviceAdd'?: (
+    params: {
+      sessionID: int,
+      directCode: string
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: () => void
+    }
+  ) => void,
+  'keybase.1.device.rotateDeviceKeys'?: (
+    params: {
+      sessionID: int,
+      pkcs11Token: string
+    },
     response: {
       error: (err: RPCError) => void,
       result: () => void
@@ -3908,6 +4290,27 @@ export type incomingCallMapType = {
       result: (result: identify_identify_result) => void
     }
   ) => void,
+  'keybase.1.identify.identifyReport'?: (
+    params: {
+      sessionID: int,
+      userAssertion: string,
+      forceRemoteCheck: boolean
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: (result: identify_identifyReport_result) => void
+    }
+  ) => void,
+  'keybase.1.identify.verifyIdentifyReport'?: (
+    params: {
+      sessionID: int,
+      report: string
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: (result: identify_verifyIdentifyReport_result) => void
+    }
+  ) => void,
   'keybase.1.identify.identify2'?: (
     params: {
       sessionID: int,
@@ -3925,6 +4328,30 @@ export type incomingCallMapType = {
       result: (result: identify_identify2_result) => void
     }
   ) => void,
+  'keybase.1.identify.identify2Batch'?: (
+    params: {
+      sessionID: int,
+      assertions: Array<string>,
+      reason: IdentifyReason,
+      parallelism: int,
+      forceRemoteCheck: boolean,
+      needProofSet: boolean
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: () => void
+    }
+  ) => void,
+  'keybase.1.identifyBatchUi.identify2BatchResult'?: (
+    params: {
+      sessionID: int,
+      result: Identify2BatchResult
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: () => void
+    }
+  ) => void,
   'keybase.1.identifyUi.delegateIdentifyUI'?: (
     params: {},
     response: {
@@ -4127,7 +4554,8 @@ export type incomingCallMapType = {
       sessionID: int,
       deviceType: string,
       usernameOrEmail: string,
-      clientType: ClientType
+      clientType: ClientType,
+      pkcs11Token: string
     },
     response: {
       error: (err: RPCError) => void,
@@ -4175,7 +4603,9 @@ export type incomingCallMapType = {
   ) => void,
   'keybase.1.login.paperKey'?: (
     params: {
-      sessionID: int
+      sessionID: int,
+      shareThreshold: int,
+      shareCount: int
     },
     response: {
       error: (err: RPCError) => void,
@@ -4224,7 +4654,9 @@ export type incomingCallMapType = {
   'keybase.1.loginUi.displayPaperKeyPhrase'?: (
     params: {
       sessionID: int,
-      phrase: string
+      phrase: string,
+      shares: Array<string>,
+      shareThreshold: int
     },
     response: {
       error: (err: RPCError) => void,
@@ -4241,6 +4673,15 @@ export type incomingCallMapType = {
       result: () => void
     }
   ) => void,
+  'keybase.1.merkle.merkleAudit'?: (
+    params: {
+      sessionID: int
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: (result: merkle_merkleAudit_result) => void
+    }
+  ) => void,
   'keybase.1.metadata.getChallenge'?: (
     params: {},
     response: {
@@ -4440,6 +4881,13 @@ export type incomingCallMapType = {
       result: () => void
     }
   ) => void,
+  'keybase.1.NotifyAudit.merkleForkDetected'?: (
+    params: {
+      fork: MerkleFork
+    } /* ,
+    response: {} // Notify call
+    */
+  ) => void,
   'keybase.1.notifyCtl.setNotifications'?: (
     params: {
       channels: NotificationChannels
@@ -4456,6 +4904,14 @@ export type incomingCallMapType = {
     response: {} // Notify call
     */
   ) => void,
+  'keybase.1.NotifyKeys.keysExpiring'?: (
+    params: {
+      uid: UID,
+      keys: Array<ExpiringKey>
+    } /* ,
+    response: {} // Notify call
+    */
+  ) => void,
   'keybase.1.NotifySession.loggedOut'?: (
     params: {} /* ,
     response: {} // Notify call
@@ -4631,6 +5087,18 @@ export type incomingCallMapType = {
       result: () => void
     }
   ) => void,
+  'keybase.1.pgp.pgpExtend'?: (
+    params: {
+      sessionID: int,
+      all: boolean,
+      fingerprints: Array<string>,
+      days: int
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: () => void
+    }
+  ) => void,
   'keybase.1.pgpUi.outputSignatureSuccess'?: (
     params: {
       sessionID: int,
@@ -4796,7 +5264,8 @@ export type incomingCallMapType = {
       sessionID: int,
       secret: bytes,
       phrase: string,
-      otherDeviceType: DeviceType
+      otherDeviceType: DeviceType,
+      directCode: string
     },
     response: {
       error: (err: RPCError) => void,
@@ -4894,7 +5363,7 @@ export type incomingCallMapType = {
     },
     response: {
       error: (err: RPCError) => void,
-      result: () => void
+      result: (result: saltpack_saltpackEncrypt_result) => void
     }
   ) => void,
   'keybase.1.saltpack.saltpackDecrypt'?: (
@@ -4933,6 +5402,16 @@ export type incomingCallMapType = {
       result: () => void
     }
   ) => void,
+  'keybase.1.saltpack.saltpackInspect'?: (
+    params: {
+      sessionID: int,
+      source: Stream
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: (result: saltpack_saltpackInspect_result) => void
+    }
+  ) => void,
   'keybase.1.saltpackUi.saltpackPromptForDecrypt'?: (
     params: {
       sessionID: int,
@@ -5316,6 +5795,16 @@ export type incomingCallMapType = {
       result: (result: user_listTrackingJSON_result) => void
     }
   ) => void,
+  'keybase.1.user.exportChain'?: (
+    params: {
+      sessionID: int,
+      username: string
+    },
+    response: {
+      error: (err: RPCError) => void,
+      result: (result: user_exportChain_result) => void
+    }
+  ) => void,
   'keybase.1.user.search'?: (
     params: {
       sessionID: int,